@@ -63,6 +63,11 @@ func handleAddCallback(ctx *ext.Context, update *ext.Update) error {
 		return dispatcher.EndGroups
 	}
 
+	if data.Conflict != "" {
+		// carried to the task through the context, see storage.Save
+		ctx.Context = storage.WithConflictPolicy(ctx.Context, data.Conflict)
+	}
+
 	dirPath := ""
 	if data.DirID != 0 {
 		dir, err := database.GetDirByID(ctx, data.DirID)
@@ -108,3 +113,26 @@ func handleAddCallback(ctx *ext.Context, update *ext.Update) error {
 	}
 	return dispatcher.EndGroups
 }
+
+// handleConflictCallback switches the conflict policy of the task to add and updates the storage keyboard
+func handleConflictCallback(ctx *ext.Context, update *ext.Update) error {
+	dataid := strings.Split(string(update.CallbackQuery.Data), " ")[1]
+	data, err := shortcut.GetCallbackDataWithAnswer[tcbdata.Add](ctx, update, dataid)
+	if err != nil {
+		return err
+	}
+	userID := update.CallbackQuery.GetUserID()
+	markup, err := msgelem.BuildAddSelectStorageKeyboard(storage.GetUserStorages(ctx, userID), data)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to build storage keyboard: %s", err)
+		ctx.AnswerCallback(msgelem.AlertCallbackAnswer(update.CallbackQuery.GetQueryID(), i18n.T(i18nk.BotMsgCommonErrorBuildStorageSelectKeyboardFailed, map[string]any{
+			"Error": err.Error(),
+		})))
+		return dispatcher.EndGroups
+	}
+	ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+		ID:          update.CallbackQuery.GetMsgID(),
+		ReplyMarkup: markup,
+	})
+	return dispatcher.EndGroups
+}
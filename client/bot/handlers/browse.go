@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/celestix/gotgproto/dispatcher"
+	"github.com/celestix/gotgproto/ext"
+	"github.com/charmbracelet/log"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/msgelem"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/shortcut"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/strutil"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	storcfg "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/core/tasks/transfer"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+	"github.com/kiss2u/SaveAny-Bot/storage/telegram"
+	"github.com/rs/xid"
+)
+
+// /ls [storage_name:/path]
+func handleLsCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strutil.ParseArgsRespectQuotes(update.EffectiveMessage.Text)
+	userID := update.GetUserChat().GetID()
+	if len(args) < 2 {
+		markup := msgelem.BuildBrowseStorageKeyboard(ctx, storage.GetUserStorages(ctx, userID))
+		if markup == nil {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgBrowseErrorNoListableStorage)), nil)
+			return dispatcher.EndGroups
+		}
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgBrowsePromptSelectStorage)), &ext.ReplyOpts{
+			Markup: markup,
+		})
+		return dispatcher.EndGroups
+	}
+
+	// the path is optional, /ls local1 browses the root of the storage
+	storName, dirPath, _ := strings.Cut(args[1], ":")
+	stor, err := storage.GetStorageByUserIDAndName(ctx, userID, storName)
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorStorageNotFound, map[string]any{
+			"StorageName": storName,
+			"Error":       err,
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	data := tcbdata.Browse{
+		StorageName: stor.Name(),
+		DirPath:     path.Clean("/" + dirPath),
+		Page:        1,
+	}
+	files, err := listBrowseDir(ctx, stor, data.DirPath)
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(err.Error()), nil)
+		return dispatcher.EndGroups
+	}
+	text, entities, markup := msgelem.BuildBrowseDirMessage(ctx, data, files)
+	ctx.SendMessage(update.EffectiveChat().GetID(), &tg.MessagesSendMessageRequest{
+		Message:     text,
+		Entities:    entities,
+		ReplyMarkup: markup,
+	})
+	return dispatcher.EndGroups
+}
+
+func handleBrowseCallback(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(string(update.CallbackQuery.Data))
+	if len(args) < 2 {
+		return dispatcher.EndGroups
+	}
+	data, err := shortcut.GetCallbackDataWithAnswer[tcbdata.Browse](ctx, update, args[1])
+	if err != nil {
+		return err
+	}
+	queryID := update.CallbackQuery.GetQueryID()
+	msgID := update.CallbackQuery.GetMsgID()
+	userID := update.CallbackQuery.GetUserID()
+
+	stor, err := storage.GetStorageByUserIDAndName(ctx, userID, data.StorageName)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to get storage: %s", err)
+		ctx.AnswerCallback(msgelem.AlertCallbackAnswer(queryID, i18n.T(i18nk.BotMsgCommonErrorGetStorageFailed, map[string]any{
+			"Error": err.Error(),
+		})))
+		return dispatcher.EndGroups
+	}
+
+	if data.File == nil {
+		files, err := listBrowseDir(ctx, stor, data.DirPath)
+		if err != nil {
+			ctx.AnswerCallback(msgelem.AlertCallbackAnswer(queryID, err.Error()))
+			return dispatcher.EndGroups
+		}
+		text, entities, markup := msgelem.BuildBrowseDirMessage(ctx, data, files)
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID:          msgID,
+			Message:     text,
+			Entities:    entities,
+			ReplyMarkup: markup,
+		})
+		return dispatcher.EndGroups
+	}
+
+	switch data.Action {
+	case "":
+		text, entities, markup := msgelem.BuildBrowseFileMessage(ctx, data)
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID:          msgID,
+			Message:     text,
+			Entities:    entities,
+			ReplyMarkup: markup,
+		})
+	case tcbdata.BrowseActionInfo:
+		ctx.AnswerCallback(msgelem.AlertCallbackAnswer(queryID, msgelem.BrowseFileInfo(*data.File)))
+	case tcbdata.BrowseActionSend:
+		if err := sendBrowseFile(ctx, userID, stor, *data.File); err != nil {
+			log.FromContext(ctx).Errorf("Failed to send file %s: %s", data.File.Path, err)
+			ctx.AnswerCallback(msgelem.AlertCallbackAnswer(queryID, i18n.T(i18nk.BotMsgBrowseErrorSendFailed, map[string]any{
+				"Error": err.Error(),
+			})))
+		}
+	case tcbdata.BrowseActionTransfer:
+		markup, err := msgelem.BuildAddSelectStorageKeyboard(storage.GetUserStorages(ctx, userID), tcbdata.Add{
+			TaskType:               tasktype.TaskTypeTransfer,
+			TransferSourceStorName: stor.Name(),
+			TransferSourcePath:     data.DirPath,
+			TransferFiles:          []string{data.File.Path},
+		})
+		if err != nil {
+			log.FromContext(ctx).Errorf("Failed to build storage selection keyboard: %s", err)
+			ctx.AnswerCallback(msgelem.AlertCallbackAnswer(queryID, i18n.T(i18nk.BotMsgTransferErrorBuildStorageSelectKeyboardFailed, map[string]any{
+				"Error": err.Error(),
+			})))
+			return dispatcher.EndGroups
+		}
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID: msgID,
+			Message: i18n.T(i18nk.BotMsgTransferInfoFilesSelectStorage, map[string]any{
+				"Count":  1,
+				"SizeMB": fmt.Sprintf("%.2f", float64(data.File.Size)/(1024*1024)),
+			}),
+			ReplyMarkup: markup,
+		})
+	}
+	return dispatcher.EndGroups
+}
+
+// listBrowseDir returns the sorted files in the directory, the error is a message for the user
+func listBrowseDir(ctx *ext.Context, stor storage.Storage, dirPath string) ([]storagetypes.FileInfo, error) {
+	listable, ok := stor.(storage.StorageListable)
+	if !ok {
+		return nil, fmt.Errorf("%s", i18n.T(i18nk.BotMsgBrowseErrorStorageNotListable, map[string]any{
+			"StorageName": stor.Name(),
+		}))
+	}
+	files, err := listable.ListFiles(ctx, dirPath)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to list files in [%s]:%s: %s", stor.Name(), dirPath, err)
+		return nil, fmt.Errorf("%s", i18n.T(i18nk.BotMsgBrowseErrorListFilesFailed, map[string]any{
+			"Error": err.Error(),
+		}))
+	}
+	msgelem.SortBrowseFiles(files)
+	return files, nil
+}
+
+// sendBrowseFile sends the file to the user with a transfer task to telegram.
+// The first telegram storage of the user is used if any, so that its upload options apply.
+func sendBrowseFile(ctx *ext.Context, userID int64, src storage.Storage, file storagetypes.FileInfo) error {
+	if _, ok := src.(storage.StorageReadable); !ok {
+		return fmt.Errorf("%s", i18n.T(i18nk.BotMsgBrowseErrorStorageNotReadable, map[string]any{
+			"StorageName": src.Name(),
+		}))
+	}
+	// the telegram storage sends to the chat given as the first part of the path
+	target, err := storage.GetTelegramStorageByUserID(ctx, userID)
+	targetPath := strconv.FormatInt(userID, 10)
+	if err != nil {
+		target, err = storage.NewStorage(ctx, &storcfg.TelegramStorageConfig{
+			BaseConfig: storcfg.BaseConfig{
+				Name:   storenum.Telegram.String(),
+				Type:   storenum.Telegram.String(),
+				Enable: true,
+			},
+			ChatID: userID,
+		})
+		if err != nil {
+			return err
+		}
+		targetPath = ""
+	}
+
+	replied, err := ctx.SendMessage(userID, &tg.MessagesSendMessageRequest{
+		Message: i18n.T(i18nk.BotMsgBrowseInfoSending, map[string]any{"Name": file.Name}),
+	})
+	if err != nil {
+		return err
+	}
+	// the sent file is not part of the archive of the telegram storage, so it is neither indexed nor renamed
+	injectCtx := tgutil.ExtWithContext(ctx.Context, ctx)
+	injectCtx = storage.WithConflictPolicy(telegram.WithoutIndex(injectCtx), conflict.Overwrite)
+	task := transfer.NewTransferTask(
+		xid.New().String(),
+		injectCtx,
+		userID,
+		[]transfer.TaskElement{*transfer.NewTaskElement(src, file, target, targetPath)},
+		transfer.NewProgressTracker(replied.ID, userID),
+		false,
+		false,
+	)
+	return core.AddTask(injectCtx, task)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/dedup"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/fnamest"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
 )
@@ -28,6 +29,14 @@ func handleConfigCmd(ctx *ext.Context, update *ext.Update) error {
 						},
 					},
 				},
+				{
+					Buttons: []tg.KeyboardButtonClass{
+						&tg.KeyboardButtonCallback{
+							Text: i18n.T(i18nk.BotMsgConfigButtonDedupPolicy),
+							Data: fmt.Appendf(nil, "%s %s", tcbdata.TypeConfig, "dedup"),
+						},
+					},
+				},
 			},
 		},
 	})
@@ -51,6 +60,8 @@ func handleConfigCallback(ctx *ext.Context, update *ext.Update) error {
 	switch args[1] {
 	case "fnamest":
 		return handleConfigFnameSTCallback(ctx, update)
+	case "dedup":
+		return handleConfigDedupCallback(ctx, update)
 	default:
 		return invaildDataAnswer()
 	}
@@ -110,6 +121,55 @@ func handleConfigFnameSTCallback(ctx *ext.Context, update *ext.Update) error {
 	return dispatcher.EndGroups
 }
 
+func handleConfigDedupCallback(ctx *ext.Context, update *ext.Update) error {
+	userID := update.CallbackQuery.GetUserID()
+	user, err := database.GetUserByChatID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	args := strings.Fields(string(update.CallbackQuery.Data))
+	if len(args) == 3 {
+		policy, err := dedup.ParsePolicy(args[2])
+		if err != nil {
+			return err
+		}
+		user.DedupPolicy = policy.String()
+		if err := database.UpdateUser(ctx, user); err != nil {
+			return err
+		}
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID: update.CallbackQuery.GetMsgID(),
+			Message: i18n.T(i18nk.BotMsgConfigInfoDedupPolicySet, map[string]any{
+				"Policy": dedup.GetDisplay(policy, config.C().Lang),
+			}),
+		})
+		return dispatcher.EndGroups
+	}
+	opts := dedup.PolicyValues()
+	buttons := make([]tg.KeyboardButtonClass, 0, len(opts))
+	for _, opt := range opts {
+		buttons = append(buttons, &tg.KeyboardButtonCallback{
+			Text: dedup.GetDisplay(opt, config.C().Lang),
+			Data: fmt.Appendf(nil, "%s %s %s", tcbdata.TypeConfig, "dedup", opt),
+		})
+	}
+	markup := &tg.ReplyInlineMarkup{Rows: []tg.KeyboardButtonRow{
+		{Buttons: buttons},
+	}}
+	current, err := dedup.ParsePolicy(user.DedupPolicy)
+	if err != nil {
+		current = dedup.Default
+	}
+	ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+		ID: update.CallbackQuery.GetMsgID(),
+		Message: i18n.T(i18nk.BotMsgConfigPromptSelectDedupPolicy, map[string]any{
+			"Policy": dedup.GetDisplay(current, config.C().Lang),
+		}),
+		ReplyMarkup: markup,
+	})
+	return dispatcher.EndGroups
+}
+
 func handleConfigFnameTmpl(ctx *ext.Context, update *ext.Update) error {
 	userID := update.GetUserChat().GetID()
 	user, err := database.GetUserByChatID(ctx, userID)
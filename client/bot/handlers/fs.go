@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"path"
+	"strings"
+
+	"github.com/celestix/gotgproto/dispatcher"
+	"github.com/celestix/gotgproto/ext"
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/strutil"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// /rm <storage_name>:/<path>
+func handleRmCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strutil.ParseArgsRespectQuotes(update.EffectiveMessage.Text)
+	if len(args) < 2 {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsRmUsage, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	stor, filePath, ok := getStorageFromArg(ctx, update, args[1])
+	if !ok {
+		return dispatcher.EndGroups
+	}
+	deletable, ok := stor.(storage.StorageDeletable)
+	if !ok {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorDeleteNotSupported, map[string]any{
+			"StorageName": stor.Name(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	if err := deletable.Delete(ctx, filePath); err != nil {
+		log.FromContext(ctx).Errorf("Failed to delete [%s]:%s: %v", stor.Name(), filePath, err)
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsInfoDeleted, map[string]any{
+		"StorageName": stor.Name(),
+		"Path":        filePath,
+	})), nil)
+	return dispatcher.EndGroups
+}
+
+// /mv <storage_name>:/<path> <new_path>
+func handleMvCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strutil.ParseArgsRespectQuotes(update.EffectiveMessage.Text)
+	if len(args) < 3 {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsMvUsage, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	stor, srcPath, ok := getStorageFromArg(ctx, update, args[1])
+	if !ok {
+		return dispatcher.EndGroups
+	}
+	movable, ok := stor.(storage.StorageMovable)
+	if !ok {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorMoveNotSupported, map[string]any{
+			"StorageName": stor.Name(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	// the new path may also be given as storage_name:/path of the same storage
+	dstPath := strings.TrimPrefix(args[2], stor.Name()+":")
+	if strings.HasSuffix(dstPath, "/") {
+		dstPath = path.Join(dstPath, path.Base(srcPath))
+	}
+	dstPath = path.Clean(dstPath)
+
+	target, skip, err := storage.ResolveConflict(ctx, movable, dstPath, 0)
+	if err == nil && !skip {
+		err = movable.Move(ctx, srcPath, target)
+	}
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to move [%s]:%s to %s: %v", stor.Name(), srcPath, dstPath, err)
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	if skip {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsInfoMoveSkipped, map[string]any{
+			"StorageName": stor.Name(),
+			"NewPath":     dstPath,
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsInfoMoved, map[string]any{
+		"StorageName": stor.Name(),
+		"Path":        srcPath,
+		"NewPath":     target,
+	})), nil)
+	return dispatcher.EndGroups
+}
+
+// /mkdir <storage_name>:/<path>
+func handleMkdirCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strutil.ParseArgsRespectQuotes(update.EffectiveMessage.Text)
+	if len(args) < 2 {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsMkdirUsage, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	stor, dirPath, ok := getStorageFromArg(ctx, update, args[1])
+	if !ok {
+		return dispatcher.EndGroups
+	}
+	dirMaker, ok := stor.(storage.StorageDirMaker)
+	if !ok {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorMkdirNotSupported, map[string]any{
+			"StorageName": stor.Name(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	if err := dirMaker.MkDir(ctx, dirPath); err != nil {
+		log.FromContext(ctx).Errorf("Failed to create directory [%s]:%s: %v", stor.Name(), dirPath, err)
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsInfoDirCreated, map[string]any{
+		"StorageName": stor.Name(),
+		"Path":        dirPath,
+	})), nil)
+	return dispatcher.EndGroups
+}
+
+// getStorageFromArg parses the storage_name:/path argument and gets the storage of the user,
+// the error is replied to the user if ok is false.
+func getStorageFromArg(ctx *ext.Context, update *ext.Update, arg string) (stor storage.Storage, storPath string, ok bool) {
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || strings.Trim(parts[1], "/") == "" {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorInvalidPath, nil)), nil)
+		return nil, "", false
+	}
+	stor, err := storage.GetStorageByUserIDAndName(ctx, update.GetUserChat().GetID(), parts[0])
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgFsErrorStorageNotFound, map[string]any{
+			"StorageName": parts[0],
+			"Error":       err,
+		})), nil)
+		return nil, "", false
+	}
+	return stor, path.Clean(parts[1]), true
+}
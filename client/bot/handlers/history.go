@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/celestix/gotgproto/dispatcher"
+	"github.com/celestix/gotgproto/ext"
+	"github.com/charmbracelet/log"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/msgelem"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/shortcut"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
+)
+
+// /history [page] [status=<status>] [type=<task_type>] [storage=<storage_name>] [tag=<tag>]
+func handleHistoryCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(update.EffectiveMessage.Text)[1:]
+	data := tcbdata.History{Page: 1}
+	for _, arg := range args {
+		if page, err := strconv.Atoi(arg); err == nil && page > 0 {
+			data.Page = page
+			continue
+		}
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgHistoryUsage)), nil)
+			return dispatcher.EndGroups
+		}
+		switch strings.ToLower(key) {
+		case "status":
+			switch value {
+			case database.TaskStatusCompleted, database.TaskStatusFailed, database.TaskStatusCancelled:
+				data.Status = value
+			default:
+				ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgHistoryErrorInvalidFilter, map[string]any{
+					"Filter": arg,
+				})), nil)
+				return dispatcher.EndGroups
+			}
+		case "type":
+			typ, err := tasktype.ParseTaskType(value)
+			if err != nil {
+				ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgHistoryErrorInvalidFilter, map[string]any{
+					"Filter": arg,
+				})), nil)
+				return dispatcher.EndGroups
+			}
+			data.TaskType = typ.String()
+		case "storage":
+			data.StorageName = value
+		case "tag":
+			data.Tag = value
+		default:
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgHistoryUsage)), nil)
+			return dispatcher.EndGroups
+		}
+	}
+	histories, total, err := getUserHistoryPage(ctx, update.GetUserChat().GetID(), data)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to get task history: %s", err)
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgHistoryErrorGetHistoryFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	if total == 0 {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgHistoryEmpty)), nil)
+		return dispatcher.EndGroups
+	}
+	text, entities, markup := msgelem.BuildHistoryMessage(ctx, histories, total, data)
+	ctx.SendMessage(update.EffectiveChat().GetID(), &tg.MessagesSendMessageRequest{
+		Message:     text,
+		Entities:    entities,
+		ReplyMarkup: markup,
+	})
+	return dispatcher.EndGroups
+}
+
+func handleHistoryCallback(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(string(update.CallbackQuery.Data))
+	if len(args) < 2 {
+		return dispatcher.EndGroups
+	}
+	data, err := shortcut.GetCallbackDataWithAnswer[tcbdata.History](ctx, update, args[1])
+	if err != nil {
+		return err
+	}
+	userID := update.CallbackQuery.GetUserID()
+	histories, total, err := getUserHistoryPage(ctx, userID, data)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to get task history: %s", err)
+		ctx.AnswerCallback(msgelem.AlertCallbackAnswer(update.CallbackQuery.GetQueryID(), i18n.T(i18nk.BotMsgHistoryErrorGetHistoryFailed, map[string]any{
+			"Error": err.Error(),
+		})))
+		return dispatcher.EndGroups
+	}
+	text, entities, markup := msgelem.BuildHistoryMessage(ctx, histories, total, data)
+	ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+		ID:          update.CallbackQuery.GetMsgID(),
+		Message:     text,
+		Entities:    entities,
+		ReplyMarkup: markup,
+	})
+	return dispatcher.EndGroups
+}
+
+func getUserHistoryPage(ctx *ext.Context, userID int64, data tcbdata.History) ([]database.TaskHistory, int64, error) {
+	filter := database.TaskHistoryFilter{
+		UserID:      userID,
+		Status:      data.Status,
+		Type:        data.TaskType,
+		StorageName: data.StorageName,
+		Tag:         data.Tag,
+	}
+	return database.GetTaskHistories(ctx, filter, (data.Page-1)*msgelem.HistoryPageSize, msgelem.HistoryPageSize)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/celestix/gotgproto/dispatcher"
+	"github.com/celestix/gotgproto/ext"
+	"github.com/charmbracelet/log"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/msgelem"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/config"
+)
+
+// /limit [download|upload <rate>] [storage <storage_name> <rate>]
+func handleLimitCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(update.EffectiveMessage.Text)[1:]
+	if len(args) == 0 {
+		ctx.Reply(update, ext.ReplyTextStyledTextArray(msgelem.BuildBandwidthStatus(bandwidth.GetStatus())), nil)
+		return dispatcher.EndGroups
+	}
+	if len(args) < 2 {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgLimitUsage)), nil)
+		return dispatcher.EndGroups
+	}
+	rate, err := config.ParseBandwidth(args[len(args)-1])
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgLimitInvalidRate, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	switch strings.ToLower(args[0]) {
+	case "download", "dl", "d":
+		bandwidth.SetDownload(rate)
+	case "upload", "ul", "u":
+		bandwidth.SetUpload(rate)
+	case "storage", "s":
+		if len(args) < 3 {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgLimitUsage)), nil)
+			return dispatcher.EndGroups
+		}
+		if err := bandwidth.SetStorage(args[1], rate); err != nil {
+			log.FromContext(ctx).Errorf("Failed to set bandwidth limit of storage %s: %v", args[1], err)
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgLimitSetFailed, map[string]any{
+				"Error": err.Error(),
+			})), nil)
+			return dispatcher.EndGroups
+		}
+	default:
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgLimitUsage)), nil)
+		return dispatcher.EndGroups
+	}
+	opts := append([]styling.StyledTextOption{
+		styling.Plain(i18n.T(i18nk.BotMsgLimitUpdated)),
+	}, msgelem.BuildBandwidthStatus(bandwidth.GetStatus())...)
+	ctx.Reply(update, ext.ReplyTextStyledTextArray(opts), nil)
+	return dispatcher.EndGroups
+}
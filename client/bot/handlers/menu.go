@@ -37,7 +37,7 @@ func showMainMenu(ctx *ext.Context, chatID int64, msgID ...int) error {
 	statusText += "━━━━━━━━━━━━━━\n"
 	statusText += fmt.Sprintf("📥 下载中: %d\n", len(runningTasks))
 	statusText += fmt.Sprintf("⏳ 队列中: %d\n", len(queuedTasks))
-	statusText += fmt.Sprintf("💾 存储: %d\n", len(storage.Storages))
+	statusText += fmt.Sprintf("💾 存储: %d\n", len(storage.GetAllStorages()))
 	statusText += "━━━━━━━━━━━━━━\n\n"
 	statusText += "选择一个操作:"
 
@@ -59,10 +59,10 @@ func showMainMenu(ctx *ext.Context, chatID int64, msgID ...int) error {
 	})
 
 	// Row 2: Quick storage buttons (up to 3)
-	if len(storage.Storages) > 0 {
+	if stors := storage.GetAllStorages(); len(stors) > 0 {
 		var storageButtons []tg.KeyboardButtonClass
 		count := 0
-		for name := range storage.Storages {
+		for name := range stors {
 			if count >= 3 {
 				break
 			}
@@ -167,7 +167,7 @@ func showStatusCallback(ctx *ext.Context, chatID int64, msgID int) error {
 	statusText += fmt.Sprintf("✅ 状态: 运行中\n")
 	statusText += fmt.Sprintf("📥 下载任务: %d\n", len(runningTasks))
 	statusText += fmt.Sprintf("⏳ 队列任务: %d\n", len(queuedTasks))
-	statusText += fmt.Sprintf("💾 存储数量: %d\n", len(storage.Storages))
+	statusText += fmt.Sprintf("💾 存储数量: %d\n", len(storage.GetAllStorages()))
 	statusText += fmt.Sprintf("⚙️ 工作线程: %d\n", config.C().Workers)
 	statusText += fmt.Sprintf("🔄 版本: %s\n", config.Version)
 	statusText += "━━━━━━━━━━━━━━"
@@ -257,12 +257,13 @@ func showTasksCallback(ctx *ext.Context, chatID int64, msgID int) error {
 func showStoragesCallback(ctx *ext.Context, chatID int64, msgID int) error {
 	storagesText := "💾 *存储位置*\n\n"
 
-	for name, s := range storage.Storages {
+	stors := storage.GetAllStorages()
+	for name, s := range stors {
 		storType := s.Type().String()
 		storagesText += fmt.Sprintf("• *%s* (%s)\n", name, storType)
 	}
 
-	if len(storage.Storages) == 0 {
+	if len(stors) == 0 {
 		storagesText += "_暂无存储配置_"
 	}
 
@@ -327,7 +328,7 @@ func handleStorageSelectCallback(ctx *ext.Context, chatID int64, msgID int, call
 	}
 	
 	// Check if storage exists
-	stor, exists := storage.Storages[storageName]
+	stor, exists := storage.GetAllStorages()[storageName]
 	if !exists {
 		_, err := ctx.EditMessage(chatID, &tg.MessagesEditMessageRequest{
 			ID:      msgID,
@@ -27,13 +27,22 @@ var CommandHandlers = []DescCommandHandler{
 	{"storage", i18nk.BotMsgCmdStorage, handleStorageCmd},
 	{"dir", i18nk.BotMsgCmdDir, handleDirCmd},
 	{"rule", i18nk.BotMsgCmdRule, handleRuleCmd},
+	{"export", i18nk.BotMsgCmdExport, handleExportCmd},
+	{"import", i18nk.BotMsgCmdImport, handleImportCmd},
 	{"save", i18nk.BotMsgCmdSave, handleSilentMode(handleSaveCmd, handleSilentSaveReplied)},
 	{"dl", i18nk.BotMsgCmdDl, handleDlCmd},
 	{"aria2dl", i18nk.BotMsgCmdAria2dl, handleAria2DlCmd},
 	{"ytdlp", i18nk.BotMsgCmdYtdlp, handleYtdlpCmd},
 	{"transfer", i18nk.BotMsgCmdTransfer, handleTransferCmd},
+	{"ls", i18nk.BotMsgCmdLs, handleLsCmd},
+	{"rm", i18nk.BotMsgCmdRm, handleRmCmd},
+	{"mv", i18nk.BotMsgCmdMv, handleMvCmd},
+	{"mkdir", i18nk.BotMsgCmdMkdir, handleMkdirCmd},
 	{"task", i18nk.BotMsgCmdTask, handleTaskCmd},
 	{"cancel", i18nk.BotMsgCmdCancel, handleCancelCmd},
+	{"history", i18nk.BotMsgCmdHistory, handleHistoryCmd},
+	{"limit", i18nk.BotMsgCmdLimit, handleLimitCmd},
+	{"reload", i18nk.BotMsgCmdReload, handleReloadCmd},
 	{"config", i18nk.BotMsgCmdConfig, handleConfigCmd},
 	{"fnametmpl", i18nk.BotMsgCmdFnametmpl, handleConfigFnameTmpl},
 	{"help", i18nk.BotMsgCmdHelp, handleHelpCmd},
@@ -59,9 +68,13 @@ func Register(disp dispatcher.Dispatcher) {
 	}
 	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix("update"), handleUpdateCallback))
 	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeAdd), handleAddCallback))
+	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeConflict), handleConflictCallback))
 	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeSetDefault), handleSetDefaultCallback))
 	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeCancel), handleCancelCallback))
 	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeConfig), handleConfigCallback))
+	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeHistory), handleHistoryCallback))
+	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeTask), handleTaskCallback))
+	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix(tcbdata.TypeBrowse), handleBrowseCallback))
 	// Register menu callback handlers
 	disp.AddHandler(handlers.NewCallbackQuery(filters.CallbackQuery.Prefix("menu:"), handleMenuCallback))
 	disp.AddHandler(handlers.NewMessage(sabotfilters.RegexUrl(regexp.MustCompile(re.TgMessageLinkRegexString)), handleSilentMode(handleMessageLink, handleSilentSaveLink)))
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/celestix/gotgproto/dispatcher"
+	"github.com/celestix/gotgproto/ext"
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/msgelem"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/core"
+)
+
+// /reload
+func handleReloadCmd(ctx *ext.Context, update *ext.Update) error {
+	report, err := core.ReloadConfig(ctx)
+	if err != nil {
+		log.FromContext(ctx).Errorf("Failed to reload config: %v", err)
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgReloadFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	ctx.Reply(update, ext.ReplyTextStyledTextArray(msgelem.BuildReloadReport(report)), nil)
+	return dispatcher.EndGroups
+}
@@ -1,20 +1,28 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"strconv"
-	"strings"
 
 	"github.com/celestix/gotgproto/dispatcher"
 	"github.com/celestix/gotgproto/ext"
+	"github.com/celestix/gotgproto/types"
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/slice"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/mediautil"
 	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/msgelem"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/re"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/ruleutil"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/shortcut"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/strutil"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
 	"github.com/kiss2u/SaveAny-Bot/database"
 	"github.com/kiss2u/SaveAny-Bot/pkg/rule"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
 func handleRuleCmd(ctx *ext.Context, update *ext.Update) error {
@@ -45,20 +53,35 @@ func handleRuleCmd(ctx *ext.Context, update *ext.Update) error {
 			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleInfoRuleModeDisabled, nil)), nil)
 		}
 	case "add":
-		// /rule add <type> <data> <storage> <dirpath>
-		if len(args) < 6 {
+		// /rule add <type> <data> [<storage> <dirpath>] [--priority=<n>] [--stop] [actions]
+		rd := &database.Rule{UserID: user.ID}
+		args, err = ruleutil.ParseFlags(args, rd, func(chat string) (int64, error) {
+			return tgutil.ParseChatID(ctx, chat)
+		})
+		if err != nil {
+			var flagErr *ruleutil.FlagError
+			if !errors.As(err, &flagErr) {
+				flagErr = &ruleutil.FlagError{Err: err}
+			}
+			if flagErr.Flag == "--priority" {
+				ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleErrorInvalidPriority, map[string]any{
+					"Priority": flagErr.Value,
+				})), nil)
+				return dispatcher.EndGroups
+			}
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleErrorInvalidAction, map[string]any{
+				"Action": flagErr.Flag,
+				"Error":  flagErr.Err.Error(),
+			})), nil)
+			return dispatcher.EndGroups
+		}
+		// the storage and path can be left out if the rule has actions
+		if len(args) != 6 && !(len(args) == 4 && ruleutil.HasActions(*rd)) {
 			ctx.Reply(update, ext.ReplyTextStyledTextArray(msgelem.BuildRuleHelpStyling(user.ApplyRule, user.Rules)), nil)
 			return dispatcher.EndGroups
 		}
 		ruleTypeArg := args[2]
-		ruleType, err := func() (rule.RuleType, error) {
-			for _, t := range rule.Values() {
-				if strings.EqualFold(t.String(), ruleTypeArg) {
-					return t, nil
-				}
-			}
-			return rule.RuleType(""), fmt.Errorf("invalid rule type: %s\navailable: %v", ruleTypeArg, slice.Join(rule.Values(), ", "))
-		}()
+		ruleType, err := rule.ParseType(ruleTypeArg)
 		if err != nil {
 			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleErrorInvalidRuleType, map[string]any{
 				"Type":      ruleTypeArg,
@@ -66,17 +89,27 @@ func handleRuleCmd(ctx *ext.Context, update *ext.Update) error {
 			})), nil)
 			return dispatcher.EndGroups
 		}
-
 		ruleData := args[3]
-		storageName := args[4]
-		dirPath := args[5]
-
-		rd := &database.Rule{
-			Type:        ruleType.String(),
-			Data:        ruleData,
-			StorageName: storageName,
-			DirPath:     dirPath,
-			UserID:      user.ID,
+		if _, err := rule.NewMatcher(ruleType, ruleData); err != nil {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleErrorInvalidRuleData, map[string]any{
+				"Error": err.Error(),
+			})), nil)
+			return dispatcher.EndGroups
+		}
+		for _, name := range rd.Storages {
+			if _, err := storage.GetStorageByUserIDAndName(ctx, user.ChatID, name); err != nil {
+				ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleErrorInvalidAction, map[string]any{
+					"Action": "--also",
+					"Error":  err.Error(),
+				})), nil)
+				return dispatcher.EndGroups
+			}
+		}
+		rd.Type = ruleType.String()
+		rd.Data = ruleData
+		if len(args) == 6 {
+			rd.StorageName = args[4]
+			rd.DirPath = args[5]
 		}
 		if err := database.CreateRule(ctx, rd); err != nil {
 			logger.Errorf("failed to create rule: %s", err)
@@ -102,9 +135,55 @@ func handleRuleCmd(ctx *ext.Context, update *ext.Update) error {
 			return dispatcher.EndGroups
 		}
 		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleInfoDeleteRuleSuccess, nil)), nil)
+	case "test":
+		// /rule test [<message_link>], or reply to a message
+		return handleRuleTest(ctx, update, user)
 	default:
 		ctx.Reply(update, ext.ReplyTextStyledTextArray(msgelem.BuildRuleHelpStyling(user.ApplyRule, user.Rules)), nil)
 		return dispatcher.EndGroups
 	}
 	return dispatcher.EndGroups
 }
+
+// handleRuleTest shows what the rules do with the files of the replied or linked message, without saving them
+func handleRuleTest(ctx *ext.Context, update *ext.Update, user *database.User) error {
+	var (
+		replied *types.Message
+		files   []tfile.TGFileMessage
+	)
+	replyTo := update.EffectiveMessage.ReplyToMessage
+	switch {
+	case re.TgMessageLinkRegexp.MatchString(tgutil.ExtractMessageEntityUrlsText(update.EffectiveMessage.Message)):
+		linkReplied, linkFiles, _, err := shortcut.GetFilesFromUpdateLinkMessageWithReplyEdit(ctx, update)
+		if err != nil {
+			return err
+		}
+		replied, files = linkReplied, linkFiles
+	case replyTo != nil && replyTo.Message != nil:
+		fileReplied, file, err := shortcut.GetFileFromMessageWithReply(ctx, update, replyTo.Message,
+			mediautil.TfileOptions(ctx, user, replyTo.Message)...)
+		if errors.Is(err, dispatcher.ContinueGroups) {
+			// not a message with a file
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleTestUsage, nil)), nil)
+			return dispatcher.EndGroups
+		}
+		if err != nil {
+			return err
+		}
+		replied, files = fileReplied, []tfile.TGFileMessage{file}
+	default:
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgRuleTestUsage, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	runs := make([]ruleutil.DryRun, 0, len(files))
+	for _, file := range files {
+		runs = append(runs, ruleutil.TestRules(ctx, user, file))
+	}
+	text, entities := msgelem.BuildRuleTestMessage(ctx, runs)
+	ctx.EditMessage(update.EffectiveChat().GetID(), &tg.MessagesEditMessageRequest{
+		ID:       replied.ID,
+		Message:  text,
+		Entities: entities,
+	})
+	return dispatcher.EndGroups
+}
@@ -2,30 +2,33 @@ package handlers
 
 import (
 	"strings"
-	"time"
 
 	"github.com/celestix/gotgproto/dispatcher"
 	"github.com/celestix/gotgproto/ext"
 	"github.com/charmbracelet/log"
 	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/msgelem"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
 )
 
 func handleTaskCmd(ctx *ext.Context, update *ext.Update) error {
 	logger := log.FromContext(ctx)
 	args := strings.Fields(update.EffectiveMessage.Text)
 	if len(args) == 1 {
-		showRunningTasks(ctx, update)
+		showTasks(ctx, update, tcbdata.TaskViewRunning)
 		return dispatcher.EndGroups
 	}
 
 	switch args[1] {
 	case "running", "run", "r":
-		showRunningTasks(ctx, update)
+		showTasks(ctx, update, tcbdata.TaskViewRunning)
 	case "queued", "queue", "q", "waiting":
-		showQueuedTasks(ctx, update)
+		showTasks(ctx, update, tcbdata.TaskViewQueued)
 	case "cancel", "c":
 		if len(args) < 3 {
 			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksUsageCancel)), nil)
@@ -41,74 +44,135 @@ func handleTaskCmd(ctx *ext.Context, update *ext.Update) error {
 			styling.Plain(i18n.T(i18nk.BotMsgTasksCancelRequestedPrefix)),
 			styling.Code(taskID),
 		}), nil)
+	case "pause", "p":
+		if len(args) < 3 {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksUsagePause)), nil)
+			return dispatcher.EndGroups
+		}
+		taskID := args[2]
+		if err := core.PauseTask(ctx, taskID); err != nil {
+			logger.Errorf("Failed to pause task %s: %v", taskID, err)
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksPauseFailed, map[string]any{"Error": err.Error()})), nil)
+			return dispatcher.EndGroups
+		}
+		ctx.Reply(update, ext.ReplyTextStyledTextArray([]styling.StyledTextOption{
+			styling.Plain(i18n.T(i18nk.BotMsgTasksPauseRequestedPrefix)),
+			styling.Code(taskID),
+		}), nil)
+	case "resume":
+		if len(args) < 3 {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksUsageResume)), nil)
+			return dispatcher.EndGroups
+		}
+		taskID := args[2]
+		if err := core.ResumeTask(ctx, taskID); err != nil {
+			logger.Errorf("Failed to resume task %s: %v", taskID, err)
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksResumeFailed, map[string]any{"Error": err.Error()})), nil)
+			return dispatcher.EndGroups
+		}
+		ctx.Reply(update, ext.ReplyTextStyledTextArray([]styling.StyledTextOption{
+			styling.Plain(i18n.T(i18nk.BotMsgTasksResumedPrefix)),
+			styling.Code(taskID),
+		}), nil)
+	case "move", "m":
+		if len(args) < 4 {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksUsageMove)), nil)
+			return dispatcher.EndGroups
+		}
+		taskID := args[2]
+		if err := core.MoveTask(ctx, taskID, queue.MovePosition(strings.ToLower(args[3]))); err != nil {
+			logger.Errorf("Failed to move task %s: %v", taskID, err)
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksMoveFailed, map[string]any{"Error": err.Error()})), nil)
+			return dispatcher.EndGroups
+		}
+		ctx.Reply(update, ext.ReplyTextStyledTextArray([]styling.StyledTextOption{
+			styling.Plain(i18n.T(i18nk.BotMsgTasksMovedPrefix)),
+			styling.Code(taskID),
+		}), nil)
 	default:
 		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksUsage)), nil)
 	}
 	return dispatcher.EndGroups
 }
 
-func showRunningTasks(ctx *ext.Context, update *ext.Update) {
-	tasks := core.GetRunningTasks(ctx)
-	if len(tasks) == 0 {
-		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksRunningEmpty)), nil)
-		return
+func getTasksOfView(ctx *ext.Context, view string) []queue.TaskInfo {
+	if view == tcbdata.TaskViewQueued {
+		return core.GetQueuedTasks(ctx)
 	}
-	opts := make([]styling.StyledTextOption, 0, 2+len(tasks)*4)
-	opts = append(opts,
-		styling.Bold(i18n.T(i18nk.BotMsgTasksRunningTitle)),
-		styling.Plain(i18n.T(i18nk.BotMsgTasksTotalPrefix, map[string]any{"Count": len(tasks)})),
-	)
-	for _, t := range tasks {
-		created := t.Created.In(time.Local).Format("2006-01-02 15:04:05")
-		status := i18n.T(i18nk.BotMsgTasksStatusRunning)
-		if t.Cancelled {
-			status = i18n.T(i18nk.BotMsgTasksStatusCancelRequested)
-		}
-		opts = append(opts,
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldId)),
-			styling.Code(t.ID),
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldTitle)),
-			styling.Code(t.Title),
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldCreated)),
-			styling.Code(created),
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldStatus)),
-			styling.Code(status),
-		)
-	}
-	ctx.Reply(update, ext.ReplyTextStyledTextArray(opts), nil)
+	return core.GetRunningTasks(ctx)
 }
 
-func showQueuedTasks(ctx *ext.Context, update *ext.Update) {
-	tasks := core.GetQueuedTasks(ctx)
+func showTasks(ctx *ext.Context, update *ext.Update, view string) {
+	tasks := getTasksOfView(ctx, view)
 	if len(tasks) == 0 {
-		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksQueuedEmpty)), nil)
+		if view == tcbdata.TaskViewQueued {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksQueuedEmpty)), nil)
+		} else {
+			ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTasksRunningEmpty)), nil)
+		}
 		return
 	}
-	opts := make([]styling.StyledTextOption, 0, 2+len(tasks)*3)
-	opts = append(opts,
-		styling.Bold(i18n.T(i18nk.BotMsgTasksQueuedTitle)),
-		styling.Plain(i18n.T(i18nk.BotMsgTasksTotalPrefix, map[string]any{"Count": len(tasks)})),
-	)
-	for _, t := range tasks {
-		created := t.Created.In(time.Local).Format("2006-01-02 15:04:05")
-		status := i18n.T(i18nk.BotMsgTasksStatusQueued)
-		if t.Cancelled {
-			status = i18n.T(i18nk.BotMsgTasksStatusCancelRequested)
+	text, entities, markup := msgelem.BuildTaskListMessage(ctx, tasks, view)
+	ctx.SendMessage(update.EffectiveChat().GetID(), &tg.MessagesSendMessageRequest{
+		Message:     text,
+		Entities:    entities,
+		ReplyMarkup: markup,
+	})
+}
+
+// handleTaskCallback handles the control buttons of the task list, the data is "task <view> <action> [task_id]"
+func handleTaskCallback(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(string(update.CallbackQuery.Data))
+	if len(args) < 3 {
+		return dispatcher.EndGroups
+	}
+	view, action := args[1], args[2]
+	queryID := update.CallbackQuery.GetQueryID()
+	if action != tcbdata.TaskActionRefresh {
+		if len(args) < 4 {
+			return dispatcher.EndGroups
+		}
+		taskID := args[3]
+		var err error
+		var errKey i18nk.Key
+		switch action {
+		case tcbdata.TaskActionPause:
+			err, errKey = core.PauseTask(ctx, taskID), i18nk.BotMsgTasksPauseFailed
+		case tcbdata.TaskActionResume:
+			err, errKey = core.ResumeTask(ctx, taskID), i18nk.BotMsgTasksResumeFailed
+		case tcbdata.TaskActionCancel:
+			err, errKey = core.CancelTask(ctx, taskID), i18nk.BotMsgTasksCancelFailed
+		case tcbdata.TaskActionTop, tcbdata.TaskActionUp, tcbdata.TaskActionDown:
+			err, errKey = core.MoveTask(ctx, taskID, queue.MovePosition(action)), i18nk.BotMsgTasksMoveFailed
+		default:
+			return dispatcher.EndGroups
 		}
-		opts = append(opts,
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldId)),
-			styling.Code(t.ID),
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldTitle)),
-			styling.Code(t.Title),
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldCreated)),
-			styling.Code(created),
-			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldStatus)),
-			styling.Code(status),
-		)
-		if len(tasks) > 10 {
-			opts = append(opts, styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksTruncatedNote, map[string]any{"Count": len(tasks)})))
-			break
+		if err != nil {
+			log.FromContext(ctx).Errorf("Failed to %s task %s: %v", action, taskID, err)
+			ctx.AnswerCallback(msgelem.AlertCallbackAnswer(queryID, i18n.T(errKey, map[string]any{
+				"Error": err.Error(),
+			})))
+			return dispatcher.EndGroups
 		}
 	}
-	ctx.Reply(update, ext.ReplyTextStyledTextArray(opts), nil)
+	ctx.AnswerCallback(&tg.MessagesSetBotCallbackAnswerRequest{
+		QueryID: queryID,
+		Message: i18n.T(i18nk.BotMsgTasksInfoDone),
+	})
+
+	tasks := getTasksOfView(ctx, view)
+	req := &tg.MessagesEditMessageRequest{
+		ID: update.CallbackQuery.GetMsgID(),
+	}
+	if len(tasks) == 0 {
+		if view == tcbdata.TaskViewQueued {
+			req.Message = i18n.T(i18nk.BotMsgTasksQueuedEmpty)
+		} else {
+			req.Message = i18n.T(i18nk.BotMsgTasksRunningEmpty)
+		}
+	} else {
+		req.Message, req.Entities, req.ReplyMarkup = msgelem.BuildTaskListMessage(ctx, tasks, view)
+	}
+	ctx.EditMessage(update.CallbackQuery.GetUserID(), req)
+	return dispatcher.EndGroups
 }
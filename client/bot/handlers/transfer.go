@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/celestix/gotgproto/dispatcher"
@@ -26,6 +27,9 @@ import (
 func handleTransferCmd(ctx *ext.Context, update *ext.Update) error {
 	logger := log.FromContext(ctx)
 	args := strutil.ParseArgsRespectQuotes(update.EffectiveMessage.Text)
+	// --move deletes the source files after transferring
+	move := slices.Contains(args, "--move")
+	args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--move" })
 
 	if len(args) < 2 {
 		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTransferUsage, nil)), nil)
@@ -72,6 +76,14 @@ func handleTransferCmd(ctx *ext.Context, update *ext.Update) error {
 		return dispatcher.EndGroups
 	}
 
+	// Check if source storage supports deleting when moving
+	if _, ok := sourceStorage.(storage.StorageDeletable); move && !ok {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTransferErrorStorageNotDeletable, map[string]any{
+			"StorageName": sourceStorageName,
+		})), nil)
+		return dispatcher.EndGroups
+	}
+
 	// Fetch file list
 	replied, err := ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgTransferInfoFetchingFiles, nil)), nil)
 	if err != nil {
@@ -135,6 +147,7 @@ func handleTransferCmd(ctx *ext.Context, update *ext.Update) error {
 		TransferSourceStorName: sourceStorageName,
 		TransferSourcePath:     sourcePath,
 		TransferFiles:          filePaths,
+		TransferMove:           move,
 	})
 	if err != nil {
 		logger.Errorf("Failed to build storage selection keyboard: %s", err)
@@ -231,9 +244,11 @@ func handleTransferCallback(ctx *ext.Context, userID int64, targetStorage storag
 	task := transfer.NewTransferTask(
 		taskID,
 		injectCtx,
+		userID,
 		elems,
 		transfer.NewProgressTracker(msgID, userID),
 		true, // IgnoreErrors
+		data.TransferMove,
 	)
 
 	if err := core.AddTask(injectCtx, task); err != nil {
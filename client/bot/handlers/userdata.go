@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/celestix/gotgproto/dispatcher"
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/telegram/message"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/userutil"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/database"
+)
+
+// maxImportSize is the size limit of the files read by /import, an exported document is far smaller
+const maxImportSize = 1024 * 1024
+
+func handleExportCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(update.EffectiveMessage.Text)
+	format := "yaml"
+	if len(args) > 1 {
+		format = strings.ToLower(args[1])
+	}
+	if format != "yaml" && format != "json" {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgExportUsage, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	userID := update.GetUserChat().GetID()
+	user, err := database.GetUserByChatID(ctx, userID)
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgCommonErrorGetUserInfoFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	data, err := userutil.Marshal(userutil.Export(user), format == "json")
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgExportErrorExportFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	filename := fmt.Sprintf("saveany-%d.%s", userID, format)
+	upler := uploader.NewUploader(ctx.Raw)
+	file, err := upler.FromBytes(ctx, filename, data)
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgExportErrorExportFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	mime := "application/yaml"
+	if format == "json" {
+		mime = "application/json"
+	}
+	doc := message.UploadedDocument(file, styling.Plain(i18n.T(i18nk.BotMsgExportInfoExported, nil))).
+		Filename(filename).
+		MIME(mime)
+	peer := ctx.PeerStorage.GetInputPeerById(update.EffectiveChat().GetID())
+	if _, err := ctx.Sender.WithUploader(upler).To(peer).Media(ctx, doc); err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgExportErrorExportFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+	}
+	return dispatcher.EndGroups
+}
+
+func handleImportCmd(ctx *ext.Context, update *ext.Update) error {
+	args := strings.Fields(update.EffectiveMessage.Text)
+	mode := "merge"
+	if len(args) > 1 {
+		mode = strings.ToLower(args[1])
+	}
+	replied := update.EffectiveMessage.ReplyToMessage
+	if (mode != "merge" && mode != "replace") || replied == nil || replied.Media == nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportUsage, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	media, ok := replied.Media.(*tg.MessageMediaDocument)
+	if !ok {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportErrorNoFileInReply, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	document, ok := media.Document.AsNotEmpty()
+	if !ok {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportErrorNoFileInReply, nil)), nil)
+		return dispatcher.EndGroups
+	}
+	if document.Size > maxImportSize {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportErrorFileTooLarge, map[string]any{
+			"Max": maxImportSize,
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	user, err := database.GetUserByChatID(ctx, update.GetUserChat().GetID())
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgCommonErrorGetUserInfoFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	data := bytes.NewBuffer(nil)
+	if _, err := ctx.DownloadMedia(media, ext.DownloadOutputStream{Writer: data}, nil); err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportErrorDownloadFileFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	doc, err := userutil.Parse(data.Bytes())
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportErrorInvalidDocument, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	summary, err := userutil.Import(ctx, user, doc, mode == "replace")
+	if err != nil {
+		ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportErrorImportFailed, map[string]any{
+			"Error": err.Error(),
+		})), nil)
+		return dispatcher.EndGroups
+	}
+	ctx.Reply(update, ext.ReplyTextString(i18n.T(i18nk.BotMsgImportInfoImported, map[string]any{
+		"Dirs":    summary.Dirs,
+		"Rules":   summary.Rules,
+		"Watches": summary.Watches,
+	})), nil)
+	return dispatcher.EndGroups
+}
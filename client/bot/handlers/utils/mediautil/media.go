@@ -59,21 +59,13 @@ func TfileOptions(ctx context.Context, user *database.User, message *tg.Message)
 			fnameOpt = tfile.WithNameIfEmpty(tgutil.GenFileNameFromMessage(*message))
 			break
 		}
-		tmpl, err := template.New("filename").Parse(user.FilenameTemplate)
+		name, err := RenderFilenameTemplate(user.FilenameTemplate, message)
 		if err != nil {
-			log.FromContext(ctx).Errorf("failed to parse filename template: %s", err)
+			log.FromContext(ctx).Errorf("failed to render filename template: %s", err)
 			fnameOpt = tfile.WithNameIfEmpty(tgutil.GenFileNameFromMessage(*message))
 			break
 		}
-		data := BuildFilenameTemplateData(message)
-		var sb strings.Builder
-		err = tmpl.Execute(&sb, data)
-		if err != nil {
-			log.FromContext(ctx).Errorf("failed to execute filename template: %s", err)
-			fnameOpt = tfile.WithNameIfEmpty(tgutil.GenFileNameFromMessage(*message))
-			break
-		}
-		fnameOpt = tfile.WithName(sb.String())
+		fnameOpt = tfile.WithName(name)
 	default:
 		fnameOpt = tfile.WithNameIfEmpty(tgutil.GenFileNameFromMessage(*message))
 	}
@@ -81,6 +73,19 @@ func TfileOptions(ctx context.Context, user *database.User, message *tg.Message)
 	return opts
 }
 
+// RenderFilenameTemplate returns the file name made from the message with the template, see BuildFilenameTemplateData
+func RenderFilenameTemplate(tmplStr string, message *tg.Message) (string, error) {
+	tmpl, err := template.New("filename").Parse(tmplStr)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse filename template: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, BuildFilenameTemplateData(message)); err != nil {
+		return "", fmt.Errorf("failed to execute filename template: %w", err)
+	}
+	return sb.String(), nil
+}
+
 func BuildFilenameTemplateData(message *tg.Message) map[string]string {
 	data := FilenameTemplateData{
 		MsgID: func() string {
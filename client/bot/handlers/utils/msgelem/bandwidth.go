@@ -0,0 +1,45 @@
+package msgelem
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/dustin/go-humanize"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+)
+
+func bandwidthText(bytesPerSec int64) string {
+	if bytesPerSec <= 0 {
+		return i18n.T(i18nk.BotMsgLimitUnlimited)
+	}
+	return humanize.IBytes(uint64(bytesPerSec))
+}
+
+// BuildBandwidthStatus builds the text of the current bandwidth limits
+func BuildBandwidthStatus(st bandwidth.Status) []styling.StyledTextOption {
+	opts := []styling.StyledTextOption{
+		styling.Bold(i18n.T(i18nk.BotMsgLimitTitle)),
+		styling.Plain(i18n.T(i18nk.BotMsgLimitFieldDownload)),
+		styling.Code(bandwidthText(st.Download)),
+		styling.Plain("\n" + i18n.T(i18nk.BotMsgLimitFieldUpload)),
+		styling.Code(bandwidthText(st.Upload)),
+	}
+	for _, name := range slices.Sorted(maps.Keys(st.Storages)) {
+		opts = append(opts,
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgLimitFieldStoragePrefix)),
+			styling.Code(name),
+			styling.Plain(": "),
+			styling.Code(bandwidthText(st.Storages[name])),
+		)
+	}
+	if st.Scheduled {
+		opts = append(opts, styling.Italic(i18n.T(i18nk.BotMsgLimitScheduled, map[string]any{
+			"Download": bandwidthText(st.DownloadBase),
+			"Upload":   bandwidthText(st.UploadBase),
+		})))
+	}
+	return opts
+}
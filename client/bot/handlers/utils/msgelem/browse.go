@@ -0,0 +1,188 @@
+package msgelem
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/dustin/go-humanize"
+	"github.com/gotd/td/telegram/message/entity"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/common/cache"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+	"github.com/rs/xid"
+)
+
+const BrowsePageSize = 10
+
+// browseButton returns the button that switches the browser message to the state of data
+func browseButton(ctx context.Context, text string, data tcbdata.Browse) tg.KeyboardButtonClass {
+	dataid := xid.New().String()
+	if err := cache.Set(dataid, data); err != nil {
+		log.FromContext(ctx).Errorf("Failed to set cache: %s", err)
+	}
+	return &tg.KeyboardButtonCallback{
+		Text: text,
+		Data: fmt.Appendf(nil, "%s %s", tcbdata.TypeBrowse, dataid),
+	}
+}
+
+// BuildBrowseStorageKeyboard builds the keyboard to select one of the listable storages to browse, nil if there is none
+func BuildBrowseStorageKeyboard(ctx context.Context, stors []storage.Storage) *tg.ReplyInlineMarkup {
+	buttons := make([]tg.KeyboardButtonClass, 0, len(stors))
+	for _, stor := range stors {
+		if _, ok := stor.(storage.StorageListable); !ok {
+			continue
+		}
+		buttons = append(buttons, browseButton(ctx, stor.Name(), tcbdata.Browse{
+			StorageName: stor.Name(),
+			DirPath:     "/",
+			Page:        1,
+		}))
+	}
+	if len(buttons) == 0 {
+		return nil
+	}
+	markup := &tg.ReplyInlineMarkup{}
+	for i := 0; i < len(buttons); i += 3 {
+		markup.Rows = append(markup.Rows, tg.KeyboardButtonRow{Buttons: buttons[i:min(i+3, len(buttons))]})
+	}
+	return markup
+}
+
+// SortBrowseFiles sorts the files for browsing, directories first then by name
+func SortBrowseFiles(files []storagetypes.FileInfo) {
+	slices.SortFunc(files, func(a, b storagetypes.FileInfo) int {
+		if a.IsDir != b.IsDir {
+			if a.IsDir {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+}
+
+// BuildBrowseDirMessage builds the text and keyboard of a page of the sorted files in the directory,
+// the DirPath of data should be cleaned and start with "/".
+func BuildBrowseDirMessage(ctx context.Context, data tcbdata.Browse, files []storagetypes.FileInfo) (string, []tg.MessageEntityClass, *tg.ReplyInlineMarkup) {
+	pages := max((len(files)+BrowsePageSize-1)/BrowsePageSize, 1)
+	data.Page = min(max(data.Page, 1), pages)
+
+	opts := []styling.StyledTextOption{
+		styling.Bold(i18n.T(i18nk.BotMsgBrowseDirTitle, map[string]any{
+			"StorageName": data.StorageName,
+			"Path":        data.DirPath,
+		})),
+	}
+	if len(files) == 0 {
+		opts = append(opts, styling.Plain(i18n.T(i18nk.BotMsgBrowseEmpty)))
+	} else {
+		opts = append(opts, styling.Plain(i18n.T(i18nk.BotMsgBrowsePageInfo, map[string]any{
+			"Page":  data.Page,
+			"Pages": pages,
+			"Count": len(files),
+		})))
+	}
+	entityBuilder := entity.Builder{}
+	if err := styling.Perform(&entityBuilder, opts...); err != nil {
+		log.FromContext(ctx).Errorf("Failed to build entities: %s", err)
+	}
+	text, entities := entityBuilder.Complete()
+
+	markup := &tg.ReplyInlineMarkup{}
+	start := (data.Page - 1) * BrowsePageSize
+	for _, file := range files[start:min(start+BrowsePageSize, len(files))] {
+		next := tcbdata.Browse{StorageName: data.StorageName, DirPath: file.Path, Page: 1}
+		label := "📁 " + file.Name
+		if !file.IsDir {
+			next = data
+			next.File = &file
+			label = fmt.Sprintf("📄 %s · %s", file.Name, humanize.IBytes(uint64(max(file.Size, 0))))
+		}
+		markup.Rows = append(markup.Rows, tg.KeyboardButtonRow{
+			Buttons: []tg.KeyboardButtonClass{browseButton(ctx, label, next)},
+		})
+	}
+
+	nav := make([]tg.KeyboardButtonClass, 0, 3)
+	if data.Page > 1 {
+		prev := data
+		prev.Page--
+		nav = append(nav, browseButton(ctx, i18n.T(i18nk.BotMsgBrowseButtonPrev), prev))
+	}
+	if parent := path.Dir(data.DirPath); parent != data.DirPath {
+		nav = append(nav, browseButton(ctx, i18n.T(i18nk.BotMsgBrowseButtonUp), tcbdata.Browse{
+			StorageName: data.StorageName,
+			DirPath:     parent,
+			Page:        1,
+		}))
+	}
+	if data.Page < pages {
+		next := data
+		next.Page++
+		nav = append(nav, browseButton(ctx, i18n.T(i18nk.BotMsgBrowseButtonNext), next))
+	}
+	if len(nav) > 0 {
+		markup.Rows = append(markup.Rows, tg.KeyboardButtonRow{Buttons: nav})
+	}
+	return text, entities, markup
+}
+
+// BuildBrowseFileMessage builds the text and the action keyboard of the file selected in data
+func BuildBrowseFileMessage(ctx context.Context, data tcbdata.Browse) (string, []tg.MessageEntityClass, *tg.ReplyInlineMarkup) {
+	entityBuilder := entity.Builder{}
+	if err := styling.Perform(&entityBuilder,
+		styling.Bold(i18n.T(i18nk.BotMsgBrowseFileTitle, map[string]any{
+			"StorageName": data.StorageName,
+			"Path":        data.File.Path,
+		})),
+	); err != nil {
+		log.FromContext(ctx).Errorf("Failed to build entities: %s", err)
+	}
+	text, entities := entityBuilder.Complete()
+
+	action := func(text, act string) tg.KeyboardButtonClass {
+		actData := data
+		actData.Action = act
+		return browseButton(ctx, text, actData)
+	}
+	back := data
+	back.File = nil
+	markup := &tg.ReplyInlineMarkup{
+		Rows: []tg.KeyboardButtonRow{
+			{Buttons: []tg.KeyboardButtonClass{
+				action(i18n.T(i18nk.BotMsgBrowseButtonSend), tcbdata.BrowseActionSend),
+				action(i18n.T(i18nk.BotMsgBrowseButtonInfo), tcbdata.BrowseActionInfo),
+			}},
+			{Buttons: []tg.KeyboardButtonClass{
+				action(i18n.T(i18nk.BotMsgBrowseButtonTransfer), tcbdata.BrowseActionTransfer),
+			}},
+			{Buttons: []tg.KeyboardButtonClass{
+				browseButton(ctx, i18n.T(i18nk.BotMsgBrowseButtonBack), back),
+			}},
+		},
+	}
+	return text, entities, markup
+}
+
+// BrowseFileInfo returns the details of the file shown by the info action
+func BrowseFileInfo(file storagetypes.FileInfo) string {
+	modTime := "-"
+	if !file.ModTime.IsZero() {
+		modTime = file.ModTime.Local().Format("2006-01-02 15:04:05")
+	}
+	return i18n.T(i18nk.BotMsgBrowseFileInfo, map[string]any{
+		"Name":    file.Name,
+		"Size":    humanize.IBytes(uint64(max(file.Size, 0))),
+		"ModTime": modTime,
+	})
+}
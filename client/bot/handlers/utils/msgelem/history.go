@@ -0,0 +1,112 @@
+package msgelem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/dustin/go-humanize"
+	"github.com/gotd/td/telegram/message/entity"
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/common/cache"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
+	"github.com/rs/xid"
+)
+
+const HistoryPageSize = 10
+
+func historyStatusText(status string) string {
+	switch status {
+	case database.TaskStatusCompleted:
+		return "✅ " + i18n.T(i18nk.BotMsgHistoryStatusCompleted)
+	case database.TaskStatusFailed:
+		return "❌ " + i18n.T(i18nk.BotMsgHistoryStatusFailed)
+	case database.TaskStatusCancelled:
+		return "🚫 " + i18n.T(i18nk.BotMsgHistoryStatusCancelled)
+	default:
+		return status
+	}
+}
+
+// BuildHistoryMessage builds the text and paging keyboard of a task history page
+func BuildHistoryMessage(ctx context.Context, histories []database.TaskHistory, total int64, data tcbdata.History) (string, []tg.MessageEntityClass, tg.ReplyMarkupClass) {
+	pages := int((total + HistoryPageSize - 1) / HistoryPageSize)
+	opts := make([]styling.StyledTextOption, 0, 2+len(histories)*14)
+	opts = append(opts,
+		styling.Bold(i18n.T(i18nk.BotMsgHistoryTitle)),
+		styling.Plain(i18n.T(i18nk.BotMsgHistoryPageInfo, map[string]any{
+			"Page":  data.Page,
+			"Pages": pages,
+			"Count": total,
+		})),
+	)
+	for _, h := range histories {
+		opts = append(opts,
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldTitle)),
+			styling.Code(h.Title),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldId)),
+			styling.Code(h.TaskID),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgHistoryFieldPath)),
+			styling.Code(fmt.Sprintf("[%s]:%s", h.StorageName, h.StoragePath)),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgHistoryFieldSize)),
+			styling.Code(humanize.IBytes(uint64(max(h.Bytes, 0)))),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgHistoryFieldDuration)),
+			styling.Code(h.Duration.Round(time.Second).String()),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgHistoryFieldFinished)),
+			styling.Code(h.CreatedAt.In(time.Local).Format("2006-01-02 15:04:05")),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldStatus)),
+			styling.Plain(historyStatusText(h.Status)),
+		)
+		if h.Error != "" {
+			opts = append(opts,
+				styling.Plain("\n"+i18n.T(i18nk.BotMsgHistoryFieldError)),
+				styling.Code(h.Error),
+			)
+		}
+		if len(h.Tags) > 0 {
+			opts = append(opts,
+				styling.Plain("\n"+i18n.T(i18nk.BotMsgHistoryFieldTags)),
+				styling.Code(strings.Join(h.Tags, ", ")),
+			)
+		}
+		opts = append(opts, styling.Plain("\n"))
+	}
+	entityBuilder := entity.Builder{}
+	if err := styling.Perform(&entityBuilder, opts...); err != nil {
+		log.FromContext(ctx).Errorf("Failed to build entities: %s", err)
+	}
+	text, entities := entityBuilder.Complete()
+
+	buttons := make([]tg.KeyboardButtonClass, 0, 2)
+	pageButton := func(text string, page int) {
+		pageData := data
+		pageData.Page = page
+		dataid := xid.New().String()
+		if err := cache.Set(dataid, pageData); err != nil {
+			log.FromContext(ctx).Errorf("Failed to set cache: %s", err)
+			return
+		}
+		buttons = append(buttons, &tg.KeyboardButtonCallback{
+			Text: text,
+			Data: fmt.Appendf(nil, "%s %s", tcbdata.TypeHistory, dataid),
+		})
+	}
+	if data.Page > 1 {
+		pageButton(i18n.T(i18nk.BotMsgHistoryButtonPrev), data.Page-1)
+	}
+	if data.Page < pages {
+		pageButton(i18n.T(i18nk.BotMsgHistoryButtonNext), data.Page+1)
+	}
+	if len(buttons) == 0 {
+		return text, entities, nil
+	}
+	return text, entities, &tg.ReplyInlineMarkup{
+		Rows: []tg.KeyboardButtonRow{{Buttons: buttons}},
+	}
+}
@@ -0,0 +1,44 @@
+package msgelem
+
+import (
+	"strings"
+
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/core"
+)
+
+// BuildReloadReport builds the text of the changes applied by reloading the config
+func BuildReloadReport(report core.ReloadReport) []styling.StyledTextOption {
+	if !report.Changed() && len(report.Errors) == 0 {
+		return []styling.StyledTextOption{styling.Plain(i18n.T(i18nk.BotMsgReloadUnchanged))}
+	}
+	opts := []styling.StyledTextOption{styling.Bold(i18n.T(i18nk.BotMsgReloadTitle))}
+	lines := []struct {
+		prefix i18nk.Key
+		keys   []string
+	}{
+		{i18nk.BotMsgReloadAppliedPrefix, report.Applied},
+		{i18nk.BotMsgReloadRestartPrefix, report.Restart},
+		{i18nk.BotMsgReloadStoragesAddedPrefix, report.Storages.Added},
+		{i18nk.BotMsgReloadStoragesChangedPrefix, report.Storages.Changed},
+		{i18nk.BotMsgReloadStoragesRemovedPrefix, report.Storages.Removed},
+	}
+	for _, line := range lines {
+		if len(line.keys) == 0 {
+			continue
+		}
+		opts = append(opts,
+			styling.Plain(i18n.T(line.prefix)),
+			styling.Code(strings.Join(line.keys, ", ")),
+		)
+	}
+	if len(report.Errors) > 0 {
+		opts = append(opts, styling.Plain(i18n.T(i18nk.BotMsgReloadErrorsPrefix)))
+		for _, e := range report.Errors {
+			opts = append(opts, styling.Plain("\n"), styling.Code(e))
+		}
+	}
+	return opts
+}
@@ -1,10 +1,16 @@
 package msgelem
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/charmbracelet/log"
+	"github.com/gotd/td/telegram/message/entity"
 	"github.com/gotd/td/telegram/message/styling"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/ruleutil"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/database"
@@ -26,14 +32,107 @@ func BuildRuleHelpStyling(enabled bool, rules []database.Rule) []styling.StyledT
 		styling.Plain(i18n.T(i18nk.BotMsgRuleHelpAddSuffix, nil)),
 		styling.Code("del"),
 		styling.Plain(i18n.T(i18nk.BotMsgRuleHelpDelSuffix, nil)),
+		styling.Code("test"),
+		styling.Plain(i18n.T(i18nk.BotMsgRuleHelpTestSuffix, nil)),
+		styling.Plain(i18n.T(i18nk.BotMsgRuleHelpExprPrefix, nil)),
+		styling.Code(`/rule add EXPR "FILENAME-REGEX('\.mp4$') and not IS-ALBUM(true)" CHOSEN videos`),
+		styling.Plain(i18n.T(i18nk.BotMsgRuleHelpActions, nil)),
 		styling.Plain(i18n.T(i18nk.BotMsgRuleHelpExistingRulesPrefix, nil)),
 		styling.Blockquote(func() string {
 			var sb strings.Builder
-			for _, rule := range rules {
-				ruleText := fmt.Sprintf("%s %s %s %s", rule.Type, rule.Data, rule.StorageName, rule.DirPath)
-				sb.WriteString(fmt.Sprintf("%d: %s\n", rule.ID, ruleText))
+			for _, rule := range ruleutil.SortRules(rules) {
+				sb.WriteString(fmt.Sprintf("%d: %s\n", rule.ID, ruleutil.FormatRule(rule)))
 			}
 			return sb.String()
 		}(), true),
 	}
 }
+
+// BuildRuleTestMessage tells what the rules do with the files of the dry runs
+func BuildRuleTestMessage(ctx context.Context, runs []ruleutil.DryRun) (string, []tg.MessageEntityClass) {
+	opts := make([]styling.StyledTextOption, 0, len(runs)*16)
+	for i, run := range runs {
+		if i > 0 {
+			opts = append(opts, styling.Plain("\n\n"))
+		}
+		opts = append(opts,
+			styling.Bold(i18n.T(i18nk.BotMsgRuleTestTitle, nil)),
+			styling.Code(run.FileName),
+		)
+		if !run.Applied {
+			opts = append(opts, styling.Italic(i18n.T(i18nk.BotMsgRuleTestModeDisabled, nil)))
+		}
+		if run.Result.Matched() {
+			var sb strings.Builder
+			for _, rule := range run.Result.Matches {
+				sb.WriteString(fmt.Sprintf("%d: %s\n", rule.ID, ruleutil.FormatRule(rule)))
+			}
+			opts = append(opts,
+				styling.Plain(i18n.T(i18nk.BotMsgRuleTestMatched, nil)),
+				styling.Blockquote(strings.TrimSuffix(sb.String(), "\n"), true),
+			)
+		} else {
+			opts = append(opts, styling.Plain(i18n.T(i18nk.BotMsgRuleTestNoMatch, nil)))
+		}
+		opts = append(opts, buildDryRunTarget(run)...)
+	}
+	entityBuilder := entity.Builder{}
+	if err := styling.Perform(&entityBuilder, opts...); err != nil {
+		log.FromContext(ctx).Errorf("Failed to build entities: %s", err)
+	}
+	return entityBuilder.Complete()
+}
+
+func buildDryRunTarget(run ruleutil.DryRun) []styling.StyledTextOption {
+	if run.Err != nil {
+		return []styling.StyledTextOption{styling.Plain(i18n.T(i18nk.BotMsgRuleTestError, map[string]any{
+			"Error": run.Err.Error(),
+		}))}
+	}
+	target := run.Target
+	if target.Skip {
+		return []styling.StyledTextOption{styling.Bold(i18n.T(i18nk.BotMsgRuleTestSkipped, nil))}
+	}
+	if target.Storage == nil {
+		return []styling.StyledTextOption{styling.Plain(i18n.T(i18nk.BotMsgRuleTestNoStorage, nil))}
+	}
+	opts := []styling.StyledTextOption{
+		styling.Plain(i18n.T(i18nk.BotMsgRuleTestFieldStorage, nil)),
+		styling.Code(target.Storage.Name()),
+		styling.Plain(i18n.T(i18nk.BotMsgRuleTestFieldPath, nil)),
+		styling.Code(run.Path),
+	}
+	if len(target.Also) > 0 {
+		names := make([]string, 0, len(target.Also))
+		for _, stor := range target.Also {
+			names = append(names, stor.Name())
+		}
+		opts = append(opts,
+			styling.Plain(i18n.T(i18nk.BotMsgRuleTestFieldAlso, nil)),
+			styling.Code(strings.Join(names, ", ")),
+		)
+	}
+	if target.Conflict != "" {
+		opts = append(opts,
+			styling.Plain(i18n.T(i18nk.BotMsgRuleTestFieldConflict, nil)),
+			styling.Code(target.Conflict.String()),
+		)
+	}
+	if len(target.Extras.Tags) > 0 {
+		opts = append(opts,
+			styling.Plain(i18n.T(i18nk.BotMsgRuleTestFieldTags, nil)),
+			styling.Code(strings.Join(target.Extras.Tags, ", ")),
+		)
+	}
+	if len(target.Extras.NotifyChats) > 0 {
+		chats := make([]string, 0, len(target.Extras.NotifyChats))
+		for _, chatID := range target.Extras.NotifyChats {
+			chats = append(chats, strconv.FormatInt(chatID, 10))
+		}
+		opts = append(opts,
+			styling.Plain(i18n.T(i18nk.BotMsgRuleTestFieldNotify, nil)),
+			styling.Code(strings.Join(chats, ", ")),
+		)
+	}
+	return opts
+}
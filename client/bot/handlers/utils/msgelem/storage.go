@@ -11,7 +11,9 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/cache"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
@@ -38,6 +40,7 @@ func BuildAddSelectStorageKeyboard(stors []storage.Storage, adddata tcbdata.Add)
 		data := tcbdata.Add{
 			TaskType:         taskType,
 			SelectedStorName: storage.Name(),
+			Conflict:         adddata.Conflict,
 
 			Files:   adddata.Files,
 			AsBatch: len(adddata.Files) > 1,
@@ -57,6 +60,7 @@ func BuildAddSelectStorageKeyboard(stors []storage.Storage, adddata tcbdata.Add)
 			TransferSourceStorName: adddata.TransferSourceStorName,
 			TransferSourcePath:     adddata.TransferSourcePath,
 			TransferFiles:          adddata.TransferFiles,
+			TransferMove:           adddata.TransferMove,
 		}
 		dataid := xid.New().String()
 		err := cache.Set(dataid, data)
@@ -74,9 +78,45 @@ func BuildAddSelectStorageKeyboard(stors []storage.Storage, adddata tcbdata.Add)
 		row.Buttons = buttons[i:min(i+3, len(buttons))]
 		markup.Rows = append(markup.Rows, row)
 	}
+	// the button switches the conflict policy of the task to the next one
+	conflictData := adddata
+	conflictData.TaskType = taskType
+	conflictData.Conflict = nextConflictPolicy(adddata.Conflict)
+	dataid := xid.New().String()
+	if err := cache.Set(dataid, conflictData); err != nil {
+		return nil, err
+	}
+	policy := i18n.T(i18nk.BotMsgStorageConflictPolicyDefault, nil)
+	if adddata.Conflict != "" {
+		policy = conflict.GetDisplay(adddata.Conflict, config.C().Lang)
+	}
+	markup.Rows = append(markup.Rows, tg.KeyboardButtonRow{
+		Buttons: []tg.KeyboardButtonClass{
+			&tg.KeyboardButtonCallback{
+				Text: i18n.T(i18nk.BotMsgStorageButtonConflictPolicy, map[string]any{
+					"Policy": policy,
+				}),
+				Data: fmt.Appendf(nil, "%s %s", tcbdata.TypeConflict, dataid),
+			},
+		},
+	})
 	return markup, nil
 }
 
+// nextConflictPolicy cycles through the storage default and all the conflict policies
+func nextConflictPolicy(p conflict.Policy) conflict.Policy {
+	policies := conflict.PolicyValues()
+	if p == "" {
+		return policies[0]
+	}
+	for i, policy := range policies {
+		if policy == p && i+1 < len(policies) {
+			return policies[i+1]
+		}
+	}
+	return ""
+}
+
 func BuildAddOneSelectStorageMessage(ctx context.Context, stors []storage.Storage, file tfile.TGFileMessage, msgId int) (*tg.MessagesEditMessageRequest, error) {
 	eb := entity.Builder{}
 	var entities []tg.MessageEntityClass
@@ -2,7 +2,9 @@ package msgelem
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/gotd/td/telegram/message/entity"
@@ -10,6 +12,8 @@ import (
 	"github.com/gotd/td/tg"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tcbdata"
 )
 
 func BuildTaskAddedEntities(
@@ -36,3 +40,93 @@ func BuildTaskAddedEntities(
 	}
 	return text, entities
 }
+
+// max number of tasks shown in the task list
+const TaskListLimit = 10
+
+func taskButton(text, view, action, taskID string) tg.KeyboardButtonClass {
+	return &tg.KeyboardButtonCallback{
+		Text: text,
+		Data: fmt.Appendf(nil, "%s %s %s %s", tcbdata.TypeTask, view, action, taskID),
+	}
+}
+
+func taskStatusText(t queue.TaskInfo, queued bool) string {
+	switch {
+	case t.Cancelled:
+		return i18n.T(i18nk.BotMsgTasksStatusCancelRequested)
+	case t.Paused && queued:
+		return i18n.T(i18nk.BotMsgTasksStatusPaused)
+	case t.Paused:
+		return i18n.T(i18nk.BotMsgTasksStatusPauseRequested)
+	case queued:
+		return i18n.T(i18nk.BotMsgTasksStatusQueued)
+	default:
+		return i18n.T(i18nk.BotMsgTasksStatusRunning)
+	}
+}
+
+// BuildTaskListMessage builds the running or queued task list with the control buttons of each task.
+// view is tcbdata.TaskViewRunning or tcbdata.TaskViewQueued
+func BuildTaskListMessage(ctx context.Context, tasks []queue.TaskInfo, view string) (string, []tg.MessageEntityClass, tg.ReplyMarkupClass) {
+	queued := view == tcbdata.TaskViewQueued
+	title := i18n.T(i18nk.BotMsgTasksRunningTitle)
+	if queued {
+		title = i18n.T(i18nk.BotMsgTasksQueuedTitle)
+	}
+	shown := tasks[:min(len(tasks), TaskListLimit)]
+	opts := make([]styling.StyledTextOption, 0, 3+len(shown)*9)
+	opts = append(opts,
+		styling.Bold(title),
+		styling.Plain(i18n.T(i18nk.BotMsgTasksTotalPrefix, map[string]any{"Count": len(tasks)})),
+	)
+	rows := make([]tg.KeyboardButtonRow, 0, len(shown)+1)
+	for i, t := range shown {
+		index := map[string]any{"Index": i + 1}
+		opts = append(opts,
+			styling.Bold(fmt.Sprintf("\n#%d", i+1)),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldId)),
+			styling.Code(t.ID),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldTitle)),
+			styling.Code(t.Title),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldCreated)),
+			styling.Code(t.Created.In(time.Local).Format("2006-01-02 15:04:05")),
+			styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksFieldStatus)),
+			styling.Code(taskStatusText(t, queued)),
+		)
+		if t.Cancelled {
+			continue
+		}
+		buttons := make([]tg.KeyboardButtonClass, 0, 5)
+		if queued {
+			buttons = append(buttons,
+				taskButton(i18n.T(i18nk.BotMsgTasksButtonTop, index), view, tcbdata.TaskActionTop, t.ID),
+				taskButton(i18n.T(i18nk.BotMsgTasksButtonUp, index), view, tcbdata.TaskActionUp, t.ID),
+				taskButton(i18n.T(i18nk.BotMsgTasksButtonDown, index), view, tcbdata.TaskActionDown, t.ID),
+			)
+		}
+		if t.Paused {
+			buttons = append(buttons, taskButton(i18n.T(i18nk.BotMsgTasksButtonResume, index), view, tcbdata.TaskActionResume, t.ID))
+		} else {
+			buttons = append(buttons, taskButton(i18n.T(i18nk.BotMsgTasksButtonPause, index), view, tcbdata.TaskActionPause, t.ID))
+		}
+		buttons = append(buttons, taskButton(i18n.T(i18nk.BotMsgTasksButtonCancel, index), view, tcbdata.TaskActionCancel, t.ID))
+		rows = append(rows, tg.KeyboardButtonRow{Buttons: buttons})
+	}
+	if len(tasks) > len(shown) {
+		opts = append(opts, styling.Plain("\n"+i18n.T(i18nk.BotMsgTasksTruncatedNote, map[string]any{"Count": len(tasks)})))
+	}
+	rows = append(rows, tg.KeyboardButtonRow{Buttons: []tg.KeyboardButtonClass{
+		&tg.KeyboardButtonCallback{
+			Text: i18n.T(i18nk.BotMsgTasksButtonRefresh),
+			Data: fmt.Appendf(nil, "%s %s %s", tcbdata.TypeTask, view, tcbdata.TaskActionRefresh),
+		},
+	}})
+
+	entityBuilder := entity.Builder{}
+	if err := styling.Perform(&entityBuilder, opts...); err != nil {
+		log.FromContext(ctx).Errorf("Failed to build entities: %s", err)
+	}
+	text, entities := entityBuilder.Complete()
+	return text, entities, &tg.ReplyInlineMarkup{Rows: rows}
+}
@@ -0,0 +1,45 @@
+package ruleutil
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// DryRun is what saving a file to the default storage of a user would do, made by TestRules
+type DryRun struct {
+	FileName string // the name of the file before the rules rename it
+	Applied  bool   // whether the user applies the rules, the file is saved as if no rule matches if not
+	Result   Result // the rules are evaluated even if the user does not apply them
+	Target   Target // Storage is nil if the user has no default storage and no matched rule chooses one
+	Path     string // the path the file is saved to in the storages
+	Err      error  // the target can not be resolved, like a matched rule chooses a storage which is not available
+}
+
+// TestRules evaluates the rules of the user on the file and tells where the file would be saved to,
+// like it is saved by a watched chat, without adding a task. The file is renamed if a matched rule has a filename template.
+func TestRules(ctx context.Context, user *database.User, file tfile.TGFileMessage) DryRun {
+	run := DryRun{
+		FileName: file.Name(),
+		Applied:  user.ApplyRule,
+		Result:   ApplyRule(ctx, user.Rules, NewInput(file)),
+	}
+	if user.DefaultStorage != "" {
+		// the rules can be tested without the default storage
+		run.Target.Storage, _ = storage.GetStorageByUserIDAndName(ctx, user.ChatID, user.DefaultStorage)
+	}
+	if user.ApplyRule {
+		run.Target, run.Err = applyResult(ctx, user, file, run.Target, run.Result)
+	}
+	dirPath := run.Target.DirPath.String()
+	if run.Target.DirPath.NeedNewForAlbum() {
+		// the album is saved in a directory named after its first file, this one is taken as the first
+		dirPath = strings.TrimSuffix(path.Base(file.Name()), path.Ext(file.Name()))
+	}
+	run.Path = path.Join(dirPath, file.Name())
+	return run
+}
@@ -0,0 +1,222 @@
+package ruleutil
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/rule"
+)
+
+var (
+	ErrUnknownFlag = errors.New("unknown flag")
+	ErrInvalidTag  = errors.New("tags may only contain letters, digits, '_', '-' and '.'")
+)
+
+// FlagError tells the flag of a rule which is invalid
+type FlagError struct {
+	Flag  string
+	Value string
+	Err   error
+}
+
+func (e *FlagError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Flag, e.Err)
+}
+
+func (e *FlagError) Unwrap() error {
+	return e.Err
+}
+
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}_.\-]+$`)
+
+// ParseFlags takes the flags, like --stop and --rename=<template>, out of the args and sets them on r.
+// The args which are not flags are returned in order. The chat of --notify is resolved with resolveChat,
+// the storages of --also are not checked. The error is a *FlagError.
+func ParseFlags(args []string, r *database.Rule, resolveChat func(string) (int64, error)) ([]string, error) {
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+			continue
+		}
+		flag, value, _ := strings.Cut(arg, "=")
+		if err := setFlag(r, flag, value, resolveChat); err != nil {
+			return nil, &FlagError{Flag: flag, Value: value, Err: err}
+		}
+	}
+	return rest, nil
+}
+
+func setFlag(r *database.Rule, flag, value string, resolveChat func(string) (int64, error)) error {
+	switch flag {
+	case "--stop":
+		r.Stop = true
+	case "--skip":
+		r.Skip = true
+	case "--priority":
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		r.Priority = priority
+	case "--rename":
+		if err := validateTemplate(value); err != nil {
+			return err
+		}
+		r.FilenameTemplate = value
+	case "--conflict":
+		policy, err := conflict.ParsePolicy(value)
+		if err != nil {
+			return err
+		}
+		r.Conflict = policy.String()
+	case "--also":
+		storages := splitFlagList(value)
+		if len(storages) == 0 {
+			return errors.New("no storage given")
+		}
+		r.Storages = appendUnique(r.Storages, storages...)
+	case "--tag":
+		tags := splitFlagList(value)
+		if len(tags) == 0 {
+			return errors.New("no tag given")
+		}
+		if err := validateTags(tags); err != nil {
+			return err
+		}
+		r.Tags = appendUnique(r.Tags, tags...)
+	case "--notify":
+		if value == "" {
+			return errors.New("no chat given")
+		}
+		chatID, err := resolveChat(value)
+		if err != nil {
+			return err
+		}
+		r.NotifyChatID = chatID
+	default:
+		return ErrUnknownFlag
+	}
+	return nil
+}
+
+func validateTemplate(tmpl string) error {
+	if strings.TrimSpace(tmpl) == "" {
+		return errors.New("empty template")
+	}
+	_, err := template.New("filename").Parse(tmpl)
+	return err
+}
+
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if !tagPattern.MatchString(tag) {
+			return ErrInvalidTag
+		}
+	}
+	return nil
+}
+
+// ValidateRule checks the rule like /rule add does, except the storages, and normalizes its type and conflict policy
+func ValidateRule(r *database.Rule) error {
+	ruleType, err := rule.ParseType(r.Type)
+	if err != nil {
+		return err
+	}
+	if _, err := rule.NewMatcher(ruleType, r.Data); err != nil {
+		return err
+	}
+	r.Type = ruleType.String()
+	if r.StorageName == "" && r.DirPath == "" && !HasActions(*r) {
+		return errors.New("the rule has neither a storage, a path nor actions")
+	}
+	if r.FilenameTemplate != "" {
+		if err := validateTemplate(r.FilenameTemplate); err != nil {
+			return &FlagError{Flag: "--rename", Value: r.FilenameTemplate, Err: err}
+		}
+	}
+	if r.Conflict != "" {
+		policy, err := conflict.ParsePolicy(r.Conflict)
+		if err != nil {
+			return &FlagError{Flag: "--conflict", Value: r.Conflict, Err: err}
+		}
+		r.Conflict = policy.String()
+	}
+	if err := validateTags(r.Tags); err != nil {
+		return &FlagError{Flag: "--tag", Value: strings.Join(r.Tags, ","), Err: err}
+	}
+	return nil
+}
+
+func splitFlagList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// HasActions reports whether the rule does more than choosing the storage and directory
+func HasActions(r database.Rule) bool {
+	return r.Skip || r.FilenameTemplate != "" || r.Conflict != "" || len(r.Storages) > 0 || len(r.Tags) > 0 || r.NotifyChatID != 0
+}
+
+// FormatFlags returns the flags of the rule in the form ParseFlags takes them
+func FormatFlags(r database.Rule) []string {
+	var flags []string
+	if r.Priority != 0 {
+		flags = append(flags, fmt.Sprintf("--priority=%d", r.Priority))
+	}
+	if r.Stop {
+		flags = append(flags, "--stop")
+	}
+	if r.Skip {
+		flags = append(flags, "--skip")
+	}
+	if r.FilenameTemplate != "" {
+		flags = append(flags, "--rename="+r.FilenameTemplate)
+	}
+	if r.Conflict != "" {
+		flags = append(flags, "--conflict="+r.Conflict)
+	}
+	if len(r.Storages) > 0 {
+		flags = append(flags, "--also="+strings.Join(r.Storages, ","))
+	}
+	if len(r.Tags) > 0 {
+		flags = append(flags, "--tag="+strings.Join(r.Tags, ","))
+	}
+	if r.NotifyChatID != 0 {
+		flags = append(flags, fmt.Sprintf("--notify=%d", r.NotifyChatID))
+	}
+	return flags
+}
+
+// FormatRule returns the rule like it is added with /rule add
+func FormatRule(r database.Rule) string {
+	parts := []string{r.Type, r.Data}
+	if r.StorageName != "" || r.DirPath != "" {
+		parts = append(parts, r.StorageName, r.DirPath)
+	}
+	parts = append(parts, FormatFlags(r)...)
+	for i, part := range parts {
+		parts[i] = quoteArg(part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteArg quotes the arg if it is split or changed by strutil.ParseArgsRespectQuotes otherwise
+func quoteArg(arg string) string {
+	// a backslash is kept unless it escapes a quote or another backslash
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") && !strings.Contains(arg, `\\`) && !strings.HasSuffix(arg, `\`) {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
@@ -1,14 +1,19 @@
 package ruleutil
 
 import (
+	"cmp"
 	"context"
-
-	"github.com/duke-git/lancet/v2/convertor"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/mediautil"
+	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
 	"github.com/kiss2u/SaveAny-Bot/pkg/rule"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
 type ruleInput struct {
@@ -48,66 +53,185 @@ func (m MatchedDirPath) NeedNewForAlbum() bool {
 	return m != "" && m == rule.RuleDirPathNewForAlbum
 }
 
-func ApplyRule(ctx context.Context, rules []database.Rule, inputs *ruleInput) (matched bool, matchedStorageName matchedStorName, dirPath MatchedDirPath) {
+// Result is the outcome of ApplyRule
+type Result struct {
+	Matches     []database.Rule // the matched rules in the order they are evaluated
+	StorageName matchedStorName
+	DirPath     MatchedDirPath
+	// the actions of the matched rules
+	Skip             bool
+	FilenameTemplate string
+	Conflict         conflict.Policy
+	Storages         []string // the storages the file is saved to as well
+	Tags             []string
+	NotifyChats      []int64
+}
+
+func (r Result) Matched() bool {
+	return len(r.Matches) > 0
+}
+
+// SortRules returns the rules in the order they are evaluated, by priority and then the newest first,
+// so the last added rule wins among the ones with the same priority
+func SortRules(rules []database.Rule) []database.Rule {
+	sorted := slices.Clone(rules)
+	slices.SortStableFunc(sorted, func(a, b database.Rule) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return sorted
+}
+
+// ApplyRule evaluates the rules in the order of SortRules, the evaluation ends at a matched rule with Stop set.
+// The first matched rule with a storage or a directory decides them, and the first one with a filename template
+// or a conflict policy decides that. The other actions of all the matched rules are combined.
+func ApplyRule(ctx context.Context, rules []database.Rule, inputs *ruleInput) Result {
+	var result Result
 	if inputs == nil || len(rules) == 0 {
-		return false, "", ""
+		return result
 	}
 	logger := log.FromContext(ctx)
-	for _, ur := range rules {
-		switch ur.Type {
-		case rule.FileNameRegex.String():
-			ru, err := rule.NewRuleFileNameRegex(ur.StorageName, ur.DirPath, ur.Data)
-			if err != nil {
-				logger.Errorf("Failed to create rule: %s", err)
-				continue
-			}
-			ok, err := ru.Match(inputs.File)
-			if err != nil {
-				logger.Errorf("Failed to match rule: %s", err)
-				continue
-			}
-			if ok {
-				dirPath = MatchedDirPath(ru.StoragePath())
-				matchedStorageName = matchedStorName(ru.StorageName())
-			}
-		case rule.MessageRegex.String():
-			ru, err := rule.NewRuleMessageRegex(ur.StorageName, ur.DirPath, ur.Data)
-			if err != nil {
-				logger.Errorf("Failed to create rule: %s", err)
-				continue
-			}
-			ok, err := ru.Match(inputs.File.Message().GetMessage())
-			if err != nil {
-				logger.Errorf("Failed to match rule: %s", err)
-				continue
-			}
-			if ok {
-				dirPath = MatchedDirPath(ru.StoragePath())
-				matchedStorageName = matchedStorName(ru.StorageName())
-			}
-		case rule.IsAlbum.String():
-			matchAlbum, err := convertor.ToBool(ur.Data)
-			if err != nil {
-				matchAlbum = false
-			}
-			ru, err := rule.NewRuleMediaType(ur.StorageName, ur.DirPath, matchAlbum)
-			if err != nil {
-				logger.Errorf("Failed to create rule: %s", err)
-				continue
-			}
-			ok, err := ru.Match(inputs.File.Message().GroupedID != 0)
-			if err != nil {
-				logger.Errorf("Failed to match rule: %s", err)
-				continue
-			}
-			if ok {
-				dirPath = MatchedDirPath(ru.StoragePath())
-				matchedStorageName = matchedStorName(ru.StorageName())
+	input := rule.Input{File: inputs.File}
+	for _, ur := range SortRules(rules) {
+		m, err := rule.NewMatcher(rule.RuleType(ur.Type), ur.Data)
+		if err != nil {
+			logger.Errorf("Failed to create rule %d: %s", ur.ID, err)
+			continue
+		}
+		ok, err := m.Match(input)
+		if err != nil {
+			logger.Errorf("Failed to match rule %d: %s", ur.ID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		result.Matches = append(result.Matches, ur)
+		if result.StorageName == "" && result.DirPath == "" {
+			result.StorageName = matchedStorName(ur.StorageName)
+			result.DirPath = MatchedDirPath(ur.DirPath)
+		}
+		if result.FilenameTemplate == "" {
+			result.FilenameTemplate = ur.FilenameTemplate
+		}
+		if result.Conflict == "" && ur.Conflict != "" {
+			if policy, err := conflict.ParsePolicy(ur.Conflict); err == nil {
+				result.Conflict = policy
 			}
 		}
+		result.Skip = result.Skip || ur.Skip
+		result.Storages = appendUnique(result.Storages, ur.Storages...)
+		result.Tags = appendUnique(result.Tags, ur.Tags...)
+		if ur.NotifyChatID != 0 {
+			result.NotifyChats = appendUnique(result.NotifyChats, ur.NotifyChatID)
+		}
+		if ur.Stop {
+			break
+		}
+	}
+	return result
+}
+
+func appendUnique[T comparable](s []T, values ...T) []T {
+	for _, v := range values {
+		if !slices.Contains(s, v) {
+			s = append(s, v)
+		}
 	}
-	if matchedStorageName != "" || dirPath != "" {
-		return true, matchedStorageName, dirPath
+	return s
+}
+
+// Target is where a file is saved to
+type Target struct {
+	Storage  storage.Storage
+	DirPath  MatchedDirPath
+	Skip     bool              // the file should not be saved
+	Also     []storage.Storage // the storages the file is saved to as well, at the same path
+	Conflict conflict.Policy   // overrides the conflict policy of the storages if set
+	Extras   core.TaskExtras
+}
+
+// Storages returns Storage and the ones in Also
+func (t Target) Storages() []storage.Storage {
+	return append([]storage.Storage{t.Storage}, t.Also...)
+}
+
+// Context returns the ctx to add the tasks saving the file with
+func (t Target) Context(ctx context.Context) context.Context {
+	if t.Conflict != "" {
+		ctx = storage.WithConflictPolicy(ctx, t.Conflict)
+	}
+	if !t.Extras.IsZero() {
+		ctx = core.WithExtras(ctx, t.Extras)
+	}
+	return ctx
+}
+
+// ResolveTarget applies the rules of the user to the file, stor and dirPath are used if the user does not apply rules,
+// no rule matches or the matched rules keep them. The file is renamed if a matched rule has a filename template.
+func ResolveTarget(ctx context.Context, user *database.User, file tfile.TGFileMessage, stor storage.Storage, dirPath string) (Target, error) {
+	target := Target{Storage: stor, DirPath: MatchedDirPath(dirPath)}
+	if !user.ApplyRule || len(user.Rules) == 0 {
+		return target, nil
+	}
+	return applyResult(ctx, user, file, target, ApplyRule(ctx, user.Rules, NewInput(file)))
+}
+
+// applyResult changes the target, which has the storage and path used if no rule matches, by the result
+func applyResult(ctx context.Context, user *database.User, file tfile.TGFileMessage, target Target, result Result) (Target, error) {
+	if !result.Matched() {
+		return target, nil
+	}
+	target.Skip = result.Skip
+	target.Conflict = result.Conflict
+	target.Extras = core.TaskExtras{Tags: result.Tags, NotifyChats: result.NotifyChats}
+	if result.DirPath != "" {
+		target.DirPath = result.DirPath
+	}
+	if result.StorageName.Usable() && result.StorageName.String() != storageName(target.Storage) {
+		ruleStor, err := storage.GetStorageByUserIDAndName(ctx, user.ChatID, result.StorageName.String())
+		if err != nil {
+			return target, err
+		}
+		target.Storage = ruleStor
+	}
+	for _, name := range result.Storages {
+		if name == storageName(target.Storage) {
+			continue
+		}
+		also, err := storage.GetStorageByUserIDAndName(ctx, user.ChatID, name)
+		if err != nil {
+			return target, err
+		}
+		target.Also = append(target.Also, also)
+	}
+	if result.FilenameTemplate != "" && file.Message() != nil {
+		name, err := mediautil.RenderFilenameTemplate(result.FilenameTemplate, file.Message())
+		if err != nil {
+			log.FromContext(ctx).Errorf("Failed to rename %s: %s", file.Name(), err)
+		} else if name = strings.TrimSpace(name); name != "" {
+			file.SetName(name)
+		}
+	}
+	return target, nil
+}
+
+func storageName(stor storage.Storage) string {
+	if stor == nil {
+		return ""
+	}
+	return stor.Name()
+}
+
+// BatchContext returns the ctx to add a task saving the files of the targets with, as the files of a batch task share it.
+// The extras of the targets are combined, the conflict policies are carried by the elements of the task instead.
+func BatchContext(ctx context.Context, targets []Target) context.Context {
+	var combined Target
+	for _, t := range targets {
+		combined.Extras.Tags = appendUnique(combined.Extras.Tags, t.Extras.Tags...)
+		combined.Extras.NotifyChats = appendUnique(combined.Extras.NotifyChats, t.Extras.NotifyChats...)
 	}
-	return false, "", ""
+	return combined.Context(ctx)
 }
@@ -0,0 +1,125 @@
+package ruleutil
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/strutil"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"gorm.io/gorm"
+)
+
+func testFile(t *testing.T, name string) tfile.TGFileMessage {
+	t.Helper()
+	media := &tg.MessageMediaDocument{Document: &tg.Document{
+		Attributes: []tg.DocumentAttributeClass{&tg.DocumentAttributeFilename{FileName: name}},
+	}}
+	file, err := tfile.FromMediaMessage(media, nil, &tg.Message{ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestApplyRuleActions(t *testing.T) {
+	rules := []database.Rule{
+		{Model: gorm.Model{ID: 1}, Type: "FILENAME-REGEX", Data: `\.pdf$`, StorageName: "local", DirPath: "docs", Tags: []string{"docs"}},
+		{Model: gorm.Model{ID: 2}, Type: "FILENAME-REGEX", Data: `.`, Storages: []string{"backup"}, Tags: []string{"all", "docs"}, Conflict: "skip"},
+		{Model: gorm.Model{ID: 3}, Type: "FILENAME-REGEX", Data: `^report`, Priority: 10, FilenameTemplate: "{{.msgid}}.pdf", NotifyChatID: 42},
+		{Model: gorm.Model{ID: 4}, Type: "FILENAME-REGEX", Data: `\.tmp$`, Priority: 20, Skip: true, Stop: true},
+	}
+
+	result := ApplyRule(t.Context(), rules, NewInput(testFile(t, "report.pdf")))
+	if ids := matchIDs(result); !slices.Equal(ids, []uint{3, 2, 1}) {
+		t.Fatalf("unexpected matched rules %v", ids)
+	}
+	// rule 2 has no location, so rule 1 decides it
+	if result.StorageName != "local" || result.DirPath != "docs" {
+		t.Fatalf("unexpected location %s:%s", result.StorageName, result.DirPath)
+	}
+	if result.Skip || result.FilenameTemplate != "{{.msgid}}.pdf" || result.Conflict != conflict.Skip {
+		t.Fatalf("unexpected actions %+v", result)
+	}
+	if !slices.Equal(result.Storages, []string{"backup"}) || !slices.Equal(result.Tags, []string{"all", "docs"}) ||
+		!slices.Equal(result.NotifyChats, []int64{42}) {
+		t.Fatalf("unexpected combined actions %+v", result)
+	}
+
+	result = ApplyRule(t.Context(), rules, NewInput(testFile(t, "report.tmp")))
+	if ids := matchIDs(result); !slices.Equal(ids, []uint{4}) || !result.Skip {
+		t.Fatalf("the file should be skipped by rule 4 only, matched %v", ids)
+	}
+}
+
+func matchIDs(result Result) []uint {
+	ids := make([]uint, 0, len(result.Matches))
+	for _, m := range result.Matches {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestParseAndFormatFlags(t *testing.T) {
+	resolveChat := func(string) (int64, error) { return -100123, nil }
+	text := `/rule add FILENAME-REGEX "a b\\c" --priority=5 --skip "--rename={{.msgid}} x" --conflict=overwrite --also=s1,s2 --tag=t1 --notify=@chat`
+	var r database.Rule
+	args, err := ParseFlags(strutil.ParseArgsRespectQuotes(text), &r, resolveChat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(args, []string{"/rule", "add", "FILENAME-REGEX", `a b\c`}) {
+		t.Fatalf("unexpected args %q", args)
+	}
+	want := database.Rule{
+		Priority: 5, Skip: true, FilenameTemplate: "{{.msgid}} x", Conflict: "overwrite",
+		Storages: []string{"s1", "s2"}, Tags: []string{"t1"}, NotifyChatID: -100123,
+	}
+	if !HasActions(r) || r.Priority != want.Priority || r.FilenameTemplate != want.FilenameTemplate || r.Conflict != want.Conflict ||
+		!slices.Equal(r.Storages, want.Storages) || !slices.Equal(r.Tags, want.Tags) || r.NotifyChatID != want.NotifyChatID {
+		t.Fatalf("unexpected rule %+v", r)
+	}
+
+	// the formatted rule is parsed to the same rule
+	r.Type, r.Data = args[2], args[3]
+	var parsed database.Rule
+	args, err = ParseFlags(strutil.ParseArgsRespectQuotes(FormatRule(r)), &parsed, resolveChat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(args, []string{r.Type, r.Data}) || !slices.Equal(FormatFlags(parsed), FormatFlags(r)) {
+		t.Fatalf("the formatted rule %s is parsed differently", FormatRule(r))
+	}
+
+	for _, flag := range []string{"--unknown", "--tag=a\"b", "--conflict=maybe", "--rename={{", "--priority=x"} {
+		if _, err := ParseFlags([]string{flag}, &database.Rule{}, resolveChat); err == nil {
+			t.Errorf("%s should be invalid", flag)
+		}
+	}
+}
+
+func TestTestRules(t *testing.T) {
+	user := &database.User{Rules: []database.Rule{
+		{Model: gorm.Model{ID: 1}, Type: "FILENAME-REGEX", Data: `\.pdf$`, DirPath: "docs", FilenameTemplate: "{{.msgid}}.pdf"},
+		{Model: gorm.Model{ID: 2}, Type: "FILENAME-REGEX", Data: `^draft`, Skip: true},
+	}}
+
+	run := TestRules(t.Context(), user, testFile(t, "report.pdf"))
+	if run.Applied || !slices.Equal(matchIDs(run.Result), []uint{1}) {
+		t.Fatalf("the rules should be evaluated but not applied, got %+v", run)
+	}
+	if run.Path != "report.pdf" || run.Target.Storage != nil || run.Err != nil {
+		t.Fatalf("the file should be saved as if no rule matches, got %+v", run)
+	}
+
+	user.ApplyRule = true
+	run = TestRules(t.Context(), user, testFile(t, "report.pdf"))
+	if run.FileName != "report.pdf" || run.Path != "docs/7.pdf" {
+		t.Fatalf("the file should be renamed and moved by rule 1, got %s to %s", run.FileName, run.Path)
+	}
+	if run = TestRules(t.Context(), user, testFile(t, "draft.pdf")); !run.Target.Skip {
+		t.Fatalf("the file should be skipped by rule 2, got %+v", run)
+	}
+}
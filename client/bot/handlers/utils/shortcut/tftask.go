@@ -17,6 +17,8 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/core/tasks/batchtfile"
 	tftask "github.com/kiss2u/SaveAny-Bot/core/tasks/tfile"
 	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
 	"github.com/kiss2u/SaveAny-Bot/storage"
 	"github.com/rs/xid"
@@ -36,36 +38,36 @@ func CreateAndAddTGFileTaskWithEdit(ctx *ext.Context, userID int64, stor storage
 		})
 		return dispatcher.EndGroups
 	}
-	if user.ApplyRule && user.Rules != nil {
-		matched, matchedStorageName, matchedDirPath := ruleutil.ApplyRule(ctx, user.Rules, ruleutil.NewInput(file))
-		if !matched {
-			goto startCreateTask
-		}
-		if matchedDirPath != "" {
-			dirPath = matchedDirPath.String()
-		}
-		if matchedStorageName.Usable() {
-			stor, err = storage.GetStorageByUserIDAndName(ctx, user.ChatID, matchedStorageName.String())
-			if err != nil {
-				logger.Errorf("Failed to get storage by user ID and name: %s", err)
-				ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
-					ID: trackMsgID,
-					Message: i18n.T(i18nk.BotMsgCommonErrorGetStorageFailed, map[string]any{
-						"Error": err.Error(),
-					}),
-				})
-				return dispatcher.EndGroups
-			}
-		}
+	target, err := ruleutil.ResolveTarget(ctx, user, file, stor, dirPath)
+	if err != nil {
+		logger.Errorf("Failed to get storage by user ID and name: %s", err)
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID: trackMsgID,
+			Message: i18n.T(i18nk.BotMsgCommonErrorGetStorageFailed, map[string]any{
+				"Error": err.Error(),
+			}),
+		})
+		return dispatcher.EndGroups
+	}
+	if target.Skip {
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID: trackMsgID,
+			Message: i18n.T(i18nk.BotMsgRuleInfoSkipped, map[string]any{
+				"Name": file.Name(),
+			}),
+		})
+		return dispatcher.EndGroups
 	}
-startCreateTask:
+	stor, dirPath = target.Storage, target.DirPath.String()
 	storagePath := path.Join(dirPath, file.Name())
-	injectCtx := tgutil.ExtWithContext(ctx.Context, ctx)
+	injectCtx := target.Context(tgutil.ExtWithContext(ctx.Context, ctx))
 	taskid := xid.New().String()
-	task, err := tftask.NewTGFileTask(taskid, injectCtx, file, stor, storagePath,
+	// the file is saved to the storages the rules save it to as well by the same task, from one download
+	task, err := tftask.NewTGFileTask(taskid, injectCtx, userID, file, stor, storagePath,
 		tftask.NewProgressTrack(
 			trackMsgID,
-			userID))
+			userID),
+		target.Also...)
 	if err != nil {
 		logger.Errorf("create task failed: %s", err)
 		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
@@ -76,7 +78,7 @@ startCreateTask:
 		})
 		return dispatcher.EndGroups
 	}
-	if err := core.AddTask(injectCtx, task); err != nil {
+	if err := core.AddTask(injectCtx, task, queue.WithPriority(queue.PriorityHigh)); err != nil {
 		logger.Errorf("add task failed: %s", err)
 		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
 			ID: trackMsgID,
@@ -111,59 +113,48 @@ func CreateAndAddBatchTGFileTaskWithEdit(ctx *ext.Context, userID int64, stor st
 		return dispatcher.EndGroups
 	}
 
-	useRule := user.ApplyRule && user.Rules != nil
-
-	applyRule := func(file tfile.TGFileMessage) (string, ruleutil.MatchedDirPath) {
-		if !useRule {
-			return stor.Name(), ruleutil.MatchedDirPath(dirPath)
-		}
-		matched, storName, dirP := ruleutil.ApplyRule(ctx, user.Rules, ruleutil.NewInput(file))
-		if !matched {
-			return stor.Name(), ruleutil.MatchedDirPath(dirPath)
-		}
-		storname := storName.String()
-		if !storName.Usable() {
-			storname = stor.Name()
-		}
-		return storname, dirP
-	}
-
 	elems := make([]batchtfile.TaskElement, 0, len(files))
 	type albumFile struct {
-		file    tfile.TGFileMessage
-		storage storage.Storage
+		file     tfile.TGFileMessage
+		storages []storage.Storage
+		conflict conflict.Policy
 	}
 	albumFiles := make(map[int64][]albumFile, 0)
+	targets := make([]ruleutil.Target, 0, len(files))
 	for _, file := range files {
-		storName, dirPath := applyRule(file)
-		fileStor := stor
-		if storName != stor.Name() && storName != "" {
-			fileStor, err = storage.GetStorageByUserIDAndName(ctx, user.ChatID, storName)
-			if err != nil {
-				logger.Errorf("Failed to get storage by user ID and name: %s", err)
-				ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
-					ID: trackMsgID,
-					Message: i18n.T(i18nk.BotMsgCommonErrorGetStorageFailed, map[string]any{
-						"Error": err.Error(),
-					}),
-				})
-				return dispatcher.EndGroups
-			}
+		target, err := ruleutil.ResolveTarget(ctx, user, file, stor, dirPath)
+		if err != nil {
+			logger.Errorf("Failed to get storage by user ID and name: %s", err)
+			ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+				ID: trackMsgID,
+				Message: i18n.T(i18nk.BotMsgCommonErrorGetStorageFailed, map[string]any{
+					"Error": err.Error(),
+				}),
+			})
+			return dispatcher.EndGroups
 		}
+		if target.Skip {
+			continue
+		}
+		targets = append(targets, target)
+		dirPath := target.DirPath
 		if !dirPath.NeedNewForAlbum() {
 			storPath := path.Join(dirPath.String(), file.Name())
-			elem, err := batchtfile.NewTaskElement(fileStor, storPath, file)
-			if err != nil {
-				logger.Errorf("Failed to create task element: %s", err)
-				ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
-					ID: trackMsgID,
-					Message: i18n.T(i18nk.BotMsgCommonErrorTaskCreateFailed, map[string]any{
-						"Error": err.Error(),
-					}),
-				})
-				return dispatcher.EndGroups
+			for _, fileStor := range target.Storages() {
+				elem, err := batchtfile.NewTaskElement(fileStor, storPath, file)
+				if err != nil {
+					logger.Errorf("Failed to create task element: %s", err)
+					ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+						ID: trackMsgID,
+						Message: i18n.T(i18nk.BotMsgCommonErrorTaskCreateFailed, map[string]any{
+							"Error": err.Error(),
+						}),
+					})
+					return dispatcher.EndGroups
+				}
+				elem.Conflict = target.Conflict
+				elems = append(elems, *elem)
 			}
-			elems = append(elems, *elem)
 		} else {
 			groupId, isGroup := file.Message().GetGroupedID()
 			if !isGroup || groupId == 0 {
@@ -174,8 +165,9 @@ func CreateAndAddBatchTGFileTaskWithEdit(ctx *ext.Context, userID int64, stor st
 				albumFiles[groupId] = make([]albumFile, 0)
 			}
 			albumFiles[groupId] = append(albumFiles[groupId], albumFile{
-				file:    file,
-				storage: fileStor,
+				file:     file,
+				storages: target.Storages(),
+				conflict: target.Conflict,
 			})
 		}
 	}
@@ -186,28 +178,40 @@ func CreateAndAddBatchTGFileTaskWithEdit(ctx *ext.Context, userID int64, stor st
 		// 对于需要新建目录的文件, 将第一个文件的文件名(去除扩展名)作为目录名
 		// 存储以第一个文件的存储为准
 		albumDir := strings.TrimSuffix(path.Base(afiles[0].file.Name()), path.Ext(afiles[0].file.Name()))
-		albumStor := afiles[0].storage
+		albumStors := afiles[0].storages
 		for _, af := range afiles {
 			afstorPath := path.Join(dirPath, albumDir, af.file.Name())
-			elem, err := batchtfile.NewTaskElement(albumStor, afstorPath, af.file)
-			if err != nil {
-				logger.Errorf("Failed to create task element for album file: %s", err)
-				ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
-					ID: trackMsgID,
-					Message: i18n.T(i18nk.BotMsgCommonErrorTaskCreateFailed, map[string]any{
-						"Error": err.Error(),
-					}),
-				})
-				return dispatcher.EndGroups
+			for _, albumStor := range albumStors {
+				elem, err := batchtfile.NewTaskElement(albumStor, afstorPath, af.file)
+				if err != nil {
+					logger.Errorf("Failed to create task element for album file: %s", err)
+					ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+						ID: trackMsgID,
+						Message: i18n.T(i18nk.BotMsgCommonErrorTaskCreateFailed, map[string]any{
+							"Error": err.Error(),
+						}),
+					})
+					return dispatcher.EndGroups
+				}
+				elem.Conflict = af.conflict
+				elems = append(elems, *elem)
 			}
-			elems = append(elems, *elem)
 		}
 	}
+	if len(elems) == 0 {
+		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
+			ID: trackMsgID,
+			Message: i18n.T(i18nk.BotMsgCommonInfoBatchTasksAdded, map[string]any{
+				"Count": 0,
+			}),
+		})
+		return dispatcher.EndGroups
+	}
 
-	injectCtx := tgutil.ExtWithContext(ctx.Context, ctx)
+	injectCtx := ruleutil.BatchContext(tgutil.ExtWithContext(ctx.Context, ctx), targets)
 	taskid := xid.New().String()
-	task := batchtfile.NewBatchTGFileTask(taskid, injectCtx, elems, batchtfile.NewProgressTracker(trackMsgID, userID), true)
-	if err := core.AddTask(injectCtx, task); err != nil {
+	task := batchtfile.NewBatchTGFileTask(taskid, injectCtx, userID, elems, batchtfile.NewProgressTracker(trackMsgID, userID), true)
+	if err := core.AddTask(injectCtx, task, queue.WithPriority(queue.PriorityLow)); err != nil {
 		logger.Errorf("Failed to add batch task: %s", err)
 		ctx.EditMessage(userID, &tg.MessagesEditMessageRequest{
 			ID: trackMsgID,
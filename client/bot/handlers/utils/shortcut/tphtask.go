@@ -29,6 +29,7 @@ func CreateAndAddtelegraphWithEdit(
 	injectCtx := tgutil.ExtWithContext(ctx.Context, ctx)
 	task := tphtask.NewTask(xid.New().String(),
 		injectCtx,
+		userID,
 		tphpage.Path,
 		pics,
 		stor,
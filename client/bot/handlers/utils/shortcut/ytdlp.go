@@ -35,6 +35,7 @@ func CreateAndAddYtdlpTaskWithEdit(ctx *ext.Context, stor storage.Storage, dirPa
 	task := ytdlp.NewTask(
 		xid.New().String(),
 		injectCtx,
+		userID,
 		urls,
 		flags,
 		stor,
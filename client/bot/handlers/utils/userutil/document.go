@@ -0,0 +1,152 @@
+// Package userutil exports and imports the data of a user, like the rules, dirs, watched chats and settings
+package userutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/ruleutil"
+	"github.com/kiss2u/SaveAny-Bot/database"
+)
+
+// DocumentVersion is the version of the documents written by Export
+const DocumentVersion = 1
+
+var ErrUnsupportedVersion = errors.New("unsupported document version")
+
+// Document is the data of a user written by /export and read by /import
+type Document struct {
+	Version  int      `yaml:"version" json:"version"`
+	Settings Settings `yaml:"settings" json:"settings"`
+	Dirs     []Dir    `yaml:"dirs,omitempty" json:"dirs,omitempty"`
+	Rules    []Rule   `yaml:"rules,omitempty" json:"rules,omitempty"` // in the order they are evaluated
+	Watches  []Watch  `yaml:"watches,omitempty" json:"watches,omitempty"`
+}
+
+// Settings are the settings of the user, the ones left out are not changed by an import
+type Settings struct {
+	Silent           *bool   `yaml:"silent,omitempty" json:"silent,omitempty"`
+	DefaultStorage   *string `yaml:"default_storage,omitempty" json:"default_storage,omitempty"`
+	DefaultDir       *Dir    `yaml:"default_dir,omitempty" json:"default_dir,omitempty"` // must be in the dirs of the user
+	ApplyRule        *bool   `yaml:"apply_rule,omitempty" json:"apply_rule,omitempty"`
+	FilenameStrategy *string `yaml:"filename_strategy,omitempty" json:"filename_strategy,omitempty"`
+	FilenameTemplate *string `yaml:"filename_template,omitempty" json:"filename_template,omitempty"`
+	DedupPolicy      *string `yaml:"dedup_policy,omitempty" json:"dedup_policy,omitempty"`
+}
+
+type Dir struct {
+	Storage string `yaml:"storage" json:"storage"`
+	Path    string `yaml:"path" json:"path"`
+}
+
+// Rule is a rule like it is added with /rule add
+type Rule struct {
+	Type     string   `yaml:"type" json:"type"`
+	Data     string   `yaml:"data" json:"data"`
+	Storage  string   `yaml:"storage,omitempty" json:"storage,omitempty"`
+	Path     string   `yaml:"path,omitempty" json:"path,omitempty"`
+	Priority int      `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Stop     bool     `yaml:"stop,omitempty" json:"stop,omitempty"`
+	Skip     bool     `yaml:"skip,omitempty" json:"skip,omitempty"`
+	Rename   string   `yaml:"rename,omitempty" json:"rename,omitempty"`
+	Conflict string   `yaml:"conflict,omitempty" json:"conflict,omitempty"`
+	Also     []string `yaml:"also,omitempty" json:"also,omitempty"`
+	Tags     []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Notify   int64    `yaml:"notify,omitempty" json:"notify,omitempty"`
+}
+
+type Watch struct {
+	ChatID int64  `yaml:"chat_id" json:"chat_id"`
+	Filter string `yaml:"filter,omitempty" json:"filter,omitempty"` // like "msgre:<regex>"
+}
+
+func newRule(r database.Rule) Rule {
+	return Rule{
+		Type:     r.Type,
+		Data:     r.Data,
+		Storage:  r.StorageName,
+		Path:     r.DirPath,
+		Priority: r.Priority,
+		Stop:     r.Stop,
+		Skip:     r.Skip,
+		Rename:   r.FilenameTemplate,
+		Conflict: r.Conflict,
+		Also:     r.Storages,
+		Tags:     r.Tags,
+		Notify:   r.NotifyChatID,
+	}
+}
+
+func (r Rule) model() database.Rule {
+	return database.Rule{
+		Type:             r.Type,
+		Data:             r.Data,
+		StorageName:      r.Storage,
+		DirPath:          r.Path,
+		Priority:         r.Priority,
+		Stop:             r.Stop,
+		Skip:             r.Skip,
+		FilenameTemplate: r.Rename,
+		Conflict:         r.Conflict,
+		Storages:         r.Also,
+		Tags:             r.Tags,
+		NotifyChatID:     r.Notify,
+	}
+}
+
+// Export returns the document of the user, which must be loaded with its associations
+func Export(user *database.User) Document {
+	doc := Document{
+		Version: DocumentVersion,
+		Settings: Settings{
+			Silent:           &user.Silent,
+			DefaultStorage:   &user.DefaultStorage,
+			ApplyRule:        &user.ApplyRule,
+			FilenameStrategy: &user.FilenameStrategy,
+			FilenameTemplate: &user.FilenameTemplate,
+			DedupPolicy:      &user.DedupPolicy,
+		},
+	}
+	for _, dir := range user.Dirs {
+		doc.Dirs = append(doc.Dirs, Dir{Storage: dir.StorageName, Path: dir.Path})
+		if dir.ID == user.DefaultDir {
+			doc.Settings.DefaultDir = &Dir{Storage: dir.StorageName, Path: dir.Path}
+		}
+	}
+	for _, r := range ruleutil.SortRules(user.Rules) {
+		doc.Rules = append(doc.Rules, newRule(r))
+	}
+	for _, chat := range user.WatchChats {
+		doc.Watches = append(doc.Watches, Watch{ChatID: chat.ChatID, Filter: chat.Filter})
+	}
+	return doc
+}
+
+// Marshal writes the document as YAML, or as JSON if asJSON is set
+func Marshal(doc Document, asJSON bool) ([]byte, error) {
+	if asJSON {
+		return json.MarshalIndent(doc, "", "  ")
+	}
+	return yaml.Marshal(doc)
+}
+
+// Parse reads a document written as YAML or JSON, the unknown fields are rejected to catch typos
+func Parse(data []byte) (Document, error) {
+	var doc Document
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			return doc, err
+		}
+	} else if err := yaml.UnmarshalWithOptions(data, &doc, yaml.Strict()); err != nil {
+		return doc, errors.New(yaml.FormatError(err, false, true))
+	}
+	if doc.Version != DocumentVersion {
+		return doc, fmt.Errorf("%w: %d, expected %d", ErrUnsupportedVersion, doc.Version, DocumentVersion)
+	}
+	return doc, nil
+}
@@ -0,0 +1,84 @@
+package userutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"gorm.io/gorm"
+)
+
+func TestExportAndParse(t *testing.T) {
+	user := &database.User{
+		DefaultStorage: "local",
+		DefaultDir:     2,
+		ApplyRule:      true,
+		Dirs: []database.Dir{
+			{Model: gorm.Model{ID: 1}, StorageName: "local", Path: "videos"},
+			{Model: gorm.Model{ID: 2}, StorageName: "local", Path: "docs"},
+		},
+		Rules: []database.Rule{
+			{Model: gorm.Model{ID: 1}, Type: "FILENAME-REGEX", Data: `\.pdf$`, StorageName: "local", DirPath: "docs"},
+			{Model: gorm.Model{ID: 2}, Type: "FILENAME-REGEX", Data: `\.tmp$`, Priority: 10, Skip: true, Tags: []string{"tmp"}},
+		},
+		WatchChats: []database.WatchChat{{ChatID: -100123, Filter: "msgre:^#save"}},
+	}
+	doc := Export(user)
+	if doc.Settings.DefaultDir == nil || *doc.Settings.DefaultDir != (Dir{Storage: "local", Path: "docs"}) {
+		t.Fatalf("unexpected default dir %+v", doc.Settings.DefaultDir)
+	}
+	// the rule with the higher priority is evaluated first
+	if len(doc.Rules) != 2 || !doc.Rules[0].Skip || doc.Rules[1].Path != "docs" {
+		t.Fatalf("unexpected rules %+v", doc.Rules)
+	}
+
+	for _, asJSON := range []bool{false, true} {
+		data, err := Marshal(doc, asJSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(data)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", data, err)
+		}
+		if !reflect.DeepEqual(parsed, doc) {
+			t.Fatalf("the document is parsed differently:\n%s", data)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("version: 2\n")); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("the version should be rejected, got %v", err)
+	}
+	for _, data := range []string{
+		"version: 1\nrule:\n  - type: IS-ALBUM\n",
+		`{"version": 1, "setting": {}}`,
+		"version: [1\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%q should be invalid", data)
+		}
+	}
+}
+
+func TestRuleModel(t *testing.T) {
+	r := Rule{Type: "FILENAME-REGEX", Data: "x", Rename: "{{.msgid}}", Also: []string{"a"}, Tags: []string{"t"}, Notify: 42}
+	if got := newRule(r.model()); !reflect.DeepEqual(got, r) {
+		t.Fatalf("got %+v, want %+v", got, r)
+	}
+}
+
+func TestValidateWatchFilter(t *testing.T) {
+	for _, filter := range []string{"", "msgre:^#save"} {
+		if err := validateWatchFilter(filter); err != nil {
+			t.Errorf("%q should be valid: %v", filter, err)
+		}
+	}
+	for _, filter := range []string{"msgre", "msgre:(", "other:x", ":x"} {
+		if err := validateWatchFilter(filter); err == nil {
+			t.Errorf("%q should be invalid", filter)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package userutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/ruleutil"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/dedup"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/fnamest"
+	"github.com/kiss2u/SaveAny-Bot/pkg/rule"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// Summary tells what Import added
+type Summary struct {
+	Dirs    int
+	Rules   int
+	Watches int
+}
+
+// Import validates the document and applies it to the user, which must be loaded with its associations.
+// Nothing is changed if the document is invalid, the error then tells all the problems found.
+// With replace the dirs, rules and watched chats of the user are replaced by the ones of the document,
+// otherwise the ones the user does not have yet are added and the filters of the watched chats are updated.
+// The settings given in the document are set in both cases.
+func Import(ctx context.Context, user *database.User, doc Document, replace bool) (Summary, error) {
+	var summary Summary
+	existingDirs := make([]Dir, 0, len(user.Dirs))
+	for _, dir := range user.Dirs {
+		existingDirs = append(existingDirs, Dir{Storage: dir.StorageName, Path: dir.Path})
+	}
+	existingRules := make([]string, 0, len(user.Rules))
+	for _, r := range user.Rules {
+		existingRules = append(existingRules, ruleutil.FormatRule(r))
+	}
+	if replace {
+		existingDirs, existingRules = nil, nil
+	}
+
+	var errs []error
+	checkStorage := func(where, name string) {
+		if _, err := storage.GetStorageByUserIDAndName(ctx, user.ChatID, name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+		}
+	}
+	var data database.UserData
+	for i, dir := range doc.Dirs {
+		where := fmt.Sprintf("dirs[%d]", i)
+		if dir.Storage == "" || dir.Path == "" {
+			errs = append(errs, fmt.Errorf("%s: the storage and the path are required", where))
+			continue
+		}
+		checkStorage(where, dir.Storage)
+		if slices.Contains(existingDirs, dir) {
+			continue
+		}
+		existingDirs = append(existingDirs, dir)
+		data.Dirs = append(data.Dirs, database.Dir{StorageName: dir.Storage, Path: dir.Path})
+	}
+	for i, docRule := range doc.Rules {
+		where := fmt.Sprintf("rules[%d]", i)
+		r := docRule.model()
+		if err := ruleutil.ValidateRule(&r); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			continue
+		}
+		if r.StorageName != "" && r.StorageName != rule.RuleStorNameChosen {
+			checkStorage(where, r.StorageName)
+		}
+		for _, name := range r.Storages {
+			checkStorage(where, name)
+		}
+		formatted := ruleutil.FormatRule(r)
+		if slices.Contains(existingRules, formatted) {
+			continue
+		}
+		existingRules = append(existingRules, formatted)
+		data.Rules = append(data.Rules, r)
+	}
+	// the rules added later are evaluated first among the ones with the same priority, see ruleutil.SortRules
+	slices.Reverse(data.Rules)
+	for i, watch := range doc.Watches {
+		where := fmt.Sprintf("watches[%d]", i)
+		if watch.ChatID == 0 {
+			errs = append(errs, fmt.Errorf("%s: the chat ID is required", where))
+			continue
+		}
+		if err := validateWatchFilter(watch.Filter); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", where, err))
+			continue
+		}
+		data.WatchChats = append(data.WatchChats, database.WatchChat{ChatID: watch.ChatID, Filter: watch.Filter})
+	}
+
+	settings := *user
+	if replace {
+		// the default dir is deleted with the other dirs
+		settings.DefaultDir = 0
+	}
+	errs = append(errs, applySettings(&settings, doc.Settings, existingDirs, checkStorage)...)
+	if doc.Settings.DefaultDir != nil {
+		data.DefaultDir = &database.Dir{StorageName: doc.Settings.DefaultDir.Storage, Path: doc.Settings.DefaultDir.Path}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return summary, err
+	}
+
+	if err := database.ImportUserData(ctx, &settings, data, replace); err != nil {
+		return summary, err
+	}
+	summary.Dirs, summary.Rules, summary.Watches = len(data.Dirs), len(data.Rules), len(data.WatchChats)
+	return summary, nil
+}
+
+// applySettings sets the settings given on user, dirs are the ones the user has after the import
+func applySettings(user *database.User, s Settings, dirs []Dir, checkStorage func(where, name string)) []error {
+	var errs []error
+	if s.Silent != nil {
+		user.Silent = *s.Silent
+	}
+	if s.ApplyRule != nil {
+		user.ApplyRule = *s.ApplyRule
+	}
+	if s.DefaultStorage != nil {
+		if *s.DefaultStorage != "" {
+			checkStorage("settings.default_storage", *s.DefaultStorage)
+		}
+		user.DefaultStorage = *s.DefaultStorage
+	}
+	if s.DefaultDir != nil && !slices.Contains(dirs, *s.DefaultDir) {
+		errs = append(errs, errors.New("settings.default_dir: the dir is not in the dirs"))
+	}
+	if s.FilenameStrategy != nil {
+		if *s.FilenameStrategy != "" {
+			if _, err := fnamest.ParseFnameST(*s.FilenameStrategy); err != nil {
+				errs = append(errs, fmt.Errorf("settings.filename_strategy: %w", err))
+			}
+		}
+		user.FilenameStrategy = *s.FilenameStrategy
+	}
+	if s.FilenameTemplate != nil {
+		if _, err := template.New("filename").Parse(*s.FilenameTemplate); err != nil {
+			errs = append(errs, fmt.Errorf("settings.filename_template: %w", err))
+		}
+		user.FilenameTemplate = *s.FilenameTemplate
+	}
+	if s.DedupPolicy != nil {
+		if *s.DedupPolicy != "" {
+			if _, err := dedup.ParsePolicy(*s.DedupPolicy); err != nil {
+				errs = append(errs, fmt.Errorf("settings.dedup_policy: %w", err))
+			}
+		}
+		user.DedupPolicy = *s.DedupPolicy
+	}
+	return errs
+}
+
+// validateWatchFilter checks the filter like /watch does
+func validateWatchFilter(filter string) error {
+	if filter == "" {
+		return nil
+	}
+	filterType, filterData, _ := strings.Cut(filter, ":")
+	if filterType == "" || filterData == "" {
+		return errors.New("the filter must be like <type>:<data>")
+	}
+	switch filterType {
+	case "msgre":
+		_, err := regexp.Compile(filterData)
+		return err
+	default:
+		return fmt.Errorf("unsupported filter type %s", filterType)
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"regexp"
 	"strings"
 	"sync"
-	"text/template"
 	"time"
 
 	"github.com/celestix/gotgproto/dispatcher"
@@ -23,6 +22,7 @@ import (
 	coretfile "github.com/kiss2u/SaveAny-Bot/core/tasks/tfile"
 	"github.com/kiss2u/SaveAny-Bot/database"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/fnamest"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
 	"github.com/kiss2u/SaveAny-Bot/storage"
 	"github.com/rs/xid"
@@ -260,32 +260,27 @@ func listenMediaMessageEvent(ch chan userclient.MediaMessageEvent) {
 					logger.Warnf("Empty filename template for user %d, using default filename", user.ChatID)
 					break
 				}
-				message := file.Message()
-				tmpl, err := template.New("filename").Parse(user.FilenameTemplate)
+				name, err := mediautil.RenderFilenameTemplate(user.FilenameTemplate, file.Message())
 				if err != nil {
-					logger.Errorf("Failed to parse filename template for user %d: %s", user.ChatID, err)
+					logger.Errorf("Failed to render filename template for user %d: %s", user.ChatID, err)
 					break
 				}
-				data := mediautil.BuildFilenameTemplateData(message)
-				var sb strings.Builder
-				err = tmpl.Execute(&sb, data)
-				if err != nil {
-					log.FromContext(ctx).Errorf("failed to execute filename template: %s", err)
-					break
-				}
-				file.SetName(sb.String())
+				file.SetName(name)
 			}
 
-			// Check if this is a media group and if rules specify NEW-FOR-ALBUM
-			groupID, isGroup := file.Message().GetGroupedID()
-			needAlbumHandling := false
-			if isGroup && groupID != 0 && user.ApplyRule && user.Rules != nil {
-				_, _, matchedDirPath := ruleutil.ApplyRule(ctx, user.Rules, ruleutil.NewInput(file))
-				needAlbumHandling = matchedDirPath.NeedNewForAlbum()
+			target, err := ruleutil.ResolveTarget(ctx, user, file, stor, "")
+			if err != nil {
+				logger.Errorf("Failed to get storage by user ID and name: %s", err)
+				continue
+			}
+			if target.Skip {
+				logger.Infof("Skipped media message for user %d in chat %d by rule: %s", chat.UserID, event.ChatID, file.Name())
+				continue
 			}
 
-			if needAlbumHandling {
-				// For media groups with NEW-FOR-ALBUM rule, collect all files of the same group
+			// For media groups with NEW-FOR-ALBUM rule, collect all files of the same group
+			groupID, isGroup := file.Message().GetGroupedID()
+			if isGroup && groupID != 0 && target.DirPath.NeedNewForAlbum() {
 				watchMediaGroupMgr.addFile(event.ChatID, user.ID, file, time.Duration(config.C().Telegram.MediaGroupTimeout)*time.Second, func(files []tfile.TGFileMessage) {
 					processWatchMediaGroup(ctx, user, stor, "", files)
 				})
@@ -293,31 +288,15 @@ func listenMediaMessageEvent(ch chan userclient.MediaMessageEvent) {
 			}
 
 			// Process single file or media group without album folder creation
-			var dirPath string
-			if user.ApplyRule && user.Rules != nil {
-				matched, matchedStorageName, matchedDirPath := ruleutil.ApplyRule(ctx, user.Rules, ruleutil.NewInput(file))
-				if !matched {
-					goto startCreateTask
-				}
-				dirPath = matchedDirPath.String()
-				if matchedStorageName.Usable() {
-					stor, err = storage.GetStorageByUserIDAndName(ctx, user.ChatID, matchedStorageName.String())
-					if err != nil {
-						logger.Errorf("Failed to get storage by user ID and name: %s", err)
-						continue
-					}
-				}
-			}
-		startCreateTask:
-			storagePath := path.Join(dirPath, file.Name())
-			injectCtx := tgutil.ExtWithContext(ctx.Context, ctx)
+			storagePath := path.Join(target.DirPath.String(), file.Name())
+			injectCtx := target.Context(tgutil.ExtWithContext(ctx.Context, ctx))
 			taskid := xid.New().String()
-			task, err := coretfile.NewTGFileTask(taskid, injectCtx, file, stor, storagePath, nil)
+			task, err := coretfile.NewTGFileTask(taskid, injectCtx, user.ChatID, file, target.Storage, storagePath, nil, target.Also...)
 			if err != nil {
 				logger.Errorf("create task failed: %s", err)
 				continue
 			}
-			if err := core.AddTask(injectCtx, task); err != nil {
+			if err := core.AddTask(injectCtx, task, queue.WithPriority(queue.PriorityLow)); err != nil {
 				logger.Errorf("add task failed: %s", err)
 				continue
 			}
@@ -332,41 +311,24 @@ func processWatchMediaGroup(ctx *ext.Context, user *database.User, stor storage.
 		return
 	}
 
-	useRule := user.ApplyRule && user.Rules != nil
-
-	applyRule := func(file tfile.TGFileMessage) (string, ruleutil.MatchedDirPath) {
-		if !useRule {
-			return stor.Name(), ruleutil.MatchedDirPath(dirPath)
-		}
-		matched, storName, dirP := ruleutil.ApplyRule(ctx, user.Rules, ruleutil.NewInput(file))
-		if !matched {
-			return stor.Name(), ruleutil.MatchedDirPath(dirPath)
-		}
-		storname := storName.String()
-		if !storName.Usable() {
-			storname = stor.Name()
-		}
-		return storname, dirP
-	}
-
 	type albumFile struct {
-		file    tfile.TGFileMessage
-		storage storage.Storage
+		file   tfile.TGFileMessage
+		target ruleutil.Target
 	}
 	albumFiles := make(map[int64][]albumFile)
 
 	// Collect files by group ID
 	for _, file := range files {
-		storName, ruleDirPath := applyRule(file)
-		fileStor := stor
-		if storName != stor.Name() && storName != "" {
-			var err error
-			fileStor, err = storage.GetStorageByUserIDAndName(ctx, user.ChatID, storName)
-			if err != nil {
-				logger.Errorf("Failed to get storage by user ID and name: %s", err)
-				continue
-			}
+		target, err := ruleutil.ResolveTarget(ctx, user, file, stor, dirPath)
+		if err != nil {
+			logger.Errorf("Failed to get storage by user ID and name: %s", err)
+			continue
+		}
+		if target.Skip {
+			logger.Infof("File %s is skipped by rule", file.Name())
+			continue
 		}
+		ruleDirPath := target.DirPath
 
 		groupId, isGroup := file.Message().GetGroupedID()
 		if !isGroup || groupId == 0 {
@@ -383,13 +345,13 @@ func processWatchMediaGroup(ctx *ext.Context, user *database.User, stor storage.
 			albumFiles[groupId] = make([]albumFile, 0)
 		}
 		albumFiles[groupId] = append(albumFiles[groupId], albumFile{
-			file:    file,
-			storage: fileStor,
+			file:   file,
+			target: target,
 		})
 	}
 
 	// Process album files with folder creation
-	injectCtx := tgutil.ExtWithContext(ctx.Context, ctx)
+	baseCtx := tgutil.ExtWithContext(ctx.Context, ctx)
 	totalTasks := 0
 	for groupID, afiles := range albumFiles {
 		if len(afiles) <= 1 {
@@ -398,19 +360,20 @@ func processWatchMediaGroup(ctx *ext.Context, user *database.User, stor storage.
 
 		// Use first file's name (without extension) as album folder name
 		albumDir := strings.TrimSuffix(path.Base(afiles[0].file.Name()), path.Ext(afiles[0].file.Name()))
-		albumStor := afiles[0].storage
+		albumTarget := afiles[0].target
 
 		logger.Infof("Creating album folder for group %d: %s with %d files", groupID, albumDir, len(afiles))
 
 		for _, af := range afiles {
 			afstorPath := path.Join(dirPath, albumDir, af.file.Name())
+			injectCtx := af.target.Context(baseCtx)
 			taskid := xid.New().String()
-			task, err := coretfile.NewTGFileTask(taskid, injectCtx, af.file, albumStor, afstorPath, nil)
+			task, err := coretfile.NewTGFileTask(taskid, injectCtx, user.ChatID, af.file, albumTarget.Storage, afstorPath, nil, albumTarget.Also...)
 			if err != nil {
 				logger.Errorf("create task failed for album file: %s", err)
 				continue
 			}
-			if err := core.AddTask(injectCtx, task); err != nil {
+			if err := core.AddTask(injectCtx, task, queue.WithPriority(queue.PriorityLow)); err != nil {
 				logger.Errorf("add task failed: %s", err)
 				continue
 			}
@@ -8,16 +8,21 @@ import (
 	"time"
 
 	"slices"
+	"strings"
 
 	"github.com/charmbracelet/log"
+	"github.com/dustin/go-humanize"
 	"github.com/kiss2u/SaveAny-Bot/client/bot"
 	userclient "github.com/kiss2u/SaveAny-Bot/client/user"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/cache"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/notify"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/core"
+	tftask "github.com/kiss2u/SaveAny-Bot/core/tasks/tfile"
 	"github.com/kiss2u/SaveAny-Bot/database"
 	"github.com/kiss2u/SaveAny-Bot/parsers"
 	"github.com/kiss2u/SaveAny-Bot/storage"
@@ -45,11 +50,13 @@ func Run(cmd *cobra.Command, _ []string) {
 	}()
 
 	core.Run(ctx)
+	restoreTasks(ctx)
+	watchConfig(ctx)
 
 	<-ctx.Done()
 	logger.Info("Exiting...")
 	defer logger.Info("Exit complete")
-	cleanCache()
+	cleanCache(context.WithoutCancel(ctx))
 }
 
 func initAll(ctx context.Context, cmd *cobra.Command) (<-chan struct{}, error) {
@@ -63,8 +70,11 @@ func initAll(ctx context.Context, cmd *cobra.Command) (<-chan struct{}, error) {
 	logger.Info("Initializing...")
 	database.Init(ctx)
 	// Initialize task state persistence
-	database.InitTaskState(ctx)
+	if err := database.InitTaskState(ctx); err != nil {
+		return nil, fmt.Errorf("failed to init task state: %w", err)
+	}
 	storage.LoadStorages(ctx)
+	bandwidth.Init(ctx)
 	if config.C().Parser.PluginEnable {
 		for _, dir := range config.C().Parser.PluginDirs {
 			if err := parsers.LoadPlugins(ctx, dir); err != nil {
@@ -86,13 +96,18 @@ func initAll(ctx context.Context, cmd *cobra.Command) (<-chan struct{}, error) {
 	botChan, botClient := bot.Init(ctx)
 
 	// Start health checker with admin notifications
+	var adminNotifier *notify.AdminNotifier
+	if bot.ExtContext() != nil {
+		// tells the chats chosen by the rules about the saved files
+		core.SetTaskNotifier(notify.NewTaskNotifier(bot.ExtContext()))
+	}
 	if botClient != nil {
 		healthChecker := bot.NewHealthChecker(botClient, 30*time.Second, 10)
 
 		// Setup admin notifications
 		adminIDs := getAdminUserIDs()
 		if len(adminIDs) > 0 && bot.ExtContext() != nil {
-			adminNotifier := notify.NewAdminNotifier(bot.ExtContext(), adminIDs)
+			adminNotifier = notify.NewAdminNotifier(bot.ExtContext(), adminIDs)
 			go adminNotifier.NotifyStartup()
 
 			healthChecker.OnDisconnected = func() {
@@ -109,10 +124,57 @@ func initAll(ctx context.Context, cmd *cobra.Command) (<-chan struct{}, error) {
 		go healthChecker.Start(ctx)
 		log.Info("Health checker started")
 	}
+	go core.StartHealthProbe(ctx, func(h storage.Health) {
+		if adminNotifier == nil {
+			return
+		}
+		if h.Healthy() {
+			adminNotifier.NotifyStorageRecovered(h.Name)
+			return
+		}
+		adminNotifier.NotifyStorageUnhealthy(h.Name, storageHealthReason(h))
+	})
 
 	return botChan, nil
 }
 
+// watchConfig reloads the config when the config file is changed
+func watchConfig(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	err := config.Watch(ctx, func() {
+		if _, err := core.ReloadConfig(ctx); err != nil {
+			logger.Error("Failed to reload config, the current config is kept", "error", err)
+		}
+	})
+	if err != nil {
+		logger.Error("Failed to watch config file", "error", err)
+	}
+}
+
+// restoreTasks re-enqueues the tasks left unfinished by the last run
+func restoreTasks(ctx context.Context) {
+	logger := log.FromContext(ctx)
+	if ectx := bot.ExtContext(); ectx != nil {
+		ctx = tgutil.ExtWithContext(ctx, ectx)
+	}
+	restored, err := core.RestoreTasks(ctx)
+	if err != nil {
+		logger.Error("Failed to restore tasks", "error", err)
+		return
+	}
+	if restored > 0 {
+		logger.Infof("Restored %d unfinished tasks", restored)
+	}
+}
+
+// storageHealthReason tells why the storage is unhealthy
+func storageHealthReason(h storage.Health) string {
+	if !h.Reachable {
+		return h.Error
+	}
+	return fmt.Sprintf("剩余空间不足, 剩余 %s", humanize.IBytes(uint64(h.Stat.Free)))
+}
+
 func getAdminUserIDs() []int64 {
 	var ids []int64
 	for _, user := range config.C().Users {
@@ -138,7 +200,10 @@ func startWebServer(ctx context.Context) *web.Server {
 	return server
 }
 
-func cleanCache() {
+// cleanCache removes the cache files, except the ones of unfinished tasks which will be resumed on next startup
+// and the recent partial downloads of telegram files, which are resumed by the next task saving the same file.
+// The cache files of a task are named with the task id as prefix.
+func cleanCache(ctx context.Context) {
 	if config.C().NoCleanCache {
 		return
 	}
@@ -158,8 +223,26 @@ func cleanCache() {
 			log.Error("Failed to get absolute cache path", "error", err)
 			return
 		}
+		pendingIDs := make(map[string]struct{})
+		if states, err := database.GetPendingTasks(ctx); err != nil {
+			log.Error("Failed to get pending tasks, their cache files will be removed", "error", err)
+		} else {
+			for _, state := range states {
+				pendingIDs[state.ID] = struct{}{}
+			}
+		}
 		log.Info("Cleaning cache directory", "path", cachePath)
-		if err := fsutil.RemoveAllInDir(cachePath); err != nil {
+		if err := fsutil.RemoveAllInDirExcept(cachePath, func(name string) bool {
+			if tftask.IsResumableCache(cachePath, name) {
+				return true
+			}
+			id, _, ok := strings.Cut(name, "_")
+			if !ok {
+				return false
+			}
+			_, pending := pendingIDs[id]
+			return pending
+		}); err != nil {
 			log.Error("Failed to clean cache directory", "error", err)
 		}
 	}
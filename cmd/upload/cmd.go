@@ -10,6 +10,7 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/kiss2u/SaveAny-Bot/client/bot"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/cache"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/ioutil"
@@ -65,6 +66,7 @@ func Upload(cmd *cobra.Command, args []string) error {
 	i18n.Init(config.C().Lang)
 	cache.Init()
 	database.Init(ctx)
+	bandwidth.Init(ctx)
 
 	stor, err := storage.GetStorageByName(ctx, storname)
 	if err != nil {
@@ -113,7 +115,7 @@ func Upload(cmd *cobra.Command, args []string) error {
 		reader = file
 	}
 
-	if err := stor.Save(ctx, reader, uploadPath); err != nil {
+	if _, err := storage.Save(ctx, stor, reader, uploadPath); err != nil {
 		if progressUI != nil {
 			progressUI.SetError(err)
 			progressUI.Wait()
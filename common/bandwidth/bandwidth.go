@@ -0,0 +1,206 @@
+// Package bandwidth limits the download and upload traffic of tasks,
+// the limits come from the config and can be changed at runtime.
+package bandwidth
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/pkg/ratelimit"
+)
+
+var (
+	download = ratelimit.New(0)
+	upload   = ratelimit.New(0)
+
+	mu           sync.Mutex
+	downloadBase int64 // limits set by the config or at runtime, overridden by an active schedule
+	uploadBase   int64
+	schedule     int // index of the active schedule, -1 if none
+	storages     = make(map[string]*ratelimit.Limiter)
+)
+
+// Status is the current bandwidth limits in bytes per second, 0 means unlimited
+type Status struct {
+	Download     int64            `json:"download"`      // effective download limit
+	Upload       int64            `json:"upload"`        // effective upload limit
+	DownloadBase int64            `json:"download_base"` // download limit outside of the schedules
+	UploadBase   int64            `json:"upload_base"`
+	Scheduled    bool             `json:"scheduled"` // whether a schedule is active
+	Storages     map[string]int64 `json:"storages"`  // upload limits per storage
+}
+
+// Init loads the limits from the config and applies the schedules until ctx is done
+func Init(ctx context.Context) {
+	cfg := config.C().Bandwidth
+	// validated when loading the config
+	dl, _ := config.ParseBandwidth(cfg.Download)
+	ul, _ := config.ParseBandwidth(cfg.Upload)
+	mu.Lock()
+	downloadBase, uploadBase = dl, ul
+	schedule = -1
+	mu.Unlock()
+	apply(time.Now())
+	if len(cfg.Schedules) == 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				apply(now)
+			}
+		}
+	}()
+}
+
+// apply sets the effective limits according to the schedule active at now
+func apply(now time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	active := -1
+	for i, s := range config.C().Bandwidth.Schedules {
+		if s.Contains(now) {
+			active = i
+			break
+		}
+	}
+	if active != schedule {
+		if active >= 0 {
+			log.Infof("Bandwidth schedule %d is now active", active)
+		} else if schedule >= 0 {
+			log.Infof("Bandwidth schedule %d is no longer active", schedule)
+		}
+		schedule = active
+	}
+	if active < 0 {
+		download.SetLimit(downloadBase)
+		upload.SetLimit(uploadBase)
+		return
+	}
+	s := config.C().Bandwidth.Schedules[active]
+	dl, _ := config.ParseBandwidth(s.Download)
+	ul, _ := config.ParseBandwidth(s.Upload)
+	download.SetLimit(dl)
+	upload.SetLimit(ul)
+}
+
+// SetDownload changes the global download limit, it takes effect outside of the schedules
+func SetDownload(bytesPerSec int64) {
+	mu.Lock()
+	downloadBase = max(bytesPerSec, 0)
+	mu.Unlock()
+	apply(time.Now())
+}
+
+// SetUpload changes the global upload limit, it takes effect outside of the schedules
+func SetUpload(bytesPerSec int64) {
+	mu.Lock()
+	uploadBase = max(bytesPerSec, 0)
+	mu.Unlock()
+	apply(time.Now())
+}
+
+// SetStorage changes the upload limit of the storage
+func SetStorage(name string, bytesPerSec int64) error {
+	l := storageLimiter(name)
+	if l == nil {
+		return fmt.Errorf("storage %s not found", name)
+	}
+	l.SetLimit(bytesPerSec)
+	return nil
+}
+
+// ResetStorage drops the upload limit of the storage, it is loaded from the config again when the storage is used,
+// after the storage is changed or removed
+func ResetStorage(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(storages, name)
+}
+
+func storageLimiter(name string) *ratelimit.Limiter {
+	mu.Lock()
+	defer mu.Unlock()
+	if l, ok := storages[name]; ok {
+		return l
+	}
+	cfg := config.C().GetStorageByName(name)
+	if cfg == nil {
+		return nil
+	}
+	limit, _ := config.ParseBandwidth(cfg.GetBandwidthLimit())
+	l := ratelimit.New(limit)
+	storages[name] = l
+	return l
+}
+
+func GetStatus() Status {
+	st := Status{
+		Download: download.Limit(),
+		Upload:   upload.Limit(),
+		Storages: make(map[string]int64),
+	}
+	for _, cfg := range config.C().Storages {
+		st.Storages[cfg.GetName()] = storageLimiter(cfg.GetName()).Limit()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	st.DownloadBase = downloadBase
+	st.UploadBase = uploadBase
+	st.Scheduled = schedule >= 0
+	return st
+}
+
+// DownloadLimit returns the effective download limit in bytes per second, 0 means unlimited
+func DownloadLimit() int64 {
+	return download.Limit()
+}
+
+// DownloadReader limits the reading of a download
+func DownloadReader(ctx context.Context, r io.Reader) io.Reader {
+	return ratelimit.NewReader(ctx, r, download)
+}
+
+// DownloadWriter limits the writing of a download
+func DownloadWriter(ctx context.Context, w io.Writer) io.Writer {
+	return ratelimit.NewWriter(ctx, w, download)
+}
+
+// DownloadWriterAt limits the writing of a parallel download
+func DownloadWriterAt(ctx context.Context, w io.WriterAt) io.WriterAt {
+	return ratelimit.NewWriterAt(ctx, w, download)
+}
+
+// UploadReader limits the reading of an upload to the storage, by both the global and the storage limits.
+// If r is an io.ReadSeeker, the returned reader is an io.ReadSeeker as well.
+//
+// r is returned as is when there is no limit at all, some clients (e.g. net/http) detect the
+// length of readers like *bytes.Reader which a wrapped reader would hide.
+func UploadReader(ctx context.Context, storageName string, r io.Reader) io.Reader {
+	stor := storageLimiter(storageName)
+	if len(config.C().Bandwidth.Schedules) == 0 && upload.Limit() == 0 && stor.Limit() == 0 {
+		return r
+	}
+	return ratelimit.NewReader(ctx, r, upload, stor)
+}
+
+// StorageReader limits the reading of an upload by the storage limit only.
+// It is meant for the storages wrapping other storages (e.g. mirror, encrypt), the global limit is applied
+// by the wrapped storages, which would otherwise charge it once more for the same bytes.
+func StorageReader(ctx context.Context, storageName string, r io.Reader) io.Reader {
+	stor := storageLimiter(storageName)
+	if stor.Limit() == 0 {
+		return r
+	}
+	return ratelimit.NewReader(ctx, r, stor)
+}
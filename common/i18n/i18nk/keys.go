@@ -10,6 +10,26 @@ const (
 	BotMsgAria2InfoAddingAria2Download                    Key = "bot.msg.aria2.info_adding_aria2_download"
 	BotMsgAria2InfoAria2DownloadAdded                     Key = "bot.msg.aria2.info_aria2_download_added"
 	BotMsgAria2InfoSelectStorage                          Key = "bot.msg.aria2.info_select_storage"
+	BotMsgBrowseButtonBack                                Key = "bot.msg.browse.button_back"
+	BotMsgBrowseButtonInfo                                Key = "bot.msg.browse.button_info"
+	BotMsgBrowseButtonNext                                Key = "bot.msg.browse.button_next"
+	BotMsgBrowseButtonPrev                                Key = "bot.msg.browse.button_prev"
+	BotMsgBrowseButtonSend                                Key = "bot.msg.browse.button_send"
+	BotMsgBrowseButtonTransfer                            Key = "bot.msg.browse.button_transfer"
+	BotMsgBrowseButtonUp                                  Key = "bot.msg.browse.button_up"
+	BotMsgBrowseDirTitle                                  Key = "bot.msg.browse.dir_title"
+	BotMsgBrowseEmpty                                     Key = "bot.msg.browse.empty"
+	BotMsgBrowseErrorListFilesFailed                      Key = "bot.msg.browse.error_list_files_failed"
+	BotMsgBrowseErrorNoListableStorage                    Key = "bot.msg.browse.error_no_listable_storage"
+	BotMsgBrowseErrorSendFailed                           Key = "bot.msg.browse.error_send_failed"
+	BotMsgBrowseErrorStorageNotListable                   Key = "bot.msg.browse.error_storage_not_listable"
+	BotMsgBrowseErrorStorageNotReadable                   Key = "bot.msg.browse.error_storage_not_readable"
+	BotMsgBrowseFileInfo                                  Key = "bot.msg.browse.file_info"
+	BotMsgBrowseFileTitle                                 Key = "bot.msg.browse.file_title"
+	BotMsgBrowseInfoSending                               Key = "bot.msg.browse.info_sending"
+	BotMsgBrowsePageInfo                                  Key = "bot.msg.browse.page_info"
+	BotMsgBrowsePromptSelectStorage                       Key = "bot.msg.browse.prompt_select_storage"
+	BotMsgBrowseUsage                                     Key = "bot.msg.browse.usage"
 	BotMsgCancelErrorCancelFailed                         Key = "bot.msg.cancel.error_cancel_failed"
 	BotMsgCancelInfoCancelRequested                       Key = "bot.msg.cancel.info_cancel_requested"
 	BotMsgCancelInfoCancellingTask                        Key = "bot.msg.cancel.info_cancelling_task"
@@ -19,11 +39,19 @@ const (
 	BotMsgCmdConfig                                       Key = "bot.msg.cmd.config"
 	BotMsgCmdDir                                          Key = "bot.msg.cmd.dir"
 	BotMsgCmdDl                                           Key = "bot.msg.cmd.dl"
+	BotMsgCmdExport                                       Key = "bot.msg.cmd.export"
 	BotMsgCmdFnametmpl                                    Key = "bot.msg.cmd.fnametmpl"
 	BotMsgCmdHelp                                         Key = "bot.msg.cmd.help"
+	BotMsgCmdHistory                                      Key = "bot.msg.cmd.history"
 	BotMsgCmdImport                                       Key = "bot.msg.cmd.import"
+	BotMsgCmdLimit                                        Key = "bot.msg.cmd.limit"
+	BotMsgCmdLs                                           Key = "bot.msg.cmd.ls"
 	BotMsgCmdLswatch                                      Key = "bot.msg.cmd.lswatch"
+	BotMsgCmdMkdir                                        Key = "bot.msg.cmd.mkdir"
+	BotMsgCmdMv                                           Key = "bot.msg.cmd.mv"
 	BotMsgCmdParser                                       Key = "bot.msg.cmd.parser"
+	BotMsgCmdReload                                       Key = "bot.msg.cmd.reload"
+	BotMsgCmdRm                                           Key = "bot.msg.cmd.rm"
 	BotMsgCmdRule                                         Key = "bot.msg.cmd.rule"
 	BotMsgCmdSave                                         Key = "bot.msg.cmd.save"
 	BotMsgCmdSilent                                       Key = "bot.msg.cmd.silent"
@@ -63,13 +91,13 @@ const (
 	BotMsgCommonErrorTaskAddFailed                        Key = "bot.msg.common.error_task_add_failed"
 	BotMsgCommonErrorTaskCreateFailed                     Key = "bot.msg.common.error_task_create_failed"
 	BotMsgCommonErrorUpdateUserInfoFailed                 Key = "bot.msg.common.error_update_user_info_failed"
-	BotMsgCommonErrorUserGeneric                         Key = "bot.msg.common.error_user_generic"
-	BotMsgCommonErrorUserStorageAccess                   Key = "bot.msg.common.error_user_storage_access"
-	BotMsgCommonErrorUserTaskQueueFull                   Key = "bot.msg.common.error_user_task_queue_full"
-	BotMsgCommonErrorUserFileNotFound                    Key = "bot.msg.common.error_user_file_not_found"
-	BotMsgCommonErrorUserPermissionDenied                Key = "bot.msg.common.error_user_permission_denied"
-	BotMsgCommonErrorUserInvalidInput                    Key = "bot.msg.common.error_user_invalid_input"
-	BotMsgCommonErrorUserNetwork                         Key = "bot.msg.common.error_user_network"
+	BotMsgCommonErrorUserFileNotFound                     Key = "bot.msg.common.error_user_file_not_found"
+	BotMsgCommonErrorUserGeneric                          Key = "bot.msg.common.error_user_generic"
+	BotMsgCommonErrorUserInvalidInput                     Key = "bot.msg.common.error_user_invalid_input"
+	BotMsgCommonErrorUserNetwork                          Key = "bot.msg.common.error_user_network"
+	BotMsgCommonErrorUserPermissionDenied                 Key = "bot.msg.common.error_user_permission_denied"
+	BotMsgCommonErrorUserStorageAccess                    Key = "bot.msg.common.error_user_storage_access"
+	BotMsgCommonErrorUserTaskQueueFull                    Key = "bot.msg.common.error_user_task_queue_full"
 	BotMsgCommonInfoBatchTasksAdded                       Key = "bot.msg.common.info_batch_tasks_added"
 	BotMsgCommonInfoDefaultStorageSet                     Key = "bot.msg.common.info_default_storage_set"
 	BotMsgCommonInfoDefaultStorageWithDirSet              Key = "bot.msg.common.info_default_storage_with_dir_set"
@@ -83,13 +111,16 @@ const (
 	BotMsgCommonPromptSelectDefaultDir                    Key = "bot.msg.common.prompt_select_default_dir"
 	BotMsgCommonPromptSelectDefaultStorage                Key = "bot.msg.common.prompt_select_default_storage"
 	BotMsgCommonPromptSelectDir                           Key = "bot.msg.common.prompt_select_dir"
+	BotMsgConfigButtonDedupPolicy                         Key = "bot.msg.config.button_dedup_policy"
 	BotMsgConfigButtonFilenameStrategy                    Key = "bot.msg.config.button_filename_strategy"
 	BotMsgConfigErrorInvalidCallbackData                  Key = "bot.msg.config.error_invalid_callback_data"
 	BotMsgConfigErrorInvalidTemplate                      Key = "bot.msg.config.error_invalid_template"
 	BotMsgConfigFnametmplHelp                             Key = "bot.msg.config.fnametmpl_help"
 	BotMsgConfigInfoCurrentTemplatePrefix                 Key = "bot.msg.config.info_current_template_prefix"
+	BotMsgConfigInfoDedupPolicySet                        Key = "bot.msg.config.info_dedup_policy_set"
 	BotMsgConfigInfoFilenameStrategySet                   Key = "bot.msg.config.info_filename_strategy_set"
 	BotMsgConfigInfoTemplateUpdated                       Key = "bot.msg.config.info_template_updated"
+	BotMsgConfigPromptSelectDedupPolicy                   Key = "bot.msg.config.prompt_select_dedup_policy"
 	BotMsgConfigPromptSelectFilenameStrategy              Key = "bot.msg.config.prompt_select_filename_strategy"
 	BotMsgConfigPromptSelectOption                        Key = "bot.msg.config.prompt_select_option"
 	BotMsgDirButtonDefault                                Key = "bot.msg.dir.button_default"
@@ -113,7 +144,57 @@ const (
 	BotMsgDlErrorNoValidLinks                             Key = "bot.msg.dl.error_no_valid_links"
 	BotMsgDlInfoFilesSelectStorage                        Key = "bot.msg.dl.info_files_select_storage"
 	BotMsgDlUsage                                         Key = "bot.msg.dl.usage"
+	BotMsgExportErrorExportFailed                         Key = "bot.msg.export.error_export_failed"
+	BotMsgExportInfoExported                              Key = "bot.msg.export.info_exported"
+	BotMsgExportUsage                                     Key = "bot.msg.export.usage"
+	BotMsgFsErrorDeleteNotSupported                       Key = "bot.msg.fs.error_delete_not_supported"
+	BotMsgFsErrorFailed                                   Key = "bot.msg.fs.error_failed"
+	BotMsgFsErrorInvalidPath                              Key = "bot.msg.fs.error_invalid_path"
+	BotMsgFsErrorMkdirNotSupported                        Key = "bot.msg.fs.error_mkdir_not_supported"
+	BotMsgFsErrorMoveNotSupported                         Key = "bot.msg.fs.error_move_not_supported"
+	BotMsgFsErrorStorageNotFound                          Key = "bot.msg.fs.error_storage_not_found"
+	BotMsgFsInfoDeleted                                   Key = "bot.msg.fs.info_deleted"
+	BotMsgFsInfoDirCreated                                Key = "bot.msg.fs.info_dir_created"
+	BotMsgFsInfoMoveSkipped                               Key = "bot.msg.fs.info_move_skipped"
+	BotMsgFsInfoMoved                                     Key = "bot.msg.fs.info_moved"
+	BotMsgFsMkdirUsage                                    Key = "bot.msg.fs.mkdir_usage"
+	BotMsgFsMvUsage                                       Key = "bot.msg.fs.mv_usage"
+	BotMsgFsRmUsage                                       Key = "bot.msg.fs.rm_usage"
 	BotMsgHelpTextFmt                                     Key = "bot.msg.help_text_fmt"
+	BotMsgHistoryButtonNext                               Key = "bot.msg.history.button_next"
+	BotMsgHistoryButtonPrev                               Key = "bot.msg.history.button_prev"
+	BotMsgHistoryEmpty                                    Key = "bot.msg.history.empty"
+	BotMsgHistoryErrorGetHistoryFailed                    Key = "bot.msg.history.error_get_history_failed"
+	BotMsgHistoryErrorInvalidFilter                       Key = "bot.msg.history.error_invalid_filter"
+	BotMsgHistoryFieldDuration                            Key = "bot.msg.history.field_duration"
+	BotMsgHistoryFieldError                               Key = "bot.msg.history.field_error"
+	BotMsgHistoryFieldFinished                            Key = "bot.msg.history.field_finished"
+	BotMsgHistoryFieldPath                                Key = "bot.msg.history.field_path"
+	BotMsgHistoryFieldSize                                Key = "bot.msg.history.field_size"
+	BotMsgHistoryFieldTags                                Key = "bot.msg.history.field_tags"
+	BotMsgHistoryPageInfo                                 Key = "bot.msg.history.page_info"
+	BotMsgHistoryStatusCancelled                          Key = "bot.msg.history.status_cancelled"
+	BotMsgHistoryStatusCompleted                          Key = "bot.msg.history.status_completed"
+	BotMsgHistoryStatusFailed                             Key = "bot.msg.history.status_failed"
+	BotMsgHistoryTitle                                    Key = "bot.msg.history.title"
+	BotMsgHistoryUsage                                    Key = "bot.msg.history.usage"
+	BotMsgImportErrorDownloadFileFailed                   Key = "bot.msg.import.error_download_file_failed"
+	BotMsgImportErrorFileTooLarge                         Key = "bot.msg.import.error_file_too_large"
+	BotMsgImportErrorImportFailed                         Key = "bot.msg.import.error_import_failed"
+	BotMsgImportErrorInvalidDocument                      Key = "bot.msg.import.error_invalid_document"
+	BotMsgImportErrorNoFileInReply                        Key = "bot.msg.import.error_no_file_in_reply"
+	BotMsgImportInfoImported                              Key = "bot.msg.import.info_imported"
+	BotMsgImportUsage                                     Key = "bot.msg.import.usage"
+	BotMsgLimitFieldDownload                              Key = "bot.msg.limit.field_download"
+	BotMsgLimitFieldStoragePrefix                         Key = "bot.msg.limit.field_storage_prefix"
+	BotMsgLimitFieldUpload                                Key = "bot.msg.limit.field_upload"
+	BotMsgLimitInvalidRate                                Key = "bot.msg.limit.invalid_rate"
+	BotMsgLimitScheduled                                  Key = "bot.msg.limit.scheduled"
+	BotMsgLimitSetFailed                                  Key = "bot.msg.limit.set_failed"
+	BotMsgLimitTitle                                      Key = "bot.msg.limit.title"
+	BotMsgLimitUnlimited                                  Key = "bot.msg.limit.unlimited"
+	BotMsgLimitUpdated                                    Key = "bot.msg.limit.updated"
+	BotMsgLimitUsage                                      Key = "bot.msg.limit.usage"
 	BotMsgMediaGroupErrorBuildStorageSelectKeyboardFailed Key = "bot.msg.media_group.error_build_storage_select_keyboard_failed"
 	BotMsgMediaGroupInfoGroupFoundFilesSelectStorage      Key = "bot.msg.media_group.info_group_found_files_select_storage"
 	BotMsgMediaGroupInfoSavingFiles                       Key = "bot.msg.media_group.info_saving_files"
@@ -154,18 +235,29 @@ const (
 	BotMsgProgressDownloadedPrefix                        Key = "bot.msg.progress.downloaded_prefix"
 	BotMsgProgressDownloadingPrefix                       Key = "bot.msg.progress.downloading_prefix"
 	BotMsgProgressErrorPrefix                             Key = "bot.msg.progress.error_prefix"
+	BotMsgProgressFileAlreadySavedPrefix                  Key = "bot.msg.progress.file_already_saved_prefix"
+	BotMsgProgressFileExistsSkippedPrefix                 Key = "bot.msg.progress.file_exists_skipped_prefix"
+	BotMsgProgressFileLinkedPrefix                        Key = "bot.msg.progress.file_linked_prefix"
 	BotMsgProgressFileNamePrefix                          Key = "bot.msg.progress.file_name_prefix"
 	BotMsgProgressFileProcessingPrefix                    Key = "bot.msg.progress.file_processing_prefix"
 	BotMsgProgressFileSizePrefix                          Key = "bot.msg.progress.file_size_prefix"
 	BotMsgProgressFileStartPrefix                         Key = "bot.msg.progress.file_start_prefix"
+	BotMsgProgressLinkedAtPrefix                          Key = "bot.msg.progress.linked_at_prefix"
+	BotMsgProgressLinkedToPrefix                          Key = "bot.msg.progress.linked_to_prefix"
+	BotMsgProgressMirrorMemberErrorPrefix                 Key = "bot.msg.progress.mirror_member_error_prefix"
+	BotMsgProgressMirrorMemberSaved                       Key = "bot.msg.progress.mirror_member_saved"
+	BotMsgProgressMirrorResultsPrefix                     Key = "bot.msg.progress.mirror_results_prefix"
 	BotMsgProgressParsedDonePrefix                        Key = "bot.msg.progress.parsed_done_prefix"
 	BotMsgProgressParsedStartPrefix                       Key = "bot.msg.progress.parsed_start_prefix"
 	BotMsgProgressProcessingListPrefix                    Key = "bot.msg.progress.processing_list_prefix"
 	BotMsgProgressProcessingNone                          Key = "bot.msg.progress.processing_none"
 	BotMsgProgressSavePathPrefix                          Key = "bot.msg.progress.save_path_prefix"
+	BotMsgProgressSkippedAlreadySavedPrefix               Key = "bot.msg.progress.skipped_already_saved_prefix"
 	BotMsgProgressTaskCanceled                            Key = "bot.msg.progress.task_canceled"
 	BotMsgProgressTaskCanceledWithId                      Key = "bot.msg.progress.task_canceled_with_id"
 	BotMsgProgressTaskFailedWithError                     Key = "bot.msg.progress.task_failed_with_error"
+	BotMsgProgressTaskPaused                              Key = "bot.msg.progress.task_paused"
+	BotMsgProgressTaskPausedWithId                        Key = "bot.msg.progress.task_paused_with_id"
 	BotMsgProgressTelegraphDonePrefix                     Key = "bot.msg.progress.telegraph_done_prefix"
 	BotMsgProgressTelegraphProgressPrefix                 Key = "bot.msg.progress.telegraph_progress_prefix"
 	BotMsgProgressTelegraphStartPrefix                    Key = "bot.msg.progress.telegraph_start_prefix"
@@ -187,33 +279,74 @@ const (
 	BotMsgProgressYtdlpDone                               Key = "bot.msg.progress.ytdlp_done"
 	BotMsgProgressYtdlpDownloading                        Key = "bot.msg.progress.ytdlp_downloading"
 	BotMsgProgressYtdlpStart                              Key = "bot.msg.progress.ytdlp_start"
+	BotMsgReloadAppliedPrefix                             Key = "bot.msg.reload.applied_prefix"
+	BotMsgReloadErrorsPrefix                              Key = "bot.msg.reload.errors_prefix"
+	BotMsgReloadFailed                                    Key = "bot.msg.reload.failed"
+	BotMsgReloadRestartPrefix                             Key = "bot.msg.reload.restart_prefix"
+	BotMsgReloadStoragesAddedPrefix                       Key = "bot.msg.reload.storages_added_prefix"
+	BotMsgReloadStoragesChangedPrefix                     Key = "bot.msg.reload.storages_changed_prefix"
+	BotMsgReloadStoragesRemovedPrefix                     Key = "bot.msg.reload.storages_removed_prefix"
+	BotMsgReloadTitle                                     Key = "bot.msg.reload.title"
+	BotMsgReloadUnchanged                                 Key = "bot.msg.reload.unchanged"
 	BotMsgRuleErrorCreateRuleFailed                       Key = "bot.msg.rule.error_create_rule_failed"
 	BotMsgRuleErrorDeleteRuleFailed                       Key = "bot.msg.rule.error_delete_rule_failed"
 	BotMsgRuleErrorGetUserRulesFailed                     Key = "bot.msg.rule.error_get_user_rules_failed"
+	BotMsgRuleErrorInvalidAction                          Key = "bot.msg.rule.error_invalid_action"
+	BotMsgRuleErrorInvalidPriority                        Key = "bot.msg.rule.error_invalid_priority"
+	BotMsgRuleErrorInvalidRuleData                        Key = "bot.msg.rule.error_invalid_rule_data"
 	BotMsgRuleErrorInvalidRuleId                          Key = "bot.msg.rule.error_invalid_rule_id"
 	BotMsgRuleErrorInvalidRuleType                        Key = "bot.msg.rule.error_invalid_rule_type"
 	BotMsgRuleErrorUpdateUserFailed                       Key = "bot.msg.rule.error_update_user_failed"
+	BotMsgRuleHelpActions                                 Key = "bot.msg.rule.help_actions"
 	BotMsgRuleHelpAddSuffix                               Key = "bot.msg.rule.help_add_suffix"
 	BotMsgRuleHelpAvailableOps                            Key = "bot.msg.rule.help_available_ops"
 	BotMsgRuleHelpCurrentModeDisabled                     Key = "bot.msg.rule.help_current_mode_disabled"
 	BotMsgRuleHelpCurrentModeEnabled                      Key = "bot.msg.rule.help_current_mode_enabled"
 	BotMsgRuleHelpDelSuffix                               Key = "bot.msg.rule.help_del_suffix"
 	BotMsgRuleHelpExistingRulesPrefix                     Key = "bot.msg.rule.help_existing_rules_prefix"
+	BotMsgRuleHelpExprPrefix                              Key = "bot.msg.rule.help_expr_prefix"
 	BotMsgRuleHelpSwitchSuffix                            Key = "bot.msg.rule.help_switch_suffix"
+	BotMsgRuleHelpTestSuffix                              Key = "bot.msg.rule.help_test_suffix"
 	BotMsgRuleHelpUsage                                   Key = "bot.msg.rule.help_usage"
 	BotMsgRuleInfoCreateRuleSuccess                       Key = "bot.msg.rule.info_create_rule_success"
 	BotMsgRuleInfoDeleteRuleSuccess                       Key = "bot.msg.rule.info_delete_rule_success"
 	BotMsgRuleInfoRuleModeDisabled                        Key = "bot.msg.rule.info_rule_mode_disabled"
 	BotMsgRuleInfoRuleModeEnabled                         Key = "bot.msg.rule.info_rule_mode_enabled"
+	BotMsgRuleInfoSkipped                                 Key = "bot.msg.rule.info_skipped"
+	BotMsgRuleNotifyFailed                                Key = "bot.msg.rule.notify_failed"
+	BotMsgRuleNotifySaved                                 Key = "bot.msg.rule.notify_saved"
 	BotMsgRulePromptProvideRuleId                         Key = "bot.msg.rule.prompt_provide_rule_id"
+	BotMsgRuleTestError                                   Key = "bot.msg.rule.test_error"
+	BotMsgRuleTestFieldAlso                               Key = "bot.msg.rule.test_field_also"
+	BotMsgRuleTestFieldConflict                           Key = "bot.msg.rule.test_field_conflict"
+	BotMsgRuleTestFieldNotify                             Key = "bot.msg.rule.test_field_notify"
+	BotMsgRuleTestFieldPath                               Key = "bot.msg.rule.test_field_path"
+	BotMsgRuleTestFieldStorage                            Key = "bot.msg.rule.test_field_storage"
+	BotMsgRuleTestFieldTags                               Key = "bot.msg.rule.test_field_tags"
+	BotMsgRuleTestMatched                                 Key = "bot.msg.rule.test_matched"
+	BotMsgRuleTestModeDisabled                            Key = "bot.msg.rule.test_mode_disabled"
+	BotMsgRuleTestNoMatch                                 Key = "bot.msg.rule.test_no_match"
+	BotMsgRuleTestNoStorage                               Key = "bot.msg.rule.test_no_storage"
+	BotMsgRuleTestSkipped                                 Key = "bot.msg.rule.test_skipped"
+	BotMsgRuleTestTitle                                   Key = "bot.msg.rule.test_title"
+	BotMsgRuleTestUsage                                   Key = "bot.msg.rule.test_usage"
 	BotMsgSaveErrorInvalidIdOrUsername                    Key = "bot.msg.save.error_invalid_id_or_username"
 	BotMsgSaveHelpText                                    Key = "bot.msg.save_help_text"
+	BotMsgStorageButtonConflictPolicy                     Key = "bot.msg.storage.button_conflict_policy"
+	BotMsgStorageConflictPolicyDefault                    Key = "bot.msg.storage.conflict_policy_default"
 	BotMsgStorageInfoFilenamePrefix                       Key = "bot.msg.storage.info_filename_prefix"
 	BotMsgStorageInfoPromptSelectStorage                  Key = "bot.msg.storage.info_prompt_select_storage"
 	BotMsgSyncpeersDone                                   Key = "bot.msg.syncpeers.done"
 	BotMsgSyncpeersFailed                                 Key = "bot.msg.syncpeers.failed"
 	BotMsgSyncpeersStart                                  Key = "bot.msg.syncpeers.start"
 	BotMsgSyncpeersSuccess                                Key = "bot.msg.syncpeers.success"
+	BotMsgTasksButtonCancel                               Key = "bot.msg.tasks.button_cancel"
+	BotMsgTasksButtonDown                                 Key = "bot.msg.tasks.button_down"
+	BotMsgTasksButtonPause                                Key = "bot.msg.tasks.button_pause"
+	BotMsgTasksButtonRefresh                              Key = "bot.msg.tasks.button_refresh"
+	BotMsgTasksButtonResume                               Key = "bot.msg.tasks.button_resume"
+	BotMsgTasksButtonTop                                  Key = "bot.msg.tasks.button_top"
+	BotMsgTasksButtonUp                                   Key = "bot.msg.tasks.button_up"
 	BotMsgTasksCancelFailed                               Key = "bot.msg.tasks.cancel_failed"
 	BotMsgTasksCancelRequestedPrefix                      Key = "bot.msg.tasks.cancel_requested_prefix"
 	BotMsgTasksFieldCreated                               Key = "bot.msg.tasks.field_created"
@@ -222,19 +355,31 @@ const (
 	BotMsgTasksFieldTitle                                 Key = "bot.msg.tasks.field_title"
 	BotMsgTasksInfoAddedToQueueFull                       Key = "bot.msg.tasks.info_added_to_queue_full"
 	BotMsgTasksInfoAddedToQueuePrefix                     Key = "bot.msg.tasks.info_added_to_queue_prefix"
+	BotMsgTasksInfoDone                                   Key = "bot.msg.tasks.info_done"
 	BotMsgTasksInfoFilenamePrefix                         Key = "bot.msg.tasks.info_filename_prefix"
 	BotMsgTasksInfoQueueLengthPrefix                      Key = "bot.msg.tasks.info_queue_length_prefix"
+	BotMsgTasksMoveFailed                                 Key = "bot.msg.tasks.move_failed"
+	BotMsgTasksMovedPrefix                                Key = "bot.msg.tasks.moved_prefix"
+	BotMsgTasksPauseFailed                                Key = "bot.msg.tasks.pause_failed"
+	BotMsgTasksPauseRequestedPrefix                       Key = "bot.msg.tasks.pause_requested_prefix"
 	BotMsgTasksQueuedEmpty                                Key = "bot.msg.tasks.queued_empty"
 	BotMsgTasksQueuedTitle                                Key = "bot.msg.tasks.queued_title"
+	BotMsgTasksResumeFailed                               Key = "bot.msg.tasks.resume_failed"
+	BotMsgTasksResumedPrefix                              Key = "bot.msg.tasks.resumed_prefix"
 	BotMsgTasksRunningEmpty                               Key = "bot.msg.tasks.running_empty"
 	BotMsgTasksRunningTitle                               Key = "bot.msg.tasks.running_title"
 	BotMsgTasksStatusCancelRequested                      Key = "bot.msg.tasks.status_cancel_requested"
+	BotMsgTasksStatusPauseRequested                       Key = "bot.msg.tasks.status_pause_requested"
+	BotMsgTasksStatusPaused                               Key = "bot.msg.tasks.status_paused"
 	BotMsgTasksStatusQueued                               Key = "bot.msg.tasks.status_queued"
 	BotMsgTasksStatusRunning                              Key = "bot.msg.tasks.status_running"
 	BotMsgTasksTotalPrefix                                Key = "bot.msg.tasks.total_prefix"
 	BotMsgTasksTruncatedNote                              Key = "bot.msg.tasks.truncated_note"
 	BotMsgTasksUsage                                      Key = "bot.msg.tasks.usage"
 	BotMsgTasksUsageCancel                                Key = "bot.msg.tasks.usage_cancel"
+	BotMsgTasksUsageMove                                  Key = "bot.msg.tasks.usage_move"
+	BotMsgTasksUsagePause                                 Key = "bot.msg.tasks.usage_pause"
+	BotMsgTasksUsageResume                                Key = "bot.msg.tasks.usage_resume"
 	BotMsgTelegraphErrorBuildStorageSelectKeyboardFailed  Key = "bot.msg.telegraph.error_build_storage_select_keyboard_failed"
 	BotMsgTelegraphInfoPicCountPrefix                     Key = "bot.msg.telegraph.info_pic_count_prefix"
 	BotMsgTelegraphInfoPromptSelectStorage                Key = "bot.msg.telegraph.info_prompt_select_storage"
@@ -246,6 +391,7 @@ const (
 	BotMsgTransferErrorInvalidTarget                      Key = "bot.msg.transfer.error_invalid_target"
 	BotMsgTransferErrorListFilesFailed                    Key = "bot.msg.transfer.error_list_files_failed"
 	BotMsgTransferErrorNoFilesToTransfer                  Key = "bot.msg.transfer.error_no_files_to_transfer"
+	BotMsgTransferErrorStorageNotDeletable                Key = "bot.msg.transfer.error_storage_not_deletable"
 	BotMsgTransferErrorStorageNotFound                    Key = "bot.msg.transfer.error_storage_not_found"
 	BotMsgTransferErrorStorageNotListable                 Key = "bot.msg.transfer.error_storage_not_listable"
 	BotMsgTransferErrorStorageNotReadable                 Key = "bot.msg.transfer.error_storage_not_readable"
@@ -70,6 +70,14 @@ func (n *AdminNotifier) NotifyTaskSuccess(taskTitle string) {
 	n.Notify("✅ 任务完成: " + taskTitle)
 }
 
+func (n *AdminNotifier) NotifyStorageUnhealthy(name, reason string) {
+	n.Notify("⚠️ 存储端异常: " + name + "\n原因: " + reason)
+}
+
+func (n *AdminNotifier) NotifyStorageRecovered(name string) {
+	n.Notify("✅ 存储端已恢复: " + name)
+}
+
 func (n *AdminNotifier) NotifyStartup() {
 	n.Notify("🚀 SaveAny-Bot 已启动")
 }
@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/database"
+)
+
+// NewTaskNotifier returns a core.TaskNotifier sending the results of the tasks with the bot
+func NewTaskNotifier(extCtx *ext.Context) core.TaskNotifier {
+	return func(ctx context.Context, chatIDs []int64, task core.Executable, status string, taskErr error) {
+		target := task.Title()
+		if r, ok := task.(core.Reportable); ok {
+			report := r.Report()
+			target = fmt.Sprintf("[%s]:%s", report.StorageName, report.StoragePath)
+		}
+		var msg string
+		switch {
+		case status == database.TaskStatusCompleted:
+			msg = i18n.T(i18nk.BotMsgRuleNotifySaved, map[string]any{"Target": target})
+		case status == database.TaskStatusFailed && taskErr != nil:
+			msg = i18n.T(i18nk.BotMsgRuleNotifyFailed, map[string]any{"Target": target, "Error": taskErr.Error()})
+		default:
+			return
+		}
+		for _, chatID := range chatIDs {
+			go extCtx.SendMessage(chatID, &tg.MessagesSendMessageRequest{Message: msg})
+		}
+	}
+}
@@ -11,11 +11,19 @@ import (
 
 // 删除文件夹内的所有文件和子目录, 但不删除文件夹本身
 func RemoveAllInDir(dirPath string) error {
+	return RemoveAllInDirExcept(dirPath, nil)
+}
+
+// 删除文件夹内 keep 返回 false 的文件和子目录, keep 为 nil 时全部删除
+func RemoveAllInDirExcept(dirPath string, keep func(name string) bool) error {
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return err
 	}
 	for _, entry := range entries {
+		if keep != nil && keep(entry.Name()) {
+			continue
+		}
 		entryPath := filepath.Join(dirPath, entry.Name())
 		if err := os.RemoveAll(entryPath); err != nil {
 			return err
@@ -58,6 +66,19 @@ func CreateFile(fp string) (*File, error) {
 	return &File{File: file}, nil
 }
 
+// OpenFile opens the file for reading and writing, it is created if not exists.
+// Unlike CreateFile, the existing content is kept.
+func OpenFile(fp string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(fp), os.ModePerm); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(fp, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: file}, nil
+}
+
 func NormalizePathname(s string) string {
 	specials := `\/:*?"<>|` + "\n\r\t"
 	var builder strings.Builder
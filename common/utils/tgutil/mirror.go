@@ -0,0 +1,37 @@
+package tgutil
+
+import (
+	"context"
+
+	"github.com/gotd/td/telegram/message/styling"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n"
+	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+)
+
+// MirrorReportStyling returns the lines of the results of the mirror storage members saved to with ctx,
+// to append to the progress messages. It returns nil if no mirror storage is saved to.
+func MirrorReportStyling(ctx context.Context) []styling.StyledTextOption {
+	summary := storagetypes.MirrorReportFromContext(ctx).Summary()
+	if len(summary) == 0 {
+		return nil
+	}
+	opts := []styling.StyledTextOption{styling.Plain(i18n.T(i18nk.BotMsgProgressMirrorResultsPrefix, nil))}
+	for _, member := range summary {
+		opts = append(opts,
+			styling.Plain("\n  - "),
+			styling.Code(member.StorageName),
+			styling.Plain(i18n.T(i18nk.BotMsgProgressMirrorMemberSaved, map[string]any{
+				"Saved": member.Saved,
+				"Total": member.Total,
+			})),
+		)
+		if member.LastErr != nil {
+			opts = append(opts,
+				styling.Plain(i18n.T(i18nk.BotMsgProgressMirrorMemberErrorPrefix, nil)),
+				styling.Bold(member.LastErr.Error()),
+			)
+		}
+	}
+	return opts
+}
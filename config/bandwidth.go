@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+type bandwidthConfig struct {
+	Download  string              `toml:"download" mapstructure:"download" json:"download"` // 全局下载限速, 每秒字节数, 如 "10MB", 为空或 0 时不限制
+	Upload    string              `toml:"upload" mapstructure:"upload" json:"upload"`       // 全局上传限速
+	Schedules []bandwidthSchedule `toml:"schedules" mapstructure:"schedules" json:"schedules"`
+}
+
+// bandwidthSchedule 在每天的 [Start, End) 时间段内替代全局限速, 为空时不限制
+type bandwidthSchedule struct {
+	Start    string `toml:"start" mapstructure:"start" json:"start"` // 如 "01:00"
+	End      string `toml:"end" mapstructure:"end" json:"end"`       // 如 "07:00", 小于 Start 时跨越午夜
+	Download string `toml:"download" mapstructure:"download" json:"download"`
+	Upload   string `toml:"upload" mapstructure:"upload" json:"upload"`
+}
+
+// ParseBandwidth parses a rate like "10MB" or "512KiB" to bytes per second, empty, "0" and "unlimited" mean no limit (0)
+func ParseBandwidth(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.EqualFold(s, "unlimited") {
+		return 0, nil
+	}
+	n, err := humanize.ParseBytes(strings.TrimSuffix(strings.TrimSuffix(s, "/s"), "ps"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q: %w", s, err)
+	}
+	return int64(n), nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether the time of day of t is in the schedule
+func (s bandwidthSchedule) Contains(t time.Time) bool {
+	start, err := parseClock(s.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(s.End)
+	if err != nil {
+		return false
+	}
+	now := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+func (c bandwidthConfig) Validate() error {
+	for _, s := range []string{c.Download, c.Upload} {
+		if _, err := ParseBandwidth(s); err != nil {
+			return err
+		}
+	}
+	for i, sch := range c.Schedules {
+		for _, s := range []string{sch.Start, sch.End} {
+			if _, err := parseClock(s); err != nil {
+				return fmt.Errorf("schedule %d: %w", i, err)
+			}
+		}
+		for _, s := range []string{sch.Download, sch.Upload} {
+			if _, err := ParseBandwidth(s); err != nil {
+				return fmt.Errorf("schedule %d: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
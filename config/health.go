@@ -0,0 +1,41 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dustin/go-humanize"
+)
+
+type healthConfig struct {
+	Interval int    `toml:"interval" mapstructure:"interval" json:"interval"` // 探测存储端状态的间隔秒数, 为 0 时不探测
+	MinFree  string `toml:"min_free" mapstructure:"min_free" json:"min_free"` // 剩余空间低于此值时视为不健康, 如 "5GB", 为空时不检查
+}
+
+// MinFreeBytes returns the minimum free space in bytes, 0 if not set
+func (c healthConfig) MinFreeBytes() int64 {
+	n, _ := parseSize(c.MinFree)
+	return n
+}
+
+func (c healthConfig) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("invalid interval %d", c.Interval)
+	}
+	if _, err := parseSize(c.MinFree); err != nil {
+		return err
+	}
+	return nil
+}
+
+func parseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := humanize.ParseBytes(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", s, err)
+	}
+	return int64(n), nil
+}
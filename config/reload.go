@@ -0,0 +1,165 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// ReloadReport tells the settings changed by Reload, by their keys in the config file
+type ReloadReport struct {
+	Applied  []string       `json:"applied"` // the settings which are used at once
+	Restart  []string       `json:"restart"` // the settings which take effect after a restart, the old values are kept until then
+	Storages StorageChanges `json:"storages"`
+}
+
+// StorageChanges tells the names of the storages changed by Reload
+type StorageChanges struct {
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+// Changed reports whether any setting is changed
+func (r ReloadReport) Changed() bool {
+	return len(r.Applied) > 0 || len(r.Restart) > 0
+}
+
+// reloadSettings are the sections of the config compared by Reload, the ones with restart are read once at startup
+var reloadSettings = []struct {
+	key     string
+	restart bool
+	value   func(c *Config) any
+}{
+	{key: "lang", restart: true, value: func(c *Config) any { return c.Lang }},
+	{key: "workers", value: func(c *Config) any { return c.Workers }},
+	{key: "retry", value: func(c *Config) any { return c.Retry }},
+	{key: "no_clean_cache", value: func(c *Config) any { return c.NoCleanCache }},
+	{key: "threads", value: func(c *Config) any { return c.Threads }},
+	{key: "stream", value: func(c *Config) any { return c.Stream }},
+	{key: "proxy", value: func(c *Config) any { return c.Proxy }},
+	{key: "aria2", restart: true, value: func(c *Config) any { return c.Aria2 }},
+	{key: "cache", restart: true, value: func(c *Config) any { return c.Cache }},
+	{key: "users", value: func(c *Config) any { return c.Users }},
+	{key: "temp", restart: true, value: func(c *Config) any { return c.Temp }},
+	{key: "db", restart: true, value: func(c *Config) any { return c.DB }},
+	{key: "telegram", restart: true, value: func(c *Config) any { return c.Telegram }},
+	{key: "parser", value: func(c *Config) any { return c.Parser }},
+	{key: "hook", value: func(c *Config) any { return c.Hook }},
+	{key: "web", restart: true, value: func(c *Config) any { return c.Web }},
+	{key: "bandwidth", restart: true, value: func(c *Config) any { return c.Bandwidth }},
+	{key: "health", value: func(c *Config) any { return c.Health }},
+}
+
+// serializes Reload, viper can not read concurrently
+var reloadMu sync.Mutex
+
+// keepRestartSettings copies the settings which need a restart from old to next
+func keepRestartSettings(next, old *Config) {
+	next.Lang = old.Lang
+	next.Aria2 = old.Aria2
+	next.Cache = old.Cache
+	next.Temp = old.Temp
+	next.DB = old.DB
+	next.Telegram = old.Telegram
+	next.Web = old.Web
+	next.Bandwidth = old.Bandwidth
+}
+
+// Reload reads the config file, or fetches the remote config, again and applies it if it is valid.
+// The settings used by other packages at startup only, like the worker count and the storages, must be applied by the caller,
+// see ReloadReport.
+func Reload() (ReloadReport, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if remoteURL != "" {
+		if err := readRemoteConfig(remoteURL); err != nil {
+			return ReloadReport{}, err
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// the local file is read locked, as the storages changed at runtime are written to it
+	if remoteURL == "" {
+		if err := viper.ReadInConfig(); err != nil {
+			return ReloadReport{}, fmt.Errorf("failed to read config file: %w", err)
+		}
+	}
+	next, err := load(viper.GetViper())
+	if err != nil {
+		return ReloadReport{}, err
+	}
+	report := diffConfig(cfg, next)
+	if slices.Contains(report.Applied, "proxy") {
+		if err := setProxy(next.Proxy); err != nil {
+			return ReloadReport{}, err
+		}
+	}
+	keepRestartSettings(next, cfg)
+	cfg = next
+	indexUsers(cfg)
+	return report, nil
+}
+
+func diffConfig(old, next *Config) ReloadReport {
+	var report ReloadReport
+	for _, setting := range reloadSettings {
+		if reflect.DeepEqual(setting.value(old), setting.value(next)) {
+			continue
+		}
+		if setting.restart {
+			report.Restart = append(report.Restart, setting.key)
+		} else {
+			report.Applied = append(report.Applied, setting.key)
+		}
+	}
+
+	for _, sc := range next.Storages {
+		oldSC := old.GetStorageByName(sc.GetName())
+		switch {
+		case oldSC == nil:
+			report.Storages.Added = append(report.Storages.Added, sc.GetName())
+		case !reflect.DeepEqual(oldSC, sc):
+			report.Storages.Changed = append(report.Storages.Changed, sc.GetName())
+		}
+	}
+	for _, sc := range old.Storages {
+		if next.GetStorageByName(sc.GetName()) == nil {
+			report.Storages.Removed = append(report.Storages.Removed, sc.GetName())
+		}
+	}
+	if len(report.Storages.Added) > 0 || len(report.Storages.Changed) > 0 || len(report.Storages.Removed) > 0 {
+		report.Applied = append(report.Applied, "storages")
+	}
+	return report
+}
+
+// the settings which can be saved by SaveSettings
+var savableSettings = []string{"lang", "workers", "retry", "threads", "stream", "proxy"}
+
+// SaveSettings writes the top-level settings, keyed like in the config file, to the config file.
+// The config in memory is not changed, call Reload to apply them.
+func SaveSettings(settings map[string]any) error {
+	for key, value := range settings {
+		if !slices.Contains(savableSettings, key) {
+			return fmt.Errorf("setting %s can not be saved", key)
+		}
+		if proxy, ok := value.(string); ok && key == "proxy" && proxy != "" {
+			if _, err := newProxyTransport(proxy); err != nil {
+				return fmt.Errorf("invalid proxy: %w", err)
+			}
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return updateConfigFile(func(doc map[string]any) error {
+		for key, value := range settings {
+			doc[key] = normalizeValue(value)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestReload(t *testing.T) {
+	path := setupTestConfig(t)
+	viper.SetConfigFile(path)
+	t.Cleanup(viper.Reset)
+
+	changed := strings.NewReplacer(
+		"workers = 2", "workers = 4\nlang = \"zh-Hans\"",
+		`base_path = "downloads"`, `base_path = "other"`,
+	).Replace(testConfig) + `
+[[storages]]
+name = "added"
+type = "local"
+enable = true
+base_path = "added"
+`
+	if err := os.WriteFile(path, []byte(changed), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report, err := Reload()
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !slices.Equal(report.Applied, []string{"workers", "storages"}) || !slices.Equal(report.Restart, []string{"lang"}) {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if !slices.Equal(report.Storages.Added, []string{"added"}) || !slices.Equal(report.Storages.Changed, []string{"local"}) {
+		t.Fatalf("unexpected storage changes: %+v", report.Storages)
+	}
+	if C().Workers != 4 || C().Lang != "" {
+		t.Fatalf("the workers should be applied and the lang kept until a restart, got %d and %q", C().Workers, C().Lang)
+	}
+
+	// an invalid config is not applied
+	if err := os.WriteFile(path, []byte(changed+"\n[[storages]]\nname = \"added\"\ntype = \"local\"\nenable = true\nbase_path = \"x\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reload(); err == nil {
+		t.Fatalf("a config with duplicate storages should not be reloaded")
+	}
+	if C().GetStorageByName("added") == nil || C().Workers != 4 {
+		t.Fatalf("the config should be kept when the reload fails")
+	}
+
+	if err := SaveSettings(map[string]any{"workers": 3}); err != nil {
+		t.Fatalf("SaveSettings failed: %v", err)
+	}
+	if err := SaveSettings(map[string]any{"telegram": map[string]any{}}); err == nil {
+		t.Fatalf("only the top-level settings should be saved")
+	}
+	if got := readTestConfig(t, path)["workers"]; got != int64(3) {
+		t.Fatalf("the workers should be written, got %v", got)
+	}
+}
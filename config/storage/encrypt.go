@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"fmt"
+
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+)
+
+type EncryptStorageConfig struct {
+	BaseConfig
+	// Name of the storage the encrypted files are saved to, it does not need to be enabled for any user
+	Storage  string `toml:"storage" mapstructure:"storage" json:"storage"`
+	Password string `toml:"password" mapstructure:"password" json:"password"`
+	// Salt of the key derivation, optional, changing the password or the salt makes the saved files unreadable
+	Salt string `toml:"salt" mapstructure:"salt" json:"salt"`
+	// Also encrypt the file and directory names
+	EncryptFilenames bool `toml:"encrypt_filenames" mapstructure:"encrypt_filenames" json:"encrypt_filenames"`
+}
+
+func (e *EncryptStorageConfig) Validate() error {
+	if e.Storage == "" {
+		return fmt.Errorf("storage is required for encrypt storage")
+	}
+	if e.Storage == e.Name {
+		return fmt.Errorf("encrypt storage %s can not wrap itself", e.Name)
+	}
+	if e.Password == "" {
+		return fmt.Errorf("password is required for encrypt storage")
+	}
+	return nil
+}
+
+func (e *EncryptStorageConfig) GetType() storenum.StorageType {
+	return storenum.Encrypt
+}
+
+func (e *EncryptStorageConfig) GetName() string {
+	return e.Name
+}
+
+func (e *EncryptStorageConfig) ReferencedStorages() []string {
+	return []string{e.Storage}
+}
@@ -17,6 +17,10 @@ var storageFactories = map[storenum.StorageType]func(cfg *BaseConfig) (StorageCo
 	storenum.S3:       createStorageConfig(&S3StorageConfig{}),
 	storenum.Telegram: createStorageConfig(&TelegramStorageConfig{}),
 	storenum.Rclone:   createStorageConfig(&RcloneStorageConfig{}),
+	storenum.Sftp:     createStorageConfig(&SFTPStorageConfig{}),
+	storenum.Ftp:      createStorageConfig(&FTPStorageConfig{}),
+	storenum.Encrypt:  createStorageConfig(&EncryptStorageConfig{}),
+	storenum.Mirror:   createStorageConfig(&MirrorStorageConfig{}),
 }
 
 func createStorageConfig(configType StorageConfig) func(cfg *BaseConfig) (StorageConfig, error) {
@@ -44,27 +48,52 @@ func LoadStorageConfigs(v *viper.Viper) ([]StorageConfig, error) {
 		if !baseCfg.Enable {
 			continue
 		}
-		st, err := storenum.ParseStorageType(baseCfg.Type)
+		cfg, err := newStorageConfig(baseCfg)
 		if err != nil {
-			return nil, fmt.Errorf("invalid storage type %s for %s: %w", baseCfg.Type, baseCfg.Name, err)
+			return nil, err
 		}
+		configs = append(configs, cfg)
+	}
 
-		factory, ok := storageFactories[st]
-		if !ok {
-			return nil, fmt.Errorf("unsupported storage type: %s", baseCfg.Type)
-		}
+	return configs, nil
+}
 
-		cfg, err := factory(&baseCfg)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create storage config for %s: %w", baseCfg.Name, err)
-		}
+// ParseStorageConfig creates and validates the storage config of a single entry of the storages,
+// raw has the same keys as in the config file. It is returned even if the storage is not enabled.
+func ParseStorageConfig(raw map[string]any) (StorageConfig, error) {
+	var baseCfg BaseConfig
+	if err := mapstructure.Decode(raw, &baseCfg); err != nil {
+		return nil, fmt.Errorf("failed to decode storage config: %w", err)
+	}
+	if baseCfg.Name == "" {
+		return nil, fmt.Errorf("name is required for storage")
+	}
+	return newStorageConfig(baseCfg)
+}
 
-		if err := cfg.Validate(); err != nil {
-			return nil, fmt.Errorf("invalid storage config for %s: %w", baseCfg.Name, err)
-		}
+func newStorageConfig(baseCfg BaseConfig) (StorageConfig, error) {
+	st, err := storenum.ParseStorageType(baseCfg.Type)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage type %s for %s: %w", baseCfg.Type, baseCfg.Name, err)
+	}
 
-		configs = append(configs, cfg)
+	factory, ok := storageFactories[st]
+	if !ok {
+		return nil, fmt.Errorf("unsupported storage type: %s", baseCfg.Type)
 	}
 
-	return configs, nil
+	cfg, err := factory(&baseCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage config for %s: %w", baseCfg.Name, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid storage config for %s: %w", baseCfg.Name, err)
+	}
+	return cfg, nil
+}
+
+// StorageReferrer is implemented by the configs of the storages which save through other storages
+type StorageReferrer interface {
+	ReferencedStorages() []string
 }
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+)
+
+// TLS modes of the ftp storage
+const (
+	FTPTLSNone     = ""
+	FTPTLSExplicit = "explicit" // AUTH TLS on the plain port, usually 21
+	FTPTLSImplicit = "implicit" // TLS from the start, usually on port 990
+)
+
+type FTPStorageConfig struct {
+	BaseConfig
+	Host     string `toml:"host" mapstructure:"host" json:"host"`
+	Port     int    `toml:"port" mapstructure:"port" json:"port"`
+	Username string `toml:"username" mapstructure:"username" json:"username"`
+	Password string `toml:"password" mapstructure:"password" json:"password"`
+	BasePath string `toml:"base_path" mapstructure:"base_path" json:"base_path"`
+	// One of FTPTLSNone, FTPTLSExplicit and FTPTLSImplicit
+	TLS                string `toml:"tls" mapstructure:"tls" json:"tls"`
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify" mapstructure:"insecure_skip_verify" json:"insecure_skip_verify"`
+	// Use PASV instead of EPSV for the passive data connections, for servers that do not support EPSV
+	DisableEPSV bool `toml:"disable_epsv" mapstructure:"disable_epsv" json:"disable_epsv"`
+}
+
+func (f *FTPStorageConfig) Validate() error {
+	if f.Host == "" {
+		return fmt.Errorf("host is required for ftp storage")
+	}
+	if f.Port < 0 || f.Port > 65535 {
+		return fmt.Errorf("invalid port %d for ftp storage", f.Port)
+	}
+	if !slices.Contains([]string{FTPTLSNone, FTPTLSExplicit, FTPTLSImplicit}, f.TLS) {
+		return fmt.Errorf("invalid tls %q for ftp storage, must be empty, %q or %q", f.TLS, FTPTLSExplicit, FTPTLSImplicit)
+	}
+	if f.BasePath == "" {
+		return fmt.Errorf("base_path is required for ftp storage")
+	}
+	return nil
+}
+
+func (f *FTPStorageConfig) GetType() storenum.StorageType {
+	return storenum.Ftp
+}
+
+func (f *FTPStorageConfig) GetName() string {
+	return f.Name
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+)
+
+// Policies of the mirror storage, when a save succeeds
+const (
+	MirrorPolicyAll        = "all"         // every member saves the file, the default
+	MirrorPolicyAtLeast    = "at_least"    // at least min_success members save the file
+	MirrorPolicyBestEffort = "best_effort" // any member saves the file
+)
+
+type MirrorStorageConfig struct {
+	BaseConfig
+	// Names of the storages every file is saved to, they do not need to be enabled for any user
+	Storages []string `toml:"storages" mapstructure:"storages" json:"storages"`
+	// One of MirrorPolicyAll, MirrorPolicyAtLeast and MirrorPolicyBestEffort, empty for MirrorPolicyAll
+	Policy     string `toml:"policy" mapstructure:"policy" json:"policy"`
+	MinSuccess int    `toml:"min_success" mapstructure:"min_success" json:"min_success"`
+}
+
+func (m *MirrorStorageConfig) Validate() error {
+	if len(m.Storages) == 0 {
+		return fmt.Errorf("storages is required for mirror storage")
+	}
+	for i, name := range m.Storages {
+		if name == "" || name == m.Name {
+			return fmt.Errorf("invalid member %q of mirror storage %s", name, m.Name)
+		}
+		if slices.Contains(m.Storages[:i], name) {
+			return fmt.Errorf("duplicate member %s of mirror storage %s", name, m.Name)
+		}
+	}
+	switch m.Policy {
+	case "", MirrorPolicyAll, MirrorPolicyBestEffort:
+	case MirrorPolicyAtLeast:
+		if m.MinSuccess < 1 || m.MinSuccess > len(m.Storages) {
+			return fmt.Errorf("min_success must be between 1 and %d for mirror storage %s", len(m.Storages), m.Name)
+		}
+	default:
+		return fmt.Errorf("invalid policy %q for mirror storage, must be %q, %q or %q", m.Policy, MirrorPolicyAll, MirrorPolicyAtLeast, MirrorPolicyBestEffort)
+	}
+	return nil
+}
+
+// RequiredSuccess returns how many members must save a file for the save to succeed
+func (m *MirrorStorageConfig) RequiredSuccess() int {
+	switch m.Policy {
+	case MirrorPolicyAtLeast:
+		return m.MinSuccess
+	case MirrorPolicyBestEffort:
+		return 1
+	default:
+		return len(m.Storages)
+	}
+}
+
+func (m *MirrorStorageConfig) GetType() storenum.StorageType {
+	return storenum.Mirror
+}
+
+func (m *MirrorStorageConfig) GetName() string {
+	return m.Name
+}
+
+func (m *MirrorStorageConfig) ReferencedStorages() []string {
+	return m.Storages
+}
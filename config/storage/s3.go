@@ -16,6 +16,8 @@ type S3StorageConfig struct {
 	BasePath        string `toml:"base_path" mapstructure:"base_path" json:"base_path"`
 	Region          string `toml:"region" mapstructure:"region" json:"region"`
 	VirtualHost     bool   `toml:"virtual_host" mapstructure:"virtual_host" json:"virtual_host"`
+	PartSizeMB      int64  `toml:"part_size_mb" mapstructure:"part_size_mb" json:"part_size_mb"`
+	UploadThreads   int    `toml:"upload_threads" mapstructure:"upload_threads" json:"upload_threads"`
 }
 
 func (m *S3StorageConfig) Validate() error {
@@ -31,6 +33,9 @@ func (m *S3StorageConfig) Validate() error {
 	if m.BasePath == "" {
 		return fmt.Errorf("base_path is required for s3 storage")
 	}
+	if m.PartSizeMB < 0 || m.UploadThreads < 0 {
+		return fmt.Errorf("part_size_mb and upload_threads of s3 storage must not be negative")
+	}
 	return nil
 }
 
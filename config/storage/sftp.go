@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"fmt"
+
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+)
+
+type SFTPStorageConfig struct {
+	BaseConfig
+	Host     string `toml:"host" mapstructure:"host" json:"host"`
+	Port     int    `toml:"port" mapstructure:"port" json:"port"`
+	Username string `toml:"username" mapstructure:"username" json:"username"`
+	Password string `toml:"password" mapstructure:"password" json:"password"`
+	// Path to the private key file, used instead of or along with the password
+	PrivateKeyPath       string `toml:"private_key_path" mapstructure:"private_key_path" json:"private_key_path"`
+	PrivateKeyPassphrase string `toml:"private_key_passphrase" mapstructure:"private_key_passphrase" json:"private_key_passphrase"`
+	// The pinned host key, a line of known_hosts/authorized_keys format or a SHA256 fingerprint like "SHA256:..."
+	HostKey string `toml:"host_key" mapstructure:"host_key" json:"host_key"`
+	// Skip the host key check, only for trusted networks
+	InsecureIgnoreHostKey bool   `toml:"insecure_ignore_host_key" mapstructure:"insecure_ignore_host_key" json:"insecure_ignore_host_key"`
+	BasePath              string `toml:"base_path" mapstructure:"base_path" json:"base_path"`
+}
+
+func (s *SFTPStorageConfig) Validate() error {
+	if s.Host == "" {
+		return fmt.Errorf("host is required for sftp storage")
+	}
+	if s.Port < 0 || s.Port > 65535 {
+		return fmt.Errorf("invalid port %d for sftp storage", s.Port)
+	}
+	if s.Username == "" {
+		return fmt.Errorf("username is required for sftp storage")
+	}
+	if s.Password == "" && s.PrivateKeyPath == "" {
+		return fmt.Errorf("password or private_key_path is required for sftp storage")
+	}
+	if s.HostKey == "" && !s.InsecureIgnoreHostKey {
+		return fmt.Errorf("host_key is required for sftp storage, or set insecure_ignore_host_key to skip the check")
+	}
+	if s.BasePath == "" {
+		return fmt.Errorf("base_path is required for sftp storage")
+	}
+	return nil
+}
+
+func (s *SFTPStorageConfig) GetType() storenum.StorageType {
+	return storenum.Sftp
+}
+
+func (s *SFTPStorageConfig) GetName() string {
+	return s.Name
+}
@@ -8,11 +8,28 @@ type StorageConfig interface {
 	Validate() error
 	GetType() storenum.StorageType
 	GetName() string
+	GetBandwidthLimit() string
+	GetConflict() string
+	IsEnabled() bool
 }
 
 type BaseConfig struct {
-	Name      string         `toml:"name" mapstructure:"name" json:"name"`
-	Type      string         `toml:"type" mapstructure:"type" json:"type"`
-	Enable    bool           `toml:"enable" mapstructure:"enable" json:"enable"`
-	RawConfig map[string]any `toml:"-" mapstructure:",remain"`
+	Name           string         `toml:"name" mapstructure:"name" json:"name"`
+	Type           string         `toml:"type" mapstructure:"type" json:"type"`
+	Enable         bool           `toml:"enable" mapstructure:"enable" json:"enable"`
+	BandwidthLimit string         `toml:"bandwidth_limit" mapstructure:"bandwidth_limit" json:"bandwidth_limit"` // 上传到该存储的限速, 如 "5MB", 为空时不限制
+	Conflict       string         `toml:"conflict" mapstructure:"conflict" json:"conflict"`                      // 文件已存在时的处理策略, 见 pkg/enums/conflict, 为空时重命名
+	RawConfig      map[string]any `toml:"-" mapstructure:",remain"`
+}
+
+func (c BaseConfig) GetBandwidthLimit() string {
+	return c.BandwidthLimit
+}
+
+func (c BaseConfig) GetConflict() string {
+	return c.Conflict
+}
+
+func (c BaseConfig) IsEnabled() bool {
+	return c.Enable
 }
@@ -0,0 +1,339 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var (
+	ErrConfigNotWritable = errors.New("config: the config is not a local file and can not be changed")
+	ErrStorageExists     = errors.New("config: storage already exists")
+	ErrStorageNotFound   = errors.New("config: storage not found")
+	ErrStorageReferenced = errors.New("config: storage is used by other storages")
+	ErrUnknownUser       = errors.New("config: unknown user")
+	ErrFailedToWrite     = errors.New("config: failed to write config file")
+)
+
+// validateStorages checks the names, bandwidth limits and conflict policies of the storages
+func validateStorages(storages []storage.StorageConfig) error {
+	storageNames := make(map[string]struct{})
+	for _, storage := range storages {
+		if _, ok := storageNames[storage.GetName()]; ok {
+			return fmt.Errorf("duplicate storage name: %s", storage.GetName())
+		}
+		storageNames[storage.GetName()] = struct{}{}
+		if _, err := ParseBandwidth(storage.GetBandwidthLimit()); err != nil {
+			return fmt.Errorf("invalid bandwidth_limit for storage %s: %w", storage.GetName(), err)
+		}
+		if c := storage.GetConflict(); c != "" {
+			if _, err := conflict.ParsePolicy(c); err != nil {
+				return fmt.Errorf("invalid conflict for storage %s: %w", storage.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseStorage creates and validates the storage config from raw, which has the same keys as an entry of the storages in the config file
+func ParseStorage(raw map[string]any) (storage.StorageConfig, error) {
+	sc, err := storage.ParseStorageConfig(normalizeValue(raw).(map[string]any))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateStorages([]storage.StorageConfig{sc}); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// AddStorage adds the storage to the config file, and to the config if it is enabled.
+// If userIDs is not nil, the storage is made available to these users only, otherwise the users are not changed,
+// so only the users in blacklist mode can use it.
+func AddStorage(raw map[string]any, userIDs []int64) (storage.StorageConfig, error) {
+	raw = normalizeValue(raw).(map[string]any)
+	sc, err := ParseStorage(raw)
+	if err != nil {
+		return nil, err
+	}
+	name := sc.GetName()
+
+	mu.Lock()
+	defer mu.Unlock()
+	users := cfg.Users
+	if userIDs != nil {
+		if users, err = grantStorage(cfg.Users, name, userIDs); err != nil {
+			return nil, err
+		}
+	}
+	err = updateConfigFile(func(doc map[string]any) error {
+		entries := docStorages(doc)
+		if cfg.GetStorageByName(name) != nil || slices.IndexFunc(entries, entryNamed(name)) >= 0 {
+			return fmt.Errorf("%w: %s", ErrStorageExists, name)
+		}
+		doc["storages"] = append(entries, raw)
+		setDocUsers(doc, users)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Users = users
+	if sc.IsEnabled() {
+		cfg.Storages = append(slices.Clip(cfg.Storages), sc)
+	}
+	indexUsers(cfg)
+	return sc, nil
+}
+
+// UpdateStorage replaces the config of the storage with the name in raw, the storage is removed from the config if it is not enabled anymore.
+// The users are not changed.
+func UpdateStorage(raw map[string]any) (storage.StorageConfig, error) {
+	raw = normalizeValue(raw).(map[string]any)
+	sc, err := ParseStorage(raw)
+	if err != nil {
+		return nil, err
+	}
+	name := sc.GetName()
+	enabled := sc.IsEnabled()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		if err := checkNotReferenced(name); err != nil {
+			return nil, err
+		}
+	}
+	err = updateConfigFile(func(doc map[string]any) error {
+		entries := docStorages(doc)
+		i := slices.IndexFunc(entries, entryNamed(name))
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrStorageNotFound, name)
+		}
+		entries[i] = raw
+		doc["storages"] = entries
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	storages := slices.DeleteFunc(slices.Clone(cfg.Storages), func(c storage.StorageConfig) bool {
+		return c.GetName() == name
+	})
+	if enabled {
+		if i := slices.IndexFunc(cfg.Storages, func(c storage.StorageConfig) bool { return c.GetName() == name }); i >= 0 {
+			storages = slices.Insert(storages, i, sc)
+		} else {
+			storages = append(storages, sc)
+		}
+	}
+	cfg.Storages = storages
+	indexUsers(cfg)
+	return sc, nil
+}
+
+// RemoveStorage removes the storage from the config file, the config and the storages of the users
+func RemoveStorage(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if err := checkNotReferenced(name); err != nil {
+		return err
+	}
+	users := make([]userConfig, len(cfg.Users))
+	for i, user := range cfg.Users {
+		user.Storages = slices.DeleteFunc(slices.Clone(user.Storages), func(s string) bool { return s == name })
+		users[i] = user
+	}
+	err := updateConfigFile(func(doc map[string]any) error {
+		entries := docStorages(doc)
+		i := slices.IndexFunc(entries, entryNamed(name))
+		if i < 0 {
+			return fmt.Errorf("%w: %s", ErrStorageNotFound, name)
+		}
+		doc["storages"] = slices.Delete(entries, i, i+1)
+		setDocUsers(doc, users)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg.Users = users
+	cfg.Storages = slices.DeleteFunc(slices.Clone(cfg.Storages), func(c storage.StorageConfig) bool {
+		return c.GetName() == name
+	})
+	indexUsers(cfg)
+	return nil
+}
+
+// ReferringStorages returns the names of the storages saving through the storage with the name, like the mirror storages having it as member
+func (c Config) ReferringStorages(name string) []string {
+	var names []string
+	for _, sc := range c.Storages {
+		if r, ok := sc.(storage.StorageReferrer); ok && slices.Contains(r.ReferencedStorages(), name) {
+			names = append(names, sc.GetName())
+		}
+	}
+	return names
+}
+
+// checkNotReferenced returns ErrStorageReferenced if other storages save through the storage, the caller must hold mu
+func checkNotReferenced(name string) error {
+	if referring := cfg.ReferringStorages(name); len(referring) > 0 {
+		return fmt.Errorf("%w: %s is used by %v", ErrStorageReferenced, name, referring)
+	}
+	return nil
+}
+
+// grantStorage returns a copy of users where the storage is available to the users of userIDs only
+func grantStorage(users []userConfig, name string, userIDs []int64) ([]userConfig, error) {
+	for _, id := range userIDs {
+		if !slices.ContainsFunc(users, func(u userConfig) bool { return u.ID == id }) {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownUser, id)
+		}
+	}
+	granted := make([]userConfig, len(users))
+	for i, user := range users {
+		user.Storages = slices.DeleteFunc(slices.Clone(user.Storages), func(s string) bool { return s == name })
+		// a whitelist lists the storages the user can use, a blacklist the ones the user can not
+		if slices.Contains(userIDs, user.ID) != user.Blacklist {
+			user.Storages = append(user.Storages, name)
+		}
+		granted[i] = user
+	}
+	return granted, nil
+}
+
+// updateConfigFile reads the config file, calls update with its content and writes the result back.
+// The comments and the order of the keys in the file are not kept.
+func updateConfigFile(update func(doc map[string]any) error) error {
+	if configPath == "" {
+		return ErrConfigNotWritable
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWrite, err)
+	}
+	doc := make(map[string]any)
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: failed to parse config file: %w", ErrFailedToWrite, err)
+	}
+	if err := update(doc); err != nil {
+		return err
+	}
+	data, err = toml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWrite, err)
+	}
+	if err := writeFileAtomic(configPath, data); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToWrite, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it to path,
+// so the file is not left half written if the bot stops meanwhile
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func docStorages(doc map[string]any) []map[string]any {
+	var entries []map[string]any
+	list, _ := doc["storages"].([]any)
+	for _, item := range list {
+		if entry, ok := item.(map[string]any); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// setDocUsers sets the storages of the users in the config file to the ones of users
+func setDocUsers(doc map[string]any, users []userConfig) {
+	list, _ := doc["users"].([]any)
+	for _, item := range list {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := entry["id"].(int64)
+		i := slices.IndexFunc(users, func(u userConfig) bool { return u.ID == id })
+		if i < 0 {
+			continue
+		}
+		storages := users[i].Storages
+		if storages == nil {
+			storages = []string{}
+		}
+		entry["storages"] = storages
+	}
+}
+
+func entryNamed(name string) func(entry map[string]any) bool {
+	return func(entry map[string]any) bool {
+		n, _ := entry["name"].(string)
+		return n == name
+	}
+}
+
+// normalizeValue drops the nil values and turns the whole numbers decoded from JSON to integers,
+// so they are written to the config file as TOML integers
+func normalizeValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			if value != nil {
+				m[key] = normalizeValue(value)
+			}
+		}
+		return m
+	case []any:
+		s := make([]any, 0, len(v))
+		for _, value := range v {
+			if value != nil {
+				s = append(s, normalizeValue(value))
+			}
+		}
+		return s
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
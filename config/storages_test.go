@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const testConfig = `
+workers = 2
+
+[[storages]]
+name = "local"
+type = "local"
+enable = true
+base_path = "downloads"
+
+[[users]]
+id = 1
+storages = ["local"]
+
+[[users]]
+id = 2
+blacklist = true
+storages = []
+`
+
+// setupTestConfig writes testConfig to a temporary file and loads it like Init
+func setupTestConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	local, err := storage.ParseStorageConfig(map[string]any{"name": "local", "type": "local", "enable": true, "base_path": "downloads"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldCfg, oldPath := cfg, configPath
+	t.Cleanup(func() {
+		cfg, configPath = oldCfg, oldPath
+		indexUsers(cfg)
+	})
+	cfg = &Config{
+		Workers:  2,
+		Retry:    1,
+		Threads:  1,
+		Storages: []storage.StorageConfig{local},
+		Users: []userConfig{
+			{ID: 1, Storages: []string{"local"}},
+			{ID: 2, Blacklist: true, Storages: []string{}},
+		},
+	}
+	configPath = path
+	indexUsers(cfg)
+	return path
+}
+
+func readTestConfig(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := make(map[string]any)
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("the written config is invalid: %v", err)
+	}
+	return doc
+}
+
+func TestAddStorage(t *testing.T) {
+	path := setupTestConfig(t)
+
+	// the numbers of a JSON request are float64
+	raw := map[string]any{
+		"name": "sftp", "type": "sftp", "enable": true, "host": "example.com", "port": float64(2222),
+		"username": "user", "password": "pass", "insecure_ignore_host_key": true, "host_key": nil, "base_path": "/data",
+	}
+	if _, err := AddStorage(raw, []int64{1}); err != nil {
+		t.Fatalf("AddStorage failed: %v", err)
+	}
+	if !C().HasStorage(1, "sftp") || C().HasStorage(2, "sftp") {
+		t.Fatalf("the storage should be available to user 1 only")
+	}
+	if C().GetStorageByName("sftp") == nil {
+		t.Fatalf("the storage should be added to the config")
+	}
+
+	doc := readTestConfig(t, path)
+	if doc["workers"] != int64(2) {
+		t.Fatalf("the other settings should be kept, got %v", doc["workers"])
+	}
+	entries := docStorages(doc)
+	if len(entries) != 2 || entries[1]["port"] != int64(2222) {
+		t.Fatalf("unexpected storages in the config file: %v", entries)
+	}
+	if _, ok := entries[1]["host_key"]; ok {
+		t.Fatalf("the nil values should not be written")
+	}
+	users := doc["users"].([]any)
+	if got := users[1].(map[string]any)["storages"]; !slices.Equal(got.([]any), []any{"sftp"}) {
+		t.Fatalf("the storage should be in the blacklist of user 2, got %v", got)
+	}
+
+	if _, err := AddStorage(raw, nil); !errors.Is(err, ErrStorageExists) {
+		t.Fatalf("adding the storage again should fail with ErrStorageExists, got %v", err)
+	}
+	if _, err := AddStorage(map[string]any{"name": "other", "type": "local", "enable": true, "base_path": "x"}, []int64{3}); !errors.Is(err, ErrUnknownUser) {
+		t.Fatalf("expected ErrUnknownUser, got %v", err)
+	}
+	if _, err := AddStorage(map[string]any{"name": "invalid", "type": "local", "enable": true}, nil); err == nil {
+		t.Fatalf("an invalid storage should not be added")
+	}
+}
+
+func TestUpdateAndRemoveStorage(t *testing.T) {
+	path := setupTestConfig(t)
+
+	mirror := map[string]any{"name": "mirror", "type": "mirror", "enable": true, "storages": []any{"local"}}
+	if _, err := AddStorage(mirror, nil); err != nil {
+		t.Fatalf("AddStorage failed: %v", err)
+	}
+	if got := C().ReferringStorages("local"); !slices.Equal(got, []string{"mirror"}) {
+		t.Fatalf("unexpected referring storages: %v", got)
+	}
+	if err := RemoveStorage("local"); !errors.Is(err, ErrStorageReferenced) {
+		t.Fatalf("removing a storage used by a mirror should fail, got %v", err)
+	}
+	if err := RemoveStorage("mirror"); err != nil {
+		t.Fatalf("RemoveStorage failed: %v", err)
+	}
+
+	if _, err := UpdateStorage(map[string]any{"name": "local", "type": "local", "enable": true, "base_path": "other"}); err != nil {
+		t.Fatalf("UpdateStorage failed: %v", err)
+	}
+	if got := C().GetStorageByName("local").(*storage.LocalStorageConfig).BasePath; got != "other" {
+		t.Fatalf("the storage should be updated, got base path %s", got)
+	}
+	if _, err := UpdateStorage(map[string]any{"name": "missing", "type": "local", "enable": true, "base_path": "x"}); !errors.Is(err, ErrStorageNotFound) {
+		t.Fatalf("expected ErrStorageNotFound, got %v", err)
+	}
+
+	// a disabled storage stays in the config file only
+	if _, err := UpdateStorage(map[string]any{"name": "local", "type": "local", "enable": false, "base_path": "other"}); err != nil {
+		t.Fatalf("UpdateStorage failed: %v", err)
+	}
+	if C().GetStorageByName("local") != nil {
+		t.Fatalf("the disabled storage should be removed from the config")
+	}
+	if entries := docStorages(readTestConfig(t, path)); len(entries) != 1 || entries[0]["enable"] != false {
+		t.Fatalf("unexpected storages in the config file: %v", entries)
+	}
+
+	if err := RemoveStorage("local"); err != nil {
+		t.Fatalf("RemoveStorage failed: %v", err)
+	}
+	doc := readTestConfig(t, path)
+	if entries := docStorages(doc); len(entries) != 0 {
+		t.Fatalf("the storage should be removed from the config file: %v", entries)
+	}
+	if got := doc["users"].([]any)[0].(map[string]any)["storages"]; len(got.([]any)) != 0 {
+		t.Fatalf("the storage should be removed from the users, got %v", got)
+	}
+	if err := RemoveStorage("local"); !errors.Is(err, ErrStorageNotFound) {
+		t.Fatalf("expected ErrStorageNotFound, got %v", err)
+	}
+}
+
+func TestRemoteConfigNotWritable(t *testing.T) {
+	setupTestConfig(t)
+	configPath = ""
+	if err := RemoveStorage("local"); !errors.Is(err, ErrConfigNotWritable) {
+		t.Fatalf("expected ErrConfigNotWritable, got %v", err)
+	}
+	if C().GetStorageByName("local") == nil {
+		t.Fatalf("the config should not be changed")
+	}
+}
@@ -8,13 +8,37 @@ type userConfig struct {
 	ID        int64    `toml:"id" mapstructure:"id" json:"id"`                      // telegram user id
 	Storages  []string `toml:"storages" mapstructure:"storages" json:"storages"`    // storage names
 	Blacklist bool     `toml:"blacklist" mapstructure:"blacklist" json:"blacklist"` // 黑名单模式, storage names 中的存储将不会被使用, 默认为白名单模式
+	MaxTasks  int      `toml:"max_tasks" mapstructure:"max_tasks" json:"max_tasks"` // 该用户同时运行的最大任务数, 0 为不限制
 }
 
 var userIDs []int64
 var storages []string
 var userStorages = make(map[int64][]string)
+var userMaxTasks = make(map[int64]int)
+
+// indexUsers computes the storages and limits of the users of c, the caller must hold mu
+func indexUsers(c *Config) {
+	storages = make([]string, 0, len(c.Storages))
+	for _, storage := range c.Storages {
+		storages = append(storages, storage.GetName())
+	}
+	userIDs = make([]int64, 0, len(c.Users))
+	userStorages = make(map[int64][]string, len(c.Users))
+	userMaxTasks = make(map[int64]int, len(c.Users))
+	for _, user := range c.Users {
+		userIDs = append(userIDs, user.ID)
+		userMaxTasks[user.ID] = user.MaxTasks
+		if user.Blacklist {
+			userStorages[user.ID] = slice.Compact(slice.Difference(storages, user.Storages))
+		} else {
+			userStorages[user.ID] = user.Storages
+		}
+	}
+}
 
 func (c Config) GetStorageNamesByUserID(userID int64) []string {
+	mu.RLock()
+	defer mu.RUnlock()
 	us, ok := userStorages[userID]
 	if ok {
 		return us
@@ -23,13 +47,24 @@ func (c Config) GetStorageNamesByUserID(userID int64) []string {
 }
 
 func (c Config) GetUsersID() []int64 {
+	mu.RLock()
+	defer mu.RUnlock()
 	return userIDs
 }
 
 func (c Config) HasStorage(userID int64, storageName string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
 	us, ok := userStorages[userID]
 	if !ok {
 		return false
 	}
 	return slice.Contain(us, storageName)
 }
+
+// GetUserMaxTasks returns the max number of running tasks of the user, 0 means no limit
+func (c Config) GetUserMaxTasks(userID int64) int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return userMaxTasks[userID]
+}
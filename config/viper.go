@@ -7,9 +7,9 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/duke-git/lancet/v2/slice"
 	"github.com/kiss2u/SaveAny-Bot/config/storage"
 	"github.com/spf13/viper"
 	"golang.org/x/net/proxy"
@@ -34,6 +34,9 @@ type Config struct {
 	Parser   parserConfig            `toml:"parser" mapstructure:"parser" json:"parser"`
 	Hook     hookConfig              `toml:"hook" mapstructure:"hook" json:"hook"`
 	Web      WebConfig               `toml:"web" mapstructure:"web" json:"web"`
+
+	Bandwidth bandwidthConfig `toml:"bandwidth" mapstructure:"bandwidth" json:"bandwidth"`
+	Health    healthConfig    `toml:"health" mapstructure:"health" json:"health"`
 }
 
 type aria2Config struct {
@@ -43,9 +46,19 @@ type aria2Config struct {
 	KeepFile bool   `toml:"keep_file" mapstructure:"keep_file" json:"keep_file"`
 }
 
-var cfg = &Config{}
+var (
+	cfg = &Config{}
+	// guards cfg and the users index, the storages and users are changed at runtime
+	mu sync.RWMutex
+	// the local config file the changes made at runtime are written to, empty for a remote config
+	configPath string
+	// the url of the remote config, empty for a local config
+	remoteURL string
+)
 
 func C() Config {
+	mu.RLock()
+	defer mu.RUnlock()
 	return *cfg
 }
 
@@ -70,17 +83,10 @@ func Init(ctx context.Context, configFile ...string) error {
 	if len(configFile) > 0 && configFile[0] != "" {
 		cfg := configFile[0]
 		if strings.HasPrefix(cfg, "http://") || strings.HasPrefix(cfg, "https://") {
+			remoteURL = cfg
 			// 	使用远程配置文件
-			resp, err := http.Get(cfg)
-			if err != nil {
-				return fmt.Errorf("failed to fetch remote config file: %w", err)
-			}
-			defer resp.Body.Close()
-			if resp.StatusCode != http.StatusOK {
-				return fmt.Errorf("failed to fetch remote config file: status code %d", resp.StatusCode)
-			}
-			if err := viper.ReadConfig(resp.Body); err != nil {
-				return fmt.Errorf("failed to read remote config file: %w", err)
+			if err := readRemoteConfig(remoteURL); err != nil {
+				return err
 			}
 		} else {
 			viper.SetConfigFile(cfg)
@@ -113,6 +119,9 @@ func Init(ctx context.Context, configFile ...string) error {
 		// 临时目录
 		"temp.base_path": "cache/",
 
+		// 存储端状态探测
+		"health.interval": 300,
+
 		// 数据库
 		"db.path":    "data/saveany.db",
 		"db.session": "data/session.db",
@@ -137,33 +146,69 @@ func Init(ctx context.Context, configFile ...string) error {
 		fmt.Println("Error reading config file, ", err)
 		return err
 	}
+	if remoteURL == "" {
+		configPath = viper.ConfigFileUsed()
+	}
+
+	loaded, err := load(viper.GetViper())
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	cfg = loaded
+	indexUsers(cfg)
+	mu.Unlock()
+	return setProxy(loaded.Proxy)
+}
+
+// readRemoteConfig fetches the config from the http(s) url
+func readRemoteConfig(url string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch remote config file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch remote config file: status code %d", resp.StatusCode)
+	}
+	if err := viper.ReadConfig(resp.Body); err != nil {
+		return fmt.Errorf("failed to read remote config file: %w", err)
+	}
+	return nil
+}
+
+// load unmarshals and validates the config read by v
+func load(v *viper.Viper) (*Config, error) {
+	cfg := &Config{}
 
 	// 读取配置前，缓存配置文件中非空的 telegram.token
 	var originalTelegramToken string
-	if viper.IsSet("telegram.token") {
-		originalTelegramToken = viper.GetString("telegram.token")
+	if v.IsSet("telegram.token") {
+		originalTelegramToken = v.GetString("telegram.token")
 	}
-	if err := viper.Unmarshal(cfg); err != nil {
+	if err := v.Unmarshal(cfg); err != nil {
 		fmt.Println("Error unmarshalling config file, ", err)
-		return err
+		return nil, err
 	}
 
 	// 如果配置文件中 token 有值，而当前读取的结果为空，将原始值恢复回去（防止环境变量空白字符串覆盖配置文件值）
 	if originalTelegramToken != "" && cfg.Telegram.Token == "" {
 		cfg.Telegram.Token = originalTelegramToken
 	}
-	storagesConfig, err := storage.LoadStorageConfigs(viper.GetViper())
+	storagesConfig, err := storage.LoadStorageConfigs(v)
 	if err != nil {
-		return fmt.Errorf("error loading storage configs: %w", err)
+		return nil, fmt.Errorf("error loading storage configs: %w", err)
 	}
 	cfg.Storages = storagesConfig
 
-	storageNames := make(map[string]struct{})
-	for _, storage := range cfg.Storages {
-		if _, ok := storageNames[storage.GetName()]; ok {
-			return fmt.Errorf("duplicate storage name: %s", storage.GetName())
-		}
-		storageNames[storage.GetName()] = struct{}{}
+	if err := validateStorages(cfg.Storages); err != nil {
+		return nil, err
+	}
+	if err := cfg.Bandwidth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bandwidth config: %w", err)
+	}
+	if err := cfg.Health.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid health config: %w", err)
 	}
 
 	if cfg.Workers < 1 {
@@ -176,23 +221,23 @@ func Init(ctx context.Context, configFile ...string) error {
 		cfg.Retry = 1
 	}
 
-	for _, storage := range cfg.Storages {
-		storages = append(storages, storage.GetName())
-	}
-	for _, user := range cfg.Users {
-		userIDs = append(userIDs, user.ID)
-		if user.Blacklist {
-			userStorages[user.ID] = slice.Compact(slice.Difference(storages, user.Storages))
-		} else {
-			userStorages[user.ID] = user.Storages
-		}
+	return cfg, nil
+}
+
+// the transport before a proxy is set
+var defaultTransport = http.DefaultTransport
+
+// setProxy makes the http clients without their own transport use the proxy, or no proxy if it is empty
+func setProxy(proxyStr string) error {
+	if proxyStr == "" {
+		http.DefaultTransport = defaultTransport
+		return nil
 	}
-	if cfg.Proxy != "" {
-		http.DefaultTransport, err = newProxyTransport(cfg.Proxy)
-		if err != nil {
-			return fmt.Errorf("failed to create proxy transport: %w", err)
-		}
+	transport, err := newProxyTransport(proxyStr)
+	if err != nil {
+		return fmt.Errorf("failed to create proxy transport: %w", err)
 	}
+	http.DefaultTransport = transport
 	return nil
 }
 
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+// how long the config file must stay unchanged before onChange is called, editors write files in several steps
+const watchDebounce = time.Second
+
+// Watch calls onChange when the local config file is changed, until ctx is done.
+// It does nothing for a remote config.
+func Watch(ctx context.Context, onChange func()) error {
+	if configPath == "" {
+		return nil
+	}
+	path, err := filepath.Abs(configPath)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// the directory is watched, as the file is replaced instead of written by many editors
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+		logger := log.FromContext(ctx)
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != path || event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+					continue
+				}
+				timer.Reset(watchDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warnf("Error watching config file: %v", err)
+			case <-timer.C:
+				onChange()
+			}
+		}
+	}()
+	return nil
+}
@@ -3,11 +3,15 @@ package core
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/database"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
 	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
 )
 
 var queueInstance *queue.TaskQueue[Executable]
@@ -19,22 +23,89 @@ type Executable interface {
 	Execute(ctx context.Context) error
 }
 
-func worker(ctx context.Context, qe *queue.TaskQueue[Executable], semaphore chan struct{}) {
+var (
+	workersMu     sync.Mutex
+	workersCtx    context.Context
+	workerCount   int // number of the running workers
+	workersWanted int
+)
+
+// SetWorkers changes the number of tasks running at once, the new workers start at once
+// and the extra ones stop after their current task
+func SetWorkers(n int) {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	if workersCtx == nil {
+		return
+	}
+	workersWanted = max(n, 1)
+	for ; workerCount < workersWanted; workerCount++ {
+		go worker(workersCtx, queueInstance)
+	}
+}
+
+// workerStops reports whether there are more workers than wanted, then the calling worker is counted as stopped
+func workerStops() bool {
+	workersMu.Lock()
+	defer workersMu.Unlock()
+	if workerCount > workersWanted {
+		workerCount--
+		return true
+	}
+	return false
+}
+
+func worker(ctx context.Context, qe *queue.TaskQueue[Executable]) {
 	logger := log.FromContext(ctx)
-	execHooks := config.C().Hook.Exec
 	for {
-		semaphore <- struct{}{}
+		if workerStops() {
+			return
+		}
 		qtask, err := qe.Get()
 		if err != nil {
 			logger.Error("Failed to get task from queue:", err)
 			break // queue closed and empty
 		}
+		// the workers were reduced while waiting, leave the task to the others
+		if workerStops() {
+			if err := qe.Requeue(qtask.ID); err != nil {
+				logger.Errorf("Failed to requeue task %s: %v", qtask.ID, err)
+			}
+			return
+		}
 		exe := qtask.Data
+		// read for each task, the hooks can be changed by reloading the config
+		execHooks := config.C().Hook.Exec
 		logger.Infof("Processing task: %s", exe.TaskID())
+		updateTaskStatus(ctx, exe, database.TaskStatusRunning, nil)
 		if err := ExecCommandString(qtask.Context(), execHooks.TaskBeforeStart); err != nil {
 			logger.Errorf("Failed to execute before start hook for task %s: %v", exe.TaskID(), err)
 		}
-		if err := exe.Execute(qtask.Context()); err != nil {
+		start := time.Now()
+		// collects the results of the mirror storage members for the progress messages
+		execCtx, _ := storagetypes.WithMirrorReport(qtask.Context())
+		err = exe.Execute(execCtx)
+		if err != nil && ctx.Err() == nil && !qtask.Cancelled() && queue.IsPaused(qtask.Context()) {
+			logger.Infof("Task %s was paused", exe.TaskID())
+			if err := qe.Requeue(qtask.ID); err != nil {
+				logger.Errorf("Failed to requeue paused task %s: %v", exe.TaskID(), err)
+			}
+			// it may have been resumed while stopping
+			status := database.TaskStatusPending
+			if qtask.Paused() {
+				status = database.TaskStatusPaused
+			}
+			updateTaskStatus(ctx, exe, status, nil)
+			continue
+		}
+		status := taskStatusFromError(ctx, err)
+		updateTaskStatus(ctx, exe, status, err)
+		if status != database.TaskStatusPending {
+			extras := ExtrasFromContext(qtask.Context())
+			recordHistory(ctx, exe, extras, status, err, time.Since(start))
+			notifyTask(ctx, extras, exe, status, err)
+		}
+		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				logger.Infof("Task %s was canceled", exe.TaskID())
 				if err := ExecCommandString(ctx, execHooks.TaskCancel); err != nil {
@@ -53,29 +124,87 @@ func worker(ctx context.Context, qe *queue.TaskQueue[Executable], semaphore chan
 			}
 		}
 		qe.Done(qtask.ID)
-		<-semaphore
 	}
 }
 
 func Run(ctx context.Context) {
 	log.FromContext(ctx).Info("Start processing tasks...")
-	semaphore := make(chan struct{}, config.C().Workers)
 	if queueInstance == nil {
-		queueInstance = queue.NewTaskQueue[Executable]()
-	}
-	for range config.C().Workers {
-		go worker(ctx, queueInstance, semaphore)
+		queueInstance = queue.NewTaskQueue[Executable](queue.WithOwnerLimit(func(owner int64) int {
+			return config.C().GetUserMaxTasks(owner)
+		}))
 	}
+	workersMu.Lock()
+	workersCtx = ctx
+	workersMu.Unlock()
+	SetWorkers(config.C().Workers)
+}
 
+// newQueueTask wraps the task for the queue, the owner is the user who added the task if it is Reportable,
+// so the tasks without a progress message, like the ones of the watched chats, are limited too
+func newQueueTask(ctx context.Context, task Executable, opts ...queue.TaskOption) *queue.Task[Executable] {
+	if r, ok := task.(Reportable); ok {
+		opts = append([]queue.TaskOption{queue.WithOwner(r.Report().UserID)}, opts...)
+	}
+	return queue.NewTask(ctx, task.TaskID(), task.Title(), task, opts...)
 }
 
-func AddTask(ctx context.Context, task Executable) error {
-	return queueInstance.Add(queue.NewTask(ctx, task.TaskID(), task.Title(), task))
+// AddTask persists the task if it is Serializable, then adds it to the queue.
+// A SpaceRequirer task is rejected with storage.ErrInsufficientSpace if it does not fit in its storages.
+// Use queue.WithPriority to schedule it before or after the normal tasks.
+func AddTask(ctx context.Context, task Executable, opts ...queue.TaskOption) error {
+	if err := checkSpace(ctx, task); err != nil {
+		return err
+	}
+	qtask := newQueueTask(ctx, task, opts...)
+	if err := persistTask(ctx, task, qtask.Priority); err != nil {
+		log.FromContext(ctx).Warnf("Failed to persist task %s, it will not be resumed after restart: %v", task.TaskID(), err)
+	}
+	if err := queueInstance.Add(qtask); err != nil {
+		if _, ok := task.(Serializable); ok {
+			database.DeleteTaskState(ctx, task.TaskID())
+		}
+		return err
+	}
+	return nil
 }
 
 func CancelTask(ctx context.Context, id string) error {
-	err := queueInstance.CancelTask(id)
-	return err
+	if err := queueInstance.CancelTask(id); err != nil {
+		return err
+	}
+	// queued tasks are dropped by the queue without reaching a worker, so mark them here
+	updateTaskStatusByID(ctx, id, database.TaskStatusCancelled)
+	return nil
+}
+
+// PauseTask pauses a queued or running task, a running task stops and waits in the queue until resumed
+func PauseTask(ctx context.Context, id string) error {
+	if err := queueInstance.Pause(id); err != nil {
+		return err
+	}
+	updateTaskStatusByID(ctx, id, database.TaskStatusPaused)
+	return nil
+}
+
+func ResumeTask(ctx context.Context, id string) error {
+	if err := queueInstance.Resume(id); err != nil {
+		return err
+	}
+	updateTaskStatusByID(ctx, id, database.TaskStatusPending)
+	return nil
+}
+
+// MoveTask moves a queued task, see queue.TaskQueue.Move
+func MoveTask(ctx context.Context, id string, to queue.MovePosition) error {
+	priority, err := queueInstance.Move(id, to)
+	if err != nil {
+		return err
+	}
+	if err := database.UpdateTaskPriority(ctx, id, int(priority)); err != nil {
+		log.FromContext(ctx).Errorf("Failed to update priority of task %s: %v", id, err)
+	}
+	return nil
 }
 
 func GetLength(ctx context.Context) int {
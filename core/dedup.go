@@ -0,0 +1,192 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/dedup"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// DuplicateError tells that the file was not saved again because it has already been saved
+type DuplicateError struct {
+	StorageName string
+	StoragePath string
+	LinkPath    string // the path in the same storage linked to the saved file with the link policy, empty if skipped
+}
+
+func (e *DuplicateError) Error() string {
+	if e.LinkPath != "" {
+		return fmt.Sprintf("file already saved at [%s]:%s, linked at %s", e.StorageName, e.StoragePath, e.LinkPath)
+	}
+	return fmt.Sprintf("file already saved at [%s]:%s", e.StorageName, e.StoragePath)
+}
+
+// FileIdentity identifies a saved file, by its source and by its content
+type FileIdentity struct {
+	Key    string // source identity, e.g. the document id of a telegram file, empty if unknown
+	SHA256 string // hex encoded content hash, empty if unknown
+	Size   int64
+}
+
+// GetDedupPolicy returns the dedup policy of the user, the default policy if not set
+func GetDedupPolicy(ctx context.Context, userID int64) dedup.Policy {
+	if userID == 0 {
+		return dedup.Default
+	}
+	user, err := database.GetUserByChatID(ctx, userID)
+	if err != nil || user.DedupPolicy == "" {
+		return dedup.Default
+	}
+	policy, err := dedup.ParsePolicy(user.DedupPolicy)
+	if err != nil {
+		return dedup.Default
+	}
+	return policy
+}
+
+// FindSavedFile returns the newest record of the file in the storages the user can access, nil if not found.
+// Records whose files no longer exist in their storages are removed.
+func FindSavedFile(ctx context.Context, userID int64, id FileIdentity) *database.FileIndex {
+	logger := log.FromContext(ctx)
+	indexes, err := database.GetFileIndexes(ctx, id.Key, id.SHA256)
+	if err != nil {
+		logger.Errorf("Failed to get file indexes: %v", err)
+		return nil
+	}
+	for _, index := range indexes {
+		if id.Size > 0 && index.Size > 0 && id.Size != index.Size {
+			continue
+		}
+		if userID != 0 && !config.C().HasStorage(userID, index.StorageName) {
+			continue
+		}
+		stor, err := storage.GetStorageByName(ctx, index.StorageName)
+		if err != nil {
+			continue
+		}
+		if !storage.FileExists(ctx, stor, index.StoragePath) {
+			logger.Debugf("Removing stale file index %d of [%s]:%s", index.ID, index.StorageName, index.StoragePath)
+			if err := database.DeleteFileIndex(ctx, index.ID); err != nil {
+				logger.Errorf("Failed to remove file index %d: %v", index.ID, err)
+			}
+			continue
+		}
+		return &index
+	}
+	return nil
+}
+
+// HandleDuplicate applies the dedup policy of the user to the file about to be saved to storagePath of stor.
+// It returns a DuplicateError if the file is not saved again, as the user skips such files or it is linked to the saved file.
+// Otherwise it returns where to save the file: with the overwrite policy the storage and path of the saved file,
+// with ctx set to overwrite it, else stor and storagePath unchanged.
+func HandleDuplicate(ctx context.Context, userID int64, policy dedup.Policy, id FileIdentity,
+	stor storage.Storage, storagePath string,
+) (context.Context, storage.Storage, string, *DuplicateError) {
+	if policy == dedup.Rename {
+		return ctx, stor, storagePath, nil
+	}
+	saved := FindSavedFile(ctx, userID, id)
+	if saved == nil {
+		return ctx, stor, storagePath, nil
+	}
+	logger := log.FromContext(ctx)
+	dupErr := &DuplicateError{StorageName: saved.StorageName, StoragePath: saved.StoragePath}
+	switch policy {
+	case dedup.Overwrite:
+		savedStor, err := storage.GetStorageByName(ctx, saved.StorageName)
+		if err != nil {
+			logger.Warnf("Failed to get storage %s of the saved file, saving a new copy: %v", saved.StorageName, err)
+			return ctx, stor, storagePath, nil
+		}
+		logger.Infof("Overwriting file already saved at [%s]:%s", saved.StorageName, saved.StoragePath)
+		return storage.WithConflictPolicy(ctx, conflict.Overwrite), savedStor, saved.StoragePath, nil
+	case dedup.Link:
+		if linkPath, ok := linkSavedFile(ctx, saved, stor, storagePath, id.Size); ok {
+			RecordSavedFile(ctx, userID, id, policy, stor.Name(), linkPath)
+			dupErr.LinkPath = linkPath
+			return ctx, stor, storagePath, dupErr
+		}
+	}
+	logger.Infof("Skipping file already saved at [%s]:%s", saved.StorageName, saved.StoragePath)
+	return ctx, stor, storagePath, dupErr
+}
+
+// linkSavedFile links storagePath to the saved file, it is only possible if both are in the same storage which supports links.
+// It returns the linked path, which follows the conflict policy of the storage, and false if the file was not linked.
+func linkSavedFile(ctx context.Context, saved *database.FileIndex, stor storage.Storage, storagePath string, size int64) (string, bool) {
+	linkable, ok := stor.(storage.StorageLinkable)
+	if !ok || saved.StorageName != stor.Name() || saved.StoragePath == storagePath {
+		return "", false
+	}
+	logger := log.FromContext(ctx)
+	target, skip, err := storage.ResolveConflict(ctx, stor, storagePath, size)
+	if err != nil || skip {
+		return "", false
+	}
+	if err := linkable.Link(ctx, saved.StoragePath, target); err != nil {
+		logger.Errorf("Failed to link [%s]:%s to the saved file: %v", stor.Name(), target, err)
+		return "", false
+	}
+	logger.Infof("Linked [%s]:%s to the file already saved at %s", stor.Name(), target, saved.StoragePath)
+	return target, true
+}
+
+// RecordSavedFile records the saved file for deduplication, errors are only logged.
+// With the overwrite policy, the earlier records of the same file are replaced.
+func RecordSavedFile(ctx context.Context, userID int64, id FileIdentity, policy dedup.Policy, storageName, storagePath string) {
+	if id.Key == "" && id.SHA256 == "" {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	logger := log.FromContext(ctx)
+	if policy == dedup.Overwrite {
+		if err := database.DeleteFileIndexes(ctx, id.Key, id.SHA256); err != nil {
+			logger.Errorf("Failed to remove file indexes: %v", err)
+		}
+	}
+	if err := database.CreateFileIndex(ctx, &database.FileIndex{
+		UserID:      userID,
+		FileKey:     id.Key,
+		SHA256:      id.SHA256,
+		Size:        id.Size,
+		StorageName: storageName,
+		StoragePath: storagePath,
+	}); err != nil {
+		logger.Errorf("Failed to record file index: %v", err)
+	}
+}
+
+// NewContentHash returns the hash used for the content of files
+func NewContentHash() hash.Hash {
+	return sha256.New()
+}
+
+// HashSum returns the hex encoded sum of the content hash
+func HashSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// HashFile returns the hex encoded content hash of the local file
+func HashFile(fp string) (string, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := NewContentHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return HashSum(h), nil
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// TaskExtras are the details given to a task when it is added, like by the rules which matched its file.
+// They are kept when the task is restored after a restart.
+type TaskExtras struct {
+	Tags        []string `json:"tags,omitempty"`         // recorded in the task history
+	NotifyChats []int64  `json:"notify_chats,omitempty"` // the chats told when the task finishes
+}
+
+func (e TaskExtras) IsZero() bool {
+	return len(e.Tags) == 0 && len(e.NotifyChats) == 0
+}
+
+type extrasKey struct{}
+
+// WithExtras returns a ctx adding the extras to the tasks added with it
+func WithExtras(ctx context.Context, extras TaskExtras) context.Context {
+	return context.WithValue(ctx, extrasKey{}, extras)
+}
+
+// ExtrasFromContext returns the extras set by WithExtras
+func ExtrasFromContext(ctx context.Context) TaskExtras {
+	extras, _ := ctx.Value(extrasKey{}).(TaskExtras)
+	return extras
+}
+
+func marshalExtras(extras TaskExtras) (string, error) {
+	if extras.IsZero() {
+		return "", nil
+	}
+	data, err := json.Marshal(extras)
+	return string(data), err
+}
+
+// TaskNotifier tells the chats about the finished task, taskErr is nil if it is completed
+type TaskNotifier func(ctx context.Context, chatIDs []int64, task Executable, status string, taskErr error)
+
+var (
+	taskNotifier   TaskNotifier
+	taskNotifierMu sync.RWMutex
+)
+
+// SetTaskNotifier sets how the chats in TaskExtras.NotifyChats are told, they are not told until it is set
+func SetTaskNotifier(notifier TaskNotifier) {
+	taskNotifierMu.Lock()
+	defer taskNotifierMu.Unlock()
+	taskNotifier = notifier
+}
+
+func notifyTask(ctx context.Context, extras TaskExtras, task Executable, status string, taskErr error) {
+	if len(extras.NotifyChats) == 0 {
+		return
+	}
+	taskNotifierMu.RLock()
+	notifier := taskNotifier
+	taskNotifierMu.RUnlock()
+	if notifier != nil {
+		notifier(ctx, extras.NotifyChats, task, status, taskErr)
+	}
+}
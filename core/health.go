@@ -0,0 +1,62 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// how often the interval is checked again while the probe is disabled
+const healthProbeIdle = time.Minute
+
+// SpaceRequirer is implemented by tasks which know the bytes they save to each storage before they run
+type SpaceRequirer interface {
+	RequiredSpace() map[storage.Storage]int64
+}
+
+// checkSpace rejects the task if a storage it saves to reports less free space than the task needs
+func checkSpace(ctx context.Context, task Executable) error {
+	sr, ok := task.(SpaceRequirer)
+	if !ok {
+		return nil
+	}
+	for stor, size := range sr.RequiredSpace() {
+		if err := storage.CheckSpace(ctx, stor, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartHealthProbe probes the storages every health.interval seconds of the config until ctx is done.
+// onChange is called with the storages which became unhealthy or healthy again.
+func StartHealthProbe(ctx context.Context, onChange func(storage.Health)) {
+	logger := log.FromContext(ctx)
+	for {
+		// read every time, as the config may be reloaded
+		hc := config.C().Health
+		wait := time.Duration(hc.Interval) * time.Second
+		if hc.Interval > 0 {
+			for _, h := range storage.ProbeAll(ctx, hc.MinFreeBytes()) {
+				if h.Healthy() {
+					logger.Infof("Storage %s is healthy again", h.Name)
+				} else {
+					logger.Warnf("Storage %s is unhealthy, reachable: %t, low space: %t", h.Name, h.Reachable, h.LowSpace)
+				}
+				if onChange != nil {
+					onChange(h)
+				}
+			}
+		} else {
+			wait = healthProbeIdle
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
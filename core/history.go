@@ -0,0 +1,46 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/database"
+)
+
+// Report holds the details of a task which are recorded in the task history
+type Report struct {
+	UserID      int64 // telegram chat id of the user who created the task, 0 if unknown
+	StorageName string
+	StoragePath string
+	Bytes       int64 // size of the task content, 0 if unknown
+}
+
+// Reportable is implemented by tasks which can report their details for the task history
+type Reportable interface {
+	Report() Report
+}
+
+func recordHistory(ctx context.Context, task Executable, extras TaskExtras, status string, taskErr error, duration time.Duration) {
+	history := &database.TaskHistory{
+		TaskID:   task.TaskID(),
+		Type:     task.Type().String(),
+		Title:    task.Title(),
+		Status:   status,
+		Duration: duration,
+		Tags:     extras.Tags,
+	}
+	if r, ok := task.(Reportable); ok {
+		report := r.Report()
+		history.UserID = report.UserID
+		history.StorageName = report.StorageName
+		history.StoragePath = report.StoragePath
+		history.Bytes = report.Bytes
+	}
+	if taskErr != nil {
+		history.Error = taskErr.Error()
+	}
+	if err := database.CreateTaskHistory(context.WithoutCancel(ctx), history); err != nil {
+		log.FromContext(ctx).Errorf("Failed to record history of task %s: %v", task.TaskID(), err)
+	}
+}
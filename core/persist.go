@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// Serializable is implemented by tasks whose state can be persisted and restored after a restart.
+type Serializable interface {
+	Executable
+	// MarshalTask returns the data needed to rebuild the task, it will be stored in database.TaskState.Data
+	MarshalTask() ([]byte, error)
+}
+
+// Restorer rebuilds a task from the data returned by Serializable.MarshalTask
+type Restorer func(ctx context.Context, id string, data []byte) (Executable, error)
+
+var restorers = make(map[tasktype.TaskType]Restorer)
+
+// RegisterRestorer registers the restorer for a task type, it should be called in init functions
+func RegisterRestorer(typ tasktype.TaskType, restorer Restorer) {
+	restorers[typ] = restorer
+}
+
+// finished task states are kept for a while for inspection, then purged on startup
+const finishedTaskStateTTL = 7 * 24 * time.Hour
+
+func persistTask(ctx context.Context, task Executable, priority queue.Priority) error {
+	st, ok := task.(Serializable)
+	if !ok {
+		return nil
+	}
+	data, err := st.MarshalTask()
+	if err != nil {
+		return fmt.Errorf("failed to marshal task: %w", err)
+	}
+	extras, err := marshalExtras(ExtrasFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to marshal task extras: %w", err)
+	}
+	return database.PersistTask(ctx, task.TaskID(), task.Title(), task.Type().String(), string(data), int(priority),
+		storage.ConflictPolicyFromContext(ctx).String(), extras)
+}
+
+// UpdateTaskData persists the state of the task again, so a task restored after restart does not redo
+// the work done since it was added, e.g. the files of a batch already saved. Errors are only logged.
+func UpdateTaskData(ctx context.Context, task Serializable) {
+	logger := log.FromContext(ctx)
+	data, err := task.MarshalTask()
+	if err != nil {
+		logger.Errorf("Failed to marshal task %s: %v", task.TaskID(), err)
+		return
+	}
+	if err := database.UpdateTaskData(context.WithoutCancel(ctx), task.TaskID(), string(data)); err != nil {
+		logger.Errorf("Failed to update data of task %s: %v", task.TaskID(), err)
+	}
+}
+
+func updateTaskStatus(ctx context.Context, task Executable, status string, taskErr error) {
+	if _, ok := task.(Serializable); !ok {
+		return
+	}
+	errMsg := ""
+	if taskErr != nil && status != database.TaskStatusPending {
+		errMsg = taskErr.Error()
+	}
+	// the task context may be already cancelled here
+	if err := database.UpdateTaskStatus(context.WithoutCancel(ctx), task.TaskID(), status, errMsg); err != nil {
+		log.FromContext(ctx).Errorf("Failed to update status of task %s: %v", task.TaskID(), err)
+	}
+}
+
+func updateTaskStatusByID(ctx context.Context, id string, status string) {
+	if err := database.UpdateTaskStatus(context.WithoutCancel(ctx), id, status, ""); err != nil {
+		log.FromContext(ctx).Errorf("Failed to update status of task %s: %v", id, err)
+	}
+}
+
+// taskStatusFromError returns the status a task should be persisted with after it returned
+func taskStatusFromError(ctx context.Context, err error) string {
+	switch {
+	case err == nil:
+		return database.TaskStatusCompleted
+	case ctx.Err() != nil:
+		// the whole program is exiting, keep the task to resume it on next startup
+		return database.TaskStatusPending
+	case errors.Is(err, context.Canceled):
+		return database.TaskStatusCancelled
+	default:
+		return database.TaskStatusFailed
+	}
+}
+
+// RestoreTasks re-enqueues the tasks that were not finished before the last exit.
+// The ctx should carry everything the restorers need, e.g. the bot ext context.
+func RestoreTasks(ctx context.Context) (int, error) {
+	logger := log.FromContext(ctx)
+	if err := database.DeleteFinishedTaskStates(ctx, time.Now().Add(-finishedTaskStateTTL)); err != nil {
+		logger.Warnf("Failed to purge finished task states: %v", err)
+	}
+	states, err := database.GetPendingTasks(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending tasks: %w", err)
+	}
+	restored := 0
+	for _, state := range states {
+		// the conflict policy chosen when the task was added
+		taskCtx := ctx
+		if policy, err := conflict.ParsePolicy(state.Conflict); err == nil {
+			taskCtx = storage.WithConflictPolicy(ctx, policy)
+		}
+		if state.Extras != "" {
+			var extras TaskExtras
+			if err := json.Unmarshal([]byte(state.Extras), &extras); err != nil {
+				logger.Warnf("Failed to unmarshal extras of task %s: %v", state.ID, err)
+			}
+			taskCtx = WithExtras(taskCtx, extras)
+		}
+		task, err := restoreTask(taskCtx, state)
+		if err != nil {
+			logger.Errorf("Failed to restore task %s: %v", state.ID, err)
+			if err := database.UpdateTaskStatus(ctx, state.ID, database.TaskStatusFailed, err.Error()); err != nil {
+				logger.Errorf("Failed to update status of task %s: %v", state.ID, err)
+			}
+			continue
+		}
+		if err := queueInstance.Add(newQueueTask(taskCtx, task, queue.WithPriority(queue.Priority(state.Priority)))); err != nil {
+			logger.Errorf("Failed to enqueue restored task %s: %v", state.ID, err)
+			continue
+		}
+		status := database.TaskStatusPending
+		if state.Status == database.TaskStatusPaused {
+			if err := queueInstance.Pause(state.ID); err != nil {
+				logger.Errorf("Failed to pause restored task %s: %v", state.ID, err)
+			} else {
+				status = database.TaskStatusPaused
+			}
+		}
+		if err := database.UpdateTaskStatus(ctx, state.ID, status, ""); err != nil {
+			logger.Errorf("Failed to update status of task %s: %v", state.ID, err)
+		}
+		logger.Infof("Restored task %s", task.Title())
+		restored++
+	}
+	return restored, nil
+}
+
+func restoreTask(ctx context.Context, state database.TaskState) (Executable, error) {
+	typ, err := tasktype.ParseTaskType(state.Type)
+	if err != nil {
+		return nil, err
+	}
+	restorer, ok := restorers[typ]
+	if !ok {
+		return nil, fmt.Errorf("no restorer registered for task type %s", typ)
+	}
+	return restorer(ctx, state.ID, []byte(state.Data))
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/parsers"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// ReloadReport is the result of ReloadConfig
+type ReloadReport struct {
+	config.ReloadReport
+	Errors []string `json:"errors"` // the changes which failed to apply, like storages which can not be created
+}
+
+var reloadMu sync.Mutex
+
+// ReloadConfig reads the config again and applies the changes to the workers, parsers and storages.
+// The config is not changed if it is invalid.
+func ReloadConfig(ctx context.Context) (ReloadReport, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	logger := log.FromContext(ctx)
+	cfgReport, err := config.Reload()
+	if err != nil {
+		return ReloadReport{}, err
+	}
+	report := ReloadReport{ReloadReport: cfgReport}
+	c := config.C()
+
+	if slices.Contains(report.Applied, "workers") {
+		SetWorkers(c.Workers)
+	}
+	if slices.Contains(report.Applied, "parser") {
+		if err := parsers.Reload(ctx); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	changes := report.Storages
+	for _, name := range slices.Concat(changes.Changed, changes.Removed) {
+		bandwidth.ResetStorage(name)
+		retireStorages(ctx, storage.RemoveStorage(ctx, name))
+	}
+	// the storages dropped with the changed ones, like their mirror storages, are created again here too
+	for _, sc := range c.Storages {
+		if _, err := storage.GetStorageByName(ctx, sc.GetName()); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("storage %s: %v", sc.GetName(), err))
+		}
+	}
+	if slices.Contains(report.Applied, "users") || slices.Contains(report.Applied, "storages") {
+		storage.RefreshUserStorages(ctx)
+	}
+
+	if report.Changed() {
+		logger.Infof("Reloaded config, applied: %v, needs restart: %v", report.Applied, report.Restart)
+	}
+	for _, e := range report.Errors {
+		logger.Errorf("Failed to apply config change: %s", e)
+	}
+	return report, nil
+}
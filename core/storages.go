@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	storcfg "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// how often the replaced or removed storages are checked for tasks still using them
+const retireInterval = 10 * time.Second
+
+// AddStorage creates the storage from raw, an entry of the storages in the config file, and adds it to the config.
+// The storage is created first, so one which can not be initialized is not saved. See config.AddStorage for userIDs.
+func AddStorage(ctx context.Context, raw map[string]any, userIDs []int64) (storcfg.StorageConfig, error) {
+	sc, err := config.ParseStorage(raw)
+	if err != nil {
+		return nil, err
+	}
+	if config.C().GetStorageByName(sc.GetName()) != nil {
+		return nil, fmt.Errorf("%w: %s", config.ErrStorageExists, sc.GetName())
+	}
+	var stor storage.Storage
+	if sc.IsEnabled() {
+		if stor, err = storage.NewStorage(ctx, sc); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := config.AddStorage(raw, userIDs); err != nil {
+		if stor != nil {
+			storage.CloseStorage(ctx, stor)
+		}
+		return nil, err
+	}
+	if stor != nil {
+		retireStorages(ctx, storage.PutStorage(ctx, stor))
+	}
+	return sc, nil
+}
+
+// UpdateStorage replaces the config of the storage with the name in raw and creates it again.
+// The old storage is closed once the tasks which may still use it are done.
+func UpdateStorage(ctx context.Context, raw map[string]any) (storcfg.StorageConfig, error) {
+	sc, err := config.ParseStorage(raw)
+	if err != nil {
+		return nil, err
+	}
+	var stor storage.Storage
+	if sc.IsEnabled() {
+		if stor, err = storage.NewStorage(ctx, sc); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := config.UpdateStorage(raw); err != nil {
+		if stor != nil {
+			storage.CloseStorage(ctx, stor)
+		}
+		return nil, err
+	}
+	bandwidth.ResetStorage(sc.GetName())
+	if stor != nil {
+		retireStorages(ctx, storage.PutStorage(ctx, stor))
+	} else {
+		retireStorages(ctx, storage.RemoveStorage(ctx, sc.GetName()))
+	}
+	return sc, nil
+}
+
+// RemoveStorage removes the storage from the config, it is closed once the tasks which may still use it are done.
+// The storages used by other storages can not be removed.
+func RemoveStorage(ctx context.Context, name string) error {
+	if err := config.RemoveStorage(name); err != nil {
+		return err
+	}
+	bandwidth.ResetStorage(name)
+	retireStorages(ctx, storage.RemoveStorage(ctx, name))
+	return nil
+}
+
+func retireStorages(ctx context.Context, stors []storage.Storage) {
+	if len(stors) == 0 {
+		return
+	}
+	storage.Retire(ctx, stors, storageInUse, retireInterval)
+}
+
+// SourceStorager is implemented by tasks which read files from storages, e.g. transfers
+type SourceStorager interface {
+	// SourceStorages returns the names of the storages the task reads from
+	SourceStorages() []string
+}
+
+// storageInUse reports whether a queued or running task saves to or reads from the storage with the name.
+// The tasks created after the storage is replaced count too, as they can not be told apart by the name.
+func storageInUse(name string) bool {
+	if queueInstance == nil {
+		return false
+	}
+	for _, task := range queueInstance.Data() {
+		if r, ok := task.(Reportable); ok && r.Report().StorageName == name {
+			return true
+		}
+		// the tasks saving to several storages report only the first one
+		if sr, ok := task.(SpaceRequirer); ok {
+			for stor := range sr.RequiredSpace() {
+				if stor.Name() == name {
+					return true
+				}
+			}
+		}
+		if s, ok := task.(SourceStorager); ok && slices.Contains(s.SourceStorages(), name) {
+			return true
+		}
+	}
+	return false
+}
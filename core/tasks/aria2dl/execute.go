@@ -12,6 +12,8 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/pkg/aria2"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
 // Execute implements core.Executable.
@@ -23,10 +25,19 @@ func (t *Task) Execute(ctx context.Context) error {
 		t.Progress.OnStart(ctx, t)
 	}
 
+	// The download may have been paused by a previous run
+	if status, err := t.Aria2Client.TellStatus(ctx, t.GID); err == nil && status.IsDownloadPaused() {
+		if _, err := t.Aria2Client.Unpause(ctx, t.GID); err != nil {
+			logger.Warnf("Failed to unpause aria2 download %s: %v", t.GID, err)
+		}
+	}
+
 	// Wait for aria2 download to complete
 	if err := t.waitForDownload(ctx); err != nil {
-		// If context was canceled, also cancel the aria2 download
-		if errors.Is(err, context.Canceled) {
+		// If context was canceled, also pause or cancel the aria2 download
+		if queue.IsPaused(ctx) {
+			t.pauseAria2Download()
+		} else if errors.Is(err, context.Canceled) {
 			t.cancelAria2Download()
 		}
 		logger.Errorf("Aria2 download failed: %v", err)
@@ -205,9 +216,10 @@ func (t *Task) transferFile(ctx context.Context, filePath string) error {
 
 	logger.Infof("Transferring file %s to %s:%s", fileName, t.Storage.Name(), destPath)
 
-	if err := t.Storage.Save(ctx, f, destPath); err != nil {
+	if _, err := storage.Save(ctx, t.Storage, f, destPath); err != nil {
 		return fmt.Errorf("failed to save file %s to storage: %w", fileName, err)
 	}
+	t.transferredBytes.Add(fileInfo.Size())
 
 	logger.Infof("Successfully transferred file %s", fileName)
 	return nil
@@ -248,3 +260,15 @@ func (t *Task) cancelAria2Download() {
 		logger.Debugf("Failed to remove download result for %s: %v", t.GID, err)
 	}
 }
+
+// pauseAria2Download pauses the aria2 download task, it is unpaused when the task runs again
+func (t *Task) pauseAria2Download() {
+	logger := log.FromContext(t.ctx)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := t.Aria2Client.ForcePause(ctx, t.GID); err != nil {
+		logger.Warnf("Failed to pause aria2 download %s: %v", t.GID, err)
+	} else {
+		logger.Infof("Paused aria2 download %s", t.GID)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
 	"github.com/kiss2u/SaveAny-Bot/pkg/aria2"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
 
 type ProgressTracker interface {
@@ -129,12 +130,16 @@ func (p *Progress) OnDone(ctx context.Context, task *Task, err error) {
 	logger := log.FromContext(ctx)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
+			msgKey := i18nk.BotMsgProgressTaskCanceledWithId
+			if queue.IsPaused(ctx) {
+				msgKey = i18nk.BotMsgProgressTaskPausedWithId
+			}
 			logger.Infof("Aria2 task %s was canceled", task.TaskID())
 			ext := tgutil.ExtFromContext(ctx)
 			if ext != nil {
 				ext.EditMessage(p.chatID, &tg.MessagesEditMessageRequest{
 					ID: p.msgID,
-					Message: i18n.T(i18nk.BotMsgProgressTaskCanceledWithId, map[string]any{
+					Message: i18n.T(msgKey, map[string]any{
 						"TaskID": task.TaskID(),
 					}),
 				})
@@ -166,6 +171,10 @@ func (p *Progress) OnDone(ctx context.Context, task *Task, err error) {
 		logger.Errorf("Failed to build entities: %s", err)
 		return
 	}
+	if err := styling.Perform(&entityBuilder, tgutil.MirrorReportStyling(ctx)...); err != nil {
+		logger.Errorf("Failed to build entities: %s", err)
+		return
+	}
 	text, entities := entityBuilder.Complete()
 	req := &tg.MessagesEditMessageRequest{
 		ID: p.msgID,
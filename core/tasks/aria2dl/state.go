@@ -0,0 +1,82 @@
+package aria2dl
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/aria2"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeAria2, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+type taskState struct {
+	GID            string   `json:"gid"`
+	URIs           []string `json:"uris"`
+	Storage        string   `json:"storage"`
+	StorPath       string   `json:"stor_path"`
+	UserID         int64    `json:"user_id,omitempty"` // older states have only the progress chat, which is the user
+	ProgressChatID int64    `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int      `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		UserID:   t.UserID,
+		GID:      t.GID,
+		URIs:     t.URIs,
+		Storage:  t.Storage.Name(),
+		StorPath: t.StorPath,
+	}
+	if p, ok := t.Progress.(*Progress); ok {
+		state.ProgressChatID = p.chatID
+		state.ProgressMsgID = p.msgID
+	}
+	return json.Marshal(state)
+}
+
+// restoreTask rebuilds the task with a new aria2 client.
+// If aria2 no longer knows the GID (e.g. aria2 was restarted too), the URIs are added again.
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	if !config.C().Aria2.Enable {
+		return nil, errors.New("aria2 is not enabled")
+	}
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	stor, err := storage.GetStorageByName(ctx, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+	client, err := aria2.NewClient(config.C().Aria2.Url, config.C().Aria2.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aria2 client: %w", err)
+	}
+	gid := state.GID
+	if _, err := client.TellStatus(ctx, gid); err != nil {
+		if len(state.URIs) == 0 {
+			return nil, fmt.Errorf("aria2 download %s not found and no URIs to add again: %w", gid, err)
+		}
+		gid, err = client.AddURI(ctx, state.URIs, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add aria2 download again: %w", err)
+		}
+	}
+	var progress ProgressTracker
+	if state.ProgressMsgID != 0 {
+		progress = NewProgress(state.ProgressMsgID, state.ProgressChatID)
+	}
+	return NewTask(id, ctx, cmp.Or(state.UserID, state.ProgressChatID), gid, state.URIs, client, stor, state.StorPath, progress), nil
+}
@@ -3,6 +3,7 @@ package aria2dl
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/pkg/aria2"
@@ -14,6 +15,7 @@ var _ core.Executable = (*Task)(nil)
 
 type Task struct {
 	ID          string
+	UserID      int64 // telegram chat id of the user who added the task
 	ctx         context.Context
 	GID         string
 	URIs        []string
@@ -21,6 +23,8 @@ type Task struct {
 	Storage     storage.Storage
 	StorPath    string
 	Progress    ProgressTracker
+
+	transferredBytes atomic.Int64 // bytes saved to storage
 }
 
 // Title implements core.Executable.
@@ -41,6 +45,7 @@ func (t *Task) TaskID() string {
 func NewTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	gid string,
 	uris []string,
 	aria2Client *aria2.Client,
@@ -50,6 +55,7 @@ func NewTask(
 ) *Task {
 	return &Task{
 		ID:          id,
+		UserID:      userID,
 		ctx:         ctx,
 		GID:         gid,
 		URIs:        uris,
@@ -59,3 +65,14 @@ func NewTask(
 		Progress:    progressTracker,
 	}
 }
+
+// Report implements core.Reportable.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID:      t.UserID,
+		StorageName: t.Storage.Name(),
+		StoragePath: t.StorPath,
+		Bytes:       t.transferredBytes.Load(),
+	}
+	return report
+}
@@ -70,6 +70,7 @@ func TestTaskCreation(t *testing.T) {
 	task := NewTask(
 		"test-task-id",
 		ctx,
+		0,
 		"test-gid",
 		[]string{"http://example.com/file.zip"},
 		nil,
@@ -107,6 +108,7 @@ func TestProgressTracker(t *testing.T) {
 	task := NewTask(
 		"test-task-id",
 		ctx,
+		0,
 		"test-gid",
 		[]string{"http://example.com/file.zip"},
 		nil,
@@ -151,6 +153,7 @@ func TestTaskTitle(t *testing.T) {
 	task := NewTask(
 		"test-task-id",
 		ctx,
+		0,
 		"test-gid-123",
 		[]string{"http://example.com/file.zip"},
 		nil,
@@ -188,6 +191,7 @@ func TestContextCancellation(t *testing.T) {
 	task := NewTask(
 		"test-task-id",
 		ctx,
+		0,
 		"test-gid",
 		[]string{"http://example.com/file.zip"},
 		nil, // nil client will cause Execute to fail/timeout
@@ -9,11 +9,14 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/retry"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/tdler"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/ioutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -24,14 +27,26 @@ func (t *Task) Execute(ctx context.Context) error {
 	workers := config.C().Workers
 	eg, gctx := errgroup.WithContext(ctx)
 	eg.SetLimit(workers)
+	// a resumed task continues with the elements not saved yet, each of them is downloaded again
+	var savedSize int64
+	pending := make([]TaskElement, 0, len(t.elems))
+	t.processingMu.RLock()
 	for _, elem := range t.elems {
+		if t.saved[elem.ID] {
+			savedSize += elem.File.Size()
+			continue
+		}
+		pending = append(pending, elem)
+	}
+	t.processingMu.RUnlock()
+	t.downloaded.Store(savedSize)
+	for _, elem := range pending {
 		eg.Go(func() error {
-			t.processingMu.RLock()
+			t.processingMu.Lock()
 			if t.processing[elem.ID] != nil {
+				t.processingMu.Unlock()
 				return fmt.Errorf("element with ID %s is already being processed", elem.ID)
 			}
-			t.processingMu.RUnlock()
-			t.processingMu.Lock()
 			t.processing[elem.ID] = &elem
 			t.processingMu.Unlock()
 			defer func() {
@@ -39,7 +54,14 @@ func (t *Task) Execute(ctx context.Context) error {
 				delete(t.processing, elem.ID)
 				t.processingMu.Unlock()
 			}()
-			return t.processElement(gctx, elem)
+			if err := t.processElement(gctx, elem); err != nil {
+				return err
+			}
+			t.processingMu.Lock()
+			t.saved[elem.ID] = true
+			t.processingMu.Unlock()
+			core.UpdateTaskData(ctx, t)
+			return nil
 		})
 	}
 	err := eg.Wait()
@@ -54,14 +76,18 @@ func (t *Task) Execute(ctx context.Context) error {
 
 func (t *Task) processElement(ctx context.Context, elem TaskElement) error {
 	logger := log.FromContext(ctx).WithPrefix(fmt.Sprintf("file[%s]", elem.File.Name()))
+	if elem.Conflict != "" {
+		ctx = storage.WithConflictPolicy(ctx, elem.Conflict)
+	}
 	if elem.stream {
 		pr, pw := io.Pipe()
 		defer pr.Close()
 		errg, uploadCtx := errgroup.WithContext(ctx)
 		errg.Go(func() error {
-			return elem.Storage.Save(uploadCtx, pr, elem.Path)
+			_, err := storage.Save(uploadCtx, elem.Storage, pr, elem.Path)
+			return err
 		})
-		wr := ioutil.NewProgressWriter(pw, func(n int) {
+		wr := ioutil.NewProgressWriter(bandwidth.DownloadWriter(ctx, pw), func(n int) {
 			t.downloaded.Add(int64(n))
 			t.Progress.OnProgress(ctx, t)
 		})
@@ -91,7 +117,7 @@ func (t *Task) processElement(ctx context.Context, elem TaskElement) error {
 			logger.Errorf("Failed to close local file: %v", err)
 		}
 	}()
-	wrAt := ioutil.NewProgressWriterAt(localFile, func(n int) {
+	wrAt := ioutil.NewProgressWriterAt(bandwidth.DownloadWriterAt(ctx, localFile), func(n int) {
 		t.downloaded.Add(int64(n))
 		t.Progress.OnProgress(ctx, t)
 	})
@@ -119,7 +145,7 @@ func (t *Task) processElement(ctx context.Context, elem TaskElement) error {
 			return fmt.Errorf("failed to open cache file: %w", err)
 		}
 		defer file.Close()
-		if err = elem.Storage.Save(vctx, file, elem.Path); err != nil {
+		if _, err = storage.Save(vctx, elem.Storage, file, elem.Path); err != nil {
 			logger.Errorf("Failed to save file: %s, retrying...", err)
 			return err
 		}
@@ -17,6 +17,7 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
 
 type ProgressTracker interface {
@@ -134,8 +135,12 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
+			msgKey := i18nk.BotMsgProgressTaskCanceled
+			if queue.IsPaused(ctx) {
+				msgKey = i18nk.BotMsgProgressTaskPaused
+			}
 			stylingErr = styling.Perform(&entityBuilder,
-				styling.Plain(i18n.T(i18nk.BotMsgProgressTaskCanceled, nil)),
+				styling.Plain(i18n.T(msgKey, nil)),
 			)
 		} else {
 			stylingErr = styling.Perform(&entityBuilder,
@@ -154,6 +159,9 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 		)
 	}
 
+	if stylingErr == nil {
+		stylingErr = styling.Perform(&entityBuilder, tgutil.MirrorReportStyling(ctx)...)
+	}
 	if stylingErr != nil {
 		log.FromContext(ctx).Errorf("Failed to build entities: %s", stylingErr)
 		return
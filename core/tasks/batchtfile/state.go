@@ -0,0 +1,124 @@
+package batchtfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	tftask "github.com/kiss2u/SaveAny-Bot/core/tasks/tfile"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeTgbatch, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+// elementState is the persisted form of a TaskElement, the file is fetched again from its message like tfile tasks do
+type elementState struct {
+	ChatID    int64  `json:"chat_id"`
+	MessageID int    `json:"message_id"`
+	FileName  string `json:"file_name"`
+	FileSize  int64  `json:"file_size"`
+	Storage   string `json:"storage"`
+	Path      string `json:"path"`
+	Conflict  string `json:"conflict,omitempty"`
+}
+
+// taskState is the persisted form of a Task, only the elements not saved yet are kept
+type taskState struct {
+	UserID         int64          `json:"user_id"`
+	Elements       []elementState `json:"elements"`
+	IgnoreErrors   bool           `json:"ignore_errors,omitempty"`
+	ProgressChatID int64          `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int            `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		UserID:       t.UserID,
+		IgnoreErrors: t.IgnoreErrors,
+	}
+	t.processingMu.RLock()
+	defer t.processingMu.RUnlock()
+	for _, elem := range t.elems {
+		if t.saved[elem.ID] {
+			continue
+		}
+		fm, ok := elem.File.(tfile.TGFileMessage)
+		if !ok || fm.Message() == nil {
+			return nil, fmt.Errorf("file %s has no source message", elem.File.Name())
+		}
+		msg := fm.Message()
+		state.Elements = append(state.Elements, elementState{
+			ChatID:    tgutil.ChatIdFromPeer(msg.PeerID),
+			MessageID: msg.GetID(),
+			FileName:  elem.File.Name(),
+			FileSize:  elem.File.Size(),
+			Storage:   elem.Storage.Name(),
+			Path:      elem.Path,
+			Conflict:  elem.Conflict.String(),
+		})
+	}
+	if p, ok := t.Progress.(*Progress); ok {
+		state.ProgressChatID = p.ChatID
+		state.ProgressMsgID = p.MessageID
+	}
+	return json.Marshal(state)
+}
+
+// restoreTask rebuilds the task with the elements not saved before the restart.
+// The elements whose message or storage is gone are dropped, the task fails only if none is left.
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	logger := log.FromContext(ctx)
+	elems := make([]TaskElement, 0, len(state.Elements))
+	var errs []error
+	for _, es := range state.Elements {
+		elem, err := restoreElement(ctx, es)
+		if err != nil {
+			logger.Warnf("Failed to restore file %s of task %s: %v", es.FileName, id, err)
+			errs = append(errs, err)
+			continue
+		}
+		elems = append(elems, *elem)
+	}
+	if len(elems) == 0 {
+		return nil, fmt.Errorf("no file of the batch can be restored: %w", errors.Join(errs...))
+	}
+	return NewBatchTGFileTask(id, ctx, state.UserID, elems,
+		NewProgressTracker(state.ProgressMsgID, state.ProgressChatID), state.IgnoreErrors), nil
+}
+
+func restoreElement(ctx context.Context, es elementState) (*TaskElement, error) {
+	stor, err := storage.GetStorageByName(ctx, es.Storage)
+	if err != nil {
+		return nil, err
+	}
+	file, err := tftask.FetchFile(ctx, es.ChatID, es.MessageID, tfile.WithName(es.FileName), tfile.WithSizeIfZero(es.FileSize))
+	if err != nil {
+		return nil, err
+	}
+	elem, err := NewTaskElement(stor, es.Path, file)
+	if err != nil {
+		return nil, err
+	}
+	if es.Conflict != "" {
+		if elem.Conflict, err = conflict.ParsePolicy(es.Conflict); err != nil {
+			return nil, err
+		}
+	}
+	return elem, nil
+}
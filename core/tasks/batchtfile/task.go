@@ -3,12 +3,14 @@ package batchtfile
 import (
 	"context"
 	"fmt"
+	"path"
 	"path/filepath"
 	"sync"
 	"sync/atomic"
 
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
 	"github.com/kiss2u/SaveAny-Bot/storage"
@@ -22,12 +24,14 @@ type TaskElement struct {
 	Storage   storage.Storage
 	Path      string
 	File      tfile.TGFile
+	Conflict  conflict.Policy // overrides the conflict policy of the task if set, the files of a batch may match different rules
 	localPath string
 	stream    bool
 }
 
 type Task struct {
 	ID           string
+	UserID       int64 // telegram chat id of the user who added the task
 	ctx          context.Context
 	elems        []TaskElement
 	Progress     ProgressTracker
@@ -37,6 +41,7 @@ type Task struct {
 	processing   map[string]TaskElementInfo
 	processingMu sync.RWMutex
 	failed       map[string]error // [TODO] errors for each element
+	saved        map[string]bool  // the elements saved, they are not saved again when the paused task is resumed
 }
 
 // Title implements core.Exectable.
@@ -45,7 +50,7 @@ func (t *Task) Title() string {
 }
 
 func (t *Task) Type() tasktype.TaskType {
-	return tasktype.TaskTypeTgfiles
+	return tasktype.TaskTypeTgbatch
 }
 
 func NewTaskElement(
@@ -80,12 +85,14 @@ func NewTaskElement(
 func NewBatchTGFileTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	files []TaskElement,
 	progress ProgressTracker,
 	ignoreErrors bool,
 ) *Task {
 	task := &Task{
 		ID:         id,
+		UserID:     userID,
 		ctx:        ctx,
 		elems:      files,
 		Progress:   progress,
@@ -101,6 +108,30 @@ func NewBatchTGFileTask(
 		IgnoreErrors: ignoreErrors,
 		processingMu: sync.RWMutex{},
 		failed:       make(map[string]error),
+		saved:        make(map[string]bool),
 	}
 	return task
 }
+
+// Report implements core.Reportable.
+// The storage and path of the first element are reported, as elements may be saved to different places.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID: t.UserID,
+		Bytes:  t.totalSize,
+	}
+	if len(t.elems) > 0 {
+		report.StorageName = t.elems[0].Storage.Name()
+		report.StoragePath = path.Dir(t.elems[0].Path)
+	}
+	return report
+}
+
+// RequiredSpace implements core.SpaceRequirer.
+func (t *Task) RequiredSpace() map[storage.Storage]int64 {
+	sizes := make(map[storage.Storage]int64)
+	for _, elem := range t.elems {
+		sizes[elem.Storage] += elem.File.Size()
+	}
+	return sizes
+}
@@ -11,10 +11,13 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/retry"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/ioutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -24,6 +27,7 @@ func (t *Task) Execute(ctx context.Context) error {
 	if t.Progress != nil {
 		t.Progress.OnStart(ctx, t)
 	}
+	t.dedupPolicy = core.GetDedupPolicy(ctx, t.UserID)
 	// head all links to get file info
 	eg, gctx := errgroup.WithContext(ctx)
 	eg.SetLimit(config.C().Workers)
@@ -128,9 +132,17 @@ func (t *Task) processLink(ctx context.Context, file *File) error {
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			return fmt.Errorf("GET %s returned status %d", file.URL, resp.StatusCode)
 		}
+		body := bandwidth.DownloadReader(ctx, resp.Body)
 		ctx = context.WithValue(ctx, ctxkey.ContentLength, file.Size)
+		storPath := filepath.Join(t.StorPath, file.Name)
+		hash := core.NewContentHash()
 		if t.stream {
-			return t.Storage.Save(ctx, resp.Body, filepath.Join(t.StorPath, file.Name))
+			savedPath, err := storage.Save(ctx, t.Storage, io.TeeReader(body, hash), storPath)
+			if err != nil {
+				return err
+			}
+			t.recordFile(ctx, file, core.HashSum(hash), t.Storage, savedPath)
+			return nil
 		}
 		cacheFile, err := fsutil.CreateFile(filepath.Join(config.C().Temp.BasePath,
 			fmt.Sprintf("direct_%s_%s", t.ID, file.Name)))
@@ -142,7 +154,7 @@ func (t *Task) processLink(ctx context.Context, file *File) error {
 				logger.Errorf("Failed to close and remove cache file: %v", err)
 			}
 		}()
-		wr := ioutil.NewProgressWriter(cacheFile, func(n int) {
+		wr := ioutil.NewProgressWriter(io.MultiWriter(cacheFile, hash), func(n int) {
 			t.downloadedBytes.Add(int64(n))
 			if t.Progress != nil {
 				t.Progress.OnProgress(ctx, t)
@@ -151,7 +163,7 @@ func (t *Task) processLink(ctx context.Context, file *File) error {
 
 		copyResultCh := make(chan error, 1)
 		go func() {
-			_, err := io.Copy(wr, resp.Body)
+			_, err := io.Copy(wr, body)
 			copyResultCh <- err
 		}()
 		select {
@@ -162,14 +174,39 @@ func (t *Task) processLink(ctx context.Context, file *File) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		}
+		sum := core.HashSum(hash)
+		ctx, stor, storPath, dupErr := core.HandleDuplicate(ctx, t.UserID, t.dedupPolicy,
+			core.FileIdentity{SHA256: sum, Size: file.Size}, t.Storage, storPath)
+		if dupErr != nil {
+			t.skippedMu.Lock()
+			t.skipped = append(t.skipped, dupErr)
+			t.skippedMu.Unlock()
+			return nil
+		}
 		_, err = cacheFile.Seek(0, 0)
 		if err != nil {
 			return fmt.Errorf("failed to seek cache file for resource %s: %w", file.URL, err)
 		}
-		return t.Storage.Save(ctx, cacheFile, filepath.Join(t.StorPath, file.Name))
+		savedPath, err := storage.Save(ctx, stor, cacheFile, storPath)
+		if err != nil {
+			return err
+		}
+		t.recordFile(ctx, file, sum, stor, savedPath)
+		return nil
 	}, retry.RetryTimes(uint(config.C().Retry)), retry.Context(ctx))
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 	return err
 }
+
+// recordFile records the file saved to savedPath, nothing is recorded if the file was skipped
+func (t *Task) recordFile(ctx context.Context, file *File, sum string, stor storage.Storage, savedPath string) {
+	if savedPath == "" {
+		return
+	}
+	core.RecordSavedFile(ctx, t.UserID, core.FileIdentity{
+		SHA256: sum,
+		Size:   file.Size,
+	}, t.dedupPolicy, stor.Name(), savedPath)
+}
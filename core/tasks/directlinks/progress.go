@@ -16,6 +16,8 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
 
 type TaskInfo interface {
@@ -26,6 +28,7 @@ type TaskInfo interface {
 	StoragePath() string
 	DownloadedBytes() int64
 	Processing() []FileInfo
+	Skipped() []*core.DuplicateError
 }
 
 type FileInfo interface {
@@ -51,12 +54,16 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 	logger := log.FromContext(ctx)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
+			msgKey := i18nk.BotMsgProgressTaskCanceledWithId
+			if queue.IsPaused(ctx) {
+				msgKey = i18nk.BotMsgProgressTaskPausedWithId
+			}
 			logger.Infof("Parsed task %s was canceled", info.TaskID())
 			ext := tgutil.ExtFromContext(ctx)
 			if ext != nil {
 				ext.EditMessage(p.chatID, &tg.MessagesEditMessageRequest{
 					ID: p.msgID,
-					Message: i18n.T(i18nk.BotMsgProgressTaskCanceledWithId, map[string]any{
+					Message: i18n.T(msgKey, map[string]any{
 						"TaskID": info.TaskID(),
 					}),
 				})
@@ -78,12 +85,29 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 	logger.Infof("Parsed task %s completed successfully", info.TaskID())
 
 	entityBuilder := entity.Builder{}
-	if err := styling.Perform(&entityBuilder,
+	opts := []styling.StyledTextOption{
 		styling.Plain(i18n.T(i18nk.BotMsgProgressDirectDonePrefix, nil)),
 		styling.Code(fmt.Sprintf("%d", info.TotalFiles())),
 		styling.Plain(i18n.T(i18nk.BotMsgProgressSavePathPrefix, nil)),
 		styling.Code(fmt.Sprintf("[%s]:%s", info.StorageName(), info.StoragePath())),
-	); err != nil {
+	}
+	if skipped := info.Skipped(); len(skipped) > 0 {
+		opts = append(opts, styling.Plain(i18n.T(i18nk.BotMsgProgressSkippedAlreadySavedPrefix, nil)))
+		for _, dup := range skipped {
+			opts = append(opts,
+				styling.Plain("\n  - "),
+				styling.Code(fmt.Sprintf("[%s]:%s", dup.StorageName, dup.StoragePath)),
+			)
+			if dup.LinkPath != "" {
+				opts = append(opts,
+					styling.Plain(i18n.T(i18nk.BotMsgProgressLinkedAtPrefix, nil)),
+					styling.Code(dup.LinkPath),
+				)
+			}
+		}
+	}
+	opts = append(opts, tgutil.MirrorReportStyling(ctx)...)
+	if err := styling.Perform(&entityBuilder, opts...); err != nil {
 		logger.Errorf("Failed to build entities: %s", err)
 		return
 	}
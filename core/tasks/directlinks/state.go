@@ -0,0 +1,64 @@
+package directlinks
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeDirectlinks, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+type taskState struct {
+	Links          []string `json:"links"`
+	Storage        string   `json:"storage"`
+	StorPath       string   `json:"stor_path"`
+	UserID         int64    `json:"user_id,omitempty"` // older states have only the progress chat, which is the user
+	ProgressChatID int64    `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int      `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		UserID:   t.UserID,
+		Links:    make([]string, 0, len(t.files)),
+		Storage:  t.Storage.Name(),
+		StorPath: t.StorPath,
+	}
+	for _, file := range t.files {
+		state.Links = append(state.Links, file.URL)
+	}
+	if p, ok := t.Progress.(*Progress); ok {
+		state.ProgressChatID = p.chatID
+		state.ProgressMsgID = p.msgID
+	}
+	return json.Marshal(state)
+}
+
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	if len(state.Links) == 0 {
+		return nil, fmt.Errorf("no links in task state")
+	}
+	stor, err := storage.GetStorageByName(ctx, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+	var progress ProgressTracker
+	if state.ProgressMsgID != 0 {
+		progress = NewProgress(state.ProgressMsgID, state.ProgressChatID)
+	}
+	return NewTask(id, ctx, cmp.Or(state.UserID, state.ProgressChatID), state.Links, stor, state.StorPath, progress), nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"sync"
 	"sync/atomic"
 
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/dedup"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
 	"github.com/kiss2u/SaveAny-Bot/storage"
 )
@@ -31,6 +33,7 @@ var _ core.Executable = (*Task)(nil)
 
 type Task struct {
 	ID       string
+	UserID   int64 // telegram chat id of the user who added the task
 	ctx      context.Context
 	files    []*File
 	Storage  storage.Storage
@@ -46,6 +49,9 @@ type Task struct {
 	processing      map[string]*File // {"url": File}
 	processingMu    sync.RWMutex
 	failed          map[string]error // [TODO] errors for each file
+	dedupPolicy     dedup.Policy
+	skipped         []*core.DuplicateError // files skipped as they have already been saved
+	skippedMu       sync.Mutex
 }
 
 // Title implements core.Exectable.
@@ -82,6 +88,13 @@ func (t *Task) StoragePath() string {
 	return t.StorPath
 }
 
+// Skipped implements TaskInfo.
+func (t *Task) Skipped() []*core.DuplicateError {
+	t.skippedMu.Lock()
+	defer t.skippedMu.Unlock()
+	return slices.Clone(t.skipped)
+}
+
 // TotalBytes implements TaskInfo.
 func (t *Task) TotalBytes() int64 {
 	return t.totalBytes
@@ -103,6 +116,7 @@ func (t *Task) TaskID() string {
 func NewTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	links []string,
 	stor storage.Storage,
 	storPath string,
@@ -118,6 +132,7 @@ func NewTask(
 	}
 	return &Task{
 		ID:           id,
+		UserID:       userID,
 		ctx:          ctx,
 		files:        files,
 		Storage:      stor,
@@ -131,3 +146,14 @@ func NewTask(
 		totalFiles:   int64(len(files)),
 	}
 }
+
+// Report implements core.Reportable.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID:      t.UserID,
+		StorageName: t.Storage.Name(),
+		StoragePath: t.StoragePath(),
+		Bytes:       t.totalBytes,
+	}
+	return report
+}
@@ -11,11 +11,13 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/retry"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/ioutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
 	"github.com/kiss2u/SaveAny-Bot/pkg/parser"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -87,6 +89,7 @@ func (t *Task) processResource(ctx context.Context, resource parser.Resource) er
 		if resp.StatusCode != http.StatusOK {
 			return fmt.Errorf("failed to download resource %s: %s", resource.URL, resp.Status)
 		}
+		body := bandwidth.DownloadReader(ctx, resp.Body)
 		ctx = context.WithValue(ctx, ctxkey.ContentLength, func() int64 {
 			if resource.Size > 0 {
 				return resource.Size
@@ -94,7 +97,8 @@ func (t *Task) processResource(ctx context.Context, resource parser.Resource) er
 			return resp.ContentLength
 		}())
 		if t.stream {
-			return t.Stor.Save(ctx, resp.Body, path.Join(t.StorPath, resource.Filename))
+			_, err := storage.Save(ctx, t.Stor, body, path.Join(t.StorPath, resource.Filename))
+			return err
 		}
 		cacheFile, err := fsutil.CreateFile(filepath.Join(config.C().Temp.BasePath,
 			fmt.Sprintf("resource_%s_%s", t.ID, resource.Filename)))
@@ -115,7 +119,7 @@ func (t *Task) processResource(ctx context.Context, resource parser.Resource) er
 
 		copyResultCh := make(chan error, 1)
 		go func() {
-			_, err := io.Copy(wr, resp.Body)
+			_, err := io.Copy(wr, body)
 			copyResultCh <- err
 		}()
 		select {
@@ -130,7 +134,8 @@ func (t *Task) processResource(ctx context.Context, resource parser.Resource) er
 		if err != nil {
 			return fmt.Errorf("failed to seek cache file for resource %s: %w", resource.URL, err)
 		}
-		return t.Stor.Save(ctx, cacheFile, path.Join(t.StorPath, resource.Filename))
+		_, err = storage.Save(ctx, t.Stor, cacheFile, path.Join(t.StorPath, resource.Filename))
+		return err
 	}, retry.Context(ctx), retry.RetryTimes(uint(config.C().Retry)))
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -16,6 +16,7 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
 
 var progressUpdatesLevels = []struct {
@@ -160,12 +161,16 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 	logger := log.FromContext(ctx)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
+			msgKey := i18nk.BotMsgProgressTaskCanceledWithId
+			if queue.IsPaused(ctx) {
+				msgKey = i18nk.BotMsgProgressTaskPausedWithId
+			}
 			logger.Infof("Parsed task %s was canceled", info.TaskID())
 			ext := tgutil.ExtFromContext(ctx)
 			if ext != nil {
 				ext.EditMessage(p.ChatID, &tg.MessagesEditMessageRequest{
 					ID: p.MessageID,
-					Message: i18n.T(i18nk.BotMsgProgressTaskCanceledWithId, map[string]any{
+					Message: i18n.T(msgKey, map[string]any{
 						"TaskID": info.TaskID(),
 					}),
 				})
@@ -196,6 +201,10 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 		logger.Errorf("Failed to build entities: %s", err)
 		return
 	}
+	if err := styling.Perform(&entityBuilder, tgutil.MirrorReportStyling(ctx)...); err != nil {
+		logger.Errorf("Failed to build entities: %s", err)
+		return
+	}
 	text, entities := entityBuilder.Complete()
 	req := &tg.MessagesEditMessageRequest{
 		ID: p.MessageID,
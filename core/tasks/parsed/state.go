@@ -0,0 +1,62 @@
+package parsed
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/parser"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeParseditem, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+type taskState struct {
+	Item           *parser.Item `json:"item"`
+	Storage        string       `json:"storage"`
+	StorPath       string       `json:"stor_path"`
+	UserID         int64        `json:"user_id,omitempty"` // older states have only the progress chat, which is the user
+	ProgressChatID int64        `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int          `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		UserID:   t.UserID,
+		Item:     t.item,
+		Storage:  t.Stor.Name(),
+		StorPath: t.StorPath,
+	}
+	if p, ok := t.progress.(*Progress); ok {
+		state.ProgressChatID = p.ChatID
+		state.ProgressMsgID = p.MessageID
+	}
+	return json.Marshal(state)
+}
+
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	if state.Item == nil {
+		return nil, fmt.Errorf("no parsed item in task state")
+	}
+	stor, err := storage.GetStorageByName(ctx, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+	var progress ProgressTracker
+	if state.ProgressMsgID != 0 {
+		progress = NewProgress(state.ProgressMsgID, state.ProgressChatID)
+	}
+	return NewTask(id, ctx, cmp.Or(state.UserID, state.ProgressChatID), stor, state.StorPath, state.Item, progress), nil
+}
@@ -19,6 +19,7 @@ var _ core.Executable = (*Task)(nil)
 
 type Task struct {
 	ID         string
+	UserID     int64 // telegram chat id of the user who added the task
 	Ctx        context.Context
 	Stor       storage.Storage
 	StorPath   string
@@ -52,6 +53,7 @@ func (t *Task) TaskID() string {
 func NewTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	stor storage.Storage,
 	storPath string,
 	item *parser.Item,
@@ -62,6 +64,7 @@ func NewTask(
 	stream := config.C().Stream && !ok
 	return &Task{
 		ID:             id,
+		UserID:         userID,
 		Ctx:            ctx,
 		Stor:           stor,
 		StorPath:       storPath,
@@ -87,3 +90,14 @@ func NewTask(
 		failed:          make(map[string]error),
 	}
 }
+
+// Report implements core.Reportable.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID:      t.UserID,
+		StorageName: t.Stor.Name(),
+		StoragePath: t.StorPath,
+		Bytes:       t.totalBytes,
+	}
+	return report
+}
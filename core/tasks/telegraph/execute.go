@@ -9,8 +9,10 @@ import (
 
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/retry"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -53,6 +55,7 @@ func (t *Task) processPic(ctx context.Context, picUrl string, index int) error {
 			return fmt.Errorf("failed to download picture %s: %w", picUrl, err)
 		}
 		defer body.Close()
+		r := bandwidth.DownloadReader(ctx, body)
 		filename := fmt.Sprintf("%d%s", index+1, path.Ext(picUrl))
 		if t.cannotStream {
 			cacheFile, err := fsutil.CreateFile(filepath.Join(config.C().Temp.BasePath,
@@ -67,7 +70,7 @@ func (t *Task) processPic(ctx context.Context, picUrl string, index int) error {
 					logger.Errorf("Failed to close and remove cache file for picture %s: %v", filename, err)
 				}
 			}()
-			_, err = io.Copy(cacheFile, body)
+			_, err = io.Copy(cacheFile, r)
 			if err != nil {
 				return fmt.Errorf("failed to copy picture %s to cache file: %w", filename, err)
 			}
@@ -75,12 +78,12 @@ func (t *Task) processPic(ctx context.Context, picUrl string, index int) error {
 			if err != nil {
 				return fmt.Errorf("failed to seek cache file for picture %s: %w", filename, err)
 			}
-			err = t.Stor.Save(ctx, cacheFile, path.Join(t.StorPath, filename))
+			_, err = storage.Save(ctx, t.Stor, cacheFile, path.Join(t.StorPath, filename))
 			if err != nil {
 				return fmt.Errorf("failed to save picture %s: %w", filename, err)
 			}
 		} else {
-			err = t.Stor.Save(ctx, body, path.Join(t.StorPath, filename))
+			_, err = storage.Save(ctx, t.Stor, r, path.Join(t.StorPath, filename))
 		}
 
 		if err != nil {
@@ -0,0 +1,60 @@
+package telegraph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tphutil"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeTphpics, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+type taskState struct {
+	PhPath         string   `json:"ph_path"`
+	Pics           []string `json:"pics"`
+	Storage        string   `json:"storage"`
+	StorPath       string   `json:"stor_path"`
+	UserID         int64    `json:"user_id"`
+	ProgressChatID int64    `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int      `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		PhPath:   t.PhPath,
+		Pics:     t.Pics,
+		Storage:  t.Stor.Name(),
+		StorPath: t.StorPath,
+		UserID:   t.UserID,
+	}
+	if p, ok := t.progress.(*Progress); ok {
+		state.ProgressChatID = p.ChatID
+		state.ProgressMsgID = p.MessageID
+	}
+	return json.Marshal(state)
+}
+
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	if len(state.Pics) == 0 {
+		return nil, fmt.Errorf("no pictures in task state")
+	}
+	stor, err := storage.GetStorageByName(ctx, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+	return NewTask(id, ctx, state.UserID, state.PhPath, state.Pics, stor, state.StorPath, tphutil.DefaultClient(),
+		NewProgress(state.ProgressMsgID, state.ProgressChatID)), nil
+}
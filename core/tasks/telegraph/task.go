@@ -15,6 +15,7 @@ var _ core.Executable = (*Task)(nil)
 
 type Task struct {
 	ID       string
+	UserID   int64 // telegram chat id of the user who added the task
 	Ctx      context.Context
 	PhPath   string
 	Pics     []string
@@ -40,6 +41,7 @@ func (t *Task) Type() tasktype.TaskType {
 func NewTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	phPath string,
 	pics []string,
 	stor storage.Storage,
@@ -50,6 +52,7 @@ func NewTask(
 	_, cannotStream := stor.(storage.StorageCannotStream)
 	telegraph := &Task{
 		ID:           id,
+		UserID:       userID,
 		Ctx:          ctx,
 		PhPath:       phPath,
 		Pics:         pics,
@@ -63,3 +66,13 @@ func NewTask(
 	}
 	return telegraph
 }
+
+// Report implements core.Reportable.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID:      t.UserID,
+		StorageName: t.Stor.Name(),
+		StoragePath: t.StorPath,
+	}
+	return report
+}
@@ -0,0 +1,93 @@
+package tfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+)
+
+// cachePath returns the path of the cache file of the task.
+// The cache of a telegram file is named after the file rather than the task, so the partial cache left by a failed
+// or cancelled task is resumed by the next task saving the same file. The cache is cleaned on startup, see cmd.cleanCache.
+func cachePath(id string, file tfile.TGFile) (string, error) {
+	prefix := id
+	if key := tfile.Key(file); key != "" {
+		prefix = strings.ReplaceAll(key, ":", "-")
+	}
+	return filepath.Abs(filepath.Join(config.C().Temp.BasePath, fmt.Sprintf("%s_%s", prefix, file.Name())))
+}
+
+// partialCacheTTL is how long the partial cache of a file is kept for the next task saving it
+const partialCacheTTL = 3 * 24 * time.Hour
+
+// IsResumableCache reports whether the file named name in the cache dir belongs to a partial download
+// which is recent enough to be resumed, the cache cleaning keeps these.
+func IsResumableCache(dir, name string) bool {
+	stat, err := os.Stat(filepath.Join(dir, strings.TrimSuffix(name, partsSuffix)+partsSuffix))
+	return err == nil && time.Since(stat.ModTime()) < partialCacheTTL
+}
+
+type cacheLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+var (
+	cacheLocksMu sync.Mutex
+	cacheLocks   = make(map[string]*cacheLock)
+)
+
+// lockCache waits until no other task uses the cache file at path, as the tasks saving the same file share it.
+// The returned func releases the cache.
+func lockCache(ctx context.Context, path string) (func(), error) {
+	cacheLocksMu.Lock()
+	lock, ok := cacheLocks[path]
+	if !ok {
+		lock = &cacheLock{ch: make(chan struct{}, 1)}
+		cacheLocks[path] = lock
+	}
+	lock.refs++
+	cacheLocksMu.Unlock()
+
+	release := func() {
+		cacheLocksMu.Lock()
+		defer cacheLocksMu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(cacheLocks, path)
+		}
+	}
+	select {
+	case lock.ch <- struct{}{}:
+		return func() {
+			<-lock.ch
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// removeCache removes the cache file and its parts sidecar
+func (t *Task) removeCache() error {
+	return errors.Join(
+		removeIfExists(t.localPath),
+		removeIfExists(t.localPath+partsSuffix),
+	)
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package tfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockCache(t *testing.T) {
+	unlock, err := lockCache(t.Context(), "cache")
+	if err != nil {
+		t.Fatalf("lockCache: %v", err)
+	}
+	// another task saving the same file waits for the first one
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := lockCache(ctx, "cache"); err == nil {
+		t.Fatal("the cache should be locked")
+	}
+	unlock()
+	unlock, err = lockCache(t.Context(), "cache")
+	if err != nil {
+		t.Fatalf("lockCache after unlock: %v", err)
+	}
+	unlock()
+	if len(cacheLocks) != 0 {
+		t.Fatalf("the released locks should be removed, got %d", len(cacheLocks))
+	}
+}
+
+func TestIsResumableCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"partial", "partial" + partsSuffix, "old", "old" + partsSuffix, "complete"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-partialCacheTTL - time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old"+partsSuffix), old, old); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{
+		"partial":               true,
+		"partial" + partsSuffix: true,
+		"old":                   false,
+		"old" + partsSuffix:     false,
+		"complete":              false,
+	} {
+		if got := IsResumableCache(dir, name); got != want {
+			t.Errorf("IsResumableCache(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
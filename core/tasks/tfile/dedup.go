@@ -0,0 +1,41 @@
+package tfile
+
+import (
+	"context"
+
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/dedup"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+// dedupState holds the dedup policy of the task owner and the identity of the file
+type dedupState struct {
+	userID int64
+	policy dedup.Policy
+	id     core.FileIdentity
+}
+
+func (t *Task) newDedupState(ctx context.Context) *dedupState {
+	return &dedupState{
+		userID: t.UserID,
+		policy: core.GetDedupPolicy(ctx, t.UserID),
+		id: core.FileIdentity{
+			Key:  tfile.Key(t.File),
+			Size: t.File.Size(),
+		},
+	}
+}
+
+// handle applies the dedup policy before the file is saved, see core.HandleDuplicate.
+// With the overwrite policy, the task saves the file to the storage and path of the saved file.
+func (d *dedupState) handle(ctx context.Context, t *Task) (context.Context, *core.DuplicateError) {
+	ctx, stor, storPath, dupErr := core.HandleDuplicate(ctx, d.userID, d.policy, d.id, t.Storage, t.Path)
+	t.Storage, t.Path = stor, storPath
+	return ctx, dupErr
+}
+
+// record records the file saved to savedPath of stor, which may differ from the task path after resolving a name conflict
+func (d *dedupState) record(ctx context.Context, stor storage.Storage, savedPath string) {
+	core.RecordSavedFile(ctx, d.userID, d.id, d.policy, stor.Name(), savedPath)
+}
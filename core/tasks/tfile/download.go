@@ -0,0 +1,186 @@
+package tfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/duke-git/lancet/v2/retry"
+	"github.com/dustin/go-humanize"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/common/tdler"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/pkg/consts/tglimit"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"golang.org/x/sync/errgroup"
+)
+
+// downloadToCache downloads the file to the cache file.
+// The downloaded parts are recorded in a sidecar file, so a retry, a resumed task or a task restored after restart
+// only downloads the missing parts.
+func (t *Task) downloadToCache(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+	if t.File.Size() <= 0 {
+		// the parts can not be tracked without the file size
+		localFile, err := fsutil.CreateFile(t.localPath)
+		if err != nil {
+			return fmt.Errorf("failed to create local file: %w", err)
+		}
+		defer localFile.Close()
+		if _, err := tdler.NewDownloader(t.File).Parallel(ctx, newWriterAt(ctx, bandwidth.DownloadWriterAt(ctx, localFile), t.Progress, t, nil)); err != nil {
+			return fmt.Errorf("failed to download file: %w", err)
+		}
+		return nil
+	}
+
+	localFile, err := fsutil.OpenFile(t.localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer localFile.Close()
+	parts, err := openPartBitmap(t.localPath+partsSuffix, t.File.Size(), tglimit.MaxPartSize)
+	if err != nil {
+		return err
+	}
+	defer parts.Close()
+	if stat, err := localFile.Stat(); err != nil {
+		return fmt.Errorf("failed to get local file stat: %w", err)
+	} else if stat.Size() == 0 {
+		// the cache file is new, the parts recorded before are gone
+		if err := parts.Reset(); err != nil {
+			return err
+		}
+	}
+	if done := parts.DoneBytes(); done > 0 {
+		logger.Infof("Resuming download, %s of %s already downloaded", humanize.IBytes(uint64(done)), humanize.IBytes(uint64(t.File.Size())))
+	}
+
+	wrAt := newWriterAt(ctx, bandwidth.DownloadWriterAt(ctx, localFile), t.Progress, t, parts)
+	err = retry.Retry(func() error {
+		err := t.downloadParts(ctx, wrAt, parts.Missing())
+		if err != nil && ctx.Err() == nil {
+			logger.Warnf("Failed to download file, retrying with the missing parts: %v", err)
+		}
+		return err
+	}, retry.RetryTimes(uint(config.C().Retry)), retry.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", err)
+	}
+	return nil
+}
+
+// downloadParts downloads the given parts of the file in parallel
+func (t *Task) downloadParts(ctx context.Context, wrAt io.WriterAt, parts []int) error {
+	errg, ctx := errgroup.WithContext(ctx)
+	partCh := make(chan int)
+	errg.Go(func() error {
+		defer close(partCh)
+		for _, part := range parts {
+			select {
+			case partCh <- part:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+	for range dlutil.BestThreads(t.File.Size(), config.C().Threads) {
+		errg.Go(func() error {
+			for part := range partCh {
+				if err := t.downloadPart(ctx, wrAt, part); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return errg.Wait()
+}
+
+// maxPartTimeouts is the number of times a part is requested again after a timeout, waiting a bit longer each time.
+// The part then fails, and the download is retried with the missing parts.
+const maxPartTimeouts = 5
+
+func (t *Task) downloadPart(ctx context.Context, wrAt io.WriterAt, part int) error {
+	offset := int64(part) * tglimit.MaxPartSize
+	want := min(tglimit.MaxPartSize, t.File.Size()-offset)
+	refreshed := false
+	timeouts := 0
+	for {
+		file := t.currentFile()
+		req := &tg.UploadGetFileRequest{
+			Location: file.Location(),
+			Offset:   offset,
+			Limit:    tglimit.MaxPartSize,
+		}
+		req.SetPrecise(true)
+		res, err := file.Dler().UploadGetFile(ctx, req)
+		if flood, err := tgerr.FloodWait(ctx, err); err != nil {
+			if flood {
+				continue
+			}
+			if tgerr.Is(err, tg.ErrTimeout) && timeouts < maxPartTimeouts {
+				timeouts++
+				select {
+				case <-time.After(time.Duration(timeouts) * time.Second):
+					continue
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			if !refreshed && tgerr.Is(err, tg.ErrFileReferenceExpired, tg.ErrFileReferenceInvalid) {
+				if err := t.refreshFile(ctx, file); err != nil {
+					return fmt.Errorf("failed to refresh file reference: %w", err)
+				}
+				refreshed = true
+				continue
+			}
+			return fmt.Errorf("failed to get part %d: %w", part, err)
+		}
+		result, ok := res.(*tg.UploadFile)
+		if !ok {
+			return fmt.Errorf("unexpected response type %T for part %d", res, part)
+		}
+		if int64(len(result.Bytes)) != want {
+			return fmt.Errorf("unexpected size of part %d: got %d, want %d", part, len(result.Bytes), want)
+		}
+		_, err = wrAt.WriteAt(result.Bytes, offset)
+		return err
+	}
+}
+
+func (t *Task) currentFile() tfile.TGFile {
+	t.fileMu.Lock()
+	defer t.fileMu.Unlock()
+	return t.File
+}
+
+// refreshFile gets the file again from its message to renew the expired file reference.
+// expired is the file used by the failed request, it is not refreshed again if another part already did.
+func (t *Task) refreshFile(ctx context.Context, expired tfile.TGFile) error {
+	t.fileMu.Lock()
+	defer t.fileMu.Unlock()
+	if t.File != expired {
+		return nil
+	}
+	fm, ok := t.File.(tfile.TGFileMessage)
+	if !ok || fm.Message() == nil {
+		return errors.New("file has no source message")
+	}
+	msg := fm.Message()
+	file, err := FetchFile(ctx, tgutil.ChatIdFromPeer(msg.PeerID), msg.GetID(), tfile.WithName(t.File.Name()), tfile.WithSize(t.File.Size()))
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Debugf("Refreshed file reference of %s", t.File.Name())
+	t.File = file
+	return nil
+}
@@ -2,16 +2,18 @@ package tfile
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/retry"
-	"github.com/kiss2u/SaveAny-Bot/common/tdler"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/fsutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
 func (t *Task) Execute(ctx context.Context) error {
@@ -19,33 +21,60 @@ func (t *Task) Execute(ctx context.Context) error {
 	if t.Progress != nil {
 		t.Progress.OnStart(ctx, t)
 	}
+	dd := t.newDedupState(ctx)
+	ctx, dupErr := dd.handle(ctx, t)
+	if dupErr != nil {
+		if t.Progress != nil {
+			t.Progress.OnDone(ctx, t, dupErr)
+		}
+		return nil
+	}
+	// check before downloading, the path is checked again when saving
+	if _, skip, err := storage.ResolveConflict(ctx, t.Storage, t.Path, t.File.Size()); err != nil || skip {
+		if skip {
+			logger.Infof("Skipping existing file %s", t.Path)
+			err = &storage.SkippedError{StorageName: t.Storage.Name(), StoragePath: t.Path}
+		}
+		if t.Progress != nil {
+			t.Progress.OnDone(ctx, t, err)
+		}
+		if skip {
+			return nil
+		}
+		return err
+	}
 	if t.stream {
-		return executeStream(ctx, t)
+		return executeStream(ctx, t, dd)
 	}
 
 	logger.Info("Starting file download")
-	localFile, err := fsutil.CreateFile(t.localPath)
-	if err != nil {
-		return fmt.Errorf("failed to create local file: %w", err)
-	}
-	defer func() {
-		if err := localFile.CloseAndRemove(); err != nil {
-			logger.Errorf("Failed to close local file: %v", err)
-		}
-	}()
-	wrAt := newWriterAt(ctx, localFile, t.Progress, t)
-
+	var err error
 	defer func() {
 		if t.Progress != nil {
 			t.Progress.OnDone(ctx, t, err)
 		}
 	}()
-	_, err = tdler.NewDownloader(t.File).Parallel(ctx, wrAt)
+	unlock, err := lockCache(ctx, t.localPath)
 	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+		return err
+	}
+	defer unlock()
+	// the cache is kept if the task fails, is cancelled or paused, so the next task saving the file
+	// only downloads the missing parts. It is removed once the file is saved.
+	done := false
+	defer func() {
+		if !done {
+			return
+		}
+		if err := t.removeCache(); err != nil {
+			logger.Errorf("Failed to remove cache file: %v", err)
+		}
+	}()
+	if err = t.downloadToCache(ctx); err != nil {
+		return err
 	}
 	logger.Infof("File downloaded successfully")
-	if path.Ext(t.File.Name()) == "" {
+	if path.Ext(t.Path) == "" {
 		ext := fsutil.DetectFileExt(t.localPath)
 		if ext != "" {
 			t.Path = t.Path + ext
@@ -56,20 +85,57 @@ func (t *Task) Execute(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to get file stat: %w", err)
 	}
+	if dd.id.SHA256, err = core.HashFile(t.localPath); err != nil {
+		logger.Errorf("Failed to hash file: %v", err)
+	}
+	if ctx, dupErr = dd.handle(ctx, t); dupErr != nil {
+		// reported by the deferred OnDone, the task itself is done
+		err = dupErr
+		done = true
+		return nil
+	}
 	vctx := context.WithValue(ctx, ctxkey.ContentLength, fileStat.Size())
-	err = retry.Retry(func() error {
+	var (
+		errs    []error
+		skipped bool
+	)
+	for i, stor := range t.storages() {
+		savedPath, saveErr := t.saveCache(vctx, stor)
+		if saveErr != nil {
+			errs = append(errs, fmt.Errorf("failed to save file to %s after retries: %w", stor.Name(), saveErr))
+			continue
+		}
+		if savedPath == "" {
+			skipped = skipped || i == 0
+			continue
+		}
+		dd.record(ctx, stor, savedPath)
+	}
+	if len(errs) > 0 {
+		// the downloaded cache is kept for the retry
+		err = errors.Join(errs...)
+		return err
+	}
+	done = true
+	if skipped {
+		err = &storage.SkippedError{StorageName: t.Storage.Name(), StoragePath: t.Path}
+	}
+	return nil
+}
+
+// saveCache saves the downloaded cache file to stor, it returns the saved path which is empty if the file is skipped
+func (t *Task) saveCache(ctx context.Context, stor storage.Storage) (string, error) {
+	var savedPath string
+	err := retry.Retry(func() error {
 		file, err := os.Open(t.localPath)
 		if err != nil {
 			return fmt.Errorf("failed to open cache file: %w", err)
 		}
 		defer file.Close()
-		if err = t.Storage.Save(vctx, file, t.Path); err != nil {
+		if savedPath, err = storage.Save(ctx, stor, file, t.Path); err != nil {
 			return fmt.Errorf("failed to save file: %w", err)
 		}
 		return nil
-	}, retry.RetryTimes(uint(config.C().Retry)), retry.Context(vctx))
-	if err != nil {
-		return fmt.Errorf("failed to save file after retries: %w", err)
-	}
-	return nil
+	}, retry.RetryTimes(uint(config.C().Retry)), retry.Context(ctx))
+	return savedPath, err
 }
@@ -0,0 +1,132 @@
+package tfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// partsSuffix is the suffix of the sidecar file which records the downloaded parts of a cache file
+const partsSuffix = ".parts"
+
+// size of the sidecar header, which holds the file size and the part size
+const partsHeaderSize = 16
+
+// partBitmap records the downloaded parts of a file in a sidecar file,
+// so an interrupted download can continue with the missing parts only.
+type partBitmap struct {
+	mu       sync.Mutex
+	file     *os.File
+	bits     []byte
+	size     int64
+	partSize int64
+	parts    int
+}
+
+// openPartBitmap opens the sidecar file at path, the recorded parts are discarded if it was written for another file size or part size.
+func openPartBitmap(path string, size int64, partSize int) (*partBitmap, error) {
+	if size <= 0 || partSize <= 0 {
+		return nil, errors.New("file size and part size must be positive")
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open parts file: %w", err)
+	}
+	parts := int((size + int64(partSize) - 1) / int64(partSize))
+	b := &partBitmap{
+		file:     file,
+		bits:     make([]byte, (parts+7)/8),
+		size:     size,
+		partSize: int64(partSize),
+		parts:    parts,
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read parts file: %w", err)
+	}
+	if len(data) == partsHeaderSize+len(b.bits) &&
+		int64(binary.BigEndian.Uint64(data[:8])) == size &&
+		int64(binary.BigEndian.Uint64(data[8:16])) == b.partSize {
+		copy(b.bits, data[partsHeaderSize:])
+		return b, nil
+	}
+	if err := b.Reset(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// Reset forgets all the recorded parts
+func (b *partBitmap) Reset() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.bits)
+	data := make([]byte, partsHeaderSize+len(b.bits))
+	binary.BigEndian.PutUint64(data[:8], uint64(b.size))
+	binary.BigEndian.PutUint64(data[8:16], uint64(b.partSize))
+	if err := b.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate parts file: %w", err)
+	}
+	if _, err := b.file.WriteAt(data, 0); err != nil {
+		return fmt.Errorf("failed to write parts file: %w", err)
+	}
+	return nil
+}
+
+func (b *partBitmap) has(part int) bool {
+	return b.bits[part/8]&(1<<(part%8)) != 0
+}
+
+// MarkWritten records the part written at off if the write covers the whole part
+func (b *partBitmap) MarkWritten(off int64, n int) error {
+	if off%b.partSize != 0 {
+		return nil
+	}
+	part := int(off / b.partSize)
+	if part >= b.parts || int64(n) < min(b.partSize, b.size-off) {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.has(part) {
+		return nil
+	}
+	b.bits[part/8] |= 1 << (part % 8)
+	_, err := b.file.WriteAt(b.bits[part/8:part/8+1], int64(partsHeaderSize+part/8))
+	return err
+}
+
+// Missing returns the indexes of the parts which are not downloaded yet
+func (b *partBitmap) Missing() []int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	missing := make([]int, 0, b.parts)
+	for part := range b.parts {
+		if !b.has(part) {
+			missing = append(missing, part)
+		}
+	}
+	return missing
+}
+
+// DoneBytes returns the size of the downloaded parts
+func (b *partBitmap) DoneBytes() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var done int64
+	for part := range b.parts {
+		if b.has(part) {
+			done += min(b.partSize, b.size-int64(part)*b.partSize)
+		}
+	}
+	return done
+}
+
+func (b *partBitmap) Close() error {
+	return b.file.Close()
+}
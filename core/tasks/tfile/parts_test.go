@@ -0,0 +1,52 @@
+package tfile
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPartBitmap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file"+partsSuffix)
+	// 3 parts, the last one is 2 bytes
+	parts, err := openPartBitmap(path, 10, 4)
+	if err != nil {
+		t.Fatalf("openPartBitmap: %v", err)
+	}
+	if err := parts.MarkWritten(4, 4); err != nil {
+		t.Fatalf("MarkWritten: %v", err)
+	}
+	// not aligned or not a whole part, ignored
+	parts.MarkWritten(1, 4)
+	parts.MarkWritten(0, 2)
+	if err := parts.MarkWritten(8, 2); err != nil {
+		t.Fatalf("MarkWritten: %v", err)
+	}
+	if got := parts.Missing(); !slices.Equal(got, []int{0}) {
+		t.Fatalf("expected missing parts [0], got %v", got)
+	}
+	if got := parts.DoneBytes(); got != 6 {
+		t.Fatalf("expected 6 bytes done, got %d", got)
+	}
+	parts.Close()
+
+	// reopened with the same size, the parts are kept
+	parts, err = openPartBitmap(path, 10, 4)
+	if err != nil {
+		t.Fatalf("openPartBitmap: %v", err)
+	}
+	if got := parts.Missing(); !slices.Equal(got, []int{0}) {
+		t.Fatalf("expected missing parts [0] after reopen, got %v", got)
+	}
+	parts.Close()
+
+	// reopened for another file size, the parts are discarded
+	parts, err = openPartBitmap(path, 12, 4)
+	if err != nil {
+		t.Fatalf("openPartBitmap: %v", err)
+	}
+	defer parts.Close()
+	if got := parts.Missing(); !slices.Equal(got, []int{0, 1, 2}) {
+		t.Fatalf("expected all parts missing, got %v", got)
+	}
+}
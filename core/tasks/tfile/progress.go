@@ -15,6 +15,9 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
 type ProgressTracker interface {
@@ -129,10 +132,39 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 	entityBuilder := entity.Builder{}
 	var stylingErr error
 
-	if err != nil {
+	var dupErr *core.DuplicateError
+	var skipErr *storage.SkippedError
+	if errors.As(err, &dupErr) && dupErr.LinkPath != "" {
+		stylingErr = styling.Perform(&entityBuilder,
+			styling.Plain(i18n.T(i18nk.BotMsgProgressFileLinkedPrefix, nil)),
+			styling.Code(info.FileName()),
+			styling.Plain(i18n.T(i18nk.BotMsgProgressSavePathPrefix, nil)),
+			styling.Code(fmt.Sprintf("[%s]:%s", dupErr.StorageName, dupErr.LinkPath)),
+			styling.Plain(i18n.T(i18nk.BotMsgProgressLinkedToPrefix, nil)),
+			styling.Code(dupErr.StoragePath),
+		)
+	} else if errors.As(err, &dupErr) {
+		stylingErr = styling.Perform(&entityBuilder,
+			styling.Plain(i18n.T(i18nk.BotMsgProgressFileAlreadySavedPrefix, nil)),
+			styling.Code(info.FileName()),
+			styling.Plain(i18n.T(i18nk.BotMsgProgressSavePathPrefix, nil)),
+			styling.Code(fmt.Sprintf("[%s]:%s", dupErr.StorageName, dupErr.StoragePath)),
+		)
+	} else if errors.As(err, &skipErr) {
+		stylingErr = styling.Perform(&entityBuilder,
+			styling.Plain(i18n.T(i18nk.BotMsgProgressFileExistsSkippedPrefix, nil)),
+			styling.Code(info.FileName()),
+			styling.Plain(i18n.T(i18nk.BotMsgProgressSavePathPrefix, nil)),
+			styling.Code(fmt.Sprintf("[%s]:%s", skipErr.StorageName, skipErr.StoragePath)),
+		)
+	} else if err != nil {
 		if errors.Is(err, context.Canceled) {
+			msgKey := i18nk.BotMsgProgressTaskCanceled
+			if queue.IsPaused(ctx) {
+				msgKey = i18nk.BotMsgProgressTaskPaused
+			}
 			stylingErr = styling.Perform(&entityBuilder,
-				styling.Plain(i18n.T(i18nk.BotMsgProgressTaskCanceled, nil)),
+				styling.Plain(i18n.T(msgKey, nil)),
 				styling.Plain("\n"),
 				styling.Plain(i18n.T(i18nk.BotMsgProgressFileNamePrefix, nil)),
 				styling.Code(info.FileName()),
@@ -154,6 +186,9 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 		)
 	}
 
+	if stylingErr == nil {
+		stylingErr = styling.Perform(&entityBuilder, tgutil.MirrorReportStyling(ctx)...)
+	}
 	if stylingErr != nil {
 		log.FromContext(ctx).Errorf("Failed to build entities: %s", stylingErr)
 		return
@@ -0,0 +1,121 @@
+package tfile
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/celestix/gotgproto/ext"
+	uc "github.com/kiss2u/SaveAny-Bot/client/user"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeTgfiles, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+// taskState is the persisted form of a Task.
+// The file location is not stored because its file reference expires, the file is fetched again from the message instead.
+type taskState struct {
+	ChatID         int64    `json:"chat_id"`
+	MessageID      int      `json:"message_id"`
+	FileName       string   `json:"file_name"`
+	FileSize       int64    `json:"file_size"`
+	Storage        string   `json:"storage"`
+	Also           []string `json:"also,omitempty"`
+	Path           string   `json:"path"`
+	UserID         int64    `json:"user_id,omitempty"` // older states have only the progress chat, which is the user
+	ProgressChatID int64    `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int      `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	fm, ok := t.File.(tfile.TGFileMessage)
+	if !ok || fm.Message() == nil {
+		return nil, errors.New("file has no source message")
+	}
+	msg := fm.Message()
+	state := taskState{
+		UserID:    t.UserID,
+		ChatID:    tgutil.ChatIdFromPeer(msg.PeerID),
+		MessageID: msg.GetID(),
+		FileName:  t.File.Name(),
+		FileSize:  t.File.Size(),
+		Storage:   t.Storage.Name(),
+		Path:      t.Path,
+	}
+	for _, also := range t.Also {
+		state.Also = append(state.Also, also.Name())
+	}
+	if p, ok := t.Progress.(*Progress); ok {
+		state.ProgressChatID = p.ChatID
+		state.ProgressMsgID = p.MessageID
+	}
+	return json.Marshal(state)
+}
+
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	stor, err := storage.GetStorageByName(ctx, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+	also := make([]storage.Storage, 0, len(state.Also))
+	for _, name := range state.Also {
+		alsoStor, err := storage.GetStorageByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		also = append(also, alsoStor)
+	}
+	file, err := FetchFile(ctx, state.ChatID, state.MessageID, tfile.WithName(state.FileName), tfile.WithSizeIfZero(state.FileSize))
+	if err != nil {
+		return nil, err
+	}
+	var progress ProgressTracker
+	if state.ProgressMsgID != 0 {
+		progress = NewProgressTrack(state.ProgressMsgID, state.ProgressChatID)
+	}
+	return NewTGFileTask(id, ctx, cmp.Or(state.UserID, state.ProgressChatID), file, stor, state.Path, progress, also...)
+}
+
+// FetchFile gets the file of a message again, which also refreshes its file reference.
+// It uses the bot client from ctx first, then the userbot if enabled.
+func FetchFile(ctx context.Context, chatID int64, msgID int, opts ...tfile.TGFileOption) (tfile.TGFileMessage, error) {
+	clients := make([]*ext.Context, 0, 2)
+	if extCtx := tgutil.ExtFromContext(ctx); extCtx != nil {
+		clients = append(clients, extCtx)
+	}
+	if config.C().Telegram.Userbot.Enable && uc.GetCtx() != nil {
+		clients = append(clients, uc.GetCtx())
+	}
+	if len(clients) == 0 {
+		return nil, errors.New("no telegram client available")
+	}
+	var errs []error
+	for _, client := range clients {
+		msg, err := tgutil.GetMessageByID(client, chatID, msgID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if msg.Media == nil {
+			return nil, fmt.Errorf("message %d in chat %d has no media", msgID, chatID)
+		}
+		return tfile.FromMediaMessage(msg.Media, client.Raw, msg, opts...)
+	}
+	return nil, errors.Join(errs...)
+}
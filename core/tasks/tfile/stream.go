@@ -6,26 +6,44 @@ import (
 	"io"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/tdler"
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 	"golang.org/x/sync/errgroup"
 )
 
-func executeStream(ctx context.Context, task *Task) error {
+func executeStream(ctx context.Context, task *Task, dd *dedupState) error {
 	logger := log.FromContext(ctx).WithPrefix(fmt.Sprintf("file[%s]", task.File.Name()))
 
-	pr, pw := io.Pipe()
-	defer pr.Close()
+	// the download is written to a pipe for each storage, a storage failing fails the whole task
+	stors := task.storages()
+	savedPaths := make([]string, len(stors))
+	pws := make([]*io.PipeWriter, len(stors))
+	writers := make([]io.Writer, len(stors))
 	errg, uploadCtx := errgroup.WithContext(ctx)
+	for i, stor := range stors {
+		pr, pw := io.Pipe()
+		pws[i], writers[i] = pw, pw
+		errg.Go(func() error {
+			// a storage returning before reading all fails the download instead of blocking it
+			defer pr.Close()
+			var err error
+			if savedPaths[i], err = storage.Save(uploadCtx, stor, pr, task.Path); err != nil {
+				return fmt.Errorf("failed to save file to %s: %w", stor.Name(), err)
+			}
+			return nil
+		})
+	}
+	hash := core.NewContentHash()
+	wr := newWriter(ctx, io.MultiWriter(bandwidth.DownloadWriter(ctx, io.MultiWriter(writers...)), hash), task.Progress, task)
 	errg.Go(func() error {
-		return task.Storage.Save(uploadCtx, pr, task.Path)
-	})
-	wr := newWriter(ctx, pw, task.Progress, task)
-	errg.Go(func() error {
-		defer pw.Close()
 		logger.Info("Starting file download in stream mode")
 		_, err := tdler.NewDownloader(task.File).Stream(uploadCtx, wr)
 		if err != nil {
 			logger.Errorf("Failed to download file: %v", err)
+		}
+		for _, pw := range pws {
 			pw.CloseWithError(err)
 		}
 		return err
@@ -40,5 +58,14 @@ func executeStream(ctx context.Context, task *Task) error {
 		return err
 	}
 	logger.Info("File downloaded successfully in stream mode")
+	dd.id.SHA256 = core.HashSum(hash)
+	for i, stor := range stors {
+		if savedPaths[i] != "" {
+			dd.record(ctx, stor, savedPaths[i])
+		}
+	}
+	if savedPaths[0] == "" {
+		err = &storage.SkippedError{StorageName: task.Storage.Name(), StoragePath: task.Path}
+	}
 	return nil
 }
@@ -3,7 +3,8 @@ package tfile
 import (
 	"context"
 	"fmt"
-	"path/filepath"
+	"slices"
+	"sync"
 
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/core"
@@ -16,13 +17,16 @@ var _ core.Executable = (*Task)(nil)
 
 type Task struct {
 	ID        string
+	UserID    int64 // telegram chat id of the user who added the task
 	Ctx       context.Context
 	File      tfile.TGFile
 	Storage   storage.Storage
+	Also      []storage.Storage // the storages the file is saved to as well at the same path, from the same download
 	Path      string
 	Progress  ProgressTracker
 	stream    bool // true if the file should be downloaded in stream mode
 	localPath string
+	fileMu    sync.Mutex // guards File when its file reference is refreshed during download
 }
 
 // Title implements core.Exectable.
@@ -37,36 +41,69 @@ func (t *Task) Type() tasktype.TaskType {
 func NewTGFileTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	file tfile.TGFile,
 	stor storage.Storage,
 	path string,
 	progress ProgressTracker,
+	also ...storage.Storage,
 ) (*Task, error) {
-	_, ok := stor.(storage.StorageCannotStream)
-	if !config.C().Stream || ok {
-		cachePath, err := filepath.Abs(filepath.Join(config.C().Temp.BasePath, fmt.Sprintf("%s_%s", id, file.Name())))
-		if err != nil {
-			return nil, fmt.Errorf("failed to get absolute path for cache: %w", err)
-		}
-		tfile := &Task{
-			ID:        id,
-			Ctx:       ctx,
-			File:      file,
-			Storage:   stor,
-			Path:      path,
-			Progress:  progress,
-			localPath: cachePath,
-		}
-		return tfile, nil
-	}
 	tfileTask := &Task{
 		ID:       id,
+		UserID:   userID,
 		Ctx:      ctx,
 		File:     file,
 		Storage:  stor,
+		Also:     also,
 		Path:     path,
 		Progress: progress,
-		stream:   true,
 	}
+	// the file is downloaded once for all the storages, it is cached if any of them can not save a stream
+	stream := config.C().Stream
+	for _, s := range tfileTask.storages() {
+		if _, ok := s.(storage.StorageCannotStream); ok {
+			stream = false
+		}
+	}
+	if stream {
+		tfileTask.stream = true
+		return tfileTask, nil
+	}
+	localPath, err := cachePath(id, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for cache: %w", err)
+	}
+	tfileTask.localPath = localPath
 	return tfileTask, nil
 }
+
+// storages returns Storage and the ones in Also, which may include Storage after the dedup policy changed it
+func (t *Task) storages() []storage.Storage {
+	stors := []storage.Storage{t.Storage}
+	for _, s := range t.Also {
+		if !slices.ContainsFunc(stors, func(o storage.Storage) bool { return o.Name() == s.Name() }) {
+			stors = append(stors, s)
+		}
+	}
+	return stors
+}
+
+// Report implements core.Reportable.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID:      t.UserID,
+		StorageName: t.Storage.Name(),
+		StoragePath: t.Path,
+		Bytes:       t.File.Size(),
+	}
+	return report
+}
+
+// RequiredSpace implements core.SpaceRequirer.
+func (t *Task) RequiredSpace() map[storage.Storage]int64 {
+	sizes := make(map[storage.Storage]int64)
+	for _, stor := range t.storages() {
+		sizes[stor] = t.File.Size()
+	}
+	return sizes
+}
@@ -2,6 +2,7 @@ package tfile
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"sync/atomic"
 )
@@ -13,6 +14,7 @@ type ProgressWriterAt struct {
 	downloaded *atomic.Int64
 	total      int64
 	info       TaskInfo
+	parts      *partBitmap // records the written parts, nil if the download is not resumable
 }
 
 func (w *ProgressWriterAt) WriteAt(p []byte, off int64) (int, error) {
@@ -20,6 +22,11 @@ func (w *ProgressWriterAt) WriteAt(p []byte, off int64) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	if w.parts != nil {
+		if err := w.parts.MarkWritten(off, at); err != nil {
+			return at, fmt.Errorf("failed to record downloaded part: %w", err)
+		}
+	}
 	if w.progress != nil {
 		w.progress.OnProgress(w.ctx, w.info, w.downloaded.Add(int64(at)), w.total)
 	}
@@ -31,14 +38,20 @@ func newWriterAt(
 	wrAt io.WriterAt,
 	progress ProgressTracker,
 	taskInfo TaskInfo,
+	parts *partBitmap,
 ) *ProgressWriterAt {
+	downloaded := &atomic.Int64{}
+	if parts != nil {
+		downloaded.Store(parts.DoneBytes())
+	}
 	return &ProgressWriterAt{
 		ctx:        ctx,
 		progress:   progress,
-		downloaded: &atomic.Int64{},
+		downloaded: downloaded,
 		total:      taskInfo.FileSize(),
 		wrAt:       wrAt,
 		info:       taskInfo,
+		parts:      parts,
 	}
 }
 
@@ -72,52 +72,112 @@ func (t *Task) Execute(ctx context.Context) error {
 func (t *Task) processElement(ctx context.Context, elem TaskElement) error {
 	logger := log.FromContext(ctx).WithPrefix(fmt.Sprintf("file[%s]", elem.FileInfo.Name))
 
+	// Build target storage path: /target_path/filename
+	storagePath := path.Join(elem.TargetPath, elem.FileInfo.Name)
+
+	// Move the file inside the storage if it can, without copying the content
+	if movable, ok := elem.SourceStorage.(storage.StorageMovable); ok && t.Move &&
+		elem.SourceStorage.Name() == elem.TargetStorage.Name() {
+		return t.moveElement(ctx, movable, elem, storagePath)
+	}
+
+	savedPath, size, err := t.copyElement(ctx, elem, storagePath)
+	if err != nil {
+		return err
+	}
+
+	t.uploaded.Add(size)
+	t.Progress.OnProgress(ctx, t)
+
+	logger.Info("File uploaded successfully")
+	if !t.Move {
+		return nil
+	}
+	// keep the source if the target was skipped, it is not a copy of the source
+	if savedPath == "" {
+		logger.Info("Target exists and is skipped, keeping the source file")
+		return nil
+	}
+	deletable, ok := elem.SourceStorage.(storage.StorageDeletable)
+	if !ok {
+		return fmt.Errorf("source storage %s does not support deleting", elem.SourceStorage.Name())
+	}
+	if err := deletable.Delete(ctx, elem.SourcePath); err != nil {
+		return fmt.Errorf("failed to delete source file: %w", err)
+	}
+	logger.Info("Source file deleted")
+	return nil
+}
+
+// copyElement copies the file to the target storage and returns the saved path, which is empty if skipped
+func (t *Task) copyElement(ctx context.Context, elem TaskElement, storagePath string) (string, int64, error) {
+	logger := log.FromContext(ctx).WithPrefix(fmt.Sprintf("file[%s]", elem.FileInfo.Name))
+
 	// Check whether the source storage supports reading
 	readableStorage, ok := elem.SourceStorage.(storage.StorageReadable)
 	if !ok {
-		return fmt.Errorf("source storage %s does not support reading", elem.SourceStorage.Name())
+		return "", 0, fmt.Errorf("source storage %s does not support reading", elem.SourceStorage.Name())
 	}
 
 	logger.Info("Opening file from source storage")
 	reader, size, err := readableStorage.OpenFile(ctx, elem.SourcePath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return "", 0, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer reader.Close()
 
-	// Build target storage path: /target_path/filename
-	storagePath := path.Join(elem.TargetPath, elem.FileInfo.Name)
-
 	// Inject file size into context
 	ctx = context.WithValue(ctx, ctxkey.ContentLength, size)
 
 	if config.C().Stream {
-		if err := elem.TargetStorage.Save(ctx, reader, storagePath); err != nil {
-			return fmt.Errorf("failed to upload file to storage: %w", err)
-		}
-	} else {
-		logger.Info("Downloading to temporary file for ReadSeeker support")
-		tempFile, err := t.downloadToTemp(reader, elem.FileInfo.Name)
+		savedPath, err := storage.Save(ctx, elem.TargetStorage, reader, storagePath)
 		if err != nil {
-			return fmt.Errorf("failed to download to temp: %w", err)
+			return "", 0, fmt.Errorf("failed to upload file to storage: %w", err)
 		}
-		defer os.Remove(tempFile.Name())
-		defer tempFile.Close()
+		return savedPath, size, nil
+	}
 
-		if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
-			return fmt.Errorf("failed to seek temp file: %w", err)
-		}
+	logger.Info("Downloading to temporary file for ReadSeeker support")
+	tempFile, err := t.downloadToTemp(reader, elem.FileInfo.Name)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to download to temp: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
 
-		logger.Infof("Uploading file to storage (size: %d bytes)", size)
-		if err := elem.TargetStorage.Save(ctx, tempFile, storagePath); err != nil {
-			return fmt.Errorf("failed to upload file to storage: %w", err)
-		}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return "", 0, fmt.Errorf("failed to seek temp file: %w", err)
 	}
 
-	t.uploaded.Add(size)
-	t.Progress.OnProgress(ctx, t)
+	logger.Infof("Uploading file to storage (size: %d bytes)", size)
+	savedPath, err := storage.Save(ctx, elem.TargetStorage, tempFile, storagePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to upload file to storage: %w", err)
+	}
+	return savedPath, size, nil
+}
 
-	logger.Info("File uploaded successfully")
+// moveElement moves the file within its storage, applying the conflict policy of the storage
+func (t *Task) moveElement(ctx context.Context, movable storage.StorageMovable, elem TaskElement, storagePath string) error {
+	logger := log.FromContext(ctx).WithPrefix(fmt.Sprintf("file[%s]", elem.FileInfo.Name))
+	if path.Clean(elem.SourcePath) == path.Clean(storagePath) {
+		logger.Info("Source and target are the same file, nothing to move")
+	} else {
+		target, skip, err := storage.ResolveConflict(ctx, movable, storagePath, elem.FileInfo.Size)
+		if err != nil {
+			return err
+		}
+		if skip {
+			logger.Info("Target exists and is skipped, keeping the source file")
+		} else {
+			logger.Infof("Moving file to %s", target)
+			if err := movable.Move(ctx, elem.SourcePath, target); err != nil {
+				return fmt.Errorf("failed to move file: %w", err)
+			}
+		}
+	}
+	t.uploaded.Add(elem.FileInfo.Size)
+	t.Progress.OnProgress(ctx, t)
 	return nil
 }
 
@@ -208,6 +208,11 @@ func (p *Progress) OnDone(ctx context.Context, info TaskInfo, err error) {
 		return
 	}
 
+	if err := styling.Perform(&entityBuilder, tgutil.MirrorReportStyling(ctx)...); err != nil {
+		log.FromContext(ctx).Errorf("Failed to build entities: %s", err)
+		return
+	}
+
 	text, entities := entityBuilder.Complete()
 	req := &tg.MessagesEditMessageRequest{
 		ID: p.MessageID,
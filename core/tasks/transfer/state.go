@@ -0,0 +1,80 @@
+package transfer
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeTransfer, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+type elementState struct {
+	SourceStorage string                `json:"source_storage"`
+	FileInfo      storagetypes.FileInfo `json:"file_info"`
+	TargetStorage string                `json:"target_storage"`
+	TargetPath    string                `json:"target_path"`
+}
+
+type taskState struct {
+	Elems          []elementState `json:"elems"`
+	IgnoreErrors   bool           `json:"ignore_errors"`
+	Move           bool           `json:"move,omitempty"`
+	UserID         int64          `json:"user_id,omitempty"` // older states have only the progress chat, which is the user
+	ProgressChatID int64          `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int            `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		UserID:       t.UserID,
+		Elems:        make([]elementState, 0, len(t.elems)),
+		IgnoreErrors: t.IgnoreErrors,
+		Move:         t.Move,
+	}
+	for _, elem := range t.elems {
+		state.Elems = append(state.Elems, elementState{
+			SourceStorage: elem.SourceStorage.Name(),
+			FileInfo:      elem.FileInfo,
+			TargetStorage: elem.TargetStorage.Name(),
+			TargetPath:    elem.TargetPath,
+		})
+	}
+	if p, ok := t.Progress.(*Progress); ok {
+		state.ProgressChatID = p.ChatID
+		state.ProgressMsgID = p.MessageID
+	}
+	return json.Marshal(state)
+}
+
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	elems := make([]TaskElement, 0, len(state.Elems))
+	for _, es := range state.Elems {
+		source, err := storage.GetStorageByName(ctx, es.SourceStorage)
+		if err != nil {
+			return nil, err
+		}
+		target, err := storage.GetStorageByName(ctx, es.TargetStorage)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, *NewTaskElement(source, es.FileInfo, target, es.TargetPath))
+	}
+	// the transfer task always reports its progress
+	progress := NewProgressTracker(state.ProgressMsgID, state.ProgressChatID)
+	return NewTransferTask(id, ctx, cmp.Or(state.UserID, state.ProgressChatID), elems, progress, state.IgnoreErrors, state.Move), nil
+}
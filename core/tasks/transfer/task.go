@@ -3,6 +3,7 @@ package transfer
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
 
@@ -26,10 +27,12 @@ type TaskElement struct {
 
 type Task struct {
 	ID           string
+	UserID       int64 // telegram chat id of the user who added the task
 	ctx          context.Context
 	elems        []TaskElement
 	Progress     ProgressTracker
 	IgnoreErrors bool
+	Move         bool // delete the source files after they are transferred
 	uploaded     atomic.Int64
 	totalSize    int64
 	processing   map[string]TaskElementInfo
@@ -72,12 +75,15 @@ func NewTaskElement(
 func NewTransferTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	elems []TaskElement,
 	progress ProgressTracker,
 	ignoreErrors bool,
+	move bool,
 ) *Task {
 	task := &Task{
 		ID:       id,
+		UserID:   userID,
 		ctx:      ctx,
 		elems:    elems,
 		Progress: progress,
@@ -91,7 +97,47 @@ func NewTransferTask(
 		}(),
 		processing:   make(map[string]TaskElementInfo),
 		IgnoreErrors: ignoreErrors,
+		Move:         move,
 		failed:       make(map[string]error),
 	}
 	return task
 }
+
+// Report implements core.Reportable.
+// The target of the first element is reported, as elements may be transferred to different places.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID: t.UserID,
+		Bytes:  t.totalSize,
+	}
+	if len(t.elems) > 0 {
+		report.StorageName = t.elems[0].TargetStorage.Name()
+		report.StoragePath = t.elems[0].TargetPath
+	}
+	return report
+}
+
+// SourceStorages implements core.SourceStorager.
+func (t *Task) SourceStorages() []string {
+	names := make([]string, 0, 1)
+	for _, elem := range t.elems {
+		if name := elem.SourceStorage.Name(); !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RequiredSpace implements core.SpaceRequirer.
+// The files moved within a storage are not counted, as they do not take more space.
+func (t *Task) RequiredSpace() map[storage.Storage]int64 {
+	sizes := make(map[storage.Storage]int64)
+	for _, elem := range t.elems {
+		if _, movable := elem.SourceStorage.(storage.StorageMovable); movable && t.Move &&
+			elem.SourceStorage.Name() == elem.TargetStorage.Name() {
+			continue
+		}
+		sizes[elem.TargetStorage] += elem.FileInfo.Size
+	}
+	return sizes
+}
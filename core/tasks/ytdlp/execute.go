@@ -6,13 +6,16 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/log"
 	ytdlp "github.com/lrstanley/go-ytdlp"
 
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
 // Execute implements core.Executable.
@@ -95,6 +98,11 @@ func (t *Task) downloadFiles(ctx context.Context, tempDir string) ([]string, err
 	// Note: If custom flags are provided, users have full control over format/quality
 	// The output path is always set above to ensure downloads go to the correct directory
 
+	// Apply the current download limit, a --limit-rate in the custom flags takes precedence
+	if limit := bandwidth.DownloadLimit(); limit > 0 {
+		cmd = cmd.LimitRate(strconv.FormatInt(limit, 10))
+	}
+
 	if t.Progress != nil {
 		t.Progress.OnProgress(ctx, t, "Downloading...")
 	}
@@ -171,9 +179,10 @@ func (t *Task) transferFile(ctx context.Context, filePath string) error {
 
 	logger.Infof("Transferring file %s to %s:%s", fileName, t.Storage.Name(), destPath)
 
-	if err := t.Storage.Save(ctx, f, destPath); err != nil {
+	if _, err := storage.Save(ctx, t.Storage, f, destPath); err != nil {
 		return fmt.Errorf("failed to save file %s to storage: %w", fileName, err)
 	}
+	t.transferredBytes.Add(fileInfo.Size())
 
 	logger.Infof("Successfully transferred file %s", fileName)
 
@@ -15,6 +15,7 @@ import (
 	"github.com/kiss2u/SaveAny-Bot/common/i18n"
 	"github.com/kiss2u/SaveAny-Bot/common/i18n/i18nk"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
 
 // ProgressTracker defines the interface for tracking ytdlp task progress
@@ -122,12 +123,16 @@ func (p *Progress) OnDone(ctx context.Context, task *Task, err error) {
 	logger := log.FromContext(ctx)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
+			msgKey := i18nk.BotMsgProgressTaskCanceledWithId
+			if queue.IsPaused(ctx) {
+				msgKey = i18nk.BotMsgProgressTaskPausedWithId
+			}
 			logger.Infof("yt-dlp task %s was canceled", task.TaskID())
 			ext := tgutil.ExtFromContext(ctx)
 			if ext != nil {
 				ext.EditMessage(p.chatID, &tg.MessagesEditMessageRequest{
 					ID: p.msgID,
-					Message: i18n.T(i18nk.BotMsgProgressTaskCanceledWithId, map[string]any{
+					Message: i18n.T(msgKey, map[string]any{
 						"TaskID": task.TaskID(),
 					}),
 				})
@@ -159,6 +164,10 @@ func (p *Progress) OnDone(ctx context.Context, task *Task, err error) {
 		logger.Errorf("Failed to build entities: %s", err)
 		return
 	}
+	if err := styling.Perform(&entityBuilder, tgutil.MirrorReportStyling(ctx)...); err != nil {
+		logger.Errorf("Failed to build entities: %s", err)
+		return
+	}
 	text, entities := entityBuilder.Complete()
 	req := &tg.MessagesEditMessageRequest{
 		ID: p.msgID,
@@ -0,0 +1,60 @@
+package ytdlp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
+	"github.com/kiss2u/SaveAny-Bot/storage"
+)
+
+func init() {
+	core.RegisterRestorer(tasktype.TaskTypeYtdlp, restoreTask)
+}
+
+var _ core.Serializable = (*Task)(nil)
+
+type taskState struct {
+	URLs           []string `json:"urls"`
+	Flags          []string `json:"flags,omitempty"`
+	Storage        string   `json:"storage"`
+	StorPath       string   `json:"stor_path"`
+	UserID         int64    `json:"user_id,omitempty"` // older states have only the progress chat, which is the user
+	ProgressChatID int64    `json:"progress_chat_id,omitempty"`
+	ProgressMsgID  int      `json:"progress_msg_id,omitempty"`
+}
+
+// MarshalTask implements core.Serializable.
+func (t *Task) MarshalTask() ([]byte, error) {
+	state := taskState{
+		UserID:   t.UserID,
+		URLs:     t.URLs,
+		Flags:    t.Flags,
+		Storage:  t.Storage.Name(),
+		StorPath: t.StorPath,
+	}
+	if p, ok := t.Progress.(*Progress); ok {
+		state.ProgressChatID = p.chatID
+		state.ProgressMsgID = p.msgID
+	}
+	return json.Marshal(state)
+}
+
+func restoreTask(ctx context.Context, id string, data []byte) (core.Executable, error) {
+	var state taskState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task state: %w", err)
+	}
+	stor, err := storage.GetStorageByName(ctx, state.Storage)
+	if err != nil {
+		return nil, err
+	}
+	var progress ProgressTracker
+	if state.ProgressMsgID != 0 {
+		progress = NewProgress(state.ProgressMsgID, state.ProgressChatID)
+	}
+	return NewTask(id, ctx, cmp.Or(state.UserID, state.ProgressChatID), state.URLs, state.Flags, stor, state.StorPath, progress), nil
+}
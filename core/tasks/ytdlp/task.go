@@ -3,6 +3,7 @@ package ytdlp
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
@@ -13,12 +14,15 @@ var _ core.Executable = (*Task)(nil)
 
 type Task struct {
 	ID       string
+	UserID   int64 // telegram chat id of the user who added the task
 	ctx      context.Context
 	URLs     []string
 	Flags    []string
 	Storage  storage.Storage
 	StorPath string
 	Progress ProgressTracker
+
+	transferredBytes atomic.Int64 // bytes saved to storage
 }
 
 // Title implements core.Executable.
@@ -43,6 +47,7 @@ func (t *Task) TaskID() string {
 func NewTask(
 	id string,
 	ctx context.Context,
+	userID int64,
 	urls []string,
 	flags []string,
 	stor storage.Storage,
@@ -51,6 +56,7 @@ func NewTask(
 ) *Task {
 	return &Task{
 		ID:       id,
+		UserID:   userID,
 		ctx:      ctx,
 		URLs:     urls,
 		Flags:    flags,
@@ -59,3 +65,14 @@ func NewTask(
 		Progress: progressTracker,
 	}
 }
+
+// Report implements core.Reportable.
+func (t *Task) Report() core.Report {
+	report := core.Report{
+		UserID:      t.UserID,
+		StorageName: t.Storage.Name(),
+		StoragePath: t.StorPath,
+		Bytes:       t.transferredBytes.Load(),
+	}
+	return report
+}
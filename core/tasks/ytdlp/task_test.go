@@ -26,7 +26,7 @@ func TestNewTask(t *testing.T) {
 	stor := &MockStorage{}
 	storPath := "test-path"
 
-	task := NewTask("test-id", ctx, urls, flags, stor, storPath, nil)
+	task := NewTask("test-id", ctx, 42, urls, flags, stor, storPath, nil)
 
 	if task == nil {
 		t.Fatal("NewTask returned nil")
@@ -47,6 +47,11 @@ func TestNewTask(t *testing.T) {
 	if task.Storage.Name() != "test-storage" {
 		t.Errorf("Expected storage name 'test-storage', got '%s'", task.Storage.Name())
 	}
+
+	// the user owns the task in the queue and the history even without a progress message
+	if task.Report().UserID != 42 {
+		t.Errorf("Expected user ID 42, got %d", task.Report().UserID)
+	}
 }
 
 func TestNewTaskWithoutFlags(t *testing.T) {
@@ -56,7 +61,7 @@ func TestNewTaskWithoutFlags(t *testing.T) {
 	stor := &MockStorage{}
 	storPath := "test-path"
 
-	task := NewTask("test-id-2", ctx, urls, flags, stor, storPath, nil)
+	task := NewTask("test-id-2", ctx, 0, urls, flags, stor, storPath, nil)
 
 	if task == nil {
 		t.Fatal("NewTask returned nil")
@@ -76,14 +81,14 @@ func TestTaskTitle(t *testing.T) {
 	stor := &MockStorage{}
 
 	// Test with single URL
-	task1 := NewTask("id1", ctx, []string{"https://example.com/video"}, nil, stor, "path", nil)
+	task1 := NewTask("id1", ctx, 0, []string{"https://example.com/video"}, nil, stor, "path", nil)
 	title1 := task1.Title()
 	if title1 == "" {
 		t.Error("Task title should not be empty")
 	}
 
 	// Test with multiple URLs
-	task2 := NewTask("id2", ctx, []string{"https://example.com/v1", "https://example.com/v2"}, nil, stor, "path", nil)
+	task2 := NewTask("id2", ctx, 0, []string{"https://example.com/v1", "https://example.com/v2"}, nil, stor, "path", nil)
 	title2 := task2.Title()
 	if title2 == "" {
 		t.Error("Task title should not be empty")
@@ -93,7 +98,7 @@ func TestTaskTitle(t *testing.T) {
 func TestTaskType(t *testing.T) {
 	ctx := context.Background()
 	stor := &MockStorage{}
-	task := NewTask("id", ctx, []string{"https://example.com"}, nil, stor, "path", nil)
+	task := NewTask("id", ctx, 0, []string{"https://example.com"}, nil, stor, "path", nil)
 
 	taskType := task.Type()
 	if taskType.String() != "ytdlp" {
@@ -106,7 +111,7 @@ func TestTaskID(t *testing.T) {
 	stor := &MockStorage{}
 	expectedID := "test-task-id-123"
 
-	task := NewTask(expectedID, ctx, []string{"https://example.com"}, nil, stor, "path", nil)
+	task := NewTask(expectedID, ctx, 0, []string{"https://example.com"}, nil, stor, "path", nil)
 
 	if task.TaskID() != expectedID {
 		t.Errorf("Expected task ID '%s', got '%s'", expectedID, task.TaskID())
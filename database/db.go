@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -35,7 +36,7 @@ func Init(ctx context.Context) {
 		logger.Fatal("Failed to open database: ", err)
 	}
 	logger.Debug("Database connected")
-	if err := db.AutoMigrate(&User{}, &Dir{}, &Rule{}, &WatchChat{}, &MessageLog{}); err != nil {
+	if err := db.AutoMigrate(&User{}, &Dir{}, &Rule{}, &WatchChat{}, &MessageLog{}, &TaskHistory{}, &FileIndex{}, &TelegramFile{}); err != nil {
 		logger.Fatal("Database migration failed; if upgrading from an old version, try deleting the database file and retrying", "error", err)
 	}
 	if err := syncUsers(ctx); err != nil {
@@ -87,4 +88,9 @@ func syncUsers(ctx context.Context) error {
 // GetDB returns the database instance for a given context
 func GetDB(ctx context.Context) *gorm.DB {
 	return db.WithContext(ctx)
-}
\ No newline at end of file
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, the pattern must be used with ESCAPE '\'
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// FileIndex records where a file has been saved, it is used to find the repeated saves of the same file
+type FileIndex struct {
+	gorm.Model
+	UserID      int64  `gorm:"index" json:"user_id"`  // telegram chat id, 0 if unknown
+	FileKey     string `gorm:"index" json:"file_key"` // source identity of the file, e.g. "tgdoc:<document id>", empty if unknown
+	SHA256      string `gorm:"index" json:"sha256"`   // hex encoded content hash, empty if unknown
+	Size        int64  `json:"size"`
+	StorageName string `gorm:"index" json:"storage_name"`
+	StoragePath string `json:"storage_path"`
+}
+
+func CreateFileIndex(ctx context.Context, index *FileIndex) error {
+	return GetDB(ctx).Create(index).Error
+}
+
+// whereFileIndex matches the records of the file key or the content hash
+func whereFileIndex(query *gorm.DB, fileKey, sha256 string) *gorm.DB {
+	switch {
+	case fileKey != "" && sha256 != "":
+		return query.Where("file_key = ? OR sha256 = ?", fileKey, sha256)
+	case fileKey != "":
+		return query.Where("file_key = ?", fileKey)
+	default:
+		return query.Where("sha256 = ?", sha256)
+	}
+}
+
+// GetFileIndexes returns the records matching the file key or the content hash, newest first.
+// Empty key and hash match nothing.
+func GetFileIndexes(ctx context.Context, fileKey, sha256 string) ([]FileIndex, error) {
+	if fileKey == "" && sha256 == "" {
+		return nil, nil
+	}
+	var indexes []FileIndex
+	err := whereFileIndex(GetDB(ctx), fileKey, sha256).Order("id DESC").Find(&indexes).Error
+	return indexes, err
+}
+
+func DeleteFileIndex(ctx context.Context, id uint) error {
+	return GetDB(ctx).Unscoped().Delete(&FileIndex{}, id).Error
+}
+
+// DeleteFileIndexes removes the records of the file key or the content hash, empty key and hash match nothing
+func DeleteFileIndexes(ctx context.Context, fileKey, sha256 string) error {
+	if fileKey == "" && sha256 == "" {
+		return nil
+	}
+	return whereFileIndex(GetDB(ctx).Unscoped(), fileKey, sha256).Delete(&FileIndex{}).Error
+}
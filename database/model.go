@@ -16,6 +16,7 @@ type User struct {
 	WatchChats       []WatchChat
 	FilenameStrategy string
 	FilenameTemplate string
+	DedupPolicy      string // how to handle the files already saved, see pkg/enums/dedup
 }
 
 type WatchChat struct {
@@ -39,6 +40,15 @@ type Rule struct {
 	Data        string
 	StorageName string
 	DirPath     string
+	Priority    int  // the rules with a higher priority are evaluated first
+	Stop        bool // the rules after this one are not evaluated if it matches
+	// the actions of the rule, see ruleutil.ApplyRule for how the ones of several matched rules are combined
+	Skip             bool     // the matched files are not saved
+	FilenameTemplate string   // renames the file, like User.FilenameTemplate
+	Conflict         string   // the conflict policy for saving the file, see pkg/enums/conflict
+	Storages         []string `gorm:"serializer:json"` // the storages the file is saved to as well
+	Tags             []string `gorm:"serializer:json"` // recorded in the task history
+	NotifyChatID     int64    // the chat told when the file is saved
 }
 
 // MessageLog stores incoming Telegram messages for debugging
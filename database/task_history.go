@@ -0,0 +1,65 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskHistory is the record of a finished task
+type TaskHistory struct {
+	gorm.Model
+	TaskID      string        `gorm:"index" json:"task_id"`
+	Type        string        `gorm:"index" json:"type"`
+	Title       string        `json:"title"`
+	UserID      int64         `gorm:"index" json:"user_id"` // telegram chat id, 0 if unknown
+	StorageName string        `gorm:"index" json:"storage_name"`
+	StoragePath string        `json:"storage_path"`
+	Bytes       int64         `json:"bytes"`
+	Duration    time.Duration `json:"duration"`
+	Status      string        `gorm:"index" json:"status"` // completed, failed, cancelled
+	Error       string        `json:"error,omitempty"`
+	Tags        []string      `gorm:"serializer:json" json:"tags,omitempty"`
+}
+
+// TaskHistoryFilter filters the task histories, zero values match everything
+type TaskHistoryFilter struct {
+	UserID      int64
+	Status      string
+	Type        string
+	StorageName string
+	Tag         string
+}
+
+func CreateTaskHistory(ctx context.Context, history *TaskHistory) error {
+	return GetDB(ctx).Create(history).Error
+}
+
+// GetTaskHistories returns the matched task histories, newest first, and the total count of matched records
+func GetTaskHistories(ctx context.Context, filter TaskHistoryFilter, offset, limit int) ([]TaskHistory, int64, error) {
+	query := GetDB(ctx).Model(&TaskHistory{})
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.StorageName != "" {
+		query = query.Where("storage_name = ?", filter.StorageName)
+	}
+	if filter.Tag != "" {
+		// the tags are stored as a JSON array, the tags can not contain quotes
+		query = query.Where(`tags LIKE ? ESCAPE '\'`, `%"`+escapeLike(filter.Tag)+`"%`)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var histories []TaskHistory
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&histories).Error
+	return histories, total, err
+}
@@ -5,17 +5,30 @@ import (
 	"time"
 )
 
+// Task statuses persisted in TaskState.Status
+const (
+	TaskStatusPending   = "pending"
+	TaskStatusRunning   = "running"
+	TaskStatusPaused    = "paused"
+	TaskStatusCompleted = "completed"
+	TaskStatusFailed    = "failed"
+	TaskStatusCancelled = "cancelled"
+)
+
 // TaskState represents the persisted state of a task
 type TaskState struct {
-	ID          string    `gorm:"primaryKey" json:"id"`
-	Title       string    `json:"title"`
-	Type        string    `json:"type"`
-	Status      string    `json:"status"` // pending, running, completed, failed, cancelled
-	Data        string    `json:"data"`   // JSON serialized task data
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `gorm:"primaryKey" json:"id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Status      string     `gorm:"index" json:"status"` // pending, running, paused, completed, failed, cancelled
+	Data        string     `json:"data"`                // JSON serialized task data
+	Priority    int        `json:"priority"`
+	Conflict    string     `json:"conflict,omitempty"` // conflict policy overriding the one of the storage, empty if not overridden
+	Extras      string     `json:"extras,omitempty"`   // JSON serialized core.TaskExtras, empty if none
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
-	Error       string    `json:"error,omitempty"`
+	Error       string     `json:"error,omitempty"`
 }
 
 func (TaskState) TableName() string {
@@ -39,9 +52,13 @@ func GetTaskState(ctx context.Context, id string) (*TaskState, error) {
 	return &task, nil
 }
 
+// GetPendingTasks returns the tasks that were queued, running or paused, oldest first
 func GetPendingTasks(ctx context.Context) ([]TaskState, error) {
 	var tasks []TaskState
-	err := GetDB(ctx).Where("status IN ?", []string{"pending", "running"}).Find(&tasks).Error
+	err := GetDB(ctx).
+		Where("status IN ?", []string{TaskStatusPending, TaskStatusRunning, TaskStatusPaused}).
+		Order("created_at").
+		Find(&tasks).Error
 	return tasks, err
 }
 
@@ -49,14 +66,24 @@ func DeleteTaskState(ctx context.Context, id string) error {
 	return GetDB(ctx).Where("id = ?", id).Delete(&TaskState{}).Error
 }
 
+// DeleteFinishedTaskStates removes the finished tasks which completed before the given time
+func DeleteFinishedTaskStates(ctx context.Context, before time.Time) error {
+	return GetDB(ctx).
+		Where("status IN ? AND completed_at < ?", []string{TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled}, before).
+		Delete(&TaskState{}).Error
+}
+
 // PersistTask saves a task to the database for recovery
-func PersistTask(ctx context.Context, id, title, taskType, data string) error {
+func PersistTask(ctx context.Context, id, title, taskType, data string, priority int, conflict, extras string) error {
 	task := &TaskState{
 		ID:        id,
 		Title:     title,
 		Type:      taskType,
-		Status:    "pending",
+		Status:    TaskStatusPending,
 		Data:      data,
+		Priority:  priority,
+		Conflict:  conflict,
+		Extras:    extras,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -72,9 +99,25 @@ func UpdateTaskStatus(ctx context.Context, id, status, errMsg string) error {
 	if errMsg != "" {
 		updates["error"] = errMsg
 	}
-	if status == "completed" || status == "failed" || status == "cancelled" {
+	if status == TaskStatusCompleted || status == TaskStatusFailed || status == TaskStatusCancelled {
 		now := time.Now()
 		updates["completed_at"] = &now
 	}
 	return GetDB(ctx).Model(&TaskState{}).Where("id = ?", id).Updates(updates).Error
 }
+
+// UpdateTaskData updates the data of a persisted task, for the tasks which keep their progress in it
+func UpdateTaskData(ctx context.Context, id, data string) error {
+	return GetDB(ctx).Model(&TaskState{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"data":       data,
+		"updated_at": time.Now(),
+	}).Error
+}
+
+// UpdateTaskPriority updates the priority of a persisted task
+func UpdateTaskPriority(ctx context.Context, id string, priority int) error {
+	return GetDB(ctx).Model(&TaskState{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"priority":   priority,
+		"updated_at": time.Now(),
+	}).Error
+}
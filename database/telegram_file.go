@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TelegramFile records a file saved to a telegram storage, so that the storage can find, list and read it again
+type TelegramFile struct {
+	gorm.Model
+	StorageName string `gorm:"index:idx_telegram_file_path" json:"storage_name"`
+	Path        string `gorm:"index:idx_telegram_file_path" json:"path"` // the cleaned storage path with a leading slash
+	ChatID      int64  `json:"chat_id"`
+	MessageIDs  []int  `gorm:"serializer:json" json:"message_ids"` // the messages of the split zip parts in order, or the only message
+	Size        int64  `json:"size"`                               // size of the original file, -1 if unknown
+	Split       bool   `json:"split"`                              // the file is split into zip parts
+}
+
+// SaveTelegramFile adds the file to the index, replacing the ones saved to the same path before.
+// The replaced files are returned, their messages are left to the caller.
+func SaveTelegramFile(ctx context.Context, file *TelegramFile) ([]TelegramFile, error) {
+	var replaced []TelegramFile
+	err := GetDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("storage_name = ? AND path = ?", file.StorageName, file.Path).
+			Find(&replaced).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("storage_name = ? AND path = ?", file.StorageName, file.Path).
+			Delete(&TelegramFile{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(file).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+func GetTelegramFile(ctx context.Context, storageName, path string) (*TelegramFile, error) {
+	var file TelegramFile
+	err := GetDB(ctx).Where("storage_name = ? AND path = ?", storageName, path).First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// GetTelegramFilesUnder returns the files whose path is under the directory, at any depth, sorted by path
+func GetTelegramFilesUnder(ctx context.Context, storageName, dir string) ([]TelegramFile, error) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	escaped := escapeLike(prefix)
+	var files []TelegramFile
+	err := GetDB(ctx).
+		Where(`storage_name = ? AND path LIKE ? ESCAPE '\'`, storageName, escaped+"%").
+		Order("path").
+		Find(&files).Error
+	if err != nil {
+		return nil, err
+	}
+	// LIKE is case insensitive in sqlite
+	matched := files[:0]
+	for _, f := range files {
+		if strings.HasPrefix(f.Path, prefix) {
+			matched = append(matched, f)
+		}
+	}
+	return matched, nil
+}
+
+func DeleteTelegramFile(ctx context.Context, id uint) error {
+	return GetDB(ctx).Unscoped().Delete(&TelegramFile{}, id).Error
+}
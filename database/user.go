@@ -2,7 +2,9 @@ package database
 
 import (
 	"context"
+	"fmt"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -48,3 +50,62 @@ func GetUserByID(ctx context.Context, id uint) (*User, error) {
 		Where("id = ?", id).First(&user).Error
 	return &user, err
 }
+
+// UserData is what ImportUserData adds to a user
+type UserData struct {
+	Dirs       []Dir
+	Rules      []Rule      // created in order, so the later ones get the higher IDs
+	WatchChats []WatchChat // replace the watched chats of the user with the same chat IDs
+	// the dir set as the default one, by its storage name and path, as the IDs differ between databases
+	DefaultDir *Dir
+}
+
+// the columns of User saved by ImportUserData
+var userSettingColumns = []string{"silent", "default_storage", "default_dir", "apply_rule", "filename_strategy", "filename_template", "dedup_policy"}
+
+// ImportUserData saves the settings of the user and adds the data to them in a transaction.
+// The dirs, rules and watched chats of the user are deleted first if replace is set.
+func ImportUserData(ctx context.Context, user *User, data UserData, replace bool) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if replace {
+			for _, model := range []any{&Dir{}, &Rule{}, &WatchChat{}} {
+				if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+					return err
+				}
+			}
+		}
+		for _, dir := range data.Dirs {
+			dir.UserID = user.ID
+			if err := tx.Create(&dir).Error; err != nil {
+				return err
+			}
+		}
+		for _, rule := range data.Rules {
+			rule.UserID = user.ID
+			if err := tx.Create(&rule).Error; err != nil {
+				return err
+			}
+		}
+		for _, chat := range data.WatchChats {
+			chat.UserID = user.ID
+			if err := tx.Unscoped().Where("user_id = ? AND chat_id = ?", user.ID, chat.ChatID).Delete(&WatchChat{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&chat).Error; err != nil {
+				return err
+			}
+		}
+		if data.DefaultDir != nil {
+			var dir Dir
+			if err := tx.Where("user_id = ? AND storage_name = ? AND path = ?", user.ID, data.DefaultDir.StorageName, data.DefaultDir.Path).
+				First(&dir).Error; err != nil {
+				return fmt.Errorf("failed to find the default dir: %w", err)
+			}
+			user.DefaultDir = dir.ID
+		}
+		// the associations are saved above
+		settings := *user
+		settings.Dirs, settings.Rules, settings.WatchChats = nil, nil, nil
+		return tx.Model(&User{}).Where("id = ?", user.ID).Select(userSettingColumns).Updates(&settings).Error
+	})
+}
@@ -5,6 +5,7 @@ package js
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,13 +14,17 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/dop251/goja"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/parsers/parsers"
 	"github.com/kiss2u/SaveAny-Bot/pkg/parser"
 )
 
+var ErrPluginUnloaded = errors.New("js: parser plugin was unloaded")
+
 type jsParser struct {
 	meta  PluginMeta
 	vm    *goja.Runtime
 	reqCh chan jsParserReq
+	done  chan struct{} // closed when the plugin is unloaded
 }
 
 type jsParserReq struct {
@@ -36,17 +41,33 @@ type jsParserResp struct {
 
 func (p *jsParser) CanHandle(url string) bool {
 	respCh := make(chan jsParserResp, 1)
-	p.reqCh <- jsParserReq{method: ParserMethodCanHandle, url: url, respCh: respCh}
-	resp := <-respCh
-	return resp.ok && resp.err == nil
+	select {
+	case p.reqCh <- jsParserReq{method: ParserMethodCanHandle, url: url, respCh: respCh}:
+	case <-p.done:
+		return false
+	}
+	select {
+	case resp := <-respCh:
+		return resp.ok && resp.err == nil
+	case <-p.done:
+		return false
+	}
 }
 
 func (p *jsParser) Parse(ctx context.Context, url string) (*parser.Item, error) {
 	respCh := make(chan jsParserResp, 1)
-	p.reqCh <- jsParserReq{method: ParserMethodParse, url: url, respCh: respCh}
+	select {
+	case p.reqCh <- jsParserReq{method: ParserMethodParse, url: url, respCh: respCh}:
+	case <-p.done:
+		return nil, ErrPluginUnloaded
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 	select {
 	case resp := <-respCh:
 		return resp.item, resp.err
+	case <-p.done:
+		return nil, ErrPluginUnloaded
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	}
@@ -56,11 +77,18 @@ func newJSParser(vm *goja.Runtime, canHandleFunc, parseFunc goja.Value, metadata
 	p := &jsParser{
 		vm:    vm,
 		reqCh: make(chan jsParserReq, 10),
+		done:  make(chan struct{}),
 		meta:  metadata,
 	}
 
 	go func() {
-		for req := range p.reqCh {
+		for {
+			var req jsParserReq
+			select {
+			case req = <-p.reqCh:
+			case <-p.done:
+				return
+			}
 			switch req.method {
 			case ParserMethodCanHandle:
 				fn, _ := goja.AssertFunction(canHandleFunc)
@@ -102,6 +130,16 @@ func newJSParser(vm *goja.Runtime, canHandleFunc, parseFunc goja.Value, metadata
 	return p
 }
 
+// UnloadPlugins removes the loaded JS parser plugins, the parses in progress fail with ErrPluginUnloaded
+func UnloadPlugins() {
+	for _, pser := range parsers.Remove(func(p parser.Parser) bool {
+		_, ok := p.(*jsParser)
+		return ok
+	}) {
+		close(pser.(*jsParser).done)
+	}
+}
+
 // 加载指定文件夹下的所有 JS 解析器插件
 func LoadPlugins(ctx context.Context, dir string) error {
 	entries, err := os.ReadDir(dir)
@@ -14,3 +14,5 @@ func LoadPlugins(ctx context.Context, dir string) error {
 func AddPlugin(ctx context.Context, code string, name string) error {
 	return errors.New("JS parser plugins are not supported in this build")
 }
+
+func UnloadPlugins() {}
@@ -2,8 +2,10 @@ package parsers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/kiss2u/SaveAny-Bot/config"
 	"github.com/kiss2u/SaveAny-Bot/parsers/js"
 	"github.com/kiss2u/SaveAny-Bot/parsers/native/kemono"
 	"github.com/kiss2u/SaveAny-Bot/parsers/native/twitter"
@@ -63,6 +65,22 @@ func LoadPlugins(ctx context.Context, dir string) error {
 	return js.LoadPlugins(ctx, dir)
 }
 
+// Reload configures the parsers again and loads the JS parser plugins again from the configured directories
+func Reload(ctx context.Context) error {
+	parsers.Reconfigure()
+	js.UnloadPlugins()
+	if !config.C().Parser.PluginEnable {
+		return nil
+	}
+	var errs []error
+	for _, dir := range config.C().Parser.PluginDirs {
+		if err := LoadPlugins(ctx, dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to load parser plugins from %s: %w", dir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func AddPlugin(ctx context.Context, code string, name string) error {
 	return js.AddPlugin(ctx, code, name)
 }
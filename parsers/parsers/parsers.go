@@ -41,3 +41,25 @@ func Get() []parser.Parser {
 	defer mu.Unlock()
 	return parsers
 }
+
+// Remove removes the parsers which match, it returns the removed ones
+func Remove(match func(p parser.Parser) bool) []parser.Parser {
+	mu.Lock()
+	defer mu.Unlock()
+	var removed, kept []parser.Parser
+	for _, pser := range parsers {
+		if match(pser) {
+			removed = append(removed, pser)
+		} else {
+			kept = append(kept, pser)
+		}
+	}
+	parsers = kept
+	return removed
+}
+
+// Reconfigure configures the parsers again with the current config
+func Reconfigure() {
+	configOnce.Do(func() {})
+	configParsers()
+}
@@ -0,0 +1,29 @@
+package conflict
+
+//go:generate go-enum --values --names --noprefix --flag --nocase
+
+// Policy decides what to do when a file already exists at the path to save to
+/* ENUM(
+rename, overwrite, skip, fail, compare-size-then-skip
+) */
+type Policy string
+
+// Default keeps both files by saving the new one with a suffixed name
+const Default = Rename
+
+var policyDisplay = map[Policy]map[string]string{
+	Rename:              {"zh-Hans": "重命名", "en": "Rename"},
+	Overwrite:           {"zh-Hans": "覆盖", "en": "Overwrite"},
+	Skip:                {"zh-Hans": "跳过", "en": "Skip"},
+	Fail:                {"zh-Hans": "报错", "en": "Fail"},
+	CompareSizeThenSkip: {"zh-Hans": "大小相同则跳过", "en": "Skip if same size"},
+}
+
+func GetDisplay(p Policy, lang string) string {
+	if display, ok := policyDisplay[p]; ok {
+		if str, ok := display[lang]; ok {
+			return str
+		}
+	}
+	return policyDisplay[p]["en"]
+}
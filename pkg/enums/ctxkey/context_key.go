@@ -1,6 +1,6 @@
 package ctxkey
 
-// ENUM(content-length)
+// ENUM(content-length, conflict-policy)
 //
 //go:generate go-enum --values --names --flag --nocase --noprefix
 type ContextKey string
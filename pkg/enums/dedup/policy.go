@@ -0,0 +1,29 @@
+package dedup
+
+//go:generate go-enum --values --names --noprefix --flag --nocase
+
+// Policy decides what to do when the file to save has already been saved before:
+// save a new copy, skip it, save it again over the saved file, or link the save path to the saved file
+/* ENUM(
+rename, skip, overwrite, link
+) */
+type Policy string
+
+// Default keeps saving every file as before
+const Default = Rename
+
+var policyDisplay = map[Policy]map[string]string{
+	Rename:    {"zh-Hans": "保存新副本", "en": "Save a new copy"},
+	Skip:      {"zh-Hans": "跳过", "en": "Skip"},
+	Overwrite: {"zh-Hans": "覆盖已保存的文件", "en": "Overwrite the saved file"},
+	Link:      {"zh-Hans": "链接到已保存的文件", "en": "Link to the saved file"},
+}
+
+func GetDisplay(p Policy, lang string) string {
+	if display, ok := policyDisplay[p]; ok {
+		if str, ok := display[lang]; ok {
+			return str
+		}
+	}
+	return policyDisplay[p]["en"]
+}
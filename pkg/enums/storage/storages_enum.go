@@ -26,6 +26,14 @@ const (
 	S3 StorageType = "s3"
 	// Rclone is a StorageType of type rclone.
 	Rclone StorageType = "rclone"
+	// Sftp is a StorageType of type sftp.
+	Sftp StorageType = "sftp"
+	// Ftp is a StorageType of type ftp.
+	Ftp StorageType = "ftp"
+	// Encrypt is a StorageType of type encrypt.
+	Encrypt StorageType = "encrypt"
+	// Mirror is a StorageType of type mirror.
+	Mirror StorageType = "mirror"
 )
 
 var ErrInvalidStorageType = fmt.Errorf("not a valid StorageType, try [%s]", strings.Join(_StorageTypeNames, ", "))
@@ -38,6 +46,10 @@ var _StorageTypeNames = []string{
 	string(Telegram),
 	string(S3),
 	string(Rclone),
+	string(Sftp),
+	string(Ftp),
+	string(Encrypt),
+	string(Mirror),
 }
 
 // StorageTypeNames returns a list of possible string values of StorageType.
@@ -80,6 +92,10 @@ var _StorageTypeValue = map[string]StorageType{
 	"telegram": Telegram,
 	"s3":       S3,
 	"rclone":   Rclone,
+	"sftp":     Sftp,
+	"ftp":      Ftp,
+	"encrypt":  Encrypt,
+	"mirror":   Mirror,
 }
 
 // ParseStorageType attempts to convert a string to a StorageType.
@@ -1,5 +1,5 @@
 package tasktype
 
 //go:generate go-enum --values --names --flag --nocase
-// ENUM(tgfiles,tphpics,parseditem,directlinks,aria2,ytdlp,transfer)
+// ENUM(tgfiles,tphpics,parseditem,directlinks,aria2,ytdlp,transfer,tgbatch)
 type TaskType string
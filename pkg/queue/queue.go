@@ -1,26 +1,56 @@
 package queue
 
 import (
+	"cmp"
 	"container/list"
+	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 )
 
+// TaskQueue schedules tasks by priority first, then the tasks moved by Move in their order,
+// then round-robin across their owners. Tasks with the same priority and owner are taken in the order of addition.
 type TaskQueue[T any] struct {
 	tasks          *list.List
 	taskMap        map[string]*Task[T]
 	runningTaskMap map[string]*Task[T]
+	ownerRunning   map[int64]int    // number of running tasks per owner
+	lastServed     map[int64]uint64 // the serveSeq when a task of the owner was last taken
+	serveSeq       uint64
+	ownerLimit     func(owner int64) int
 	mu             sync.RWMutex
 	cond           *sync.Cond
 	closed         bool
 }
 
-func NewTaskQueue[T any]() *TaskQueue[T] {
+type QueueOption func(*queueOptions)
+
+type queueOptions struct {
+	ownerLimit func(owner int64) int
+}
+
+// WithOwnerLimit sets the max number of running tasks of an owner.
+// The func is called on every scheduling, a value <= 0 means no limit.
+func WithOwnerLimit(limit func(owner int64) int) QueueOption {
+	return func(o *queueOptions) {
+		o.ownerLimit = limit
+	}
+}
+
+func NewTaskQueue[T any](opts ...QueueOption) *TaskQueue[T] {
+	o := queueOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 	tq := &TaskQueue[T]{
 		tasks:          list.New(),
 		taskMap:        make(map[string]*Task[T]),
 		runningTaskMap: make(map[string]*Task[T]),
+		ownerRunning:   make(map[int64]int),
+		lastServed:     make(map[int64]uint64),
+		ownerLimit:     o.ownerLimit,
 	}
 	tq.cond = sync.NewCond(&tq.mu)
 	return tq
@@ -50,46 +80,250 @@ func (tq *TaskQueue[T]) Add(task *Task[T]) error {
 	return nil
 }
 
-// Get retrieves and removes the next non-cancelled task from the queue, adding it to the running tasks.
+// Get retrieves and removes the next runnable task from the queue, adding it to the running tasks.
 // Blocks until a task is available or the queue is closed.
 func (tq *TaskQueue[T]) Get() (*Task[T], error) {
 	tq.mu.Lock()
 	defer tq.mu.Unlock()
 
-	for tq.tasks.Len() == 0 && !tq.closed {
+	for {
+		if task := tq.next(); task != nil {
+			tq.tasks.Remove(task.element)
+			task.element = nil
+			task.runCtx, task.runCancel = context.WithCancelCause(task.ctx)
+			tq.runningTaskMap[task.ID] = task
+			if task.Owner != 0 {
+				tq.ownerRunning[task.Owner]++
+			}
+			tq.serveSeq++
+			tq.lastServed[task.Owner] = tq.serveSeq
+			return task, nil
+		}
+		if tq.closed && tq.tasks.Len() == 0 {
+			return nil, fmt.Errorf("queue is closed and empty")
+		}
+		// wait for new tasks, or for running tasks to free the slots of their owners
 		tq.cond.Wait()
 	}
+}
 
-	if tq.closed && tq.tasks.Len() == 0 {
-		return nil, fmt.Errorf("queue is closed and empty")
-	}
-
-	for tq.tasks.Len() > 0 {
-		element := tq.tasks.Front()
+// next returns the task which should run next, dropping the cancelled ones on the way.
+// It picks the highest priority first, then the first pinned task, then the owner which was served least recently,
+// skipping the owners which reached their limit. Returns nil if no task can run now.
+// The caller must hold the lock.
+func (tq *TaskQueue[T]) next() *Task[T] {
+	var picked *Task[T]
+	var next *list.Element
+	for element := tq.tasks.Front(); element != nil; element = next {
+		next = element.Next()
 		task := element.Value.(*Task[T])
-
-		tq.tasks.Remove(element)
-		task.element = nil
-
-		if !task.Cancelled() {
-			tq.runningTaskMap[task.ID] = task
-			return task, nil
+		if task.Cancelled() {
+			tq.tasks.Remove(element)
+			task.element = nil
+			delete(tq.taskMap, task.ID)
+			continue
+		}
+		if task.Paused() || !tq.ownerAvailable(task.Owner) {
+			continue
+		}
+		if picked == nil || task.Priority > picked.Priority ||
+			(task.Priority == picked.Priority && task.pinned && !picked.pinned) ||
+			(task.Priority == picked.Priority && !task.pinned && !picked.pinned &&
+				tq.lastServed[task.Owner] < tq.lastServed[picked.Owner]) {
+			picked = task
 		}
 	}
+	return picked
+}
 
-	if !tq.closed {
-		return tq.Get()
+func (tq *TaskQueue[T]) ownerAvailable(owner int64) bool {
+	if owner == 0 || tq.ownerLimit == nil {
+		return true
 	}
-
-	return nil, fmt.Errorf("queue is closed and empty")
+	limit := tq.ownerLimit(owner)
+	return limit <= 0 || tq.ownerRunning[owner] < limit
 }
 
 // Done stops(cancels) and removes the task from the running tasks.
 func (tq *TaskQueue[T]) Done(taskID string) {
 	tq.mu.Lock()
 	defer tq.mu.Unlock()
+	tq.stopRunning(taskID)
 	delete(tq.taskMap, taskID)
+	// the owner may have been blocked by its limit
+	tq.cond.Broadcast()
+}
+
+// stopRunning removes the task from the running tasks and releases its owner slot.
+// The caller must hold the lock.
+func (tq *TaskQueue[T]) stopRunning(taskID string) (*Task[T], bool) {
+	task, ok := tq.runningTaskMap[taskID]
+	if !ok {
+		return nil, false
+	}
 	delete(tq.runningTaskMap, taskID)
+	if task.runCancel != nil {
+		task.runCancel(nil)
+	}
+	if task.Owner != 0 {
+		tq.ownerRunning[task.Owner]--
+		if tq.ownerRunning[task.Owner] <= 0 {
+			delete(tq.ownerRunning, task.Owner)
+		}
+	}
+	return task, true
+}
+
+// Requeue moves a running task back to the front of the queue, it is used after the task stopped because of Pause.
+// The task keeps its paused state, so it will not be taken again until resumed.
+func (tq *TaskQueue[T]) Requeue(taskID string) error {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+
+	task, ok := tq.stopRunning(taskID)
+	if !ok {
+		return fmt.Errorf("task %s is not running", taskID)
+	}
+	task.runCtx, task.runCancel = nil, nil
+	task.element = tq.tasks.PushFront(task)
+	tq.cond.Broadcast()
+	return nil
+}
+
+// Pause pauses a task. A queued task is skipped until resumed.
+// A running task gets its context cancelled with ErrPaused, the consumer should Requeue it once it stopped.
+// [WARN] Pausing a running task relies on the task's implementation to respect the cancellation, like CancelTask.
+func (tq *TaskQueue[T]) Pause(taskID string) error {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+
+	task, exists := tq.taskMap[taskID]
+	if !exists {
+		return fmt.Errorf("task %s does not exist", taskID)
+	}
+	if task.Cancelled() {
+		return fmt.Errorf("task %s has been cancelled", taskID)
+	}
+	if !task.paused.CompareAndSwap(false, true) {
+		return fmt.Errorf("task %s is already paused", taskID)
+	}
+	if _, running := tq.runningTaskMap[taskID]; running {
+		task.runCancel(ErrPaused)
+	}
+	return nil
+}
+
+// Resume makes a paused task available to Get again.
+func (tq *TaskQueue[T]) Resume(taskID string) error {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+
+	task, exists := tq.taskMap[taskID]
+	if !exists {
+		return fmt.Errorf("task %s does not exist", taskID)
+	}
+	if !task.paused.CompareAndSwap(true, false) {
+		return fmt.Errorf("task %s is not paused", taskID)
+	}
+	tq.cond.Signal()
+	return nil
+}
+
+type MovePosition string
+
+const (
+	MoveTop  MovePosition = "top"
+	MoveUp   MovePosition = "up"
+	MoveDown MovePosition = "down"
+)
+
+// Move moves a queued task in the order returned by QueuedTasks and returns its new priority.
+// Moving up or down swaps the task with its neighbour and takes the neighbour's priority,
+// moving to top takes the priority of the first task. The priorities stay the ones of the queued tasks.
+// The moved task and the ones before it in its priority are pinned, so they are taken in the order shown
+// instead of in turns of their owners.
+func (tq *TaskQueue[T]) Move(taskID string, to MovePosition) (Priority, error) {
+	tq.mu.Lock()
+	defer tq.mu.Unlock()
+
+	ordered := tq.orderedTasks()
+	i := slices.IndexFunc(ordered, func(t *Task[T]) bool { return t.ID == taskID })
+	if i < 0 {
+		return 0, fmt.Errorf("task %s is not queued", taskID)
+	}
+	task := ordered[i]
+	// lane returns the tasks of the priority among tasks, without the moved one
+	lane := func(tasks []*Task[T], priority Priority) []*Task[T] {
+		var same []*Task[T]
+		for _, t := range tasks {
+			if t != task && t.Priority == priority {
+				same = append(same, t)
+			}
+		}
+		return same
+	}
+	switch to {
+	case MoveTop:
+		if i > 0 {
+			task.Priority = ordered[0].Priority
+			tq.tasks.MoveToFront(task.element)
+			task.pinned = true
+		}
+	case MoveUp:
+		if i > 0 {
+			prev := ordered[i-1]
+			task.Priority = prev.Priority
+			before := lane(ordered[:i], prev.Priority)
+			tq.tasks.MoveBefore(task.element, prev.element)
+			tq.pin(slices.Insert(before, len(before)-1, task))
+		}
+	case MoveDown:
+		if i < len(ordered)-1 {
+			next := ordered[i+1]
+			task.Priority = next.Priority
+			tq.pin(append(lane(ordered[:i+2], next.Priority), task))
+		}
+	default:
+		return 0, fmt.Errorf("unknown move position: %s", to)
+	}
+	return task.Priority, nil
+}
+
+// pin pins the tasks of the same priority and arranges them in the queue in the given order,
+// which is the order they are taken in. The caller must hold the lock.
+func (tq *TaskQueue[T]) pin(tasks []*Task[T]) {
+	for k, task := range tasks {
+		task.pinned = true
+		if k > 0 {
+			tq.tasks.MoveAfter(task.element, tasks[k-1].element)
+		}
+	}
+}
+
+// orderedTasks returns the non-cancelled queued tasks sorted by priority, then the pinned ones first,
+// then the order in the queue. The caller must hold the lock.
+func (tq *TaskQueue[T]) orderedTasks() []*Task[T] {
+	tasks := make([]*Task[T], 0, tq.tasks.Len())
+	for element := tq.tasks.Front(); element != nil; element = element.Next() {
+		task := element.Value.(*Task[T])
+		if !task.Cancelled() {
+			tasks = append(tasks, task)
+		}
+	}
+	slices.SortStableFunc(tasks, func(a, b *Task[T]) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(boolRank(b.pinned), boolRank(a.pinned))
+	})
+	return tasks
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
 }
 
 func (tq *TaskQueue[T]) Length() int {
@@ -123,37 +357,41 @@ func (tq *TaskQueue[T]) RunningTasks() []TaskInfo {
 		if task.Cancelled() {
 			continue
 		}
-		tasks = append(tasks, TaskInfo{
-			ID:        task.ID,
-			Title:     task.Title,
-			Created:   task.created,
-			Cancelled: task.Cancelled(),
-		})
+		tasks = append(tasks, task.info())
 	}
 	return tasks
 }
 
 // QueuedTasks returns the queued (not yet running) tasks' info.
-// The sorting is in the order of addition.
+// The sorting is by priority, then the moved tasks, then the order in the queue.
+// The tasks which were not moved may still be taken in turns of their owners.
 func (tq *TaskQueue[T]) QueuedTasks() []TaskInfo {
 	tq.mu.RLock()
 	defer tq.mu.RUnlock()
 
-	tasks := make([]TaskInfo, 0, tq.tasks.Len())
-	for element := tq.tasks.Front(); element != nil; element = element.Next() {
-		task := element.Value.(*Task[T])
-		if !task.Cancelled() {
-			tasks = append(tasks, TaskInfo{
-				ID:        task.ID,
-				Title:     task.Title,
-				Created:   task.created,
-				Cancelled: task.Cancelled(),
-			})
-		}
+	ordered := tq.orderedTasks()
+	tasks := make([]TaskInfo, 0, len(ordered))
+	for _, task := range ordered {
+		tasks = append(tasks, task.info())
 	}
 	return tasks
 }
 
+// Data returns the data of the queued and running tasks, including the cancelled ones which are not done yet.
+func (tq *TaskQueue[T]) Data() []T {
+	tq.mu.RLock()
+	defer tq.mu.RUnlock()
+
+	data := make([]T, 0, tq.tasks.Len()+len(tq.runningTaskMap))
+	for element := tq.tasks.Front(); element != nil; element = element.Next() {
+		data = append(data, element.Value.(*Task[T]).Data)
+	}
+	for _, task := range tq.runningTaskMap {
+		data = append(data, task.Data)
+	}
+	return data
+}
+
 // CancelTask cancels a task by its ID.
 // It looks for the task in both queued and running tasks.
 // [NOTE] Cancelled tasks will not be removed from the queue, but marked as cancelled. Use Done to remove them.
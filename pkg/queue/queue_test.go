@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
@@ -104,3 +105,193 @@ func TestConcurrencySafety(t *testing.T) {
 	}()
 	wg.Wait()
 }
+
+func TestPriority(t *testing.T) {
+	q := queue.NewTaskQueue[int]()
+	q.Add(queue.NewTask(context.Background(), "low", "testing", 0, queue.WithPriority(queue.PriorityLow)))
+	q.Add(newTask("normal"))
+	q.Add(queue.NewTask(context.Background(), "high", "testing", 0, queue.WithPriority(queue.PriorityHigh)))
+	for _, want := range []string{"high", "normal", "low"} {
+		task, err := q.Get()
+		if err != nil {
+			t.Fatalf("unexpected error on Get: %v", err)
+		}
+		if task.ID != want {
+			t.Fatalf("expected task %s, got %s", want, task.ID)
+		}
+	}
+}
+
+func TestOwnerRoundRobin(t *testing.T) {
+	q := queue.NewTaskQueue[int]()
+	for i := range 3 {
+		q.Add(queue.NewTask(context.Background(), fmt.Sprintf("a%d", i), "testing", 0, queue.WithOwner(1)))
+	}
+	q.Add(queue.NewTask(context.Background(), "b0", "testing", 0, queue.WithOwner(2)))
+	q.Add(queue.NewTask(context.Background(), "b1", "testing", 0, queue.WithOwner(2)))
+	for _, want := range []string{"a0", "b0", "a1", "b1", "a2"} {
+		task, err := q.Get()
+		if err != nil {
+			t.Fatalf("unexpected error on Get: %v", err)
+		}
+		if task.ID != want {
+			t.Fatalf("expected task %s, got %s", want, task.ID)
+		}
+	}
+}
+
+func TestOwnerLimit(t *testing.T) {
+	q := queue.NewTaskQueue[int](queue.WithOwnerLimit(func(owner int64) int {
+		if owner == 1 {
+			return 1
+		}
+		return 0
+	}))
+	q.Add(queue.NewTask(context.Background(), "a0", "testing", 0, queue.WithOwner(1)))
+	q.Add(queue.NewTask(context.Background(), "a1", "testing", 0, queue.WithOwner(1)))
+	q.Add(queue.NewTask(context.Background(), "b0", "testing", 0, queue.WithOwner(2)))
+	for _, want := range []string{"a0", "b0"} {
+		task, err := q.Get()
+		if err != nil {
+			t.Fatalf("unexpected error on Get: %v", err)
+		}
+		if task.ID != want {
+			t.Fatalf("expected task %s, got %s", want, task.ID)
+		}
+	}
+	got := make(chan string)
+	go func() {
+		task, err := q.Get()
+		if err != nil {
+			close(got)
+			return
+		}
+		got <- task.ID
+	}()
+	select {
+	case id := <-got:
+		t.Fatalf("expected Get to block while owner is at its limit, got %s", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	q.Done("a0")
+	if id := <-got; id != "a1" {
+		t.Fatalf("expected task a1 after a0 is done, got %s", id)
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	q := queue.NewTaskQueue[int]()
+	q.Add(newTask("1"))
+	q.Add(newTask("2"))
+	if err := q.Pause("1"); err != nil {
+		t.Fatalf("unexpected error on Pause: %v", err)
+	}
+	task, err := q.Get()
+	if err != nil {
+		t.Fatalf("unexpected error on Get: %v", err)
+	}
+	if task.ID != "2" {
+		t.Fatalf("expected paused task to be skipped, got %s", task.ID)
+	}
+
+	// pause the running task, it should be requeued and not taken until resumed
+	if err := q.Pause("2"); err != nil {
+		t.Fatalf("unexpected error on Pause: %v", err)
+	}
+	if !queue.IsPaused(task.Context()) {
+		t.Fatal("expected the running task context to be cancelled with ErrPaused")
+	}
+	if err := q.Requeue("2"); err != nil {
+		t.Fatalf("unexpected error on Requeue: %v", err)
+	}
+	if err := q.Resume("2"); err != nil {
+		t.Fatalf("unexpected error on Resume: %v", err)
+	}
+	task, err = q.Get()
+	if err != nil {
+		t.Fatalf("unexpected error on Get: %v", err)
+	}
+	if task.ID != "2" || task.Context().Err() != nil {
+		t.Fatalf("expected resumed task 2 with a fresh context, got %s", task.ID)
+	}
+	if err := q.Resume("2"); err == nil {
+		t.Fatal("expected error on resuming a task which is not paused, got nil")
+	}
+}
+
+func TestMove(t *testing.T) {
+	q := queue.NewTaskQueue[int]()
+	for _, id := range []string{"1", "2", "3", "4"} {
+		q.Add(newTask(id))
+	}
+	order := func() string {
+		ids := ""
+		for _, info := range q.QueuedTasks() {
+			ids += info.ID
+		}
+		return ids
+	}
+	if _, err := q.Move("3", queue.MoveUp); err != nil {
+		t.Fatalf("unexpected error on Move: %v", err)
+	}
+	if got := order(); got != "1324" {
+		t.Fatalf("expected order 1324, got %s", got)
+	}
+	if _, err := q.Move("1", queue.MoveDown); err != nil {
+		t.Fatalf("unexpected error on Move: %v", err)
+	}
+	if got := order(); got != "3124" {
+		t.Fatalf("expected order 3124, got %s", got)
+	}
+	priority, err := q.Move("4", queue.MoveTop)
+	if err != nil {
+		t.Fatalf("unexpected error on Move: %v", err)
+	}
+	if priority != queue.PriorityNormal {
+		t.Fatalf("expected the priority of the first task, got %d", priority)
+	}
+	if got := order(); got != "4312" {
+		t.Fatalf("expected order 4312, got %s", got)
+	}
+	task, err := q.Get()
+	if err != nil {
+		t.Fatalf("unexpected error on Get: %v", err)
+	}
+	if task.ID != "4" {
+		t.Fatalf("expected task 4 moved to top, got %s", task.ID)
+	}
+	if _, err := q.Move("4", queue.MoveUp); err == nil {
+		t.Fatal("expected error on moving a running task, got nil")
+	}
+}
+
+func TestMoveWithOwners(t *testing.T) {
+	q := queue.NewTaskQueue[int]()
+	q.Add(queue.NewTask(context.Background(), "a0", "testing", 0, queue.WithOwner(1)))
+	q.Add(queue.NewTask(context.Background(), "a1", "testing", 0, queue.WithOwner(1)))
+	q.Add(queue.NewTask(context.Background(), "b0", "testing", 0, queue.WithOwner(2)))
+	q.Add(queue.NewTask(context.Background(), "a2", "testing", 0, queue.WithOwner(1)))
+	if task, _ := q.Get(); task.ID != "a0" {
+		t.Fatalf("expected task a0, got %s", task.ID)
+	}
+	// without moving, b0 would be taken next as its owner was not served yet
+	if _, err := q.Move("a2", queue.MoveUp); err != nil {
+		t.Fatalf("unexpected error on Move: %v", err)
+	}
+	var shown []string
+	for _, info := range q.QueuedTasks() {
+		shown = append(shown, info.ID)
+	}
+	for i, want := range []string{"a1", "a2", "b0"} {
+		if shown[i] != want {
+			t.Fatalf("expected queued order a1 a2 b0, got %v", shown)
+		}
+		task, err := q.Get()
+		if err != nil {
+			t.Fatalf("unexpected error on Get: %v", err)
+		}
+		if task.ID != want {
+			t.Fatalf("expected task %s as shown, got %s", want, task.ID)
+		}
+	}
+}
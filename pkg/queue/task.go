@@ -3,17 +3,53 @@ package queue
 import (
 	"container/list"
 	"context"
+	"errors"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	// ErrPaused is the cause of the context of a running task which is paused
+	ErrPaused = errors.New("task paused")
+	// ErrCancelled is the cause of the context of a task which is cancelled by Cancel
+	ErrCancelled = errors.New("task cancelled")
+)
+
+// IsPaused reports whether the ctx was cancelled because the task is paused
+func IsPaused(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrPaused)
+}
+
+// IsCancelled reports whether the ctx was cancelled because the task is cancelled,
+// unlike the task being paused or the parent context being done
+func IsCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrCancelled)
+}
+
+// Priority of a task, tasks with higher priority are always taken first
+type Priority int
+
+const (
+	PriorityLow    Priority = -1 // background jobs, e.g. batch and watch tasks
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1 // interactive tasks, e.g. a single file sent by the user
+)
+
 type Task[T any] struct {
-	ID      string
-	Title   string
-	Data    T
-	ctx     context.Context
-	cancel  context.CancelFunc
-	created time.Time
-	element *list.Element
+	ID       string
+	Title    string
+	Data     T
+	Priority Priority
+	Owner    int64 // the user the task belongs to, tasks of different owners are scheduled round-robin. 0 means no owner
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	created  time.Time
+	element  *list.Element
+	pinned   bool // moved by the user, taken in the order of the queue before the other tasks of its priority
+	paused   atomic.Bool
+	// context of the current run, it is cancelled with ErrPaused when the running task is paused
+	runCtx    context.Context
+	runCancel context.CancelCauseFunc
 }
 
 // Read-only info about a task
@@ -22,17 +58,45 @@ type TaskInfo struct {
 	Created   time.Time
 	Cancelled bool
 	Title     string
+	Paused    bool
+	Priority  Priority
+	Owner     int64
+}
+
+type TaskOption func(*taskOptions)
+
+type taskOptions struct {
+	priority Priority
+	owner    int64
 }
 
-func NewTask[T any](ctx context.Context, id string, title string, data T) *Task[T] {
-	cancelCtx, cancel := context.WithCancel(ctx)
+func WithPriority(priority Priority) TaskOption {
+	return func(o *taskOptions) {
+		o.priority = priority
+	}
+}
+
+func WithOwner(owner int64) TaskOption {
+	return func(o *taskOptions) {
+		o.owner = owner
+	}
+}
+
+func NewTask[T any](ctx context.Context, id string, title string, data T, opts ...TaskOption) *Task[T] {
+	o := taskOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cancelCtx, cancel := context.WithCancelCause(ctx)
 	return &Task[T]{
-		ID:      id,
-		Title:   title,
-		Data:    data,
-		ctx:     cancelCtx,
-		cancel:  cancel,
-		created: time.Now(),
+		ID:       id,
+		Title:    title,
+		Data:     data,
+		Priority: o.priority,
+		Owner:    o.owner,
+		ctx:      cancelCtx,
+		cancel:   cancel,
+		created:  time.Now(),
 	}
 }
 
@@ -46,9 +110,29 @@ func (t *Task[T]) Cancelled() bool {
 }
 
 func (t *Task[T]) Cancel() {
-	t.cancel()
+	t.cancel(ErrCancelled)
 }
 
+func (t *Task[T]) Paused() bool {
+	return t.paused.Load()
+}
+
+// Context returns the context the task should run with.
 func (t *Task[T]) Context() context.Context {
+	if t.runCtx != nil {
+		return t.runCtx
+	}
 	return t.ctx
 }
+
+func (t *Task[T]) info() TaskInfo {
+	return TaskInfo{
+		ID:        t.ID,
+		Title:     t.Title,
+		Created:   t.created,
+		Cancelled: t.Cancelled(),
+		Paused:    t.Paused(),
+		Priority:  t.Priority,
+		Owner:     t.Owner,
+	}
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"math"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiter is a token bucket limiting the number of bytes per second, its limit can be changed at any time.
+// A nil Limiter or a limit <= 0 means unlimited.
+type Limiter struct {
+	lim *rate.Limiter
+}
+
+func New(bytesPerSec int64) *Limiter {
+	l := &Limiter{lim: rate.NewLimiter(rate.Inf, 0)}
+	l.SetLimit(bytesPerSec)
+	return l
+}
+
+// SetLimit changes the limit in bytes per second, <= 0 means unlimited
+func (l *Limiter) SetLimit(bytesPerSec int64) {
+	if bytesPerSec <= 0 {
+		l.lim.SetLimit(rate.Inf)
+		return
+	}
+	// allow at most one second of traffic in a burst
+	l.lim.SetBurst(int(min(bytesPerSec, math.MaxInt32)))
+	l.lim.SetLimit(rate.Limit(bytesPerSec))
+}
+
+// Limit returns the current limit in bytes per second, 0 means unlimited
+func (l *Limiter) Limit() int64 {
+	if l == nil || l.lim.Limit() == rate.Inf {
+		return 0
+	}
+	return int64(l.lim.Limit())
+}
+
+// WaitN blocks until n bytes are allowed to pass or ctx is done.
+func (l *Limiter) WaitN(ctx context.Context, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		if l.lim.Limit() == rate.Inf {
+			return nil
+		}
+		// WaitN fails when n exceeds the burst, so wait in chunks
+		chunk := min(n, max(l.lim.Burst(), 1))
+		if err := l.lim.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// Wait waits n bytes on all the limiters
+func Wait(ctx context.Context, n int, limiters ...*Limiter) error {
+	for _, l := range limiters {
+		if err := l.WaitN(ctx, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type reader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters []*Limiter
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if werr := Wait(r.ctx, n, r.limiters...); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+type readSeeker struct {
+	*reader
+	s io.Seeker
+}
+
+func (r *readSeeker) Seek(offset int64, whence int) (int64, error) {
+	return r.s.Seek(offset, whence)
+}
+
+// NewReader returns a reader limited by the limiters.
+// If r is an io.ReadSeeker, the returned reader is an io.ReadSeeker as well.
+func NewReader(ctx context.Context, r io.Reader, limiters ...*Limiter) io.Reader {
+	lr := &reader{ctx: ctx, r: r, limiters: limiters}
+	if s, ok := r.(io.Seeker); ok {
+		return &readSeeker{reader: lr, s: s}
+	}
+	return lr
+}
+
+type writer struct {
+	ctx      context.Context
+	w        io.Writer
+	limiters []*Limiter
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if err := Wait(w.ctx, len(p), w.limiters...); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
+
+// NewWriter returns a writer limited by the limiters
+func NewWriter(ctx context.Context, w io.Writer, limiters ...*Limiter) io.Writer {
+	return &writer{ctx: ctx, w: w, limiters: limiters}
+}
+
+type writerAt struct {
+	ctx      context.Context
+	w        io.WriterAt
+	limiters []*Limiter
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if err := Wait(w.ctx, len(p), w.limiters...); err != nil {
+		return 0, err
+	}
+	return w.w.WriteAt(p, off)
+}
+
+// NewWriterAt returns a writer at limited by the limiters
+func NewWriterAt(ctx context.Context, w io.WriterAt, limiters ...*Limiter) io.WriterAt {
+	return &writerAt{ctx: ctx, w: w, limiters: limiters}
+}
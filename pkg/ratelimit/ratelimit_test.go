@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	l := New(0)
+	start := time.Now()
+	if err := l.WaitN(ctx, 100<<20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if time.Since(start) > 50*time.Millisecond {
+		t.Fatalf("unlimited limiter should not block")
+	}
+	if l.Limit() != 0 {
+		t.Fatalf("expected limit 0, got %d", l.Limit())
+	}
+
+	l.SetLimit(1000)
+	if l.Limit() != 1000 {
+		t.Fatalf("expected limit 1000, got %d", l.Limit())
+	}
+	// drain the initial burst, then 500 bytes should take about half a second
+	l.WaitN(ctx, 1000)
+	start = time.Now()
+	if err := l.WaitN(ctx, 500); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("expected to wait about 500ms, waited %v", elapsed)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.WaitN(cctx, 5000); err == nil {
+		t.Fatalf("expected error for cancelled context")
+	}
+
+	var nilLimiter *Limiter
+	if err := nilLimiter.WaitN(ctx, 1<<30); err != nil {
+		t.Fatalf("nil limiter should not block: %v", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := NewReader(ctx, strings.NewReader("hello")).(io.ReadSeeker); !ok {
+		t.Fatalf("expected the reader of an io.ReadSeeker to be an io.ReadSeeker")
+	}
+	if _, ok := NewReader(ctx, io.MultiReader(strings.NewReader("hello"))).(io.Seeker); ok {
+		t.Fatalf("expected the reader of a plain reader not to be an io.Seeker")
+	}
+
+	data, err := io.ReadAll(NewReader(ctx, strings.NewReader("hello world"), New(0), nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Fatalf("unexpected data %q", data)
+	}
+
+	var buf bytes.Buffer
+	if _, err := NewWriter(ctx, &buf, New(1<<20)).Write([]byte("hello")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "hello" {
+		t.Fatalf("unexpected data %q", buf.String())
+	}
+}
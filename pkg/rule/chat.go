@@ -0,0 +1,106 @@
+package rule
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseChatIDs parses comma separated chat IDs, the -100 prefix of the channel IDs in the Bot API is accepted
+func parseChatIDs(s string) ([]int64, error) {
+	list := splitList(s)
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no chat ID given")
+	}
+	ids := make([]int64, 0, len(list))
+	for _, v := range list {
+		v = strings.TrimPrefix(strings.TrimPrefix(v, "-100"), "-")
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid chat ID: %s", v)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func containsAny(ids, input []int64) bool {
+	return slices.ContainsFunc(input, func(id int64) bool { return slices.Contains(ids, id) })
+}
+
+var _ RuleClass[[]int64] = (*RuleChat)(nil)
+
+// RuleChat matches the chat the message is in or forwarded from, by comma separated chat IDs
+type RuleChat struct {
+	storInfo
+	chatIDs []int64
+}
+
+func (r RuleChat) Type() RuleType {
+	return Chat
+}
+
+func (r RuleChat) Match(input []int64) (bool, error) {
+	return containsAny(r.chatIDs, input), nil
+}
+
+func (r RuleChat) StorageName() string {
+	return r.storName
+}
+
+func (r RuleChat) StoragePath() string {
+	return r.storPath
+}
+
+func NewRuleChat(storName, storPath, chatIDs string) (*RuleChat, error) {
+	ids, err := parseChatIDs(chatIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleChat{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		chatIDs: ids,
+	}, nil
+}
+
+var _ RuleClass[[]int64] = (*RuleSender)(nil)
+
+// RuleSender matches the user who sent the message or wrote the forwarded message, by comma separated user IDs
+type RuleSender struct {
+	storInfo
+	userIDs []int64
+}
+
+func (r RuleSender) Type() RuleType {
+	return Sender
+}
+
+func (r RuleSender) Match(input []int64) (bool, error) {
+	return containsAny(r.userIDs, input), nil
+}
+
+func (r RuleSender) StorageName() string {
+	return r.storName
+}
+
+func (r RuleSender) StoragePath() string {
+	return r.storPath
+}
+
+func NewRuleSender(storName, storPath, userIDs string) (*RuleSender, error) {
+	ids, err := parseChatIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleSender{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		userIDs: ids,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package rule
+
+import (
+	"time"
+)
+
+var _ RuleClass[string] = (*RuleDate)(nil)
+
+// RuleDate matches the day the message is sent on, e.g. "2024-05-01", ">=2024-01-01", "2024-01-01..2024-06-30"
+type RuleDate struct {
+	storInfo
+	date valueRange[string]
+}
+
+func (r RuleDate) Type() RuleType {
+	return Date
+}
+
+// Match takes the date formatted as time.DateOnly, which sorts like the time
+func (r RuleDate) Match(input string) (bool, error) {
+	return input != "" && r.date.contains(input), nil
+}
+
+func (r RuleDate) StorageName() string {
+	return r.storName
+}
+
+func (r RuleDate) StoragePath() string {
+	return r.storPath
+}
+
+func parseDate(s string) (string, error) {
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(time.DateOnly), nil
+}
+
+func NewRuleDate(storName, storPath, dateRange string) (*RuleDate, error) {
+	date, err := parseRange(dateRange, parseDate)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleDate{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		date: date,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package rule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ RuleClass[time.Duration] = (*RuleDuration)(nil)
+
+// RuleDuration matches the duration of the video or audio, e.g. ">10m", "30s..5m", a number is in seconds
+type RuleDuration struct {
+	storInfo
+	duration valueRange[time.Duration]
+}
+
+func (r RuleDuration) Type() RuleType {
+	return Duration
+}
+
+// Match takes a negative duration for the files without one
+func (r RuleDuration) Match(input time.Duration) (bool, error) {
+	return input >= 0 && r.duration.contains(input), nil
+}
+
+func (r RuleDuration) StorageName() string {
+	return r.storName
+}
+
+func (r RuleDuration) StoragePath() string {
+	return r.storPath
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+func NewRuleDuration(storName, storPath, durationRange string) (*RuleDuration, error) {
+	duration, err := parseRange(durationRange, parseDuration)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleDuration{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		duration: duration,
+	}, nil
+}
+
+var _ RuleClass[int] = (*RuleResolution)(nil)
+
+// RuleResolution matches the shorter side of the video or photo, e.g. ">=1080", "720p..1080p", "1920x1080"
+type RuleResolution struct {
+	storInfo
+	resolution valueRange[int]
+}
+
+func (r RuleResolution) Type() RuleType {
+	return Resolution
+}
+
+// Match takes 0 for the files without a resolution
+func (r RuleResolution) Match(input int) (bool, error) {
+	return input > 0 && r.resolution.contains(input), nil
+}
+
+func (r RuleResolution) StorageName() string {
+	return r.storName
+}
+
+func (r RuleResolution) StoragePath() string {
+	return r.storPath
+}
+
+func parseResolution(s string) (int, error) {
+	s = strings.TrimSuffix(strings.ToLower(s), "p")
+	if w, h, ok := strings.Cut(s, "x"); ok {
+		width, err := strconv.Atoi(w)
+		if err != nil {
+			return 0, fmt.Errorf("invalid resolution: %s", s)
+		}
+		height, err := strconv.Atoi(h)
+		if err != nil {
+			return 0, fmt.Errorf("invalid resolution: %s", s)
+		}
+		return min(width, height), nil
+	}
+	return strconv.Atoi(s)
+}
+
+func NewRuleResolution(storName, storPath, resolutionRange string) (*RuleResolution, error) {
+	resolution, err := parseRange(resolutionRange, parseResolution)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleResolution{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		resolution: resolution,
+	}, nil
+}
@@ -6,6 +6,16 @@ const (
 	FileNameRegex RuleType = "FILENAME-REGEX"
 	MessageRegex  RuleType = "MESSAGE-REGEX"
 	IsAlbum       RuleType = "IS-ALBUM"
+	Size          RuleType = "SIZE"
+	MimeType      RuleType = "MIME-TYPE"
+	Extension     RuleType = "EXTENSION"
+	Chat          RuleType = "CHAT"
+	Sender        RuleType = "SENDER"
+	Date          RuleType = "DATE"
+	Duration      RuleType = "DURATION"
+	Resolution    RuleType = "RESOLUTION"
+	MediaKindType RuleType = "MEDIA-KIND"
+	Expr          RuleType = "EXPR" // an expression combining the other rule types, see ParseExpr
 )
 
 func (r RuleType) String() string {
@@ -13,5 +23,7 @@ func (r RuleType) String() string {
 }
 
 func Values() []RuleType {
-	return []RuleType{FileNameRegex, MessageRegex, IsAlbum}
+	return []RuleType{
+		FileNameRegex, MessageRegex, IsAlbum, Size, MimeType, Extension, Chat, Sender, Date, Duration, Resolution, MediaKindType, Expr,
+	}
 }
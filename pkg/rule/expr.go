@@ -0,0 +1,287 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidExpr = errors.New("invalid rule expression")
+
+type andExpr []Matcher
+
+func (e andExpr) Match(input Input) (bool, error) {
+	for _, m := range e {
+		ok, err := m.Match(input)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (e andExpr) String() string {
+	parts := make([]string, len(e))
+	for i, m := range e {
+		parts[i] = m.String()
+		if _, ok := m.(orExpr); ok {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+type orExpr []Matcher
+
+func (e orExpr) Match(input Input) (bool, error) {
+	for _, m := range e {
+		ok, err := m.Match(input)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (e orExpr) String() string {
+	parts := make([]string, len(e))
+	for i, m := range e {
+		parts[i] = m.String()
+	}
+	return strings.Join(parts, " or ")
+}
+
+type notExpr struct {
+	m Matcher
+}
+
+func (e notExpr) Match(input Input) (bool, error) {
+	ok, err := e.m.Match(input)
+	return !ok && err == nil, err
+}
+
+func (e notExpr) String() string {
+	switch e.m.(type) {
+	case andExpr, orExpr:
+		return "not (" + e.m.String() + ")"
+	}
+	return "not " + e.m.String()
+}
+
+// ParseExpr parses an expression of rule types combined with and, or, not and parentheses, e.g.
+//
+//	FILENAME-REGEX('\.mp4$') and not (IS-ALBUM(true) or MESSAGE-REGEX('draft'))
+//
+// The data of each rule type is given in parentheses, quoted with single or double quotes if it contains parentheses.
+// A backslash escapes the quote and itself in quoted data, other backslashes are kept for the regular expressions.
+// and binds tighter than or, && || and ! can be used instead of the keywords.
+func ParseExpr(s string) (Matcher, error) {
+	p := &exprParser{s: s}
+	m, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return m, nil
+}
+
+type exprParser struct {
+	s   string
+	pos int
+}
+
+func (p *exprParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at %d: %s", ErrInvalidExpr, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '-' || c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// ident reads the identifier at the current position without consuming it
+func (p *exprParser) ident() string {
+	end := p.pos
+	for end < len(p.s) && isIdentChar(p.s[end]) {
+		end++
+	}
+	return p.s[p.pos:end]
+}
+
+// consume skips the keyword or the symbol if it is at the current position
+func (p *exprParser) consume(keyword, symbol string) bool {
+	p.skipSpace()
+	if strings.EqualFold(p.ident(), keyword) {
+		p.pos += len(keyword)
+		return true
+	}
+	if strings.HasPrefix(p.s[p.pos:], symbol) {
+		p.pos += len(symbol)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(c byte) error {
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *exprParser) parseOr() (Matcher, error) {
+	var terms orExpr
+	for {
+		m, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, m)
+		if !p.consume("or", "||") {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *exprParser) parseAnd() (Matcher, error) {
+	var terms andExpr
+	for {
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, m)
+		if !p.consume("and", "&&") {
+			break
+		}
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return terms, nil
+}
+
+func (p *exprParser) parseUnary() (Matcher, error) {
+	if p.consume("not", "!") {
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{m: m}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (Matcher, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end")
+	}
+	if p.s[p.pos] == '(' {
+		p.pos++
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return m, p.expect(')')
+	}
+	name := p.ident()
+	if name == "" {
+		return nil, p.errorf("expected a rule type")
+	}
+	ruleType, err := ParseType(name)
+	newFunc, ok := predicates[ruleType]
+	if err != nil || !ok {
+		return nil, p.errorf("unknown rule type %s", name)
+	}
+	p.pos += len(name)
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	data, err := p.parseData()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	m, err := newFunc(data)
+	if err != nil {
+		return nil, p.errorf("%s: %v", ruleType, err)
+	}
+	return m, nil
+}
+
+// parseData reads the data of a rule type, quoted or up to the closing parenthesis
+func (p *exprParser) parseData() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return "", p.errorf("unexpected end")
+	}
+	quote := p.s[p.pos]
+	if quote != '\'' && quote != '"' {
+		end := strings.IndexByte(p.s[p.pos:], ')')
+		if end < 0 {
+			return "", p.errorf("expected ')'")
+		}
+		data := strings.TrimSpace(p.s[p.pos : p.pos+end])
+		p.pos += end
+		return data, nil
+	}
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c == '\\' && p.pos+1 < len(p.s) && (p.s[p.pos+1] == quote || p.s[p.pos+1] == '\\'):
+			sb.WriteByte(p.s[p.pos+1])
+			p.pos += 2
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated quote")
+}
+
+// quoteArg quotes the data of a rule type for an expression if it is needed, the reverse of parseData
+func quoteArg(data string) string {
+	if data != "" && !strings.ContainsAny(data, "()'\"\\") && strings.TrimSpace(data) == data {
+		return data
+	}
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; {
+		case c == '\'':
+			sb.WriteString(`\'`)
+		case c == '\\' && (i+1 == len(data) || data[i+1] == '\'' || data[i+1] == '\\'):
+			sb.WriteString(`\\`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package rule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+)
+
+func testInput(t *testing.T, name, text string, groupedID int64) Input {
+	t.Helper()
+	msg := &tg.Message{Message: text, GroupedID: groupedID}
+	media := &tg.MessageMediaDocument{Document: &tg.Document{
+		Attributes: []tg.DocumentAttributeClass{&tg.DocumentAttributeFilename{FileName: name}},
+	}}
+	file, err := tfile.FromMediaMessage(media, nil, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Input{File: file}
+}
+
+func TestParseExpr(t *testing.T) {
+	video := testInput(t, "clip.mp4", "my draft", 0)
+	albumPhoto := testInput(t, "photo.jpg", "holiday", 1)
+
+	tests := []struct {
+		expr       string
+		video      bool
+		albumPhoto bool
+	}{
+		{expr: `FILENAME-REGEX('\.mp4$')`, video: true},
+		{expr: `filename-regex(\.jpg$) or IS-ALBUM(true)`, albumPhoto: true},
+		{expr: `not IS-ALBUM(true)`, video: true},
+		{expr: `FILENAME-REGEX('.') and not (IS-ALBUM(true) or MESSAGE-REGEX("draft"))`},
+		{expr: `MESSAGE-REGEX('(draft|holiday)') && !IS-ALBUM(false) || FILENAME-REGEX(mp4)`, video: true, albumPhoto: true},
+		{expr: `((MESSAGE-REGEX('it\'s') or MESSAGE-REGEX(day)))`, albumPhoto: true},
+	}
+	for _, tt := range tests {
+		m, err := ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", tt.expr, err)
+		}
+		// the string of an expression is parsed to the same expression
+		again, err := ParseExpr(m.String())
+		if err != nil || again.String() != m.String() {
+			t.Fatalf("the string of %s is not parsed back: %s, %v", tt.expr, m.String(), err)
+		}
+		for _, c := range []struct {
+			input Input
+			want  bool
+		}{{video, tt.video}, {albumPhoto, tt.albumPhoto}} {
+			got, err := again.Match(c.input)
+			if err != nil {
+				t.Fatalf("failed to match %s: %v", tt.expr, err)
+			}
+			if got != c.want {
+				t.Fatalf("%s on %s: got %v, want %v", tt.expr, c.input.File.Name(), got, c.want)
+			}
+		}
+	}
+
+	for _, expr := range []string{
+		"",
+		"FILENAME-REGEX('x'",
+		"FILENAME-REGEX('x') and",
+		"UNKNOWN(x)",
+		"EXPR(x)",
+		"FILENAME-REGEX('[')",
+		"(IS-ALBUM(true)",
+		"IS-ALBUM(true) IS-ALBUM(false)",
+		"MESSAGE-REGEX('x)",
+	} {
+		if _, err := ParseExpr(expr); !errors.Is(err, ErrInvalidExpr) {
+			t.Fatalf("expected ErrInvalidExpr for %q, got %v", expr, err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package rule
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+)
+
+// Input is what the rules are matched against
+type Input struct {
+	File tfile.TGFileMessage
+}
+
+func (in Input) message() *tg.Message {
+	if msg := in.File.Message(); msg != nil {
+		return msg
+	}
+	return &tg.Message{}
+}
+
+func (in Input) messageText() string {
+	return in.message().GetMessage()
+}
+
+func (in Input) isAlbum() bool {
+	return in.message().GroupedID != 0
+}
+
+// size returns the size of the file, the size of a photo is taken from its largest size
+func (in Input) size() int64 {
+	if size := in.File.Size(); size > 0 {
+		return size
+	}
+	photo, ok := in.photo()
+	if !ok {
+		return 0
+	}
+	var size int
+	for _, ps := range photo.Sizes {
+		switch ps := ps.(type) {
+		case *tg.PhotoSize:
+			size = max(size, ps.Size)
+		case *tg.PhotoSizeProgressive:
+			if len(ps.Sizes) > 0 {
+				size = max(size, ps.Sizes[len(ps.Sizes)-1])
+			}
+		}
+	}
+	return int64(size)
+}
+
+func (in Input) document() (*tg.Document, bool) {
+	media, ok := in.message().Media.(*tg.MessageMediaDocument)
+	if !ok {
+		return nil, false
+	}
+	return media.Document.AsNotEmpty()
+}
+
+func (in Input) photo() (*tg.Photo, bool) {
+	media, ok := in.message().Media.(*tg.MessageMediaPhoto)
+	if !ok {
+		return nil, false
+	}
+	return media.Photo.AsNotEmpty()
+}
+
+func (in Input) mimeType() string {
+	if doc, ok := in.document(); ok {
+		return doc.MimeType
+	}
+	if _, ok := in.photo(); ok {
+		return "image/jpeg"
+	}
+	return ""
+}
+
+func (in Input) extension() string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(in.File.Name()), "."))
+}
+
+func (in Input) mediaKind() MediaKind {
+	if _, ok := in.photo(); ok {
+		return KindPhoto
+	}
+	doc, ok := in.document()
+	if !ok {
+		return ""
+	}
+	kind := KindDocument
+	for _, attr := range doc.Attributes {
+		switch attr := attr.(type) {
+		case *tg.DocumentAttributeSticker:
+			return KindSticker
+		case *tg.DocumentAttributeAnimated:
+			return KindAnimation
+		case *tg.DocumentAttributeVideo:
+			if attr.RoundMessage {
+				kind = KindRound
+			} else {
+				kind = KindVideo
+			}
+		case *tg.DocumentAttributeAudio:
+			if attr.Voice {
+				kind = KindVoice
+			} else {
+				kind = KindAudio
+			}
+		}
+	}
+	return kind
+}
+
+// duration returns the duration of the video or audio, -1 if it has none
+func (in Input) duration() time.Duration {
+	doc, ok := in.document()
+	if !ok {
+		return -1
+	}
+	for _, attr := range doc.Attributes {
+		switch attr := attr.(type) {
+		case *tg.DocumentAttributeVideo:
+			return time.Duration(attr.Duration * float64(time.Second))
+		case *tg.DocumentAttributeAudio:
+			return time.Duration(attr.Duration) * time.Second
+		}
+	}
+	return -1
+}
+
+// photoSizeWH is implemented by the photo sizes which tell their width and height
+type photoSizeWH interface {
+	GetW() int
+	GetH() int
+}
+
+// resolution returns the shorter side of the video or photo, like 1080 for 1920x1080, 0 if it has none
+func (in Input) resolution() int {
+	if doc, ok := in.document(); ok {
+		for _, attr := range doc.Attributes {
+			if video, ok := attr.(*tg.DocumentAttributeVideo); ok {
+				return min(video.W, video.H)
+			}
+		}
+		return 0
+	}
+	if photo, ok := in.photo(); ok {
+		short := 0
+		for _, size := range photo.Sizes {
+			if size, ok := size.(photoSizeWH); ok {
+				short = max(short, min(size.GetW(), size.GetH()))
+			}
+		}
+		return short
+	}
+	return 0
+}
+
+// date returns the day the message is sent on, in the local time zone
+func (in Input) date() string {
+	date := in.message().GetDate()
+	if date == 0 {
+		return ""
+	}
+	return time.Unix(int64(date), 0).Format(time.DateOnly)
+}
+
+func peerID(peer tg.PeerClass) int64 {
+	switch peer := peer.(type) {
+	case *tg.PeerChannel:
+		return peer.ChannelID
+	case *tg.PeerUser:
+		return peer.UserID
+	case *tg.PeerChat:
+		return peer.ChatID
+	}
+	return 0
+}
+
+// chatIDs returns the chat the message is in and the chats it is forwarded from
+func (in Input) chatIDs() []int64 {
+	msg := in.message()
+	ids := []int64{peerID(msg.PeerID)}
+	if fwd, ok := msg.GetFwdFrom(); ok {
+		if from, ok := fwd.GetFromID(); ok {
+			ids = append(ids, peerID(from))
+		}
+		if saved, ok := fwd.GetSavedFromPeer(); ok {
+			ids = append(ids, peerID(saved))
+		}
+	}
+	return ids
+}
+
+// senderIDs returns the user who sent the message and the one who wrote it if it is forwarded
+func (in Input) senderIDs() []int64 {
+	msg := in.message()
+	var ids []int64
+	if from, ok := msg.GetFromID(); ok {
+		ids = append(ids, peerID(from))
+	} else if user, ok := msg.PeerID.(*tg.PeerUser); ok {
+		// the messages in a private chat have no sender
+		ids = append(ids, user.UserID)
+	}
+	if fwd, ok := msg.GetFwdFrom(); ok {
+		if from, ok := fwd.GetFromID(); ok {
+			if user, ok := from.(*tg.PeerUser); ok {
+				ids = append(ids, user.UserID)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,115 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/duke-git/lancet/v2/convertor"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+)
+
+// Matcher is the condition of a rule, a single rule type or an expression of them
+type Matcher interface {
+	Match(input Input) (bool, error)
+	String() string
+}
+
+// predicate adapts a RuleClass to a Matcher
+type predicate[T any] struct {
+	rule  RuleClass[T]
+	data  string
+	input func(Input) T
+}
+
+func (p predicate[T]) Match(input Input) (bool, error) {
+	return p.rule.Match(p.input(input))
+}
+
+func (p predicate[T]) String() string {
+	return fmt.Sprintf("%s(%s)", p.rule.Type(), quoteArg(p.data))
+}
+
+func newPredicate[T any](rule RuleClass[T], err error, data string, input func(Input) T) (Matcher, error) {
+	if err != nil {
+		return nil, err
+	}
+	return predicate[T]{rule: rule, data: data, input: input}, nil
+}
+
+// predicates creates the matchers of the rule types usable in an expression
+var predicates = map[RuleType]func(data string) (Matcher, error){
+	FileNameRegex: func(data string) (Matcher, error) {
+		r, err := NewRuleFileNameRegex("", "", data)
+		return newPredicate(r, err, data, func(in Input) tfile.TGFile { return in.File })
+	},
+	MessageRegex: func(data string) (Matcher, error) {
+		r, err := NewRuleMessageRegex("", "", data)
+		return newPredicate(r, err, data, Input.messageText)
+	},
+	IsAlbum: func(data string) (Matcher, error) {
+		matchAlbum, err := convertor.ToBool(data)
+		if err != nil {
+			matchAlbum = false
+		}
+		r, err := NewRuleMediaType("", "", matchAlbum)
+		return newPredicate(r, err, data, Input.isAlbum)
+	},
+	Size: func(data string) (Matcher, error) {
+		r, err := NewRuleSize("", "", data)
+		return newPredicate(r, err, data, Input.size)
+	},
+	MimeType: func(data string) (Matcher, error) {
+		r, err := NewRuleMimeType("", "", data)
+		return newPredicate(r, err, data, Input.mimeType)
+	},
+	Extension: func(data string) (Matcher, error) {
+		r, err := NewRuleExtension("", "", data)
+		return newPredicate(r, err, data, Input.extension)
+	},
+	Chat: func(data string) (Matcher, error) {
+		r, err := NewRuleChat("", "", data)
+		return newPredicate(r, err, data, Input.chatIDs)
+	},
+	Sender: func(data string) (Matcher, error) {
+		r, err := NewRuleSender("", "", data)
+		return newPredicate(r, err, data, Input.senderIDs)
+	},
+	Date: func(data string) (Matcher, error) {
+		r, err := NewRuleDate("", "", data)
+		return newPredicate(r, err, data, Input.date)
+	},
+	Duration: func(data string) (Matcher, error) {
+		r, err := NewRuleDuration("", "", data)
+		return newPredicate(r, err, data, Input.duration)
+	},
+	Resolution: func(data string) (Matcher, error) {
+		r, err := NewRuleResolution("", "", data)
+		return newPredicate(r, err, data, Input.resolution)
+	},
+	MediaKindType: func(data string) (Matcher, error) {
+		r, err := NewRuleMediaKind("", "", data)
+		return newPredicate(r, err, data, Input.mediaKind)
+	},
+}
+
+// NewMatcher creates the matcher of a rule with the type and data, it fails if the data is invalid for the type
+func NewMatcher(ruleType RuleType, data string) (Matcher, error) {
+	if ruleType == Expr {
+		return ParseExpr(data)
+	}
+	newFunc, ok := predicates[ruleType]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule type: %s", ruleType)
+	}
+	return newFunc(data)
+}
+
+// ParseType returns the rule type with the name, case insensitive
+func ParseType(name string) (RuleType, error) {
+	for _, t := range Values() {
+		if strings.EqualFold(t.String(), name) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown rule type: %s", name)
+}
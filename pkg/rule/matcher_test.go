@@ -0,0 +1,109 @@
+package rule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+)
+
+func TestPredicates(t *testing.T) {
+	date := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	msg := &tg.Message{
+		PeerID: &tg.PeerChannel{ChannelID: 1001},
+		Date:   int(date.Unix()),
+		FwdFrom: tg.MessageFwdHeader{
+			FromID: &tg.PeerUser{UserID: 42},
+		},
+	}
+	msg.FwdFrom.SetFlags()
+	msg.SetFlags()
+	msg.Media = &tg.MessageMediaDocument{Document: &tg.Document{
+		MimeType: "video/mp4",
+		Size:     600 * 1000 * 1000,
+		Attributes: []tg.DocumentAttributeClass{
+			&tg.DocumentAttributeFilename{FileName: "Clip.MP4"},
+			&tg.DocumentAttributeVideo{Duration: 754.5, W: 1920, H: 1080},
+		},
+	}}
+	file, err := tfile.FromMediaMessage(msg.Media, nil, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	video := Input{File: file}
+
+	photoMsg := &tg.Message{PeerID: &tg.PeerUser{UserID: 7}, Date: int(date.AddDate(0, 1, 0).Unix())}
+	photoMsg.Media = &tg.MessageMediaPhoto{Photo: &tg.Photo{Sizes: []tg.PhotoSizeClass{
+		&tg.PhotoSize{Type: "x", W: 800, H: 1280, Size: 200 * 1000},
+	}}}
+	photoFile, err := tfile.FromMediaMessage(photoMsg.Media, nil, photoMsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	photo := Input{File: photoFile}
+
+	tests := []struct {
+		ruleType RuleType
+		data     string
+		video    bool
+		photo    bool
+	}{
+		{Size, ">500MB", true, false},
+		{Size, "<=1MB", false, true},
+		{Size, "100KB..1GB", true, true},
+		{MimeType, "video/*", true, false},
+		{MimeType, "application/pdf, image/jpeg", false, true},
+		{Extension, "mp4,.mkv", true, false},
+		{Chat, "-1001001", true, false},
+		{Chat, "42", true, false},
+		{Sender, "42", true, false},
+		{Sender, "7", false, true},
+		{Date, "2024-05-01", true, false},
+		{Date, ">2024-05-01", false, true},
+		{Date, "2024-01-01..2024-12-31", true, true},
+		{Duration, ">10m", true, false},
+		{Duration, "..600", false, false},
+		{Resolution, ">=1080p", true, false},
+		{Resolution, "720..1920x1080", true, true},
+		{MediaKindType, "video,round", true, false},
+		{MediaKindType, "photo", false, true},
+	}
+	for _, tt := range tests {
+		m, err := NewMatcher(tt.ruleType, tt.data)
+		if err != nil {
+			t.Fatalf("failed to create %s(%s): %v", tt.ruleType, tt.data, err)
+		}
+		for _, c := range []struct {
+			input Input
+			want  bool
+		}{{video, tt.video}, {photo, tt.photo}} {
+			got, err := m.Match(c.input)
+			if err != nil {
+				t.Fatalf("failed to match %s: %v", m, err)
+			}
+			if got != c.want {
+				t.Fatalf("%s on %s: got %v, want %v", m, c.input.File.Name(), got, c.want)
+			}
+		}
+	}
+
+	for _, invalid := range []struct {
+		ruleType RuleType
+		data     string
+	}{
+		{Size, "big"},
+		{Size, "1GB..1MB"},
+		{MimeType, "video/["},
+		{Extension, " , "},
+		{Chat, "@channel"},
+		{Date, "01/05/2024"},
+		{Duration, ">long"},
+		{Resolution, "..hd"},
+		{MediaKindType, "movie"},
+	} {
+		if _, err := NewMatcher(invalid.ruleType, invalid.data); err == nil {
+			t.Fatalf("%s(%s) should be invalid", invalid.ruleType, invalid.data)
+		}
+	}
+}
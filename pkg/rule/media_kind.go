@@ -0,0 +1,69 @@
+package rule
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type MediaKind string
+
+const (
+	KindPhoto     MediaKind = "photo"
+	KindVideo     MediaKind = "video"
+	KindRound     MediaKind = "round" // video message
+	KindAudio     MediaKind = "audio"
+	KindVoice     MediaKind = "voice"
+	KindAnimation MediaKind = "animation" // GIF
+	KindSticker   MediaKind = "sticker"
+	KindDocument  MediaKind = "document" // any other file
+)
+
+func MediaKinds() []MediaKind {
+	return []MediaKind{KindPhoto, KindVideo, KindRound, KindAudio, KindVoice, KindAnimation, KindSticker, KindDocument}
+}
+
+var _ RuleClass[MediaKind] = (*RuleMediaKind)(nil)
+
+// RuleMediaKind matches the kind of the media against a comma separated list, e.g. "video,animation"
+type RuleMediaKind struct {
+	storInfo
+	kinds []MediaKind
+}
+
+func (r RuleMediaKind) Type() RuleType {
+	return MediaKindType
+}
+
+func (r RuleMediaKind) Match(input MediaKind) (bool, error) {
+	return slices.Contains(r.kinds, input), nil
+}
+
+func (r RuleMediaKind) StorageName() string {
+	return r.storName
+}
+
+func (r RuleMediaKind) StoragePath() string {
+	return r.storPath
+}
+
+func NewRuleMediaKind(storName, storPath, kinds string) (*RuleMediaKind, error) {
+	list := splitList(strings.ToLower(kinds))
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no media kind given")
+	}
+	mediaKinds := make([]MediaKind, 0, len(list))
+	for _, kind := range list {
+		if !slices.Contains(MediaKinds(), MediaKind(kind)) {
+			return nil, fmt.Errorf("invalid media kind %s, available: %v", kind, MediaKinds())
+		}
+		mediaKinds = append(mediaKinds, MediaKind(kind))
+	}
+	return &RuleMediaKind{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		kinds: mediaKinds,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package rule
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+var _ RuleClass[string] = (*RuleMimeType)(nil)
+
+// RuleMimeType matches the MIME type of the file against comma separated patterns, e.g. "video/*,application/pdf"
+type RuleMimeType struct {
+	storInfo
+	patterns []string
+}
+
+func (r RuleMimeType) Type() RuleType {
+	return MimeType
+}
+
+func (r RuleMimeType) Match(input string) (bool, error) {
+	input = strings.ToLower(input)
+	for _, pattern := range r.patterns {
+		if ok, _ := path.Match(pattern, input); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r RuleMimeType) StorageName() string {
+	return r.storName
+}
+
+func (r RuleMimeType) StoragePath() string {
+	return r.storPath
+}
+
+func NewRuleMimeType(storName, storPath, patterns string) (*RuleMimeType, error) {
+	list := splitList(strings.ToLower(patterns))
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no MIME type given")
+	}
+	for _, pattern := range list {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid MIME type pattern %s: %w", pattern, err)
+		}
+	}
+	return &RuleMimeType{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		patterns: list,
+	}, nil
+}
+
+var _ RuleClass[string] = (*RuleExtension)(nil)
+
+// RuleExtension matches the extension of the file name against a comma separated list, e.g. "pdf,epub"
+type RuleExtension struct {
+	storInfo
+	extensions []string
+}
+
+func (r RuleExtension) Type() RuleType {
+	return Extension
+}
+
+func (r RuleExtension) Match(input string) (bool, error) {
+	return slices.Contains(r.extensions, strings.ToLower(input)), nil
+}
+
+func (r RuleExtension) StorageName() string {
+	return r.storName
+}
+
+func (r RuleExtension) StoragePath() string {
+	return r.storPath
+}
+
+func NewRuleExtension(storName, storPath, extensions string) (*RuleExtension, error) {
+	list := splitList(strings.ToLower(extensions))
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no extension given")
+	}
+	for i, ext := range list {
+		list[i] = strings.TrimPrefix(ext, ".")
+	}
+	return &RuleExtension{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		extensions: list,
+	}, nil
+}
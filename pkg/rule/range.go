@@ -0,0 +1,80 @@
+package rule
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// valueRange is the range of a rule data like ">x", ">=x", "<x", "<=x", "x..y" including both ends, where either end may be omitted,
+// or "x" for the exact value
+type valueRange[T cmp.Ordered] struct {
+	min, max         T
+	hasMin, hasMax   bool
+	minExcl, maxExcl bool
+}
+
+func parseRange[T cmp.Ordered](s string, parse func(string) (T, error)) (valueRange[T], error) {
+	var r valueRange[T]
+	s = strings.TrimSpace(s)
+	var err error
+	switch {
+	case strings.HasPrefix(s, ">="):
+		r.hasMin = true
+		r.min, err = parse(strings.TrimSpace(s[2:]))
+	case strings.HasPrefix(s, ">"):
+		r.hasMin, r.minExcl = true, true
+		r.min, err = parse(strings.TrimSpace(s[1:]))
+	case strings.HasPrefix(s, "<="):
+		r.hasMax = true
+		r.max, err = parse(strings.TrimSpace(s[2:]))
+	case strings.HasPrefix(s, "<"):
+		r.hasMax, r.maxExcl = true, true
+		r.max, err = parse(strings.TrimSpace(s[1:]))
+	case strings.Contains(s, ".."):
+		minStr, maxStr, _ := strings.Cut(s, "..")
+		minStr, maxStr = strings.TrimSpace(minStr), strings.TrimSpace(maxStr)
+		if minStr == "" && maxStr == "" {
+			return r, fmt.Errorf("empty range: %s", s)
+		}
+		if minStr != "" {
+			r.hasMin = true
+			if r.min, err = parse(minStr); err != nil {
+				return r, err
+			}
+		}
+		if maxStr != "" {
+			r.hasMax = true
+			r.max, err = parse(maxStr)
+		}
+		if err == nil && r.hasMin && r.hasMax && r.min > r.max {
+			return r, fmt.Errorf("the start of the range is greater than the end: %s", s)
+		}
+	default:
+		r.hasMin, r.hasMax = true, true
+		r.min, err = parse(s)
+		r.max = r.min
+	}
+	return r, err
+}
+
+func (r valueRange[T]) contains(v T) bool {
+	if r.hasMin && (v < r.min || r.minExcl && v == r.min) {
+		return false
+	}
+	if r.hasMax && (v > r.max || r.maxExcl && v == r.max) {
+		return false
+	}
+	return true
+}
+
+// splitList splits the comma separated values of a rule data, the empty ones are dropped
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
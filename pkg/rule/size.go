@@ -0,0 +1,48 @@
+package rule
+
+import (
+	"github.com/dustin/go-humanize"
+)
+
+var _ RuleClass[int64] = (*RuleSize)(nil)
+
+// RuleSize matches the file size, e.g. ">500MB", "<=10MiB", "100MB..1GB"
+type RuleSize struct {
+	storInfo
+	size valueRange[int64]
+}
+
+func (r RuleSize) Type() RuleType {
+	return Size
+}
+
+func (r RuleSize) Match(input int64) (bool, error) {
+	return input > 0 && r.size.contains(input), nil
+}
+
+func (r RuleSize) StorageName() string {
+	return r.storName
+}
+
+func (r RuleSize) StoragePath() string {
+	return r.storPath
+}
+
+func parseSize(s string) (int64, error) {
+	n, err := humanize.ParseBytes(s)
+	return int64(n), err
+}
+
+func NewRuleSize(storName, storPath, sizeRange string) (*RuleSize, error) {
+	size, err := parseRange(sizeRange, parseSize)
+	if err != nil {
+		return nil, err
+	}
+	return &RuleSize{
+		storInfo: storInfo{
+			storName: storName,
+			storPath: storPath,
+		},
+		size: size,
+	}, nil
+}
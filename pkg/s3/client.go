@@ -1,6 +1,7 @@
 package s3
 
 import (
+	"bytes"
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
@@ -138,6 +139,64 @@ func (c *Client) Put(ctx context.Context, key string, r io.Reader, size int64) e
 	return nil
 }
 
+func (c *Client) Delete(ctx context.Context, key string) error {
+	url, err := c.buildURL(key)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+	if err := signRequest(req, c.region, c.accessKey, c.secretKey, hashSHA256(nil)); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("delete object failed: %s", resp.Status)
+	}
+	return nil
+}
+
+// Copy copies the object at srcKey to dstKey in the same bucket
+func (c *Client) Copy(ctx context.Context, srcKey, dstKey string) error {
+	dstURL, err := c.buildURL(dstKey)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "PUT", dstURL, nil)
+	if err != nil {
+		return err
+	}
+	source := (&url.URL{Path: "/" + c.bucket + "/" + srcKey}).EscapedPath()
+	req.Header.Set("x-amz-copy-source", source)
+	if err := signRequest(req, c.region, c.accessKey, c.secretKey, hashSHA256(nil)); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// a copy may fail after the 200 status line, the error is in the body then
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 || bytes.Contains(body, []byte("<Error>")) {
+		return fmt.Errorf("copy object failed: %s", resp.Status)
+	}
+	return nil
+}
+
 func (c *Client) buildURL(key string) (string, error) {
 	if c.pathStyle {
 		return fmt.Sprintf("%s/%s/%s", c.endpoint, c.bucket, key), nil
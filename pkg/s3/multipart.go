@@ -0,0 +1,319 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/duke-git/lancet/v2/retry"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// MinPartSize is the smallest part S3 accepts, except for the last part
+	MinPartSize = 5 << 20
+	// MaxParts is the most parts an upload can have
+	MaxParts = 10000
+
+	DefaultPartSize    = 16 << 20
+	DefaultConcurrency = 4
+)
+
+// CompletedPart is an uploaded part of a multipart upload
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// MultipartUpload is an in-progress multipart upload
+type MultipartUpload struct {
+	Key       string    `xml:"Key"`
+	UploadID  string    `xml:"UploadId"`
+	Initiated time.Time `xml:"Initiated"`
+}
+
+type initiateMultipartUploadResult struct {
+	UploadID string `xml:"UploadId"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+type listMultipartUploadsResult struct {
+	Uploads            []MultipartUpload `xml:"Upload"`
+	IsTruncated        bool              `xml:"IsTruncated"`
+	NextKeyMarker      string            `xml:"NextKeyMarker"`
+	NextUploadIDMarker string            `xml:"NextUploadIdMarker"`
+}
+
+// MultipartOptions configures UploadMultipart, zero values use the defaults
+type MultipartOptions struct {
+	PartSize    int64 // at least MinPartSize, raised to fit the size in MaxParts
+	Concurrency int   // parts uploaded at the same time, each one is buffered in memory
+	Retry       int   // attempts of each part
+}
+
+func (o MultipartOptions) withDefaults(size int64) MultipartOptions {
+	if o.PartSize <= 0 {
+		o.PartSize = DefaultPartSize
+	}
+	o.PartSize = max(o.PartSize, MinPartSize)
+	if size > 0 && (size+o.PartSize-1)/o.PartSize > MaxParts {
+		o.PartSize = (size + MaxParts - 1) / MaxParts
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultConcurrency
+	}
+	o.Retry = max(o.Retry, 1)
+	return o
+}
+
+// CreateMultipartUpload starts a multipart upload of key and returns its upload id
+func (c *Client) CreateMultipartUpload(ctx context.Context, key string) (string, error) {
+	body, err := c.doQuery(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, hashSHA256(nil))
+	if err != nil {
+		return "", fmt.Errorf("create multipart upload failed: %w", err)
+	}
+	var result initiateMultipartUploadResult
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse create multipart upload response: %w", err)
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("create multipart upload failed: empty upload id")
+	}
+	return result.UploadID, nil
+}
+
+// UploadPart uploads a part of the multipart upload and returns its ETag, partNumber starts from 1
+func (c *Client) UploadPart(ctx context.Context, key, uploadID string, partNumber int, data []byte) (string, error) {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadID},
+	}
+	req, err := c.newQueryRequest(ctx, http.MethodPut, key, query, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = int64(len(data))
+	if err := signRequest(req, c.region, c.accessKey, c.secretKey, hashSHA256(data)); err != nil {
+		return "", err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("upload part %d failed: %s", partNumber, resp.Status)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("upload part %d failed: empty etag", partNumber)
+	}
+	return etag, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the object
+func (c *Client) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	parts = slices.Clone(parts)
+	slices.SortFunc(parts, func(a, b CompletedPart) int { return a.PartNumber - b.PartNumber })
+	payload, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	body, err := c.doQuery(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, payload, hashSHA256(payload))
+	if err != nil {
+		return fmt.Errorf("complete multipart upload failed: %w", err)
+	}
+	// like copy, completing may fail after the 200 status line
+	if bytes.Contains(body, []byte("<Error>")) {
+		return fmt.Errorf("complete multipart upload failed: %s", body)
+	}
+	return nil
+}
+
+// AbortMultipartUpload aborts the multipart upload and frees its uploaded parts
+func (c *Client) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	if _, err := c.doQuery(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, hashSHA256(nil)); err != nil {
+		return fmt.Errorf("abort multipart upload failed: %w", err)
+	}
+	return nil
+}
+
+// ListMultipartUploads lists the in-progress multipart uploads of the keys with the prefix
+func (c *Client) ListMultipartUploads(ctx context.Context, prefix string) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	query := url.Values{"uploads": {""}, "prefix": {prefix}}
+	for {
+		body, err := c.doQuery(ctx, http.MethodGet, "", query, nil, hashSHA256(nil))
+		if err != nil {
+			return nil, fmt.Errorf("list multipart uploads failed: %w", err)
+		}
+		var result listMultipartUploadsResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse list multipart uploads response: %w", err)
+		}
+		uploads = append(uploads, result.Uploads...)
+		if !result.IsTruncated || result.NextKeyMarker == "" {
+			return uploads, nil
+		}
+		query.Set("key-marker", result.NextKeyMarker)
+		query.Set("upload-id-marker", result.NextUploadIDMarker)
+	}
+}
+
+// AbortStaleMultipartUploads aborts the uploads with the prefix started before olderThan ago,
+// they are left behind by crashed or killed uploads and are billed until aborted.
+func (c *Client) AbortStaleMultipartUploads(ctx context.Context, prefix string, olderThan time.Duration) (int, error) {
+	uploads, err := c.ListMultipartUploads(ctx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	aborted := 0
+	for _, upload := range uploads {
+		if time.Since(upload.Initiated) < olderThan {
+			continue
+		}
+		if err := c.AbortMultipartUpload(ctx, upload.Key, upload.UploadID); err != nil {
+			return aborted, err
+		}
+		aborted++
+	}
+	return aborted, nil
+}
+
+// UploadMultipart uploads the stream as a multipart upload, size may be -1 if unknown.
+// Parts are read one by one and uploaded in parallel with retries,
+// the upload is aborted if any part fails.
+// A stream that fits in one part is uploaded with a single PUT instead.
+func (c *Client) UploadMultipart(ctx context.Context, key string, r io.Reader, size int64, opts MultipartOptions) error {
+	opts = opts.withDefaults(size)
+	first := make([]byte, opts.PartSize)
+	n, err := io.ReadFull(r, first)
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		return c.Put(ctx, key, bytes.NewReader(first[:n]), int64(n))
+	default:
+		return fmt.Errorf("failed to read part 1: %w", err)
+	}
+	uploadID, err := c.CreateMultipartUpload(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu    sync.Mutex
+		parts []CompletedPart
+	)
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.SetLimit(opts.Concurrency)
+	readErr := func() error {
+		for partNumber := 1; ; partNumber++ {
+			if err := gctx.Err(); err != nil {
+				return nil // the error of the failed part is returned by Wait
+			}
+			buf, n, err := first, len(first), error(nil)
+			if partNumber > 1 {
+				buf = make([]byte, opts.PartSize)
+				n, err = io.ReadFull(r, buf)
+			}
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return fmt.Errorf("failed to read part %d: %w", partNumber, err)
+			}
+			if partNumber > MaxParts {
+				return fmt.Errorf("too many parts, the part size %d is too small for the stream", opts.PartSize)
+			}
+			data := buf[:n]
+			eg.Go(func() error {
+				var etag string
+				err := retry.Retry(func() error {
+					var err error
+					etag, err = c.UploadPart(gctx, key, uploadID, partNumber, data)
+					return err
+				}, retry.RetryTimes(uint(opts.Retry)), retry.Context(gctx))
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				parts = append(parts, CompletedPart{PartNumber: partNumber, ETag: etag})
+				mu.Unlock()
+				return nil
+			})
+			if err != nil { // EOF or ErrUnexpectedEOF, this was the last part
+				return nil
+			}
+		}
+	}()
+	err = eg.Wait()
+	if readErr != nil {
+		err = readErr
+	}
+	if err == nil {
+		err = c.CompleteMultipartUpload(ctx, key, uploadID, parts)
+	}
+	if err != nil {
+		// abort even if ctx is canceled, or the uploaded parts are kept
+		abortCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		if abortErr := c.AbortMultipartUpload(abortCtx, key, uploadID); abortErr != nil {
+			return fmt.Errorf("%w (and %w)", err, abortErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (c *Client) newQueryRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u, err := c.buildURL(key)
+	if err != nil {
+		return nil, err
+	}
+	// the encoded query is sorted by key, as the signature requires
+	return http.NewRequestWithContext(ctx, method, u+"?"+query.Encode(), body)
+}
+
+// doQuery sends a signed request with the query and payload, and returns the response body
+func (c *Client) doQuery(ctx context.Context, method, key string, query url.Values, payload []byte, payloadHash string) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := c.newQueryRequest(ctx, method, key, query, body)
+	if err != nil {
+		return nil, err
+	}
+	if err := signRequest(req, c.region, c.accessKey, c.secretKey, payloadHash); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return respBody, nil
+}
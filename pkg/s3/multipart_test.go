@@ -0,0 +1,148 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+func newFakeClient(t *testing.T) (*Client, *s3mem.Backend) {
+	t.Helper()
+	backend := s3mem.New()
+	ts := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(ts.Close)
+	if err := backend.CreateBucket("test-bucket"); err != nil {
+		t.Fatalf("failed to create fake bucket: %v", err)
+	}
+	client, err := NewClient(&Config{
+		Endpoint:        ts.URL,
+		Region:          "us-east-1",
+		BucketName:      "test-bucket",
+		AccessKeyID:     "test-access-key",
+		SecretAccessKey: "test-secret",
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client, backend
+}
+
+func readObject(t *testing.T, backend *s3mem.Backend, key string) []byte {
+	t.Helper()
+	obj, err := backend.GetObject("test-bucket", key, nil)
+	if err != nil {
+		t.Fatalf("GetObject %s failed: %v", key, err)
+	}
+	defer obj.Contents.Close()
+	data, err := io.ReadAll(obj.Contents)
+	if err != nil {
+		t.Fatalf("read object %s failed: %v", key, err)
+	}
+	return data
+}
+
+func TestUploadMultipart(t *testing.T) {
+	client, backend := newFakeClient(t)
+	ctx := t.Context()
+
+	content := make([]byte, 2*MinPartSize+12345)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		data []byte
+		size int64
+	}{
+		{name: "unknown size", key: "multi/unknown.bin", data: content, size: -1},
+		{name: "known size", key: "multi/known.bin", data: content, size: int64(len(content))},
+		{name: "exact parts", key: "multi/exact.bin", data: content[:2*MinPartSize], size: -1},
+		{name: "empty", key: "multi/empty.bin", data: nil, size: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a one byte reader checks that short reads still fill the parts
+			r := iotest.OneByteReader(bytes.NewReader(tt.data))
+			err := client.UploadMultipart(ctx, tt.key, r, tt.size, MultipartOptions{PartSize: MinPartSize, Concurrency: 2})
+			if err != nil {
+				t.Fatalf("UploadMultipart failed: %v", err)
+			}
+			if got := readObject(t, backend, tt.key); !bytes.Equal(got, tt.data) {
+				t.Fatalf("uploaded content mismatch, got %d bytes, want %d", len(got), len(tt.data))
+			}
+		})
+	}
+
+	uploads, err := client.ListMultipartUploads(ctx, "multi/")
+	if err != nil {
+		t.Fatalf("ListMultipartUploads failed: %v", err)
+	}
+	if len(uploads) != 0 {
+		t.Fatalf("completed uploads should not be listed, got %d", len(uploads))
+	}
+}
+
+func TestUploadMultipartAbortsOnError(t *testing.T) {
+	client, _ := newFakeClient(t)
+	ctx := t.Context()
+
+	r := io.MultiReader(bytes.NewReader(make([]byte, MinPartSize+1)), iotest.ErrReader(io.ErrClosedPipe))
+	if err := client.UploadMultipart(ctx, "broken.bin", r, -1, MultipartOptions{PartSize: MinPartSize}); err == nil {
+		t.Fatalf("UploadMultipart should fail when the reader fails")
+	}
+	if client.Exists(ctx, "broken.bin") {
+		t.Fatalf("failed upload should not create the object")
+	}
+	uploads, err := client.ListMultipartUploads(ctx, "")
+	if err != nil {
+		t.Fatalf("ListMultipartUploads failed: %v", err)
+	}
+	if len(uploads) != 0 {
+		t.Fatalf("failed upload should be aborted, got %d uploads", len(uploads))
+	}
+}
+
+func TestAbortStaleMultipartUploads(t *testing.T) {
+	client, _ := newFakeClient(t)
+	ctx := context.Background()
+
+	if _, err := client.CreateMultipartUpload(ctx, "base/stale.bin"); err != nil {
+		t.Fatalf("CreateMultipartUpload failed: %v", err)
+	}
+	if _, err := client.CreateMultipartUpload(ctx, "other/kept.bin"); err != nil {
+		t.Fatalf("CreateMultipartUpload failed: %v", err)
+	}
+
+	aborted, err := client.AbortStaleMultipartUploads(ctx, "base/", time.Hour)
+	if err != nil {
+		t.Fatalf("AbortStaleMultipartUploads failed: %v", err)
+	}
+	if aborted != 0 {
+		t.Fatalf("recent uploads should not be aborted, got %d", aborted)
+	}
+
+	aborted, err = client.AbortStaleMultipartUploads(ctx, "base/", 0)
+	if err != nil {
+		t.Fatalf("AbortStaleMultipartUploads failed: %v", err)
+	}
+	if aborted != 1 {
+		t.Fatalf("expected 1 aborted upload, got %d", aborted)
+	}
+	uploads, err := client.ListMultipartUploads(ctx, "")
+	if err != nil {
+		t.Fatalf("ListMultipartUploads failed: %v", err)
+	}
+	if len(uploads) != 1 || uploads[0].Key != "other/kept.bin" {
+		t.Fatalf("only the upload outside the prefix should be left, got %+v", uploads)
+	}
+}
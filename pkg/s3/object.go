@@ -0,0 +1,95 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Object is an object listed in a bucket
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+}
+
+type listObjectsV2Result struct {
+	Contents              []Object `xml:"Contents"`
+	CommonPrefixes        []string `xml:"CommonPrefixes>Prefix"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// ListObjects lists the objects with the prefix, following all the pages.
+// With a delimiter, the keys containing it after the prefix are grouped into the returned common prefixes,
+// which are the directories when the delimiter is "/".
+func (c *Client) ListObjects(ctx context.Context, prefix, delimiter string) ([]Object, []string, error) {
+	var (
+		objects  []Object
+		prefixes []string
+	)
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	if delimiter != "" {
+		query.Set("delimiter", delimiter)
+	}
+	for {
+		body, err := c.doQuery(ctx, http.MethodGet, "", query, nil, hashSHA256(nil))
+		if err != nil {
+			return nil, nil, fmt.Errorf("list objects failed: %w", err)
+		}
+		var result listObjectsV2Result
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse list objects response: %w", err)
+		}
+		objects = append(objects, result.Contents...)
+		prefixes = append(prefixes, result.CommonPrefixes...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, prefixes, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// GetObject reads the object from offset, length <= 0 reads to the end.
+// It returns the body and its length, which is -1 if unknown.
+func (c *Client) GetObject(ctx context.Context, key string, offset, length int64) (io.ReadCloser, int64, error) {
+	objURL, err := c.buildURL(key)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", objURL, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset > 0 || length > 0 {
+		rng := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+		if length > 0 {
+			rng += strconv.FormatInt(offset+length-1, 10)
+		}
+		req.Header.Set("Range", rng)
+	}
+	if err := signRequest(req, c.region, c.accessKey, c.secretKey, hashSHA256(nil)); err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("get object failed: %s", resp.Status)
+	}
+	// a server ignoring the range would send the whole object
+	if req.Header.Get("Range") != "" && resp.Header.Get("Content-Range") == "" {
+		resp.Body.Close()
+		return nil, 0, fmt.Errorf("get object failed: range not satisfied")
+	}
+	return resp.Body, resp.ContentLength, nil
+}
@@ -0,0 +1,68 @@
+package s3
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestListAndGetObject(t *testing.T) {
+	client, _ := newFakeClient(t)
+	ctx := t.Context()
+
+	for _, key := range []string{"dir/a.txt", "dir/sub/b.txt", "dir/sub/c.txt", "other.txt"} {
+		if err := client.Put(ctx, key, bytes.NewReader([]byte("content of "+key)), -1); err != nil {
+			t.Fatalf("Put %s failed: %v", key, err)
+		}
+	}
+
+	objects, prefixes, err := client.ListObjects(ctx, "dir/", "/")
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(objects) != 1 || objects[0].Key != "dir/a.txt" || objects[0].Size != int64(len("content of dir/a.txt")) {
+		t.Fatalf("unexpected objects: %+v", objects)
+	}
+	if !slices.Equal(prefixes, []string{"dir/sub/"}) {
+		t.Fatalf("unexpected common prefixes: %v", prefixes)
+	}
+
+	objects, prefixes, err = client.ListObjects(ctx, "dir/", "")
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(objects) != 3 || len(prefixes) != 0 {
+		t.Fatalf("listing without delimiter should return all the keys, got %+v %v", objects, prefixes)
+	}
+
+	tests := []struct {
+		name           string
+		offset, length int64
+		want           string
+	}{
+		{name: "whole", want: "content of other.txt"},
+		{name: "from offset", offset: 11, want: "other.txt"},
+		{name: "range", offset: 11, length: 5, want: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rc, size, err := client.GetObject(ctx, "other.txt", tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("GetObject failed: %v", err)
+			}
+			defer rc.Close()
+			data, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("read failed: %v", err)
+			}
+			if string(data) != tt.want || size != int64(len(tt.want)) {
+				t.Fatalf("got %q (size %d), want %q", data, size, tt.want)
+			}
+		})
+	}
+
+	if _, _, err := client.GetObject(ctx, "missing.txt", 0, 0); err == nil {
+		t.Fatalf("GetObject of a missing key should fail")
+	}
+}
@@ -0,0 +1,88 @@
+package storagetypes
+
+import (
+	"context"
+	"sync"
+)
+
+// MemberResult is the result of saving a file to a member of a mirror storage
+type MemberResult struct {
+	StorageName string
+	Err         error
+}
+
+// MirrorSave is a file saved to a mirror storage and the results of its members
+type MirrorSave struct {
+	MirrorName  string
+	StoragePath string
+	Results     []MemberResult
+}
+
+// MirrorReport collects the saves to mirror storages made with a context, so the results can be shown to the user
+type MirrorReport struct {
+	mu    sync.Mutex
+	saves []MirrorSave
+}
+
+type mirrorReportKey struct{}
+
+// WithMirrorReport returns a ctx collecting the saves to mirror storages into the returned report
+func WithMirrorReport(ctx context.Context) (context.Context, *MirrorReport) {
+	report := &MirrorReport{}
+	return context.WithValue(ctx, mirrorReportKey{}, report), report
+}
+
+// MirrorReportFromContext returns the report set by WithMirrorReport, nil if not set
+func MirrorReportFromContext(ctx context.Context) *MirrorReport {
+	report, _ := ctx.Value(mirrorReportKey{}).(*MirrorReport)
+	return report
+}
+
+// Add records the save, replacing the earlier save to the same path, which is retried
+func (r *MirrorReport) Add(save MirrorSave) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, s := range r.saves {
+		if s.MirrorName == save.MirrorName && s.StoragePath == save.StoragePath {
+			r.saves[i] = save
+			return
+		}
+	}
+	r.saves = append(r.saves, save)
+}
+
+// MemberSummary is the results of a mirror member over all the saves of a report
+type MemberSummary struct {
+	StorageName string
+	Saved       int
+	Total       int
+	LastErr     error
+}
+
+// Summary returns the results of each member in the order they were first saved to, nil for a nil report
+func (r *MirrorReport) Summary() []MemberSummary {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var summaries []MemberSummary
+	index := make(map[string]int)
+	for _, save := range r.saves {
+		for _, result := range save.Results {
+			i, ok := index[result.StorageName]
+			if !ok {
+				i = len(summaries)
+				index[result.StorageName] = i
+				summaries = append(summaries, MemberSummary{StorageName: result.StorageName})
+			}
+			summaries[i].Total++
+			if result.Err != nil {
+				summaries[i].LastErr = result.Err
+			} else {
+				summaries[i].Saved++
+			}
+		}
+	}
+	return summaries
+}
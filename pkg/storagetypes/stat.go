@@ -0,0 +1,26 @@
+package storagetypes
+
+import "errors"
+
+// StatUnknown is the value of the fields of StorageStat which the storage can not tell
+const StatUnknown int64 = -1
+
+// ErrStatNotSupported is returned by the storages which wrap another storage when it can not report its capacity
+var ErrStatNotSupported = errors.New("storage does not support reporting its capacity")
+
+// StorageStat is the capacity of a storage in bytes
+type StorageStat struct {
+	Total int64 `json:"total"`
+	Used  int64 `json:"used"`
+	Free  int64 `json:"free"` // the space the bot can use, it may be less than Total - Used
+}
+
+// NewStorageStat returns a StorageStat with the fields unknown, the ones which are not set stay StatUnknown
+func NewStorageStat() StorageStat {
+	return StorageStat{Total: StatUnknown, Used: StatUnknown, Free: StatUnknown}
+}
+
+// FreeKnown reports whether the free space is known
+func (s StorageStat) FreeKnown() bool {
+	return s.Free >= 0
+}
@@ -1,8 +1,10 @@
 package tcbdata
 
 import (
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/tasktype"
 	"github.com/kiss2u/SaveAny-Bot/pkg/parser"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
 	"github.com/kiss2u/SaveAny-Bot/pkg/telegraph"
 	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
 )
@@ -12,6 +14,31 @@ const (
 	TypeSetDefault = "setdefault"
 	TypeConfig     = "config"
 	TypeCancel     = "cancel"
+	TypeHistory    = "history"
+	TypeTask       = "task"
+	TypeConflict   = "conflict"
+	TypeBrowse     = "browse"
+)
+
+// Views and actions of the task control buttons, the callback data is "task <view> <action> [task_id]"
+const (
+	TaskViewRunning = "running"
+	TaskViewQueued  = "queued"
+
+	TaskActionPause   = "pause"
+	TaskActionResume  = "resume"
+	TaskActionCancel  = "cancel"
+	TaskActionTop     = "top"
+	TaskActionUp      = "up"
+	TaskActionDown    = "down"
+	TaskActionRefresh = "refresh"
+)
+
+// Actions on the file selected in a storage browser
+const (
+	BrowseActionSend     = "send"
+	BrowseActionInfo     = "info"
+	BrowseActionTransfer = "transfer"
 )
 
 // type TaskDataTGFiles struct {
@@ -34,6 +61,7 @@ type Add struct {
 	SelectedStorName string
 	DirID            uint
 	SettedDir        bool
+	Conflict         conflict.Policy // overrides the conflict policy of the selected storage, empty to use it
 	// tfiles
 	Files   []tfile.TGFileMessage
 	AsBatch bool
@@ -54,9 +82,29 @@ type Add struct {
 	TransferSourceStorName string
 	TransferSourcePath     string
 	TransferFiles          []string // file paths relative to source storage
+	TransferMove           bool     // delete the source files after transferring
 }
 
 type SetDefaultStorage struct {
 	StorageName string
 	DirID       uint
 }
+
+// History is the page and filter of a task history message
+type History struct {
+	Page        int
+	Status      string
+	TaskType    string
+	StorageName string
+	Tag         string
+}
+
+// Browse is the state of a storage file browser message,
+// the directory page is shown if File is nil, otherwise the actions of the file.
+type Browse struct {
+	StorageName string
+	DirPath     string
+	Page        int
+	File        *storagetypes.FileInfo
+	Action      string
+}
@@ -143,3 +143,19 @@ func FromMediaMessage(media tg.MessageMediaClass, client downloader.Client, msg
 		message:  msg,
 	}, nil
 }
+
+// Key returns the identity of the file on Telegram, it stays the same when the file is forwarded.
+// Empty if the location is not a document or photo.
+func Key(f TGFile) string {
+	switch loc := f.Location().(type) {
+	case *tg.InputDocumentFileLocation:
+		if loc.ThumbSize != "" {
+			return fmt.Sprintf("tgdoc:%d:%s", loc.ID, loc.ThumbSize)
+		}
+		return fmt.Sprintf("tgdoc:%d", loc.ID)
+	case *tg.InputPhotoFileLocation:
+		return fmt.Sprintf("tgphoto:%d:%s", loc.ID, loc.ThumbSize)
+	default:
+		return ""
+	}
+}
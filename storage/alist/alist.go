@@ -9,10 +9,10 @@ import (
 	"net/http"
 	"net/url"
 	"path"
-	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	config "github.com/kiss2u/SaveAny-Bot/config/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
@@ -103,21 +103,16 @@ func (a *Alist) Name() string {
 }
 
 func (a *Alist) Save(ctx context.Context, reader io.Reader, storagePath string) error {
+	reader = bandwidth.UploadReader(ctx, a.Name(), reader)
 	a.logger.Infof("Saving file to %s", storagePath)
 	storagePath = a.JoinStoragePath(storagePath)
-	ext := path.Ext(storagePath)
-	base := strings.TrimSuffix(storagePath, ext)
-	candidate := storagePath
-	for i := 1; a.Exists(ctx, candidate); i++ {
-		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
-	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPut, a.baseURL+"/api/fs/put", reader)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Authorization", a.token)
-	req.Header.Set("File-Path", url.PathEscape(candidate))
+	req.Header.Set("File-Path", url.PathEscape(storagePath))
 	req.Header.Set("Content-Type", "application/octet-stream")
 	if length := ctx.Value(ctxkey.ContentLength); length != nil {
 		length, ok := length.(int64)
@@ -222,7 +217,7 @@ func (a *Alist) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.F
 	a.logger.Debugf("Listing files in directory: %s", dirPath)
 
 	reqBody := fsListRequest{
-		Path:     dirPath,
+		Path:     a.JoinStoragePath(dirPath),
 		Password: "",
 		Page:     1,
 		PerPage:  0, // 0 means all files
@@ -299,6 +294,7 @@ func (a *Alist) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, i
 	a.logger.Debugf("Opening file: %s", filePath)
 
 	// First, get file info to get the raw_url
+	filePath = a.JoinStoragePath(filePath)
 	reqBody := map[string]any{
 		"path":     filePath,
 		"password": "",
@@ -369,3 +365,125 @@ func (a *Alist) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, i
 	a.logger.Debugf("Opened file %s, size: %d bytes", filePath, getResp.Data.Size)
 	return downloadResp.Body, getResp.Data.Size, nil
 }
+
+// Delete implements StorageDeletable interface
+func (a *Alist) Delete(ctx context.Context, filePath string) error {
+	filePath = a.JoinStoragePath(filePath)
+	a.logger.Infof("Deleting file %s", filePath)
+	return a.doFsRequest(ctx, "/api/fs/remove", fsRemoveRequest{
+		Dir:   path.Dir(filePath),
+		Names: []string{path.Base(filePath)},
+	})
+}
+
+// Move implements StorageMovable interface
+func (a *Alist) Move(ctx context.Context, srcPath, dstPath string) error {
+	src := a.JoinStoragePath(srcPath)
+	dst := a.JoinStoragePath(dstPath)
+	a.logger.Infof("Moving file %s to %s", src, dst)
+	// alist moves files by name between directories, so rename in place first
+	if path.Base(src) != path.Base(dst) {
+		if err := a.doFsRequest(ctx, "/api/fs/rename", fsRenameRequest{
+			Path: src,
+			Name: path.Base(dst),
+		}); err != nil {
+			return err
+		}
+		src = path.Join(path.Dir(src), path.Base(dst))
+	}
+	if path.Dir(src) == path.Dir(dst) {
+		return nil
+	}
+	if err := a.doFsRequest(ctx, "/api/fs/mkdir", fsMkdirRequest{Path: path.Dir(dst)}); err != nil {
+		return err
+	}
+	return a.doFsRequest(ctx, "/api/fs/move", fsMoveRequest{
+		SrcDir: path.Dir(src),
+		DstDir: path.Dir(dst),
+		Names:  []string{path.Base(dst)},
+	})
+}
+
+// MkDir implements StorageDirMaker interface
+func (a *Alist) MkDir(ctx context.Context, dirPath string) error {
+	return a.doFsRequest(ctx, "/api/fs/mkdir", fsMkdirRequest{Path: a.JoinStoragePath(dirPath)})
+}
+
+// doFsRequest posts the body to the alist fs api and checks the code of the response
+func (a *Alist) doFsRequest(ctx context.Context, api string, body any) error {
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+api, bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", a.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to request %s: %s", api, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	var fsResp fsResponse
+	if err := json.Unmarshal(data, &fsResp); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if fsResp.Code != http.StatusOK {
+		return fmt.Errorf("failed to request %s: %d, %s", api, fsResp.Code, fsResp.Message)
+	}
+	return nil
+}
+
+// Stat implements StorageStatable interface.
+// The capacity is known only if the server reports the details of the mount point of the base path, otherwise it only checks the server is reachable.
+func (a *Alist) Stat(ctx context.Context) (storagetypes.StorageStat, error) {
+	bodyBytes, err := json.Marshal(map[string]any{
+		"path":     a.JoinStoragePath(""),
+		"password": "",
+	})
+	if err != nil {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/api/fs/get", bytes.NewBuffer(bodyBytes))
+	if err != nil {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", a.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to get base path info: %s", resp.Status)
+	}
+
+	var getResp fsGetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&getResp); err != nil {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to unmarshal get response: %w", err)
+	}
+	if getResp.Code != http.StatusOK {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to get base path info: %d, %s", getResp.Code, getResp.Message)
+	}
+
+	stat := storagetypes.NewStorageStat()
+	if details := getResp.Data.MountDetails; details != nil && details.TotalSpace > 0 {
+		stat.Total = details.TotalSpace
+		stat.Free = details.FreeSpace
+		stat.Used = details.TotalSpace - details.FreeSpace
+	}
+	return stat, nil
+}
@@ -57,6 +57,11 @@ type fsGetResponse struct {
 		Type     int    `json:"type"`
 		RawURL   string `json:"raw_url"`
 		Provider string `json:"provider"`
+		// only reported by the servers which support it, like OpenList, for the mount points
+		MountDetails *struct {
+			TotalSpace int64 `json:"total_space"`
+			FreeSpace  int64 `json:"free_space"`
+		} `json:"mount_details"`
 	} `json:"data"`
 }
 
@@ -89,3 +94,28 @@ type fsListResponse struct {
 		Provider string `json:"provider"`
 	} `json:"data"`
 }
+
+type fsResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type fsRemoveRequest struct {
+	Dir   string   `json:"dir"`
+	Names []string `json:"names"`
+}
+
+type fsRenameRequest struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+type fsMoveRequest struct {
+	SrcDir string   `json:"src_dir"`
+	DstDir string   `json:"dst_dir"`
+	Names  []string `json:"names"`
+}
+
+type fsMkdirRequest struct {
+	Path string `json:"path"`
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/rs/xid"
+)
+
+// ErrFileExists is returned by Save when the file already exists and the conflict policy is fail
+var ErrFileExists = errors.New("file already exists")
+
+// SkippedError tells that the file was not saved because it already exists in the storage
+type SkippedError struct {
+	StorageName string
+	StoragePath string
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("file already exists at [%s]:%s", e.StorageName, e.StoragePath)
+}
+
+// WithConflictPolicy overrides the conflict policy of the storages for the saves made with the returned ctx
+func WithConflictPolicy(ctx context.Context, policy conflict.Policy) context.Context {
+	return context.WithValue(ctx, ctxkey.ConflictPolicy, policy)
+}
+
+// ConflictPolicyFromContext returns the policy set by WithConflictPolicy, empty if not set
+func ConflictPolicyFromContext(ctx context.Context) conflict.Policy {
+	policy, _ := ctx.Value(ctxkey.ConflictPolicy).(conflict.Policy)
+	return policy
+}
+
+// GetConflictPolicy returns the conflict policy for saving to the storage,
+// the override in ctx first, then the conflict option of the storage config.
+func GetConflictPolicy(ctx context.Context, stor Storage) conflict.Policy {
+	if policy := ConflictPolicyFromContext(ctx); policy != "" {
+		return policy
+	}
+	if cfg := config.C().GetStorageByName(stor.Name()); cfg != nil && cfg.GetConflict() != "" {
+		if policy, err := conflict.ParsePolicy(cfg.GetConflict()); err == nil {
+			return policy
+		}
+	}
+	return conflict.Default
+}
+
+// ResolveConflict returns the path to save the file to according to the conflict policy,
+// skip is true if the file should not be saved. size is the size of the file to save, <= 0 if unknown.
+func ResolveConflict(ctx context.Context, stor Storage, storagePath string, size int64) (target string, skip bool, err error) {
+	policy := GetConflictPolicy(ctx, stor)
+	if policy == conflict.Overwrite {
+		return storagePath, false, nil
+	}
+	if !FileExists(ctx, stor, storagePath) {
+		return storagePath, false, nil
+	}
+	switch policy {
+	case conflict.Skip:
+		return storagePath, true, nil
+	case conflict.Fail:
+		return "", false, fmt.Errorf("%w: [%s]:%s", ErrFileExists, stor.Name(), storagePath)
+	case conflict.CompareSizeThenSkip:
+		// keep both files if they differ or the sizes are unknown
+		if existing, ok := fileSize(ctx, stor, storagePath); ok && size > 0 && existing == size {
+			return storagePath, true, nil
+		}
+	}
+	return uniquePath(ctx, stor, storagePath), false, nil
+}
+
+// Save saves the file to the storage applying its conflict policy, use it instead of calling Storage.Save directly.
+// It returns the path the file is saved to, which is empty if the file is skipped.
+func Save(ctx context.Context, stor Storage, r io.Reader, storagePath string) (string, error) {
+	target, skip, err := ResolveConflict(ctx, stor, storagePath, contentLength(ctx, r))
+	if err != nil {
+		return "", err
+	}
+	if skip {
+		log.FromContext(ctx).Infof("Skipping existing file [%s]:%s", stor.Name(), storagePath)
+		// drain the reader so that the writer of a pipe does not block
+		if _, ok := r.(io.Seeker); !ok {
+			if _, err := io.Copy(io.Discard, r); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+	if err := stor.Save(ctx, r, target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// uniquePath appends _N to the name until no file exists at the path
+func uniquePath(ctx context.Context, stor Storage, storagePath string) string {
+	ext := path.Ext(storagePath)
+	base := strings.TrimSuffix(storagePath, ext)
+	for i := 1; i <= 100; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if !FileExists(ctx, stor, candidate) {
+			return candidate
+		}
+	}
+	log.FromContext(ctx).Warnf("Too many attempts to find a unique filename for %s", storagePath)
+	return fmt.Sprintf("%s_%s%s", base, xid.New().String(), ext)
+}
+
+// fileSize returns the size of the saved file by listing its directory, ok is false if the storage is not listable
+func fileSize(ctx context.Context, stor Storage, storagePath string) (size int64, ok bool) {
+	listable, ok := stor.(StorageListable)
+	if !ok {
+		return 0, false
+	}
+	files, err := listable.ListFiles(ctx, path.Dir(storagePath))
+	if err != nil {
+		return 0, false
+	}
+	name := path.Base(storagePath)
+	for _, file := range files {
+		if !file.IsDir && file.Name == name {
+			return file.Size, true
+		}
+	}
+	return 0, false
+}
+
+// contentLength returns the size of the content to save, -1 if unknown
+func contentLength(ctx context.Context, r io.Reader) int64 {
+	if length, ok := ctx.Value(ctxkey.ContentLength).(int64); ok && length > 0 {
+		return length
+	}
+	switch r := r.(type) {
+	case *os.File:
+		if stat, err := r.Stat(); err == nil {
+			return stat.Size()
+		}
+	case interface{ Len() int }: // bytes.Reader, strings.Reader, bytes.Buffer
+		return int64(r.Len())
+	}
+	return -1
+}
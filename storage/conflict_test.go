@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	storcfg "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/conflict"
+)
+
+func TestSaveConflict(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	stor, err := NewStorage(ctx, &storcfg.LocalStorageConfig{
+		BaseConfig: storcfg.BaseConfig{Name: "test-local", Type: "local", Enable: true},
+		BasePath:   dir,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+	save := func(policy conflict.Policy, content string) (string, error) {
+		return Save(WithConflictPolicy(ctx, policy), stor, strings.NewReader(content), "a/file.txt")
+	}
+
+	if p, err := Save(ctx, stor, strings.NewReader("first"), "a/file.txt"); err != nil || p != "a/file.txt" {
+		t.Fatalf("unexpected result of first save: %q, %v", p, err)
+	}
+
+	// the default policy renames
+	if p, err := Save(ctx, stor, strings.NewReader("second"), "a/file.txt"); err != nil || p != "a/file_1.txt" {
+		t.Fatalf("expected rename to a/file_1.txt, got %q, %v", p, err)
+	}
+	if read("a/file.txt") != "first" || read("a/file_1.txt") != "second" {
+		t.Fatalf("renamed save should keep the existing file")
+	}
+
+	if p, err := save(conflict.Skip, "third"); err != nil || p != "" {
+		t.Fatalf("expected skip, got %q, %v", p, err)
+	}
+	if read("a/file.txt") != "first" {
+		t.Fatalf("skipped save should not change the existing file")
+	}
+
+	if _, err := save(conflict.Fail, "third"); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected ErrFileExists, got %v", err)
+	}
+
+	// same size as "first"
+	if p, err := save(conflict.CompareSizeThenSkip, "fifth"); err != nil || p != "" {
+		t.Fatalf("expected skip for the same size, got %q, %v", p, err)
+	}
+	if p, err := save(conflict.CompareSizeThenSkip, "different size"); err != nil || p != "a/file_2.txt" {
+		t.Fatalf("expected rename for a different size, got %q, %v", p, err)
+	}
+
+	if p, err := save(conflict.Overwrite, "overwritten"); err != nil || p != "a/file.txt" {
+		t.Fatalf("expected overwrite, got %q, %v", p, err)
+	}
+	if read("a/file.txt") != "overwritten" {
+		t.Fatalf("overwritten file has content %q", read("a/file.txt"))
+	}
+}
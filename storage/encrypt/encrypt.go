@@ -0,0 +1,227 @@
+package encrypt
+
+import (
+	"context"
+	"crypto/cipher"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// defaultSalt is used for the key derivation when no salt is configured
+const defaultSalt = "SaveAny-Bot encrypt storage"
+
+// Inner is the storage the encrypted files are saved to, storage.Storage implements it
+type Inner interface {
+	Name() string
+	Save(ctx context.Context, r io.Reader, storagePath string) error
+	Exists(ctx context.Context, storagePath string) bool
+}
+
+// Resolver returns the configured storage with the name
+type Resolver func(ctx context.Context, name string) (Inner, error)
+
+type innerListable interface {
+	ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error)
+}
+
+type innerReadable interface {
+	OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error)
+}
+
+type innerStatable interface {
+	Stat(ctx context.Context) (storagetypes.StorageStat, error)
+}
+
+type innerPathJoiner interface {
+	JoinStoragePath(p string) string
+}
+
+type innerCannotStream interface {
+	CannotStream() string
+}
+
+// Encrypt encrypts the files saved to the wrapped storage, and optionally their names
+type Encrypt struct {
+	config  storconfig.EncryptStorageConfig
+	logger  *log.Logger
+	resolve Resolver
+	inner   Inner
+	aead    cipher.AEAD
+	names   *nameCipher // nil if the names are not encrypted
+}
+
+// New returns an encrypt storage which looks up the wrapped storage with resolve
+func New(resolve Resolver) *Encrypt {
+	return &Encrypt{resolve: resolve}
+}
+
+func (e *Encrypt) Init(ctx context.Context, cfg storconfig.StorageConfig) error {
+	encryptConfig, ok := cfg.(*storconfig.EncryptStorageConfig)
+	if !ok {
+		return fmt.Errorf("failed to cast encrypt config")
+	}
+	if err := encryptConfig.Validate(); err != nil {
+		return err
+	}
+	e.config = *encryptConfig
+	e.logger = log.FromContext(ctx).WithPrefix(fmt.Sprintf("encrypt[%s]", e.config.Name))
+
+	salt := e.config.Salt
+	if salt == "" {
+		salt = defaultSalt
+	}
+	keys, err := scrypt.Key([]byte(e.config.Password), []byte(salt), 1<<15, 8, 1, 3*32)
+	if err != nil {
+		return fmt.Errorf("failed to derive keys: %w", err)
+	}
+	e.aead, err = chacha20poly1305.NewX(keys[:32])
+	if err != nil {
+		return err
+	}
+	if e.config.EncryptFilenames {
+		e.names, err = newNameCipher(keys[32:64], keys[64:])
+		if err != nil {
+			return err
+		}
+	}
+
+	// check before resolving, encrypt storages wrapping each other would resolve forever
+	if innerConfig := config.C().GetStorageByName(e.config.Storage); innerConfig != nil && innerConfig.GetType() == storenum.Encrypt {
+		return ErrNestedEncrypt
+	}
+	e.inner, err = e.resolve(ctx, e.config.Storage)
+	if err != nil {
+		return fmt.Errorf("failed to get wrapped storage %s: %w", e.config.Storage, err)
+	}
+	if _, ok := e.inner.(innerCannotStream); ok {
+		return ErrCannotStream
+	}
+	return nil
+}
+
+func (e *Encrypt) Type() storenum.StorageType {
+	return storenum.Encrypt
+}
+
+func (e *Encrypt) Name() string {
+	return e.config.Name
+}
+
+// innerPath returns the path of the encrypted file in the wrapped storage
+func (e *Encrypt) innerPath(p string) string {
+	if e.names == nil {
+		return p
+	}
+	return e.names.encryptPath(p)
+}
+
+func (e *Encrypt) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.StorageReader(ctx, e.Name(), r)
+	e.logger.Infof("Saving encrypted file to %s", storagePath)
+	er, err := newEncryptReader(e.aead, r)
+	if err != nil {
+		return err
+	}
+	if length, ok := ctx.Value(ctxkey.ContentLength).(int64); ok && length > 0 {
+		ctx = context.WithValue(ctx, ctxkey.ContentLength, encryptedSize(length))
+	}
+	return e.inner.Save(ctx, er, e.innerPath(storagePath))
+}
+
+// Exists takes the path given to Save, the encrypt storage does not join it with a base path
+func (e *Encrypt) Exists(ctx context.Context, storagePath string) bool {
+	storagePath = e.innerPath(storagePath)
+	if j, ok := e.inner.(innerPathJoiner); ok {
+		storagePath = j.JoinStoragePath(storagePath)
+	}
+	return e.inner.Exists(ctx, storagePath)
+}
+
+// ListFiles implements storage.StorageListable.
+// The sizes are the ones of the decrypted files, the files which are not encrypted by this storage are skipped.
+func (e *Encrypt) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error) {
+	listable, ok := e.inner.(innerListable)
+	if !ok {
+		return nil, ErrInnerNotListable
+	}
+	entries, err := listable.ListFiles(ctx, e.innerPath(dirPath))
+	if err != nil {
+		return nil, err
+	}
+	files := make([]storagetypes.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name
+		if e.names != nil {
+			name, err = e.names.decryptName(entry.Name)
+			if err != nil {
+				e.logger.Debugf("Skipping %s: %v", entry.Path, err)
+				continue
+			}
+		}
+		size := entry.Size
+		if !entry.IsDir {
+			if size, ok = decryptedSize(entry.Size); !ok {
+				e.logger.Debugf("Skipping %s: %v", entry.Path, ErrNotEncrypted)
+				continue
+			}
+		}
+		files = append(files, storagetypes.FileInfo{
+			Name:    name,
+			Path:    path.Join(dirPath, name),
+			Size:    size,
+			IsDir:   entry.IsDir,
+			ModTime: entry.ModTime,
+		})
+	}
+	return files, nil
+}
+
+type decryptedFile struct {
+	io.Reader
+	io.Closer
+}
+
+// OpenFile implements storage.StorageReadable, the file is decrypted as it is read.
+// A corrupted file or a wrong password fails the read with ErrCorrupted.
+func (e *Encrypt) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	readable, ok := e.inner.(innerReadable)
+	if !ok {
+		return nil, 0, ErrInnerNotReadable
+	}
+	rc, size, err := readable.OpenFile(ctx, e.innerPath(filePath))
+	if err != nil {
+		return nil, 0, err
+	}
+	dr, err := newDecryptReader(e.aead, rc)
+	if err != nil {
+		rc.Close()
+		return nil, 0, err
+	}
+	if size >= 0 {
+		if size, ok = decryptedSize(size); !ok {
+			rc.Close()
+			return nil, 0, ErrCorrupted
+		}
+	}
+	return &decryptedFile{Reader: dr, Closer: rc}, size, nil
+}
+
+// Stat implements storage.StorageStatable with the capacity of the wrapped storage
+func (e *Encrypt) Stat(ctx context.Context) (storagetypes.StorageStat, error) {
+	statable, ok := e.inner.(innerStatable)
+	if !ok {
+		return storagetypes.StorageStat{}, storagetypes.ErrStatNotSupported
+	}
+	return statable.Stat(ctx)
+}
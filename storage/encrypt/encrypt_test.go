@@ -0,0 +1,231 @@
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/charmbracelet/log"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/storage/local"
+)
+
+func newTestContext(t *testing.T) context.Context {
+	t.Helper()
+	logger := log.NewWithOptions(io.Discard, log.Options{ReportTimestamp: false})
+	return log.WithContext(t.Context(), logger)
+}
+
+// newTestStorage returns an encrypt storage wrapping a local storage in root
+func newTestStorage(t *testing.T, root, password string, encryptFilenames bool) *Encrypt {
+	t.Helper()
+	ctx := newTestContext(t)
+	inner := &local.Local{}
+	err := inner.Init(ctx, &storconfig.LocalStorageConfig{
+		BaseConfig: storconfig.BaseConfig{Name: "inner", Type: "local", Enable: true},
+		BasePath:   root,
+	})
+	if err != nil {
+		t.Fatalf("failed to init local storage: %v", err)
+	}
+	e := New(func(_ context.Context, name string) (Inner, error) {
+		if name != "inner" {
+			return nil, errors.New("unknown storage " + name)
+		}
+		return inner, nil
+	})
+	err = e.Init(ctx, &storconfig.EncryptStorageConfig{
+		BaseConfig:       storconfig.BaseConfig{Name: "test-encrypt", Type: "encrypt", Enable: true},
+		Storage:          "inner",
+		Password:         password,
+		EncryptFilenames: encryptFilenames,
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	return e
+}
+
+func testContent(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	return content
+}
+
+func TestEncrypt(t *testing.T) {
+	for _, encryptFilenames := range []bool{false, true} {
+		t.Run("encrypt_filenames="+strconv.FormatBool(encryptFilenames), func(t *testing.T) {
+			root := t.TempDir()
+			e := newTestStorage(t, root, "secret", encryptFilenames)
+			ctx := newTestContext(t)
+
+			sizes := []int{0, 1, chunkSize, chunkSize + 1, 3*chunkSize + 5}
+			for _, size := range sizes {
+				content := testContent(size)
+				name := "dir/file" + strconv.Itoa(size) + ".bin"
+				// a one byte reader checks that short reads still fill the chunks
+				if err := e.Save(ctx, iotest.OneByteReader(bytes.NewReader(content)), name); err != nil {
+					t.Fatalf("Save of %d bytes failed: %v", size, err)
+				}
+				if !e.Exists(ctx, name) {
+					t.Fatalf("Exists should return true for saved file %s", name)
+				}
+
+				raw, err := os.ReadFile(filepath.Join(root, e.innerPath(name)))
+				if err != nil {
+					t.Fatalf("failed to read the encrypted file: %v", err)
+				}
+				if int64(len(raw)) != encryptedSize(int64(size)) {
+					t.Fatalf("encrypted size of %d bytes is %d, want %d", size, len(raw), encryptedSize(int64(size)))
+				}
+				if size > 16 && bytes.Contains(raw, content) {
+					t.Fatalf("the content should be encrypted")
+				}
+
+				rc, gotSize, err := e.OpenFile(ctx, name)
+				if err != nil {
+					t.Fatalf("OpenFile failed: %v", err)
+				}
+				got, err := io.ReadAll(rc)
+				rc.Close()
+				if err != nil || !bytes.Equal(got, content) || gotSize != int64(size) {
+					t.Fatalf("decrypted content mismatch, got %d bytes (size %d), want %d: %v", len(got), gotSize, size, err)
+				}
+			}
+			if e.Exists(ctx, "dir/missing.bin") {
+				t.Fatalf("Exists should return false for missing file")
+			}
+			_, err := os.Stat(filepath.Join(root, "dir", "file1.bin"))
+			if encryptFilenames != os.IsNotExist(err) {
+				t.Fatalf("the names should be encrypted only with encrypt_filenames: %v", err)
+			}
+
+			// a file not saved by the encrypt storage is skipped
+			if err := os.WriteFile(filepath.Join(root, e.innerPath("dir"), "plain.txt"), []byte("plain"), 0o644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			files, err := e.ListFiles(ctx, "dir")
+			if err != nil {
+				t.Fatalf("ListFiles failed: %v", err)
+			}
+			if len(files) != len(sizes) {
+				t.Fatalf("unexpected files: %+v", files)
+			}
+			for _, file := range files {
+				size, err := strconv.Atoi(file.Name[len("file") : len(file.Name)-len(".bin")])
+				if err != nil || file.Path != "dir/"+file.Name || file.Size != int64(size) {
+					t.Fatalf("unexpected file info: %+v", file)
+				}
+			}
+		})
+	}
+}
+
+func TestEncryptWrongPassword(t *testing.T) {
+	root := t.TempDir()
+	ctx := newTestContext(t)
+	e := newTestStorage(t, root, "secret", false)
+	if err := e.Save(ctx, bytes.NewReader(testContent(100)), "file.bin"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	rc, _, err := newTestStorage(t, root, "wrong", false).OpenFile(ctx, "file.bin")
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("reading with a wrong password should fail with ErrCorrupted, got %v", err)
+	}
+
+	names := newTestStorage(t, root, "wrong", true)
+	if names.Exists(ctx, "file.bin") {
+		t.Fatalf("Exists should not find the file with other names")
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	e := newTestStorage(t, t.TempDir(), "secret", false)
+	er, err := newEncryptReader(e.aead, bytes.NewReader(testContent(2*chunkSize+10)))
+	if err != nil {
+		t.Fatalf("newEncryptReader failed: %v", err)
+	}
+	encrypted, err := io.ReadAll(er)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	flipped := bytes.Clone(encrypted)
+	flipped[headerSize+chunkSize] ^= 1
+	swapped := bytes.Clone(encrypted)
+	copy(swapped[headerSize:], encrypted[headerSize+encChunkSize:headerSize+2*encChunkSize])
+	copy(swapped[headerSize+encChunkSize:], encrypted[headerSize:headerSize+encChunkSize])
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{name: "flipped bit", data: flipped, err: ErrCorrupted},
+		{name: "swapped chunks", data: swapped, err: ErrCorrupted},
+		{name: "truncated at chunk boundary", data: encrypted[:headerSize+2*encChunkSize], err: ErrCorrupted},
+		{name: "truncated in chunk", data: encrypted[:len(encrypted)-1], err: ErrCorrupted},
+		{name: "truncated header", data: encrypted[:headerSize-1], err: ErrNotEncrypted},
+		{name: "plain file", data: testContent(100), err: ErrNotEncrypted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dr, err := newDecryptReader(e.aead, bytes.NewReader(tt.data))
+			if err == nil {
+				_, err = io.ReadAll(dr)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDecryptedSize(t *testing.T) {
+	for _, size := range []int64{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 5 * chunkSize} {
+		if got, ok := decryptedSize(encryptedSize(size)); !ok || got != size {
+			t.Fatalf("decryptedSize(encryptedSize(%d)) = %d, %v", size, got, ok)
+		}
+	}
+	for _, size := range []int64{0, int64(headerSize), int64(headerSize + overhead - 1), int64(headerSize + encChunkSize + 1)} {
+		if _, ok := decryptedSize(size); ok {
+			t.Fatalf("decryptedSize(%d) should not be valid", size)
+		}
+	}
+}
+
+func TestNameCipher(t *testing.T) {
+	c, err := newNameCipher(make([]byte, 32), []byte("mac key"))
+	if err != nil {
+		t.Fatalf("newNameCipher failed: %v", err)
+	}
+	encrypted := c.encryptPath("/a/./b/文件.txt")
+	if encrypted != c.encryptPath("/a/./b/文件.txt") {
+		t.Fatalf("names should be encrypted deterministically")
+	}
+	parts := strings.Split(encrypted, "/")
+	if len(parts) != 5 || parts[0] != "" || parts[2] != "." || parts[1] == "a" || parts[3] == "b" {
+		t.Fatalf("each name should be encrypted, got %s", encrypted)
+	}
+	name, err := c.decryptName(parts[4])
+	if err != nil || name != "文件.txt" {
+		t.Fatalf("unexpected decrypted name %q: %v", name, err)
+	}
+	if _, err := c.decryptName("plain.txt"); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("decrypting a plain name should fail, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package encrypt
+
+import "errors"
+
+var (
+	ErrNotEncrypted        = errors.New("encrypt: not an encrypted file")
+	ErrCorrupted           = errors.New("encrypt: file is corrupted or the password is wrong")
+	ErrInvalidName         = errors.New("encrypt: invalid encrypted name")
+	ErrNestedEncrypt       = errors.New("encrypt: can not wrap another encrypt storage")
+	ErrCannotStream        = errors.New("encrypt: can not wrap a storage which can not save streams")
+	ErrInnerNotListable    = errors.New("encrypt: the wrapped storage does not support listing files")
+	ErrInnerNotReadable    = errors.New("encrypt: the wrapped storage does not support reading files")
+	ErrFailedToEncryptFile = errors.New("encrypt: failed to encrypt file")
+)
@@ -0,0 +1,72 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+)
+
+// sivSize is the size of the synthetic iv prepended to the encrypted names
+const sivSize = 16
+
+// nameEncoding is case insensitive, so the names also work on case insensitive file systems
+var nameEncoding = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+// nameCipher encrypts the names deterministically, so the encrypted path of a file can be computed from its path.
+// The iv is the HMAC of the name, which also authenticates it, as in SIV mode.
+type nameCipher struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+func newNameCipher(encKey, macKey []byte) (*nameCipher, error) {
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	return &nameCipher{block: block, macKey: macKey}, nil
+}
+
+func (c *nameCipher) siv(name []byte) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(name)
+	return mac.Sum(nil)[:sivSize]
+}
+
+// encryptName encrypts a name, names longer than about 140 bytes exceed the 255 bytes limit of most file systems once encrypted
+func (c *nameCipher) encryptName(name string) string {
+	iv := c.siv([]byte(name))
+	out := make([]byte, sivSize+len(name))
+	copy(out, iv)
+	cipher.NewCTR(c.block, iv).XORKeyStream(out[sivSize:], []byte(name))
+	return strings.ToLower(nameEncoding.EncodeToString(out))
+}
+
+func (c *nameCipher) decryptName(encrypted string) (string, error) {
+	data, err := nameEncoding.DecodeString(strings.ToUpper(encrypted))
+	if err != nil || len(data) <= sivSize {
+		return "", ErrInvalidName
+	}
+	iv := data[:sivSize]
+	name := make([]byte, len(data)-sivSize)
+	cipher.NewCTR(c.block, iv).XORKeyStream(name, data[sivSize:])
+	if !hmac.Equal(iv, c.siv(name)) {
+		return "", ErrInvalidName
+	}
+	return string(name), nil
+}
+
+// encryptPath encrypts each name of the slash separated path
+func (c *nameCipher) encryptPath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		if part == "" || part == "." || part == ".." {
+			continue
+		}
+		parts[i] = c.encryptName(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,207 @@
+package encrypt
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// The encrypted file is a header followed by the chunks of the content, each sealed with XChaCha20-Poly1305.
+// The nonce of a chunk is the random prefix of the header and the chunk counter,
+// whose top bit marks the last chunk, so reordered, dropped or truncated chunks fail to open.
+// An empty file has one empty last chunk.
+const (
+	magic        = "SABENC01"
+	prefixSize   = 16
+	headerSize   = len(magic) + prefixSize
+	chunkSize    = 64 << 10
+	overhead     = chacha20poly1305.Overhead
+	encChunkSize = chunkSize + overhead
+
+	lastChunkFlag = 1 << 63
+)
+
+func chunkNonce(prefix []byte, counter uint64, last bool) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	copy(nonce, prefix)
+	if last {
+		counter |= lastChunkFlag
+	}
+	binary.BigEndian.PutUint64(nonce[prefixSize:], counter)
+	return nonce
+}
+
+// encryptedSize returns the size of the encrypted file of size bytes
+func encryptedSize(size int64) int64 {
+	chunks := max((size+chunkSize-1)/chunkSize, 1)
+	return int64(headerSize) + size + chunks*overhead
+}
+
+// decryptedSize returns the size of the content of the encrypted file of size bytes,
+// ok is false if no encrypted file has that size.
+func decryptedSize(size int64) (int64, bool) {
+	body := size - int64(headerSize)
+	if body < overhead {
+		return 0, false
+	}
+	chunks, rest := body/encChunkSize, body%encChunkSize
+	if rest == 0 {
+		return chunks * chunkSize, true
+	}
+	if rest < overhead {
+		return 0, false
+	}
+	return chunks*chunkSize + rest - overhead, true
+}
+
+// encryptReader reads the encrypted file of the content read from src
+type encryptReader struct {
+	aead    cipher.AEAD
+	src     io.Reader
+	prefix  []byte
+	counter uint64
+	// in holds a chunk and the first byte of the next one, which tells whether the chunk is the last
+	in       []byte
+	buffered int
+	out      []byte
+	outBuf   []byte
+	done     bool
+	err      error
+}
+
+func newEncryptReader(aead cipher.AEAD, src io.Reader) (*encryptReader, error) {
+	prefix := make([]byte, prefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToEncryptFile, err)
+	}
+	header := append([]byte(magic), prefix...)
+	return &encryptReader{
+		aead:   aead,
+		src:    src,
+		prefix: prefix,
+		in:     make([]byte, chunkSize+1),
+		out:    header,
+		outBuf: make([]byte, 0, encChunkSize),
+	}, nil
+}
+
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.sealChunk()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *encryptReader) sealChunk() {
+	n, err := io.ReadFull(r.src, r.in[r.buffered:])
+	n += r.buffered
+	last := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		r.err = err
+		return
+	}
+	size := min(n, chunkSize)
+	r.out = r.aead.Seal(r.outBuf[:0], chunkNonce(r.prefix, r.counter, last), r.in[:size], nil)
+	r.counter++
+	r.done = last
+	if !last {
+		r.in[0] = r.in[chunkSize]
+		r.buffered = 1
+	}
+}
+
+// decryptReader reads the content of the encrypted file read from src
+type decryptReader struct {
+	aead     cipher.AEAD
+	src      io.Reader
+	prefix   []byte
+	counter  uint64
+	in       []byte
+	buffered int
+	out      []byte
+	outBuf   []byte
+	done     bool
+	err      error
+}
+
+// newDecryptReader reads the header of the encrypted file from src
+func newDecryptReader(aead cipher.AEAD, src io.Reader) (*decryptReader, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(src, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrNotEncrypted
+		}
+		return nil, err
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, ErrNotEncrypted
+	}
+	return &decryptReader{
+		aead:   aead,
+		src:    src,
+		prefix: header[len(magic):],
+		in:     make([]byte, encChunkSize+1),
+		outBuf: make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		r.openChunk()
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *decryptReader) openChunk() {
+	n, err := io.ReadFull(r.src, r.in[r.buffered:])
+	n += r.buffered
+	last := false
+	switch err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		r.err = err
+		return
+	}
+	if n < overhead {
+		r.err = ErrCorrupted
+		return
+	}
+	size := min(n, encChunkSize)
+	r.out, err = r.aead.Open(r.outBuf[:0], chunkNonce(r.prefix, r.counter, last), r.in[:size], nil)
+	if err != nil {
+		r.err = ErrCorrupted
+		return
+	}
+	r.counter++
+	r.done = last
+	if !last {
+		r.in[0] = r.in[encChunkSize]
+		r.buffered = 1
+	}
+}
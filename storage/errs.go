@@ -5,5 +5,6 @@ import (
 )
 
 var (
-	ErrStorageNameEmpty = errors.New("storage name is empty")
+	ErrStorageNameEmpty  = errors.New("storage name is empty")
+	ErrInsufficientSpace = errors.New("not enough free space in storage")
 )
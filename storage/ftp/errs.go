@@ -0,0 +1,14 @@
+package ftp
+
+import "errors"
+
+var (
+	ErrFailedToConnect         = errors.New("ftp: failed to connect")
+	ErrFailedToCreateDirectory = errors.New("ftp: failed to create directory")
+	ErrFailedToWriteFile       = errors.New("ftp: failed to write file")
+	ErrFailedToListFiles       = errors.New("ftp: failed to list files")
+	ErrFailedToOpenFile        = errors.New("ftp: failed to open file")
+	ErrFailedToDeleteFile      = errors.New("ftp: failed to delete file")
+	ErrFailedToMoveFile        = errors.New("ftp: failed to move file")
+	ErrCannotResume            = errors.New("ftp: the upload can not be resumed")
+)
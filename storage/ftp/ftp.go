@@ -0,0 +1,329 @@
+package ftp
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/jlaffaye/ftp"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"github.com/rs/xid"
+)
+
+// replayBufferSize is the most bytes an interrupted upload can go back to resume,
+// it covers the data in flight in the socket buffers when the connection breaks.
+const replayBufferSize = 8 << 20
+
+type FTP struct {
+	config  storconfig.FTPStorageConfig
+	logger  *log.Logger
+	retries int
+	// replaces the dialer of the control and data connections in tests
+	dialFunc func(network, address string) (net.Conn, error)
+}
+
+func (f *FTP) Init(ctx context.Context, cfg storconfig.StorageConfig) error {
+	ftpConfig, ok := cfg.(*storconfig.FTPStorageConfig)
+	if !ok {
+		return fmt.Errorf("failed to cast ftp config")
+	}
+	if err := ftpConfig.Validate(); err != nil {
+		return err
+	}
+	f.config = *ftpConfig
+	f.logger = log.FromContext(ctx).WithPrefix(fmt.Sprintf("ftp[%s]", f.config.Name))
+	f.retries = config.C().Retry
+
+	// connect once to fail early on wrong address or credentials
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	conn.Quit()
+	return nil
+}
+
+// connect opens a logged-in connection, the connections are not shared
+// as a connection can only run one command at a time.
+func (f *FTP) connect(ctx context.Context) (*ftp.ServerConn, error) {
+	port := f.config.Port
+	if port == 0 {
+		port = 21
+		if f.config.TLS == storconfig.FTPTLSImplicit {
+			port = 990
+		}
+	}
+	opts := []ftp.DialOption{
+		ftp.DialWithContext(ctx),
+		ftp.DialWithTimeout(30 * time.Second),
+		ftp.DialWithDisabledEPSV(f.config.DisableEPSV),
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         f.config.Host,
+		InsecureSkipVerify: f.config.InsecureSkipVerify,
+	}
+	switch f.config.TLS {
+	case storconfig.FTPTLSExplicit:
+		opts = append(opts, ftp.DialWithExplicitTLS(tlsConfig))
+	case storconfig.FTPTLSImplicit:
+		opts = append(opts, ftp.DialWithTLS(tlsConfig))
+	}
+	if f.dialFunc != nil {
+		opts = append(opts, ftp.DialWithDialFunc(f.dialFunc))
+	}
+
+	conn, err := ftp.Dial(net.JoinHostPort(f.config.Host, strconv.Itoa(port)), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnect, err)
+	}
+	user, password := f.config.Username, f.config.Password
+	if user == "" {
+		user, password = "anonymous", "anonymous"
+	}
+	if err := conn.Login(user, password); err != nil {
+		conn.Quit()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnect, err)
+	}
+	return conn, nil
+}
+
+func (f *FTP) Type() storenum.StorageType {
+	return storenum.Ftp
+}
+
+func (f *FTP) Name() string {
+	return f.config.Name
+}
+
+func (f *FTP) JoinStoragePath(p string) string {
+	return path.Join("/", f.config.BasePath, p)
+}
+
+// mkdirAll creates the directory and its parents, the errors of MKD are ignored
+// as most servers fail for existing directories, the result is checked with CWD.
+func mkdirAll(conn *ftp.ServerConn, dirPath string) error {
+	current := "/"
+	for _, part := range strings.Split(strings.Trim(dirPath, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		conn.MakeDir(current)
+	}
+	return conn.ChangeDir(current)
+}
+
+// Save uploads to a temporary file next to the target then renames it.
+// An interrupted upload is resumed on a new connection from the size of the temporary file,
+// with REST+STOR or APPE if the server does not support REST.
+func (f *FTP) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, f.Name(), r)
+	f.logger.Infof("Saving file to %s", storagePath)
+	storagePath = f.JoinStoragePath(storagePath)
+	tmpPath := path.Join(path.Dir(storagePath), fmt.Sprintf(".%s.%s.tmp", path.Base(storagePath), xid.New().String()))
+
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if conn != nil {
+			conn.Quit()
+		}
+	}()
+	if err := mkdirAll(conn, path.Dir(storagePath)); err != nil {
+		f.logger.Errorf("Failed to create directory %s: %v", path.Dir(storagePath), err)
+		return ErrFailedToCreateDirectory
+	}
+
+	rr := newReplayReader(r, replayBufferSize)
+	err = conn.Stor(tmpPath, rr)
+	for attempt := 1; err != nil && attempt <= f.retries && ctx.Err() == nil; attempt++ {
+		f.logger.Warnf("Upload of %s interrupted, resuming (%d/%d): %v", tmpPath, attempt, f.retries, err)
+		if conn != nil {
+			conn.Quit()
+		}
+		conn, err = f.connect(ctx)
+		if err != nil {
+			continue
+		}
+		err = f.resume(conn, tmpPath, rr)
+		if errors.Is(err, ErrCannotResume) {
+			break
+		}
+	}
+	if err == nil {
+		err = conn.Rename(tmpPath, storagePath)
+		if err != nil {
+			// some servers do not replace an existing file on rename
+			conn.Delete(storagePath)
+			err = conn.Rename(tmpPath, storagePath)
+		}
+	}
+	if err != nil {
+		f.logger.Errorf("Failed to write file %s: %v", storagePath, err)
+		if conn != nil {
+			conn.Delete(tmpPath)
+		}
+		return fmt.Errorf("%w: %w", ErrFailedToWriteFile, err)
+	}
+	return nil
+}
+
+// resume continues the upload to filePath from the size the server received
+func (f *FTP) resume(conn *ftp.ServerConn, filePath string, rr *replayReader) error {
+	offset, err := conn.FileSize(filePath)
+	if err != nil {
+		// the file may not be created if the connection broke right away
+		offset = 0
+	}
+	if err := rr.Rewind(offset); err != nil {
+		return err
+	}
+	if offset == 0 {
+		return conn.Stor(filePath, rr)
+	}
+	err = conn.StorFrom(filePath, rr, uint64(offset))
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		// REST is not supported, append to the file instead
+		if err := rr.Rewind(offset); err != nil {
+			return err
+		}
+		return conn.Append(filePath, rr)
+	}
+	return err
+}
+
+func (f *FTP) Exists(ctx context.Context, storagePath string) bool {
+	f.logger.Debugf("Checking if file exists at %s", storagePath)
+	conn, err := f.connect(ctx)
+	if err != nil {
+		f.logger.Errorf("Failed to check if file exists at %s: %v", storagePath, err)
+		return false
+	}
+	defer conn.Quit()
+	_, err = conn.FileSize(storagePath)
+	return err == nil
+}
+
+// ListFiles implements storage.StorageListable
+func (f *FTP) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error) {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Quit()
+	fullPath := f.JoinStoragePath(dirPath)
+	entries, err := conn.List(fullPath)
+	if err != nil {
+		f.logger.Errorf("Failed to list directory %s: %v", fullPath, err)
+		return nil, fmt.Errorf("%w: %w", ErrFailedToListFiles, err)
+	}
+	files := make([]storagetypes.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Name == "." || entry.Name == ".." {
+			continue
+		}
+		files = append(files, storagetypes.FileInfo{
+			Name:    entry.Name,
+			Path:    path.Join(dirPath, entry.Name),
+			Size:    int64(entry.Size),
+			IsDir:   entry.Type == ftp.EntryTypeFolder,
+			ModTime: entry.Time,
+		})
+	}
+	return files, nil
+}
+
+// fileReader closes the connection of the download with the response
+type fileReader struct {
+	*ftp.Response
+	conn *ftp.ServerConn
+}
+
+func (r *fileReader) Close() error {
+	err := r.Response.Close()
+	r.conn.Quit()
+	return err
+}
+
+// OpenFile implements storage.StorageReadable
+func (f *FTP) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	fullPath := f.JoinStoragePath(filePath)
+	size, err := conn.FileSize(fullPath)
+	if err != nil {
+		conn.Quit()
+		return nil, 0, fmt.Errorf("%w: %w", ErrFailedToOpenFile, err)
+	}
+	resp, err := conn.Retr(fullPath)
+	if err != nil {
+		conn.Quit()
+		return nil, 0, fmt.Errorf("%w: %w", ErrFailedToOpenFile, err)
+	}
+	return &fileReader{Response: resp, conn: conn}, size, nil
+}
+
+// Delete implements storage.StorageDeletable
+func (f *FTP) Delete(ctx context.Context, filePath string) error {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Quit()
+	fullPath := f.JoinStoragePath(filePath)
+	f.logger.Infof("Deleting file %s", fullPath)
+	if err := conn.Delete(fullPath); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToDeleteFile, err)
+	}
+	return nil
+}
+
+// Move implements storage.StorageMovable
+func (f *FTP) Move(ctx context.Context, srcPath, dstPath string) error {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Quit()
+	src := f.JoinStoragePath(srcPath)
+	dst := f.JoinStoragePath(dstPath)
+	f.logger.Infof("Moving file %s to %s", src, dst)
+	if err := mkdirAll(conn, path.Dir(dst)); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCreateDirectory, err)
+	}
+	if err := conn.Rename(src, dst); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToMoveFile, err)
+	}
+	return nil
+}
+
+// MkDir implements storage.StorageDirMaker
+func (f *FTP) MkDir(ctx context.Context, dirPath string) error {
+	conn, err := f.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Quit()
+	if err := mkdirAll(conn, f.JoinStoragePath(dirPath)); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCreateDirectory, err)
+	}
+	return nil
+}
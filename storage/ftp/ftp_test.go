@@ -0,0 +1,333 @@
+package ftp
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"io"
+	"maps"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/charmbracelet/log"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"goftp.io/server/v2"
+	"goftp.io/server/v2/driver/file"
+)
+
+func newTestContext(t *testing.T) context.Context {
+	t.Helper()
+	logger := log.NewWithOptions(io.Discard, log.Options{ReportTimestamp: false})
+	return log.WithContext(t.Context(), logger)
+}
+
+// okCommand accepts the command, the test server only accepts PBSZ and PROT after AUTH TLS,
+// and so refuses them in implicit tls mode.
+type okCommand struct{}
+
+func (okCommand) IsExtend() bool                         { return false }
+func (okCommand) RequireParam() bool                     { return true }
+func (okCommand) RequireAuth() bool                      { return false }
+func (okCommand) Execute(sess *server.Session, _ string) { sess.WriteMessage(200, "OK") }
+
+// newTestServer starts an in-process ftp server serving a temporary directory with the given tls mode
+func newTestServer(t *testing.T, tlsMode string) (*storconfig.FTPStorageConfig, string) {
+	t.Helper()
+	root := t.TempDir()
+	driver, err := file.NewDriver(root)
+	if err != nil {
+		t.Fatalf("failed to create driver: %v", err)
+	}
+
+	// the server only listens by port, so pick a free one
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	opts := &server.Options{
+		Driver:   driver,
+		Auth:     &server.SimpleAuth{Name: "tester", Password: "secret"},
+		Perm:     server.NewSimplePerm("tester", "tester"),
+		Hostname: "127.0.0.1",
+		PublicIP: "127.0.0.1",
+		Port:     port,
+		Logger:   &server.DiscardLogger{},
+	}
+	if tlsMode != storconfig.FTPTLSNone {
+		opts.TLS = true
+		opts.TLSConfig = selfSignedTLSConfig(t)
+		opts.ExplicitFTPS = tlsMode == storconfig.FTPTLSExplicit
+	}
+	if tlsMode == storconfig.FTPTLSImplicit {
+		opts.Commands = maps.Clone(server.DefaultCommands())
+		opts.Commands["PBSZ"] = okCommand{}
+		opts.Commands["PROT"] = okCommand{}
+	}
+	srv, err := server.NewServer(opts)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	go srv.ListenAndServe()
+	t.Cleanup(func() { srv.Shutdown() })
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	for i := 0; ; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if i > 100 {
+			t.Fatalf("server did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return &storconfig.FTPStorageConfig{
+		BaseConfig: storconfig.BaseConfig{
+			Name:   "test-ftp",
+			Type:   "ftp",
+			Enable: true,
+		},
+		Host:               "127.0.0.1",
+		Port:               port,
+		Username:           "tester",
+		Password:           "secret",
+		BasePath:           "/base",
+		TLS:                tlsMode,
+		InsecureSkipVerify: true,
+	}, root
+}
+
+func selfSignedTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+}
+
+func TestFTP(t *testing.T) {
+	for _, tlsMode := range []string{storconfig.FTPTLSNone, storconfig.FTPTLSExplicit} {
+		t.Run("tls="+tlsMode, func(t *testing.T) {
+			cfg, root := newTestServer(t, tlsMode)
+			ctx := newTestContext(t)
+			f := &FTP{}
+			if err := f.Init(ctx, cfg); err != nil {
+				t.Fatalf("Init failed: %v", err)
+			}
+
+			content := []byte("hello ftp")
+			if err := f.Save(ctx, bytes.NewReader(content), "a/b/file.txt"); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+			if !f.Exists(ctx, f.JoinStoragePath("a/b/file.txt")) {
+				t.Fatalf("Exists should return true for saved file")
+			}
+			if f.Exists(ctx, f.JoinStoragePath("a/b/missing.txt")) {
+				t.Fatalf("Exists should return false for missing file")
+			}
+
+			// saving again replaces the file, the conflict policy is applied by storage.Save
+			content = []byte("hello again")
+			if err := f.Save(ctx, bytes.NewReader(content), "a/b/file.txt"); err != nil {
+				t.Fatalf("Save over existing file failed: %v", err)
+			}
+			got, err := os.ReadFile(filepath.Join(root, "base", "a", "b", "file.txt"))
+			if err != nil || !bytes.Equal(got, content) {
+				t.Fatalf("unexpected saved content %q: %v", got, err)
+			}
+
+			if err := f.MkDir(ctx, "a/b/sub"); err != nil {
+				t.Fatalf("MkDir failed: %v", err)
+			}
+			files, err := f.ListFiles(ctx, "a/b")
+			if err != nil {
+				t.Fatalf("ListFiles failed: %v", err)
+			}
+			// the temporary files are renamed, only the saved file and the directory are left
+			if len(files) != 2 {
+				t.Fatalf("unexpected files: %+v", files)
+			}
+			for _, file := range files {
+				switch file.Path {
+				case "a/b/file.txt":
+					if file.IsDir || file.Size != int64(len(content)) {
+						t.Fatalf("unexpected file info: %+v", file)
+					}
+				case "a/b/sub":
+					if !file.IsDir {
+						t.Fatalf("unexpected file info: %+v", file)
+					}
+				default:
+					t.Fatalf("unexpected file: %+v", file)
+				}
+			}
+
+			rc, size, err := f.OpenFile(ctx, "a/b/file.txt")
+			if err != nil {
+				t.Fatalf("OpenFile failed: %v", err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil || !bytes.Equal(data, content) || size != int64(len(content)) {
+				t.Fatalf("unexpected content %q (size %d): %v", data, size, err)
+			}
+
+			if err := f.Move(ctx, "a/b/file.txt", "moved/file.txt"); err != nil {
+				t.Fatalf("Move failed: %v", err)
+			}
+			if f.Exists(ctx, f.JoinStoragePath("a/b/file.txt")) || !f.Exists(ctx, f.JoinStoragePath("moved/file.txt")) {
+				t.Fatalf("file should be moved")
+			}
+			if err := f.Delete(ctx, "moved/file.txt"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if f.Exists(ctx, f.JoinStoragePath("moved/file.txt")) {
+				t.Fatalf("file should be deleted")
+			}
+		})
+	}
+}
+
+// The test server does not use tls for the data connections in implicit tls mode,
+// so only the commands on the control connection are tested.
+func TestFTPImplicitTLS(t *testing.T) {
+	cfg, root := newTestServer(t, storconfig.FTPTLSImplicit)
+	ctx := newTestContext(t)
+	f := &FTP{}
+	if err := f.Init(ctx, cfg); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if err := f.MkDir(ctx, "a/b"); err != nil {
+		t.Fatalf("MkDir failed: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(root, "base", "a", "b")); err != nil || !info.IsDir() {
+		t.Fatalf("directory should be created: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "base", "a", "b", "file.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if !f.Exists(ctx, f.JoinStoragePath("a/b/file.txt")) {
+		t.Fatalf("Exists should return true for existing file")
+	}
+
+	cfg.InsecureSkipVerify = false
+	if err := (&FTP{}).Init(ctx, cfg); err == nil {
+		t.Fatalf("Init should fail for an untrusted certificate")
+	}
+}
+
+// failingConn breaks the connection after limit bytes are written
+type failingConn struct {
+	net.Conn
+	limit int
+}
+
+func (c *failingConn) Write(p []byte) (int, error) {
+	if len(p) > c.limit {
+		n, _ := c.Conn.Write(p[:c.limit])
+		c.limit = 0
+		c.Conn.Close()
+		return n, errors.New("connection broken")
+	}
+	c.limit -= len(p)
+	return c.Conn.Write(p)
+}
+
+func TestFTPSaveResume(t *testing.T) {
+	cfg, root := newTestServer(t, storconfig.FTPTLSNone)
+	ctx := newTestContext(t)
+	f := &FTP{}
+	if err := f.Init(ctx, cfg); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	f.retries = 3
+
+	// the first two upload data connections break so the upload is resumed twice,
+	// the data connections are the ones not to the control port
+	var broken atomic.Int32
+	f.dialFunc = func(network, address string) (net.Conn, error) {
+		conn, err := net.Dial(network, address)
+		if err != nil {
+			return nil, err
+		}
+		if conn.RemoteAddr().(*net.TCPAddr).Port != cfg.Port && broken.Add(1) <= 2 {
+			return &failingConn{Conn: conn, limit: 300 << 10}, nil
+		}
+		return conn, nil
+	}
+
+	content := make([]byte, 1<<20)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+	// a pipe hides the length and seeking, the stream can only be read once
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(func() error {
+			_, err := io.Copy(pw, bytes.NewReader(content))
+			return err
+		}())
+	}()
+	if err := f.Save(ctx, pr, "resume/file.bin"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if broken.Load() < 2 {
+		t.Fatalf("the upload should be interrupted twice, got %d", broken.Load())
+	}
+	got, err := os.ReadFile(filepath.Join(root, "base", "resume", "file.bin"))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("resumed content mismatch, got %d bytes, want %d: %v", len(got), len(content), err)
+	}
+}
+
+func TestReplayReader(t *testing.T) {
+	content := []byte("0123456789abcdef")
+	rr := newReplayReader(iotest.OneByteReader(bytes.NewReader(content)), 4)
+
+	buf := make([]byte, 10)
+	if _, err := io.ReadFull(rr, buf); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if err := rr.Rewind(7); err != nil {
+		t.Fatalf("Rewind to a kept offset failed: %v", err)
+	}
+	rest, err := io.ReadAll(rr)
+	if err != nil || string(rest) != "789abcdef" {
+		t.Fatalf("unexpected data after rewind %q: %v", rest, err)
+	}
+	if err := rr.Rewind(0); !errors.Is(err, ErrCannotResume) {
+		t.Fatalf("Rewind past the kept bytes should fail, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package ftp
+
+import (
+	"fmt"
+	"io"
+)
+
+// replayReader keeps at least the last max bytes read from a stream,
+// so that an interrupted upload can be resumed from the size the server received.
+type replayReader struct {
+	r       io.Reader
+	max     int
+	tail    []byte // the bytes at [read-len(tail), read) of the stream
+	read    int64  // bytes read from r
+	pending []byte // bytes of tail to read again before reading r
+}
+
+func newReplayReader(r io.Reader, max int) *replayReader {
+	return &replayReader{r: r, max: max}
+}
+
+func (rr *replayReader) Read(p []byte) (int, error) {
+	if len(rr.pending) > 0 {
+		n := copy(p, rr.pending)
+		rr.pending = rr.pending[n:]
+		return n, nil
+	}
+	n, err := rr.r.Read(p)
+	if n > 0 {
+		rr.read += int64(n)
+		rr.tail = append(rr.tail, p[:n]...)
+		// trimmed only when twice the size, so the copy is amortized over many reads
+		if len(rr.tail) > 2*rr.max {
+			rr.tail = append(make([]byte, 0, 2*rr.max), rr.tail[len(rr.tail)-rr.max:]...)
+		}
+	}
+	return n, err
+}
+
+// Rewind makes the next reads start from offset of the stream,
+// it fails if the bytes since offset are no longer kept.
+func (rr *replayReader) Rewind(offset int64) error {
+	start := rr.read - int64(len(rr.tail))
+	if offset < start || offset > rr.read {
+		return fmt.Errorf("%w: offset %d is out of the kept range [%d, %d]", ErrCannotResume, offset, start, rr.read)
+	}
+	rr.pending = rr.tail[offset-start:]
+	return nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/dustin/go-humanize"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+)
+
+const (
+	// how long a probe may take before the storage is treated as unreachable
+	probeTimeout = 30 * time.Second
+	// how long the free space from the last probe is used by CheckSpace
+	spaceCacheTTL = time.Minute
+)
+
+// Health is the result of the last probe of a storage
+type Health struct {
+	Name      string                   `json:"name"`
+	Reachable bool                     `json:"reachable"`
+	LowSpace  bool                     `json:"low_space"` // the free space is below the minimum
+	Latency   time.Duration            `json:"latency"`
+	Stat      storagetypes.StorageStat `json:"stat"`
+	Error     string                   `json:"error,omitempty"`
+	CheckedAt time.Time                `json:"checked_at"`
+}
+
+// Healthy reports whether the storage is reachable and has enough free space
+func (h Health) Healthy() bool {
+	return h.Reachable && !h.LowSpace
+}
+
+var (
+	healthMu sync.RWMutex
+	// the last probes of the storages which implement StorageStatable, by name
+	healths = make(map[string]Health)
+)
+
+// GetHealth returns the last probe of the storage, ok is false if it was not probed
+func GetHealth(name string) (Health, bool) {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+	h, ok := healths[name]
+	return h, ok
+}
+
+// GetAllHealth returns the last probes of all the probed storages, sorted by name
+func GetAllHealth() []Health {
+	healthMu.RLock()
+	defer healthMu.RUnlock()
+	return slices.SortedFunc(maps.Values(healths), func(a, b Health) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
+
+// Probe gets the capacity of the storage and measures how long it takes.
+// minFree is the free space below which the storage is low on space, 0 to not check it.
+// supported is false if the storage can not report its capacity.
+func Probe(ctx context.Context, stor StorageStatable, minFree int64) (h Health, supported bool) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	start := time.Now()
+	stat, err := stor.Stat(ctx)
+	h = Health{
+		Name:      stor.Name(),
+		Latency:   time.Since(start),
+		CheckedAt: time.Now(),
+	}
+	if errors.Is(err, storagetypes.ErrStatNotSupported) {
+		return h, false
+	}
+	if err != nil {
+		h.Stat = storagetypes.NewStorageStat()
+		h.Error = err.Error()
+		return h, true
+	}
+	h.Reachable = true
+	h.Stat = stat
+	h.LowSpace = minFree > 0 && stat.FreeKnown() && stat.Free < minFree
+	return h, true
+}
+
+// ProbeAll probes the storages which implement StorageStatable and returns the ones which became healthy or unhealthy.
+// A storage found unhealthy by its first probe is returned too.
+func ProbeAll(ctx context.Context, minFree int64) []Health {
+	logger := log.FromContext(ctx)
+	stors := GetAllStorages()
+	probed := make(map[string]Health, len(stors))
+	for name, stor := range stors {
+		statable, ok := stor.(StorageStatable)
+		if !ok {
+			continue
+		}
+		h, supported := Probe(ctx, statable, minFree)
+		if !supported {
+			continue
+		}
+		if h.Error != "" {
+			logger.Warnf("Storage %s is unreachable: %s", name, h.Error)
+		}
+		probed[name] = h
+	}
+
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	var changed []Health
+	for name, h := range probed {
+		prev, ok := healths[name]
+		if (ok && prev.Healthy() != h.Healthy()) || (!ok && !h.Healthy()) {
+			changed = append(changed, h)
+		}
+	}
+	// the storages removed or replaced by one which can not report its capacity are dropped
+	healths = probed
+	return changed
+}
+
+// CheckSpace returns ErrInsufficientSpace if the storage reports less free space than size.
+// The free space of the last probe is used if it is recent, it passes if the storage can not tell its free space.
+func CheckSpace(ctx context.Context, stor Storage, size int64) error {
+	statable, ok := stor.(StorageStatable)
+	if !ok || size <= 0 {
+		return nil
+	}
+	h, ok := GetHealth(stor.Name())
+	if !ok || !h.Reachable || time.Since(h.CheckedAt) > spaceCacheTTL {
+		if h, ok = Probe(ctx, statable, 0); !ok || !h.Reachable {
+			return nil
+		}
+	}
+	if h.Stat.FreeKnown() && size > h.Stat.Free {
+		return fmt.Errorf("%w %s: %s needed, %s free", ErrInsufficientSpace, stor.Name(),
+			humanize.IBytes(uint64(size)), humanize.IBytes(uint64(h.Stat.Free)))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+)
+
+// statableStorage reports the capacity it is given
+type statableStorage struct {
+	*closableStorage
+	stat storagetypes.StorageStat
+	err  error
+}
+
+func (s *statableStorage) Stat(_ context.Context) (storagetypes.StorageStat, error) {
+	return s.stat, s.err
+}
+
+func TestProbeAllAndCheckSpace(t *testing.T) {
+	ctx := t.Context()
+	stor := &statableStorage{
+		closableStorage: newClosableStorage(t),
+		stat:            storagetypes.StorageStat{Total: 1000, Used: 900, Free: 100},
+	}
+	PutStorage(ctx, stor)
+	t.Cleanup(func() { RemoveStorage(ctx, stor.Name()) })
+
+	changed := ProbeAll(ctx, 50)
+	if len(changed) != 0 {
+		t.Fatalf("a healthy storage should not be reported on the first probe, got %v", changed)
+	}
+	if h, ok := GetHealth(stor.Name()); !ok || !h.Healthy() || h.Stat.Free != 100 {
+		t.Fatalf("unexpected health: %+v", h)
+	}
+
+	if err := CheckSpace(ctx, stor, 100); err != nil {
+		t.Fatalf("a file which fits should pass, got %v", err)
+	}
+	if err := CheckSpace(ctx, stor, 101); !errors.Is(err, ErrInsufficientSpace) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+
+	stor.stat.Free = 10
+	changed = ProbeAll(ctx, 50)
+	if len(changed) != 1 || !changed[0].LowSpace || changed[0].Healthy() {
+		t.Fatalf("the storage should become unhealthy for low space, got %v", changed)
+	}
+
+	stor.err = errors.New("connection refused")
+	if changed = ProbeAll(ctx, 50); len(changed) != 0 {
+		t.Fatalf("a storage which stays unhealthy should not be reported again, got %v", changed)
+	}
+	if h, _ := GetHealth(stor.Name()); h.Reachable || h.Error == "" {
+		t.Fatalf("the storage should be unreachable, got %+v", h)
+	}
+	// the space is not checked while the storage can not tell it
+	if err := CheckSpace(ctx, stor, 1000); err != nil {
+		t.Fatalf("an unreachable storage should not reject the file, got %v", err)
+	}
+
+	stor.err = nil
+	stor.stat.Free = storagetypes.StatUnknown
+	changed = ProbeAll(ctx, 50)
+	if len(changed) != 1 || !changed[0].Healthy() {
+		t.Fatalf("the storage should become healthy again, got %v", changed)
+	}
+	if err := CheckSpace(ctx, stor, 1000); err != nil {
+		t.Fatalf("an unknown free space should not reject the file, got %v", err)
+	}
+}
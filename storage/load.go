@@ -3,13 +3,30 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/kiss2u/SaveAny-Bot/config"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
 )
 
-var UserStorages = make(map[int64][]Storage)
+var (
+	// guards storages and userStorages, the storages can be added, replaced and removed at runtime
+	mu           sync.RWMutex
+	storages     = make(map[string]Storage)
+	userStorages = make(map[int64][]Storage)
+)
+
+// GetAllStorages returns the created storages by name
+func GetAllStorages() map[string]Storage {
+	mu.RLock()
+	defer mu.RUnlock()
+	return maps.Clone(storages)
+}
 
 // GetStorageByName returns storage by name from cache or creates new one
 // It should NOT be used to get storage for user, use GetStorageByUserIDAndName instead
@@ -18,7 +35,9 @@ func GetStorageByName(ctx context.Context, name string) (Storage, error) {
 		return nil, ErrStorageNameEmpty
 	}
 
-	storage, ok := Storages[name]
+	mu.RLock()
+	storage, ok := storages[name]
+	mu.RUnlock()
 	if ok {
 		return storage, nil
 	}
@@ -27,11 +46,21 @@ func GetStorageByName(ctx context.Context, name string) (Storage, error) {
 		return nil, fmt.Errorf("未找到存储 %s", name)
 	}
 
+	// not locked while creating, the encrypt and mirror storages get their storages with GetStorageByName
 	storage, err := NewStorage(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
-	Storages[name] = storage
+	mu.Lock()
+	defer mu.Unlock()
+	if existing, ok := storages[name]; ok {
+		CloseStorage(ctx, storage)
+		return existing, nil
+	}
+	// the storage was changed meanwhile, do not keep the outdated one
+	if config.C().GetStorageByName(name) == cfg {
+		storages[name] = storage
+	}
 	return storage, nil
 }
 
@@ -52,18 +81,36 @@ func GetUserStorages(ctx context.Context, chatID int64) []Storage {
 	if chatID <= 0 {
 		return nil
 	}
-	if storages, ok := UserStorages[chatID]; ok {
-		return storages
+	mu.RLock()
+	cached, ok := userStorages[chatID]
+	mu.RUnlock()
+	if ok {
+		return cached
 	}
-	var storages []Storage
+	return loadUserStorages(ctx, chatID)
+}
+
+func loadUserStorages(ctx context.Context, chatID int64) []Storage {
+	var stors []Storage
 	for _, name := range config.C().GetStorageNamesByUserID(chatID) {
 		storage, err := GetStorageByName(ctx, name)
 		if err != nil {
 			continue
 		}
-		storages = append(storages, storage)
+		stors = append(stors, storage)
 	}
-	return storages
+	return stors
+}
+
+// RefreshUserStorages computes the storages of the users again, after the storages or users are changed
+func RefreshUserStorages(ctx context.Context) {
+	loaded := make(map[int64][]Storage)
+	for _, user := range config.C().GetUsersID() {
+		loaded[user] = loadUserStorages(ctx, user)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	userStorages = loaded
 }
 
 func LoadStorages(ctx context.Context) {
@@ -75,9 +122,97 @@ func LoadStorages(ctx context.Context) {
 			logger.Errorf("failed to load storage %s: %v", storage.GetName(), err)
 		}
 	}
-	logger.Infof("successfully loaded %d storages", len(Storages))
-	for user := range config.C().GetUsersID() {
-		UserStorages[int64(user)] = GetUserStorages(ctx, int64(user))
+	logger.Infof("successfully loaded %d storages", len(GetAllStorages()))
+	RefreshUserStorages(ctx)
+}
+
+// PutStorage makes stor the storage of its name, replacing the existing one.
+// The storages saving through it are dropped to be created again with it.
+// It returns the replaced and dropped storages, which may still be used by tasks, see Retire.
+func PutStorage(ctx context.Context, stor Storage) []Storage {
+	mu.Lock()
+	dropped := dropStorages(stor.Name())
+	storages[stor.Name()] = stor
+	mu.Unlock()
+	RefreshUserStorages(ctx)
+	return dropped
+}
+
+// RemoveStorage removes the storage and the storages saving through it, it returns the removed storages, see Retire.
+func RemoveStorage(ctx context.Context, name string) []Storage {
+	mu.Lock()
+	dropped := dropStorages(name)
+	mu.Unlock()
+	RefreshUserStorages(ctx)
+	return dropped
+}
+
+// dropStorages removes the storage with the name and the ones saving through it, the caller must hold mu
+func dropStorages(name string) []Storage {
+	var dropped []Storage
+	c := config.C()
+	names := []string{name}
+	seen := map[string]bool{name: true}
+	for len(names) > 0 {
+		n := names[0]
+		names = names[1:]
+		if stor, ok := storages[n]; ok {
+			dropped = append(dropped, stor)
+			delete(storages, n)
+		}
+		// the encrypt and mirror storages keep the storages they got, so they must be created again
+		for _, referring := range c.ReferringStorages(n) {
+			if !seen[referring] {
+				seen[referring] = true
+				names = append(names, referring)
+			}
+		}
+	}
+	return dropped
+}
+
+// Retire closes the storages which implement io.Closer once inUse reports none of them is used by a task anymore,
+// it checks every interval until ctx is done, then they are closed anyway.
+// They are closed together as the encrypt and mirror storages among them may save through the others.
+func Retire(ctx context.Context, stors []Storage, inUse func(name string) bool, interval time.Duration) {
+	var closers []Storage
+	for _, stor := range stors {
+		if _, ok := stor.(io.Closer); ok {
+			closers = append(closers, stor)
+		}
+	}
+	if len(closers) == 0 {
+		return
+	}
+	used := func() bool {
+		return slices.ContainsFunc(stors, func(stor Storage) bool { return inUse(stor.Name()) })
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for used() {
+			select {
+			case <-ctx.Done():
+				for _, stor := range closers {
+					CloseStorage(ctx, stor)
+				}
+				return
+			case <-ticker.C:
+			}
+		}
+		for _, stor := range closers {
+			log.FromContext(ctx).Debugf("closing retired storage %s", stor.Name())
+			CloseStorage(ctx, stor)
+		}
+	}()
+}
+
+// CloseStorage closes the storage if it implements io.Closer
+func CloseStorage(ctx context.Context, stor Storage) {
+	if closer, ok := stor.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.FromContext(ctx).Warnf("failed to close storage %s: %v", stor.Name(), err)
+		}
 	}
 }
 
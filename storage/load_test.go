@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	storcfg "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/storage/local"
+)
+
+// closableStorage records whether it was closed
+type closableStorage struct {
+	*local.Local
+	closed atomic.Bool
+}
+
+func (s *closableStorage) Close() error {
+	s.closed.Store(true)
+	return nil
+}
+
+func newClosableStorage(t *testing.T) *closableStorage {
+	t.Helper()
+	stor := &closableStorage{Local: new(local.Local)}
+	err := stor.Init(t.Context(), &storcfg.LocalStorageConfig{
+		BaseConfig: storcfg.BaseConfig{Name: "test-retire", Type: "local", Enable: true},
+		BasePath:   t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	return stor
+}
+
+func TestPutAndRetireStorage(t *testing.T) {
+	ctx := t.Context()
+	first, second := newClosableStorage(t), newClosableStorage(t)
+	if replaced := PutStorage(ctx, first); len(replaced) != 0 {
+		t.Fatalf("nothing should be replaced, got %d storages", len(replaced))
+	}
+	replaced := PutStorage(ctx, second)
+	if len(replaced) != 1 || replaced[0] != first {
+		t.Fatalf("the first storage should be replaced, got %v", replaced)
+	}
+	if GetAllStorages()["test-retire"] != second {
+		t.Fatalf("the second storage should be used")
+	}
+
+	var inUse atomic.Bool
+	inUse.Store(true)
+	Retire(ctx, replaced, func(name string) bool { return inUse.Load() }, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if first.closed.Load() {
+		t.Fatalf("the storage should not be closed while in use")
+	}
+	inUse.Store(false)
+	deadline := time.Now().Add(time.Second)
+	for !first.closed.Load() {
+		if time.Now().After(deadline) {
+			t.Fatalf("the storage should be closed once not in use")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	removed := RemoveStorage(ctx, "test-retire")
+	if len(removed) != 1 || removed[0] != second {
+		t.Fatalf("the second storage should be removed, got %v", removed)
+	}
+	if _, ok := GetAllStorages()["test-retire"]; ok {
+		t.Fatalf("the storage should be removed")
+	}
+	if second.closed.Load() {
+		t.Fatalf("the removed storage should only be closed by Retire")
+	}
+}
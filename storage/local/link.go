@@ -0,0 +1,37 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/duke-git/lancet/v2/fileutil"
+)
+
+// Link implements StorageLinkable interface.
+// It makes a hard link, or a symbolic link if the file system does not support hard links.
+func (l *Local) Link(ctx context.Context, srcPath, dstPath string) error {
+	src := l.JoinStoragePath(srcPath)
+	dst := l.JoinStoragePath(dstPath)
+	l.logger.Infof("Linking file %s to %s", dst, src)
+	if err := fileutil.CreateDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	// the conflict policy may resolve to an existing file, which is replaced
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	absSrc, err := filepath.Abs(src)
+	if err != nil {
+		return err
+	}
+	if err := os.Symlink(absSrc, dst); err != nil {
+		return fmt.Errorf("failed to link file %s to %s: %w", dst, src, err)
+	}
+	return nil
+}
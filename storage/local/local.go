@@ -2,14 +2,15 @@ package local
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/duke-git/lancet/v2/fileutil"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	config "github.com/kiss2u/SaveAny-Bot/config/storage"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
@@ -50,23 +51,21 @@ func (l *Local) JoinStoragePath(path string) string {
 }
 
 func (l *Local) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, l.Name(), r)
 	l.logger.Infof("Saving file to %s", storagePath)
 	storagePath = l.JoinStoragePath(storagePath)
 
-	ext := filepath.Ext(storagePath)
-	base := strings.TrimSuffix(storagePath, ext)
-	candidate := storagePath
-	for i := 1; l.Exists(ctx, candidate); i++ {
-		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
-	}
-
-	absPath, err := filepath.Abs(candidate)
+	absPath, err := filepath.Abs(storagePath)
 	if err != nil {
 		return err
 	}
 	if err := fileutil.CreateDir(filepath.Dir(absPath)); err != nil {
 		return err
 	}
+	// replace the file rather than writing through it, it may be a link to another saved file
+	if err := os.Remove(absPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
 	file, err := os.Create(absPath)
 	if err != nil {
 		return err
@@ -131,3 +130,45 @@ func (l *Local) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, i
 	
 	return file, stat.Size(), nil
 }
+
+// Delete implements StorageDeletable interface
+func (l *Local) Delete(ctx context.Context, filePath string) error {
+	absPath := l.JoinStoragePath(filePath)
+	l.logger.Infof("Deleting file %s", absPath)
+	if err := os.Remove(absPath); err != nil {
+		return fmt.Errorf("failed to delete file %s: %w", absPath, err)
+	}
+	return nil
+}
+
+// Move implements StorageMovable interface
+func (l *Local) Move(ctx context.Context, srcPath, dstPath string) error {
+	src := l.JoinStoragePath(srcPath)
+	dst := l.JoinStoragePath(dstPath)
+	l.logger.Infof("Moving file %s to %s", src, dst)
+	if err := fileutil.CreateDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("failed to move file %s to %s: %w", src, dst, err)
+	}
+	return nil
+}
+
+// MkDir implements StorageDirMaker interface
+func (l *Local) MkDir(ctx context.Context, dirPath string) error {
+	absPath := l.JoinStoragePath(dirPath)
+	if err := os.MkdirAll(absPath, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", absPath, err)
+	}
+	return nil
+}
+
+// Stat implements StorageStatable interface, it reports the capacity of the file system of the base path
+func (l *Local) Stat(ctx context.Context) (storagetypes.StorageStat, error) {
+	total, used, free, err := diskStat(l.config.BasePath)
+	if err != nil {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to stat %s: %w", l.config.BasePath, err)
+	}
+	return storagetypes.StorageStat{Total: total, Used: used, Free: free}, nil
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+
+package local
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// diskStat returns the total, used and free bytes of the file system of the path, free is the space available to the bot
+func diskStat(path string) (total, used, free int64, err error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, 0, 0, err
+	}
+	bsize := int64(st.Bsize)
+	total = int64(st.Blocks) * bsize
+	used = (int64(st.Blocks) - int64(st.Bfree)) * bsize
+	free = int64(st.Bavail) * bsize
+	return total, used, free, nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package local
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// diskStat returns the total, used and free bytes of the volume of the path, free is the space available to the bot
+func diskStat(path string) (total, used, free int64, err error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	var freeAvailable, totalBytes, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &freeAvailable, &totalBytes, &totalFree); err != nil {
+		return 0, 0, 0, err
+	}
+	return int64(totalBytes), int64(totalBytes - totalFree), int64(freeAvailable), nil
+}
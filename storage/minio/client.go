@@ -3,6 +3,7 @@
 package minio
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -11,12 +12,13 @@ import (
 	"sync"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	config "github.com/kiss2u/SaveAny-Bot/config/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
-	"github.com/rs/xid"
 )
 
 var (
@@ -76,19 +78,9 @@ func (m *Minio) JoinStoragePath(p string) string {
 }
 
 func (m *Minio) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, m.Name(), r)
 	m.logger.Infof("Saving file from reader to %s", storagePath)
 	storagePath = m.JoinStoragePath(storagePath)
-	ext := path.Ext(storagePath)
-	base := strings.TrimSuffix(storagePath, ext)
-	candidate := storagePath
-	for i := 1; m.Exists(ctx, candidate); i++ {
-		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
-		if i > 10 {
-			m.logger.Errorf("Too many attempts to find a unique filename for %s", storagePath)
-			candidate = fmt.Sprintf("%s_%s%s", base, xid.New().String(), ext)
-			break
-		}
-	}
 	size := int64(-1)
 	if length := ctx.Value(ctxkey.ContentLength); length != nil {
 		length, ok := length.(int64)
@@ -96,7 +88,7 @@ func (m *Minio) Save(ctx context.Context, r io.Reader, storagePath string) error
 			size = length
 		}
 	}
-	_, err := m.client.PutObject(ctx, m.config.BucketName, candidate, r, size, minio.PutObjectOptions{})
+	_, err := m.client.PutObject(ctx, m.config.BucketName, storagePath, r, size, minio.PutObjectOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to upload file to minio: %w", err)
 	}
@@ -109,3 +101,86 @@ func (m *Minio) Exists(ctx context.Context, storagePath string) bool {
 	_, err := m.client.StatObject(ctx, m.config.BucketName, storagePath, minio.StatObjectOptions{})
 	return err == nil
 }
+
+// ListFiles implements storage.StorageListable, the common prefixes of the keys are the directories
+func (m *Minio) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error) {
+	prefix := m.JoinStoragePath(dirPath)
+	if prefix != "" {
+		prefix += "/"
+	}
+	var files []storagetypes.FileInfo
+	for obj := range m.client.ListObjects(ctx, m.config.BucketName, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("failed to list files in minio: %w", obj.Err)
+		}
+		name := strings.TrimPrefix(obj.Key, prefix)
+		isDir := strings.HasSuffix(name, "/")
+		name = strings.TrimSuffix(name, "/")
+		if name == "" { // the marker object of the directory itself
+			continue
+		}
+		files = append(files, storagetypes.FileInfo{
+			Name:    name,
+			Path:    path.Join(dirPath, name),
+			Size:    obj.Size,
+			IsDir:   isDir,
+			ModTime: obj.LastModified,
+		})
+	}
+	return files, nil
+}
+
+// OpenFile implements storage.StorageReadable
+func (m *Minio) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	obj, err := m.client.GetObject(ctx, m.config.BucketName, m.JoinStoragePath(filePath), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file from minio: %w", err)
+	}
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, 0, fmt.Errorf("failed to stat file in minio: %w", err)
+	}
+	return obj, stat.Size, nil
+}
+
+// Delete implements storage.StorageDeletable
+func (m *Minio) Delete(ctx context.Context, filePath string) error {
+	key := m.JoinStoragePath(filePath)
+	m.logger.Infof("Deleting file %s", key)
+	if err := m.client.RemoveObject(ctx, m.config.BucketName, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete file from minio: %w", err)
+	}
+	return nil
+}
+
+// Move implements storage.StorageMovable by copying the object then deleting the source
+func (m *Minio) Move(ctx context.Context, srcPath, dstPath string) error {
+	src := m.JoinStoragePath(srcPath)
+	dst := m.JoinStoragePath(dstPath)
+	m.logger.Infof("Moving file %s to %s", src, dst)
+	_, err := m.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: m.config.BucketName, Object: dst},
+		minio.CopySrcOptions{Bucket: m.config.BucketName, Object: src},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy file in minio: %w", err)
+	}
+	if err := m.client.RemoveObject(ctx, m.config.BucketName, src, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete source file from minio: %w", err)
+	}
+	return nil
+}
+
+// MkDir implements storage.StorageDirMaker by creating an empty "dir/" object
+func (m *Minio) MkDir(ctx context.Context, dirPath string) error {
+	key := m.JoinStoragePath(dirPath)
+	if key == "" {
+		return nil
+	}
+	_, err := m.client.PutObject(ctx, m.config.BucketName, key+"/", bytes.NewReader(nil), 0, minio.PutObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create directory in minio: %w", err)
+	}
+	return nil
+}
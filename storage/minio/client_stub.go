@@ -11,6 +11,7 @@ import (
 
 	config "github.com/kiss2u/SaveAny-Bot/config/storage"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
 )
 
 type Minio struct {
@@ -39,3 +40,23 @@ func (m *Minio) Save(_ context.Context, _ io.Reader, _ string) error {
 func (m *Minio) Exists(_ context.Context, _ string) bool {
 	return false
 }
+
+func (m *Minio) Delete(_ context.Context, _ string) error {
+	return fmt.Errorf("minio storage is not supported in this build")
+}
+
+func (m *Minio) Move(_ context.Context, _, _ string) error {
+	return fmt.Errorf("minio storage is not supported in this build")
+}
+
+func (m *Minio) MkDir(_ context.Context, _ string) error {
+	return fmt.Errorf("minio storage is not supported in this build")
+}
+
+func (m *Minio) ListFiles(_ context.Context, _ string) ([]storagetypes.FileInfo, error) {
+	return nil, fmt.Errorf("minio storage is not supported in this build")
+}
+
+func (m *Minio) OpenFile(_ context.Context, _ string) (io.ReadCloser, int64, error) {
+	return nil, 0, fmt.Errorf("minio storage is not supported in this build")
+}
@@ -0,0 +1,11 @@
+package mirror
+
+import "errors"
+
+var (
+	ErrNestedMirror     = errors.New("mirror: can not have another mirror storage as member")
+	ErrNotEnoughSaved   = errors.New("mirror: not enough members saved the file")
+	ErrIncompleteRead   = errors.New("mirror: member did not read the whole file")
+	ErrFailedToSpill    = errors.New("mirror: failed to write temporary file")
+	ErrOtherMembersFail = errors.New("mirror: canceled as too many other members failed")
+)
@@ -0,0 +1,251 @@
+package mirror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+)
+
+// Member is a storage the files are saved to, storage.Storage implements it
+type Member interface {
+	Name() string
+	Save(ctx context.Context, r io.Reader, storagePath string) error
+	Exists(ctx context.Context, storagePath string) bool
+}
+
+// Resolver returns the configured storage with the name
+type Resolver func(ctx context.Context, name string) (Member, error)
+
+type memberPathJoiner interface {
+	JoinStoragePath(p string) string
+}
+
+type memberCannotStream interface {
+	CannotStream() string
+}
+
+// Mirror saves each file to all of its member storages at once
+type Mirror struct {
+	config  storconfig.MirrorStorageConfig
+	logger  *log.Logger
+	resolve Resolver
+	members []Member
+}
+
+// New returns a mirror storage which looks up its members with resolve
+func New(resolve Resolver) *Mirror {
+	return &Mirror{resolve: resolve}
+}
+
+func (m *Mirror) Init(ctx context.Context, cfg storconfig.StorageConfig) error {
+	mirrorConfig, ok := cfg.(*storconfig.MirrorStorageConfig)
+	if !ok {
+		return fmt.Errorf("failed to cast mirror config")
+	}
+	if err := mirrorConfig.Validate(); err != nil {
+		return err
+	}
+	m.config = *mirrorConfig
+	m.logger = log.FromContext(ctx).WithPrefix(fmt.Sprintf("mirror[%s]", m.config.Name))
+
+	m.members = make([]Member, 0, len(m.config.Storages))
+	for _, name := range m.config.Storages {
+		// check before resolving, mirror storages having each other as member would resolve forever
+		if memberConfig := config.C().GetStorageByName(name); memberConfig != nil && memberConfig.GetType() == storenum.Mirror {
+			return ErrNestedMirror
+		}
+		member, err := m.resolve(ctx, name)
+		if err != nil {
+			return fmt.Errorf("failed to get member storage %s: %w", name, err)
+		}
+		m.members = append(m.members, member)
+	}
+	return nil
+}
+
+func (m *Mirror) Type() storenum.StorageType {
+	return storenum.Mirror
+}
+
+func (m *Mirror) Name() string {
+	return m.config.Name
+}
+
+// Exists takes the path given to Save, it reports whether the file exists in any member,
+// so a renamed file gets a path which is free in all of them.
+func (m *Mirror) Exists(ctx context.Context, storagePath string) bool {
+	for _, member := range m.members {
+		memberPath := storagePath
+		if j, ok := member.(memberPathJoiner); ok {
+			memberPath = j.JoinStoragePath(memberPath)
+		}
+		if member.Exists(ctx, memberPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// Save tees the reader to the members saving concurrently, the members which can not save streams
+// get the file from a temporary file written along. The members save to the same storagePath,
+// the conflict policy of the mirror is applied, not the ones of the members.
+// The results of the members are added to the storagetypes.MirrorReport of ctx.
+func (m *Mirror) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.StorageReader(ctx, m.Name(), r)
+	m.logger.Infof("Saving file to %s in %d storages", storagePath, len(m.members))
+
+	required := m.config.RequiredSuccess()
+	mctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]storagetypes.MemberResult, len(m.members))
+	var (
+		mu     sync.Mutex
+		failed int
+	)
+	setResult := func(i int, err error) {
+		results[i] = storagetypes.MemberResult{StorageName: m.members[i].Name(), Err: err}
+		if err == nil {
+			return
+		}
+		m.logger.Errorf("Failed to save file to %s: %v", m.members[i].Name(), err)
+		mu.Lock()
+		defer mu.Unlock()
+		failed++
+		// the save fails anyway, stop the other members
+		if len(m.members)-failed < required {
+			cancel(ErrOtherMembersFail)
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		streams []*memberStream
+		spilled []int
+	)
+	for i, member := range m.members {
+		if _, ok := member.(memberCannotStream); ok {
+			spilled = append(spilled, i)
+			continue
+		}
+		pr, pw := io.Pipe()
+		streams = append(streams, &memberStream{index: i, pw: pw})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := member.Save(mctx, pr, storagePath)
+			// writing to a member which stopped reading fails, instead of blocking
+			pr.CloseWithError(cmpErr(err, ErrIncompleteRead))
+			setResult(i, err)
+		}()
+	}
+
+	var spill *os.File
+	if len(spilled) > 0 {
+		var err error
+		if spill, err = createSpillFile(); err != nil {
+			for _, i := range spilled {
+				setResult(i, err)
+			}
+		} else {
+			defer func() {
+				spill.Close()
+				os.Remove(spill.Name())
+			}()
+		}
+	}
+
+	size, spillErr, copyErr := tee(mctx, r, streams, spill)
+	if copyErr != nil {
+		cancel(copyErr)
+	}
+	for _, stream := range streams {
+		stream.pw.CloseWithError(copyErr)
+	}
+	if spill != nil {
+		sctx := context.WithValue(mctx, ctxkey.ContentLength, size)
+		for _, i := range spilled {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				switch {
+				case copyErr != nil:
+					setResult(i, copyErr)
+				case spillErr != nil:
+					setResult(i, fmt.Errorf("%w: %w", ErrFailedToSpill, spillErr))
+				default:
+					setResult(i, m.saveSpilled(sctx, m.members[i], spill.Name(), storagePath))
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	for _, stream := range streams {
+		if stream.dropped && results[stream.index].Err == nil {
+			results[stream.index].Err = ErrIncompleteRead
+		}
+	}
+
+	if report := storagetypes.MirrorReportFromContext(ctx); report != nil {
+		report.Add(storagetypes.MirrorSave{MirrorName: m.Name(), StoragePath: storagePath, Results: results})
+	}
+	if copyErr != nil && !errors.Is(copyErr, ErrOtherMembersFail) {
+		return copyErr
+	}
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.StorageName, result.Err))
+		}
+	}
+	if saved := len(m.members) - len(errs); saved < required {
+		return fmt.Errorf("%w: %d of %d saved, %d required: %w", ErrNotEnoughSaved, saved, len(m.members), required, errors.Join(errs...))
+	}
+	if len(errs) > 0 {
+		m.logger.Warnf("Saved file to %d of %d storages: %v", len(m.members)-len(errs), len(m.members), errors.Join(errs...))
+	}
+	return nil
+}
+
+func (m *Mirror) saveSpilled(ctx context.Context, member Member, spillPath, storagePath string) error {
+	file, err := os.Open(spillPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToSpill, err)
+	}
+	defer file.Close()
+	return member.Save(ctx, file, storagePath)
+}
+
+func createSpillFile() (*os.File, error) {
+	tempDir := config.C().Temp.BasePath
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
+	if err := os.MkdirAll(tempDir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToSpill, err)
+	}
+	file, err := os.CreateTemp(tempDir, "mirror-*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToSpill, err)
+	}
+	return file, nil
+}
+
+// cmpErr returns err, or def if err is nil
+func cmpErr(err, def error) error {
+	if err != nil {
+		return err
+	}
+	return def
+}
@@ -0,0 +1,241 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/charmbracelet/log"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+)
+
+func newTestContext(t *testing.T) context.Context {
+	t.Helper()
+	logger := log.NewWithOptions(io.Discard, log.Options{ReportTimestamp: false})
+	return log.WithContext(t.Context(), logger)
+}
+
+// memStorage keeps the saved files in memory, it fails after reading failAfter bytes if failAfter >= 0
+type memStorage struct {
+	name      string
+	failAfter int
+	mu        sync.Mutex
+	files     map[string][]byte
+}
+
+var errMemFailed = errors.New("mem storage failed")
+
+func newMemStorage(name string) *memStorage {
+	return &memStorage{name: name, failAfter: -1, files: make(map[string][]byte)}
+}
+
+func (s *memStorage) Name() string {
+	return s.name
+}
+
+func (s *memStorage) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	if s.failAfter >= 0 {
+		io.CopyN(io.Discard, r, int64(s.failAfter))
+		return errMemFailed
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[storagePath] = data
+	return nil
+}
+
+func (s *memStorage) Exists(ctx context.Context, storagePath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.files[storagePath]
+	return ok
+}
+
+func (s *memStorage) file(storagePath string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.files[storagePath]
+}
+
+// fileOnlyStorage can not save streams, like the telegram storage
+type fileOnlyStorage struct {
+	*memStorage
+	contentLength int64
+}
+
+func (s *fileOnlyStorage) CannotStream() string {
+	return "needs a file"
+}
+
+func (s *fileOnlyStorage) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	if _, ok := r.(*os.File); !ok {
+		return errors.New("not a file")
+	}
+	s.contentLength, _ = ctx.Value(ctxkey.ContentLength).(int64)
+	return s.memStorage.Save(ctx, r, storagePath)
+}
+
+func newTestMirror(t *testing.T, policy string, minSuccess int, members ...Member) *Mirror {
+	t.Helper()
+	byName := make(map[string]Member)
+	var names []string
+	for _, member := range members {
+		byName[member.Name()] = member
+		names = append(names, member.Name())
+	}
+	m := New(func(_ context.Context, name string) (Member, error) {
+		member, ok := byName[name]
+		if !ok {
+			return nil, errors.New("unknown storage " + name)
+		}
+		return member, nil
+	})
+	err := m.Init(newTestContext(t), &storconfig.MirrorStorageConfig{
+		BaseConfig: storconfig.BaseConfig{Name: "test-mirror", Type: "mirror", Enable: true},
+		Storages:   names,
+		Policy:     policy,
+		MinSuccess: minSuccess,
+	})
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	return m
+}
+
+func TestMirrorSave(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	content := make([]byte, 200<<10+123)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	first, second := newMemStorage("first"), newMemStorage("second")
+	fileOnly := &fileOnlyStorage{memStorage: newMemStorage("file-only")}
+	m := newTestMirror(t, "", 0, first, second, fileOnly)
+	ctx, report := storagetypes.WithMirrorReport(newTestContext(t))
+
+	// a one byte reader checks that short reads are teed whole
+	if err := m.Save(ctx, iotest.OneByteReader(bytes.NewReader(content)), "a/file.bin"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	for _, s := range []*memStorage{first, second, fileOnly.memStorage} {
+		if got := s.file("a/file.bin"); !bytes.Equal(got, content) {
+			t.Fatalf("content saved to %s mismatch, got %d bytes, want %d", s.name, len(got), len(content))
+		}
+	}
+	if fileOnly.contentLength != int64(len(content)) {
+		t.Fatalf("the content length of the spilled file should be set, got %d", fileOnly.contentLength)
+	}
+	if !m.Exists(ctx, "a/file.bin") || m.Exists(ctx, "a/missing.bin") {
+		t.Fatalf("unexpected Exists results")
+	}
+	entries, _ := os.ReadDir(os.TempDir())
+	if len(entries) != 0 {
+		t.Fatalf("the temporary file should be removed, got %d files", len(entries))
+	}
+
+	summary := report.Summary()
+	if len(summary) != 3 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	for _, member := range summary {
+		if member.Saved != 1 || member.Total != 1 || member.LastErr != nil {
+			t.Fatalf("unexpected member summary: %+v", member)
+		}
+	}
+}
+
+func TestMirrorPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		minSuccess int
+		failing    int
+		wantErr    bool
+	}{
+		{name: "all succeed", policy: storconfig.MirrorPolicyAll, failing: 0},
+		{name: "all with a failure", policy: storconfig.MirrorPolicyAll, failing: 1, wantErr: true},
+		{name: "at least 2 with a failure", policy: storconfig.MirrorPolicyAtLeast, minSuccess: 2, failing: 1},
+		{name: "at least 2 with two failures", policy: storconfig.MirrorPolicyAtLeast, minSuccess: 2, failing: 2, wantErr: true},
+		{name: "best effort with two failures", policy: storconfig.MirrorPolicyBestEffort, failing: 2},
+		{name: "best effort with all failed", policy: storconfig.MirrorPolicyBestEffort, failing: 3, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var members []Member
+			for i, name := range []string{"a", "b", "c"} {
+				s := newMemStorage(name)
+				if i < tt.failing {
+					// the failing members stop reading early, which must not block the others
+					s.failAfter = i * 100
+				}
+				members = append(members, s)
+			}
+			m := newTestMirror(t, tt.policy, tt.minSuccess, members...)
+			ctx, report := storagetypes.WithMirrorReport(newTestContext(t))
+
+			err := m.Save(ctx, bytes.NewReader(make([]byte, 256<<10)), "file.bin")
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err != nil && !errors.Is(err, ErrNotEnoughSaved) {
+				t.Fatalf("expected ErrNotEnoughSaved, got %v", err)
+			}
+			failed := 0
+			for _, member := range report.Summary() {
+				if member.LastErr != nil {
+					failed++
+				}
+			}
+			if failed < tt.failing {
+				t.Fatalf("the failed members should be reported, got %+v", report.Summary())
+			}
+		})
+	}
+}
+
+func TestMirrorSaveReadError(t *testing.T) {
+	first, second := newMemStorage("first"), newMemStorage("second")
+	m := newTestMirror(t, storconfig.MirrorPolicyBestEffort, 0, first, second)
+	r := io.MultiReader(bytes.NewReader(make([]byte, 1000)), iotest.ErrReader(io.ErrClosedPipe))
+	if err := m.Save(newTestContext(t), r, "file.bin"); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("Save should fail with the read error, got %v", err)
+	}
+	if first.Exists(t.Context(), "file.bin") || second.Exists(t.Context(), "file.bin") {
+		t.Fatalf("the members should not save a partial file")
+	}
+}
+
+func TestMirrorConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  storconfig.MirrorStorageConfig
+		ok   bool
+	}{
+		{name: "default policy", cfg: storconfig.MirrorStorageConfig{Storages: []string{"a", "b"}}, ok: true},
+		{name: "no members", cfg: storconfig.MirrorStorageConfig{}},
+		{name: "duplicate member", cfg: storconfig.MirrorStorageConfig{Storages: []string{"a", "a"}}},
+		{name: "itself", cfg: storconfig.MirrorStorageConfig{BaseConfig: storconfig.BaseConfig{Name: "m"}, Storages: []string{"a", "m"}}},
+		{name: "at least in range", cfg: storconfig.MirrorStorageConfig{Storages: []string{"a", "b"}, Policy: "at_least", MinSuccess: 2}, ok: true},
+		{name: "at least out of range", cfg: storconfig.MirrorStorageConfig{Storages: []string{"a", "b"}, Policy: "at_least", MinSuccess: 3}},
+		{name: "unknown policy", cfg: storconfig.MirrorStorageConfig{Storages: []string{"a"}, Policy: "some"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); tt.ok != (err == nil) {
+				t.Fatalf("unexpected validation result: %v", err)
+			}
+		})
+	}
+}
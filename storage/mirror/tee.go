@@ -0,0 +1,62 @@
+package mirror
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// memberStream is the pipe to a member saving the stream
+type memberStream struct {
+	index   int
+	pw      *io.PipeWriter
+	dropped bool // writing failed as the member stopped reading
+}
+
+// tee copies r to the streams and the spill file, the streams whose member stopped are dropped.
+// It stops early if all the streams are dropped and there is no spill file to write.
+// It returns the size copied and the error writing the spill file, which does not stop the copy.
+func tee(ctx context.Context, r io.Reader, streams []*memberStream, spill *os.File) (size int64, spillErr, err error) {
+	buf := make([]byte, 32<<10)
+	for {
+		if (spill == nil || spillErr != nil) && !hasActive(streams) {
+			return size, spillErr, nil
+		}
+		if ctx.Err() != nil {
+			return size, spillErr, context.Cause(ctx)
+		}
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			data := buf[:n]
+			if spill != nil && spillErr == nil {
+				if _, werr := spill.Write(data); werr != nil {
+					spillErr = werr
+				}
+			}
+			for _, stream := range streams {
+				if stream.dropped {
+					continue
+				}
+				if _, werr := stream.pw.Write(data); werr != nil {
+					stream.dropped = true
+				}
+			}
+			size += int64(n)
+		}
+		if rerr == io.EOF {
+			return size, spillErr, nil
+		}
+		if rerr != nil {
+			return size, spillErr, rerr
+		}
+	}
+}
+
+func hasActive(streams []*memberStream) bool {
+	for _, stream := range streams {
+		if !stream.dropped {
+			return true
+		}
+	}
+	return false
+}
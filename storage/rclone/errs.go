@@ -10,5 +10,8 @@ var (
 	ErrFailedToOpenFile  = errors.New("rclone: failed to open file")
 	ErrFailedToCheckFile = errors.New("rclone: failed to check file exists")
 	ErrFailedToCreateDir = errors.New("rclone: failed to create directory")
+	ErrFailedToDelete    = errors.New("rclone: failed to delete file")
+	ErrFailedToMove      = errors.New("rclone: failed to move file")
+	ErrFailedToGetAbout  = errors.New("rclone: failed to get remote usage")
 	ErrCommandFailed     = errors.New("rclone: command execution failed")
 )
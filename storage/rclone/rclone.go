@@ -13,10 +13,10 @@ import (
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	config "github.com/kiss2u/SaveAny-Bot/config/storage"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
-	"github.com/rs/xid"
 )
 
 type Rclone struct {
@@ -102,21 +102,10 @@ func (r *Rclone) getRemotePath(storagePath string) string {
 }
 
 func (r *Rclone) Save(ctx context.Context, reader io.Reader, storagePath string) error {
+	reader = bandwidth.UploadReader(ctx, r.Name(), reader)
 	r.logger.Infof("Saving file to %s", storagePath)
 
-	ext := path.Ext(storagePath)
-	base := strings.TrimSuffix(storagePath, ext)
-	candidate := storagePath
-	for i := 1; r.Exists(ctx, candidate); i++ {
-		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
-		if i > 100 {
-			r.logger.Errorf("Too many attempts to find a unique filename for %s", storagePath)
-			candidate = fmt.Sprintf("%s_%s%s", base, xid.New().String(), ext)
-			break
-		}
-	}
-
-	remotePath := r.getRemotePath(candidate)
+	remotePath := r.getRemotePath(storagePath)
 	r.logger.Debugf("Remote path: %s", remotePath)
 
 	// Use rclone rcat to read from stdin and upload
@@ -134,7 +123,7 @@ func (r *Rclone) Save(ctx context.Context, reader io.Reader, storagePath string)
 		return fmt.Errorf("%w: %s", ErrFailedToSaveFile, stderr.String())
 	}
 
-	r.logger.Infof("Successfully saved file to %s", candidate)
+	r.logger.Infof("Successfully saved file to %s", storagePath)
 	return nil
 }
 
@@ -287,3 +276,83 @@ func (r *rcloneCatReader) Close() error {
 	}
 	return nil
 }
+
+// Delete implements storage.StorageDeletable
+func (r *Rclone) Delete(ctx context.Context, filePath string) error {
+	r.logger.Infof("Deleting file %s", filePath)
+	if stderr, err := r.run(ctx, "deletefile", r.getRemotePath(filePath)); err != nil {
+		r.logger.Errorf("Failed to delete file: %v, stderr: %s", err, stderr)
+		return fmt.Errorf("%w: %s", ErrFailedToDelete, stderr)
+	}
+	return nil
+}
+
+// Move implements storage.StorageMovable
+func (r *Rclone) Move(ctx context.Context, srcPath, dstPath string) error {
+	r.logger.Infof("Moving file %s to %s", srcPath, dstPath)
+	if stderr, err := r.run(ctx, "moveto", r.getRemotePath(srcPath), r.getRemotePath(dstPath)); err != nil {
+		r.logger.Errorf("Failed to move file: %v, stderr: %s", err, stderr)
+		return fmt.Errorf("%w: %s", ErrFailedToMove, stderr)
+	}
+	return nil
+}
+
+// MkDir implements storage.StorageDirMaker
+func (r *Rclone) MkDir(ctx context.Context, dirPath string) error {
+	if stderr, err := r.run(ctx, "mkdir", r.getRemotePath(dirPath)); err != nil {
+		r.logger.Errorf("Failed to create directory: %v, stderr: %s", err, stderr)
+		return fmt.Errorf("%w: %s", ErrFailedToCreateDir, stderr)
+	}
+	return nil
+}
+
+// run runs the rclone subcommand and returns its stderr
+func (r *Rclone) run(ctx context.Context, subcommand string, remotePaths ...string) (string, error) {
+	args := r.buildBaseArgs()
+	args = append(args, subcommand)
+	args = append(args, remotePaths...)
+
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stderr.String(), err
+}
+
+// aboutOutput is the output of `rclone about --json`, the fields the remote does not report are omitted
+type aboutOutput struct {
+	Total *int64 `json:"total"`
+	Used  *int64 `json:"used"`
+	Free  *int64 `json:"free"`
+}
+
+// Stat implements storage.StorageStatable with `rclone about`, not all remotes support it
+func (r *Rclone) Stat(ctx context.Context) (storagetypes.StorageStat, error) {
+	args := r.buildBaseArgs()
+	args = append(args, "about", "--json", r.getRemotePath(""))
+
+	cmd := exec.CommandContext(ctx, "rclone", args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		r.logger.Errorf("Failed to get remote usage: %v, stderr: %s", err, stderr.String())
+		return storagetypes.StorageStat{}, fmt.Errorf("%w: %s", ErrFailedToGetAbout, stderr.String())
+	}
+
+	var about aboutOutput
+	if err := json.Unmarshal(stdout.Bytes(), &about); err != nil {
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to parse about output: %w", err)
+	}
+	stat := storagetypes.NewStorageStat()
+	if about.Total != nil {
+		stat.Total = *about.Total
+	}
+	if about.Used != nil {
+		stat.Used = *about.Used
+	}
+	if about.Free != nil {
+		stat.Free = *about.Free
+	}
+	return stat, nil
+}
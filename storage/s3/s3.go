@@ -1,18 +1,23 @@
 package s3
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"path"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
 	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/s3"
-	"github.com/rs/xid"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
 )
 
 type S3 struct {
@@ -48,9 +53,39 @@ func (m *S3) Init(ctx context.Context, cfg storconfig.StorageConfig) error {
 	if err := m.client.HeadBucket(ctx); err != nil {
 		return fmt.Errorf("bucket %s not accessible: %w", m.config.BucketName, err)
 	}
+	go m.abortStaleUploads(context.WithoutCancel(ctx))
 	return nil
 }
 
+// staleUploadAge is the age after which an unfinished multipart upload is considered abandoned
+const staleUploadAge = 24 * time.Hour
+
+// abortStaleUploads aborts the multipart uploads left under the base path by crashed or killed saves
+func (m *S3) abortStaleUploads(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	prefix := m.JoinStoragePath("")
+	if prefix != "" {
+		prefix += "/"
+	}
+	aborted, err := m.client.AbortStaleMultipartUploads(ctx, prefix, staleUploadAge)
+	if err != nil {
+		m.logger.Warnf("Failed to abort stale multipart uploads: %s", err)
+		return
+	}
+	if aborted > 0 {
+		m.logger.Infof("Aborted %d stale multipart uploads", aborted)
+	}
+}
+
+func (m *S3) multipartOptions() s3.MultipartOptions {
+	return s3.MultipartOptions{
+		PartSize:    m.config.PartSizeMB * 1024 * 1024,
+		Concurrency: m.config.UploadThreads,
+		Retry:       config.C().Retry,
+	}
+}
+
 func (m *S3) Type() storenum.StorageType {
 	return storenum.S3
 }
@@ -64,21 +99,9 @@ func (m *S3) JoinStoragePath(p string) string {
 }
 
 func (m *S3) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, m.Name(), r)
 	m.logger.Infof("Saving file from reader to %s", storagePath)
 	storagePath = m.JoinStoragePath(storagePath)
-	ext := path.Ext(storagePath)
-	base := strings.TrimSuffix(storagePath, ext)
-	candidate := storagePath
-
-	// Unique filename
-	for i := 1; m.Exists(ctx, candidate); i++ {
-		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
-		if i > 10 {
-			m.logger.Errorf("Too many attempts for unique filename: %s", storagePath)
-			candidate = fmt.Sprintf("%s_%s%s", base, xid.New().String(), ext)
-			break
-		}
-	}
 
 	// Determine content length
 	size := int64(-1)
@@ -88,7 +111,15 @@ func (m *S3) Save(ctx context.Context, r io.Reader, storagePath string) error {
 		}
 	}
 
-	err := m.client.Put(ctx, candidate, r, size)
+	// a single PUT is limited to 5 GB and can only be retried as a whole,
+	// so large or unknown-size streams are uploaded in parts
+	var err error
+	opts := m.multipartOptions()
+	if size < 0 || size > max(opts.PartSize, s3.DefaultPartSize) {
+		err = m.client.UploadMultipart(ctx, storagePath, r, size, opts)
+	} else {
+		err = m.client.Put(ctx, storagePath, r, size)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to upload file to S3: %w", err)
 	}
@@ -101,3 +132,90 @@ func (m *S3) Exists(ctx context.Context, storagePath string) bool {
 
 	return m.client.Exists(ctx, storagePath)
 }
+
+// ListFiles implements storage.StorageListable, the common prefixes of the keys are the directories
+func (m *S3) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error) {
+	prefix := m.JoinStoragePath(dirPath)
+	if prefix != "" {
+		prefix += "/"
+	}
+	objects, prefixes, err := m.client.ListObjects(ctx, prefix, "/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in S3: %w", err)
+	}
+	files := make([]storagetypes.FileInfo, 0, len(objects)+len(prefixes))
+	for _, p := range prefixes {
+		name := path.Base(strings.TrimSuffix(p, "/"))
+		files = append(files, storagetypes.FileInfo{
+			Name:  name,
+			Path:  path.Join(dirPath, name),
+			IsDir: true,
+		})
+	}
+	for _, obj := range objects {
+		name := strings.TrimPrefix(obj.Key, prefix)
+		if name == "" { // the marker object of the directory itself
+			continue
+		}
+		// some servers list the marker objects of the subdirectories instead of grouping them
+		if dir, ok := strings.CutSuffix(name, "/"); ok {
+			if !slices.Contains(prefixes, prefix+name) {
+				files = append(files, storagetypes.FileInfo{Name: dir, Path: path.Join(dirPath, dir), IsDir: true})
+			}
+			continue
+		}
+		files = append(files, storagetypes.FileInfo{
+			Name:    name,
+			Path:    path.Join(dirPath, name),
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
+		})
+	}
+	return files, nil
+}
+
+// OpenFile implements storage.StorageReadable
+func (m *S3) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	rc, size, err := m.client.GetObject(ctx, m.JoinStoragePath(filePath), 0, 0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file from S3: %w", err)
+	}
+	return rc, size, nil
+}
+
+// Delete implements storage.StorageDeletable
+func (m *S3) Delete(ctx context.Context, filePath string) error {
+	key := m.JoinStoragePath(filePath)
+	m.logger.Infof("Deleting file %s", key)
+	if err := m.client.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete file from S3: %w", err)
+	}
+	return nil
+}
+
+// Move implements storage.StorageMovable, S3 has no rename so the object is copied then deleted
+func (m *S3) Move(ctx context.Context, srcPath, dstPath string) error {
+	src := m.JoinStoragePath(srcPath)
+	dst := m.JoinStoragePath(dstPath)
+	m.logger.Infof("Moving file %s to %s", src, dst)
+	if err := m.client.Copy(ctx, src, dst); err != nil {
+		return fmt.Errorf("failed to copy file in S3: %w", err)
+	}
+	if err := m.client.Delete(ctx, src); err != nil {
+		return fmt.Errorf("failed to delete source file from S3: %w", err)
+	}
+	return nil
+}
+
+// MkDir implements storage.StorageDirMaker by creating an empty "dir/" object,
+// the directories of the other objects exist implicitly in their keys.
+func (m *S3) MkDir(ctx context.Context, dirPath string) error {
+	key := m.JoinStoragePath(dirPath)
+	if key == "" {
+		return nil
+	}
+	if err := m.client.Put(ctx, key+"/", bytes.NewReader(nil), 0); err != nil {
+		return fmt.Errorf("failed to create directory in S3: %w", err)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package s3_test
 import (
 	"bytes"
 	"context"
+	"io"
 	"net/http/httptest"
 	"testing"
 
@@ -80,8 +81,9 @@ func TestS3(t *testing.T) {
 		t.Fatalf("Save with existing key failed: %v", err)
 	}
 
-	if !s.Exists(ctx, "foo/bar_1.txt") {
-		t.Fatalf("Exists should return true for unique renamed key")
+	// conflicts are resolved by storage.Save, the storage itself overwrites
+	if s.Exists(ctx, "foo/bar_1.txt") {
+		t.Fatalf("Save with existing key should not rename")
 	}
 
 	var length int64 = int64(len(content))
@@ -94,3 +96,107 @@ func TestS3(t *testing.T) {
 		t.Fatalf("Exists should return true for size_test.txt")
 	}
 }
+
+func TestS3MoveAndDelete(t *testing.T) {
+	s, _ := newFakeS3(t)
+	ctx := t.Context()
+
+	if err := s.Save(ctx, bytes.NewReader([]byte("move me")), "src/file.txt"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := s.Move(ctx, "src/file.txt", "dst/子目录/file.txt"); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if s.Exists(ctx, s.JoinStoragePath("src/file.txt")) {
+		t.Fatalf("Source should not exist after move")
+	}
+	if !s.Exists(ctx, s.JoinStoragePath("dst/子目录/file.txt")) {
+		t.Fatalf("Destination should exist after move")
+	}
+
+	if err := s.Delete(ctx, "dst/子目录/file.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if s.Exists(ctx, s.JoinStoragePath("dst/子目录/file.txt")) {
+		t.Fatalf("File should not exist after delete")
+	}
+
+	if err := s.MkDir(ctx, "empty"); err != nil {
+		t.Fatalf("MkDir failed: %v", err)
+	}
+	if !s.Exists(ctx, s.JoinStoragePath("empty")+"/") {
+		t.Fatalf("Directory marker should exist after mkdir")
+	}
+}
+
+func TestS3SaveMultipart(t *testing.T) {
+	s, cfg := newFakeS3(t)
+	cfg.PartSizeMB = 5
+	if err := s.Init(newTestContext(t), cfg); err != nil {
+		t.Fatalf("init s3 failed: %v", err)
+	}
+	ctx := t.Context()
+
+	// an unknown-size stream larger than a part is uploaded in parts
+	content := bytes.Repeat([]byte("0123456789abcdef"), 11<<16)
+	if err := s.Save(ctx, io.MultiReader(bytes.NewReader(content)), "big/unknown.bin"); err != nil {
+		t.Fatalf("Save of unknown size failed: %v", err)
+	}
+	if !s.Exists(ctx, s.JoinStoragePath("big/unknown.bin")) {
+		t.Fatalf("Exists should return true for multipart upload")
+	}
+
+	sizeCtx := context.WithValue(ctx, ctxkey.ContentLength, int64(len(content)))
+	if err := s.Save(sizeCtx, bytes.NewReader(content), "big/known.bin"); err != nil {
+		t.Fatalf("Save of known size failed: %v", err)
+	}
+	if !s.Exists(ctx, s.JoinStoragePath("big/known.bin")) {
+		t.Fatalf("Exists should return true for multipart upload")
+	}
+}
+
+func TestS3ListAndOpen(t *testing.T) {
+	s, _ := newFakeS3(t)
+	ctx := t.Context()
+
+	for _, p := range []string{"docs/a.txt", "docs/sub/b.txt"} {
+		if err := s.Save(ctx, bytes.NewReader([]byte(p)), p); err != nil {
+			t.Fatalf("Save %s failed: %v", p, err)
+		}
+	}
+	if err := s.MkDir(ctx, "docs/empty"); err != nil {
+		t.Fatalf("MkDir failed: %v", err)
+	}
+
+	files, err := s.ListFiles(ctx, "docs")
+	if err != nil {
+		t.Fatalf("ListFiles failed: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, f := range files {
+		got[f.Path] = f.IsDir
+	}
+	want := map[string]bool{"docs/a.txt": false, "docs/sub": true, "docs/empty": true}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	for p, isDir := range want {
+		if d, ok := got[p]; !ok || d != isDir {
+			t.Fatalf("unexpected files: %+v", files)
+		}
+	}
+
+	rc, size, err := s.OpenFile(ctx, "docs/sub/b.txt")
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if string(data) != "docs/sub/b.txt" || size != int64(len(data)) {
+		t.Fatalf("got %q (size %d)", data, size)
+	}
+}
@@ -0,0 +1,14 @@
+package sftp
+
+import "errors"
+
+var (
+	ErrHostKeyMismatch         = errors.New("sftp: host key mismatch")
+	ErrFailedToConnect         = errors.New("sftp: failed to connect")
+	ErrFailedToCreateDirectory = errors.New("sftp: failed to create directory")
+	ErrFailedToWriteFile       = errors.New("sftp: failed to write file")
+	ErrFailedToListFiles       = errors.New("sftp: failed to list files")
+	ErrFailedToOpenFile        = errors.New("sftp: failed to open file")
+	ErrFailedToDeleteFile      = errors.New("sftp: failed to delete file")
+	ErrFailedToMoveFile        = errors.New("sftp: failed to move file")
+)
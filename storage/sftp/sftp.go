@@ -0,0 +1,349 @@
+package sftp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	config "github.com/kiss2u/SaveAny-Bot/config/storage"
+	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"github.com/pkg/sftp"
+	"github.com/rs/xid"
+	"golang.org/x/crypto/ssh"
+)
+
+type SFTP struct {
+	config    config.SFTPStorageConfig
+	sshConfig *ssh.ClientConfig
+	logger    *log.Logger
+
+	mu        sync.Mutex
+	sshClient *ssh.Client
+	client    *sftp.Client
+}
+
+func (s *SFTP) Init(ctx context.Context, cfg config.StorageConfig) error {
+	sftpConfig, ok := cfg.(*config.SFTPStorageConfig)
+	if !ok {
+		return fmt.Errorf("failed to cast sftp config")
+	}
+	if err := sftpConfig.Validate(); err != nil {
+		return err
+	}
+	s.config = *sftpConfig
+	s.logger = log.FromContext(ctx).WithPrefix(fmt.Sprintf("sftp[%s]", s.config.Name))
+
+	auths, err := authMethods(s.config)
+	if err != nil {
+		return err
+	}
+	hostKeyCallback, err := hostKeyCallback(s.config)
+	if err != nil {
+		return err
+	}
+	s.sshConfig = &ssh.ClientConfig{
+		User:            s.config.Username,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         30 * time.Second,
+	}
+
+	// connect once to fail early on wrong credentials or host key
+	if _, err := s.getClient(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func authMethods(cfg config.SFTPStorageConfig) ([]ssh.AuthMethod, error) {
+	var auths []ssh.AuthMethod
+	if cfg.PrivateKeyPath != "" {
+		keyData, err := os.ReadFile(cfg.PrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+		var signer ssh.Signer
+		if cfg.PrivateKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(keyData, []byte(cfg.PrivateKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(keyData)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		auths = append(auths, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auths = append(auths, ssh.Password(cfg.Password))
+	}
+	return auths, nil
+}
+
+// hostKeyCallback pins the host key given as a SHA256 fingerprint or a known_hosts/authorized_keys line
+func hostKeyCallback(cfg config.SFTPStorageConfig) (ssh.HostKeyCallback, error) {
+	if cfg.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	hostKey := strings.TrimSpace(cfg.HostKey)
+	if strings.HasPrefix(hostKey, "SHA256:") {
+		return func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			if fingerprint := ssh.FingerprintSHA256(key); fingerprint != hostKey {
+				return fmt.Errorf("%w: got %s", ErrHostKeyMismatch, fingerprint)
+			}
+			return nil
+		}, nil
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err != nil {
+		_, _, key, _, _, err = ssh.ParseKnownHosts([]byte(hostKey))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse host_key: %w", err)
+	}
+	return func(_ string, _ net.Addr, got ssh.PublicKey) error {
+		if err := ssh.FixedHostKey(key)("", nil, got); err != nil {
+			return fmt.Errorf("%w: got %s", ErrHostKeyMismatch, ssh.FingerprintSHA256(got))
+		}
+		return nil
+	}, nil
+}
+
+// getClient returns the connected sftp client, connecting again if the connection was lost
+func (s *SFTP) getClient(ctx context.Context) (*sftp.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	port := s.config.Port
+	if port == 0 {
+		port = 22
+	}
+	addr := net.JoinHostPort(s.config.Host, strconv.Itoa(port))
+	dialer := net.Dialer{Timeout: s.sshConfig.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnect, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, s.sshConfig)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnect, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		sshClient.Close()
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnect, err)
+	}
+	s.sshClient = sshClient
+	s.client = client
+	s.logger.Debugf("Connected to %s", addr)
+
+	go func() {
+		err := sshClient.Wait()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.sshClient == sshClient {
+			s.logger.Warnf("Connection to %s lost: %v", addr, err)
+			s.client.Close()
+			s.sshClient = nil
+			s.client = nil
+		}
+	}()
+	return client, nil
+}
+
+// Close closes the connection, it is connected again when the storage is used
+func (s *SFTP) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sshClient == nil {
+		return nil
+	}
+	s.client.Close()
+	err := s.sshClient.Close()
+	s.sshClient = nil
+	s.client = nil
+	return err
+}
+
+func (s *SFTP) Type() storenum.StorageType {
+	return storenum.Sftp
+}
+
+func (s *SFTP) Name() string {
+	return s.config.Name
+}
+
+func (s *SFTP) JoinStoragePath(p string) string {
+	return path.Join(s.config.BasePath, p)
+}
+
+// Save writes to a temporary file next to the target then renames it,
+// so that a partial file never appears at storagePath.
+func (s *SFTP) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, s.Name(), r)
+	s.logger.Infof("Saving file to %s", storagePath)
+	storagePath = s.JoinStoragePath(storagePath)
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	dir := path.Dir(storagePath)
+	if err := client.MkdirAll(dir); err != nil {
+		s.logger.Errorf("Failed to create directory %s: %v", dir, err)
+		return ErrFailedToCreateDirectory
+	}
+	tmpPath := path.Join(dir, fmt.Sprintf(".%s.%s.tmp", path.Base(storagePath), xid.New().String()))
+	if err := s.writeFile(client, tmpPath, r); err != nil {
+		s.logger.Errorf("Failed to write file %s: %v", tmpPath, err)
+		if err := client.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+			s.logger.Warnf("Failed to remove temporary file %s: %v", tmpPath, err)
+		}
+		return ErrFailedToWriteFile
+	}
+	if err := rename(client, tmpPath, storagePath); err != nil {
+		s.logger.Errorf("Failed to rename %s to %s: %v", tmpPath, storagePath, err)
+		if err := client.Remove(tmpPath); err != nil {
+			s.logger.Warnf("Failed to remove temporary file %s: %v", tmpPath, err)
+		}
+		return ErrFailedToWriteFile
+	}
+	return nil
+}
+
+func (s *SFTP) writeFile(client *sftp.Client, filePath string, r io.Reader) error {
+	f, err := client.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rename replaces newPath atomically if the server supports posix-rename,
+// the plain sftp rename fails if newPath exists so it is removed first otherwise.
+func rename(client *sftp.Client, oldPath, newPath string) error {
+	if _, ok := client.HasExtension("posix-rename@openssh.com"); ok {
+		return client.PosixRename(oldPath, newPath)
+	}
+	if err := client.Remove(newPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return client.Rename(oldPath, newPath)
+}
+
+func (s *SFTP) Exists(ctx context.Context, storagePath string) bool {
+	s.logger.Debugf("Checking if file exists at %s", storagePath)
+	client, err := s.getClient(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to check if file exists at %s: %v", storagePath, err)
+		return false
+	}
+	_, err = client.Stat(storagePath)
+	return err == nil
+}
+
+// ListFiles implements storage.StorageListable
+func (s *SFTP) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error) {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fullPath := s.JoinStoragePath(dirPath)
+	entries, err := client.ReadDir(fullPath)
+	if err != nil {
+		s.logger.Errorf("Failed to list directory %s: %v", fullPath, err)
+		return nil, fmt.Errorf("%w: %w", ErrFailedToListFiles, err)
+	}
+	files := make([]storagetypes.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, storagetypes.FileInfo{
+			Name:    entry.Name(),
+			Path:    path.Join(dirPath, entry.Name()),
+			Size:    entry.Size(),
+			IsDir:   entry.IsDir(),
+			ModTime: entry.ModTime(),
+		})
+	}
+	return files, nil
+}
+
+// OpenFile implements storage.StorageReadable
+func (s *SFTP) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	fullPath := s.JoinStoragePath(filePath)
+	f, err := client.Open(fullPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrFailedToOpenFile, err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("%w: %w", ErrFailedToOpenFile, err)
+	}
+	return f, stat.Size(), nil
+}
+
+// Delete implements storage.StorageDeletable
+func (s *SFTP) Delete(ctx context.Context, filePath string) error {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	fullPath := s.JoinStoragePath(filePath)
+	s.logger.Infof("Deleting file %s", fullPath)
+	if err := client.Remove(fullPath); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToDeleteFile, err)
+	}
+	return nil
+}
+
+// Move implements storage.StorageMovable
+func (s *SFTP) Move(ctx context.Context, srcPath, dstPath string) error {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	src := s.JoinStoragePath(srcPath)
+	dst := s.JoinStoragePath(dstPath)
+	s.logger.Infof("Moving file %s to %s", src, dst)
+	if err := client.MkdirAll(path.Dir(dst)); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCreateDirectory, err)
+	}
+	if err := rename(client, src, dst); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToMoveFile, err)
+	}
+	return nil
+}
+
+// MkDir implements storage.StorageDirMaker
+func (s *SFTP) MkDir(ctx context.Context, dirPath string) error {
+	client, err := s.getClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err := client.MkdirAll(s.JoinStoragePath(dirPath)); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToCreateDirectory, err)
+	}
+	return nil
+}
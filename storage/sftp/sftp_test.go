@@ -0,0 +1,296 @@
+package sftp_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+	storconfig "github.com/kiss2u/SaveAny-Bot/config/storage"
+	storsftp "github.com/kiss2u/SaveAny-Bot/storage/sftp"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestContext(t *testing.T) context.Context {
+	t.Helper()
+	logger := log.NewWithOptions(io.Discard, log.Options{ReportTimestamp: false})
+	return log.WithContext(t.Context(), logger)
+}
+
+type testServer struct {
+	addr    *net.TCPAddr
+	root    string
+	hostKey ssh.PublicKey
+	userKey ed25519.PrivateKey
+}
+
+// newTestServer starts an in-process ssh server with the sftp subsystem serving a temporary directory,
+// it accepts the password "secret" and the returned user key.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+	_, userPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate user key: %v", err)
+	}
+	userSigner, err := ssh.NewSignerFromKey(userPriv)
+	if err != nil {
+		t.Fatalf("failed to create user signer: %v", err)
+	}
+
+	serverConfig := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "tester" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("wrong password")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "tester" && bytes.Equal(key.Marshal(), userSigner.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("unknown key")
+		},
+	}
+	serverConfig.AddHostKey(hostSigner)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	root := t.TempDir()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, serverConfig, root)
+		}
+	}()
+	return &testServer{
+		addr:    ln.Addr().(*net.TCPAddr),
+		root:    root,
+		hostKey: hostSigner.PublicKey(),
+		userKey: userPriv,
+	}
+}
+
+func serveConn(conn net.Conn, config *ssh.ServerConfig, root string) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChan := range chans {
+		if newChan.ChannelType() != "session" {
+			newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChan.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				// the payload is the length-prefixed subsystem name
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(root))
+				if err != nil {
+					channel.Close()
+					return
+				}
+				go func() {
+					server.Serve()
+					server.Close()
+				}()
+			}
+		}()
+	}
+}
+
+func (ts *testServer) config() *storconfig.SFTPStorageConfig {
+	return &storconfig.SFTPStorageConfig{
+		BaseConfig: storconfig.BaseConfig{
+			Name:   "test-sftp",
+			Type:   "sftp",
+			Enable: true,
+		},
+		Host:     ts.addr.IP.String(),
+		Port:     ts.addr.Port,
+		Username: "tester",
+		Password: "secret",
+		HostKey:  ssh.FingerprintSHA256(ts.hostKey),
+		BasePath: filepath.ToSlash(filepath.Join(ts.root, "base")),
+	}
+}
+
+func TestSFTP(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := newTestContext(t)
+	s := &storsftp.SFTP{}
+	if err := s.Init(ctx, ts.config()); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+
+	content := []byte("hello sftp")
+	if err := s.Save(ctx, bytes.NewReader(content), "foo/bar.txt"); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if !s.Exists(ctx, s.JoinStoragePath("foo/bar.txt")) {
+		t.Fatalf("Exists should return true for saved file")
+	}
+	if s.Exists(ctx, s.JoinStoragePath("foo/missing.txt")) {
+		t.Fatalf("Exists should return false for missing file")
+	}
+
+	// saving again replaces the file, the conflict policy is applied by storage.Save
+	content = []byte("hello again")
+	if err := s.Save(ctx, bytes.NewReader(content), "foo/bar.txt"); err != nil {
+		t.Fatalf("Save over existing file failed: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(ts.root, "base", "foo", "bar.txt"))
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("unexpected saved content %q: %v", got, err)
+	}
+
+	if err := s.MkDir(ctx, "foo/sub"); err != nil {
+		t.Fatalf("MkDir failed: %v", err)
+	}
+	files, err := s.ListFiles(ctx, "foo")
+	if err != nil {
+		t.Fatalf("ListFiles failed: %v", err)
+	}
+	// the temporary files are renamed, only the saved file and the directory are left
+	if len(files) != 2 {
+		t.Fatalf("unexpected files: %+v", files)
+	}
+	for _, f := range files {
+		switch f.Path {
+		case "foo/bar.txt":
+			if f.IsDir || f.Size != int64(len(content)) {
+				t.Fatalf("unexpected file info: %+v", f)
+			}
+		case "foo/sub":
+			if !f.IsDir {
+				t.Fatalf("unexpected file info: %+v", f)
+			}
+		default:
+			t.Fatalf("unexpected file: %+v", f)
+		}
+	}
+
+	rc, size, err := s.OpenFile(ctx, "foo/bar.txt")
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil || !bytes.Equal(data, content) || size != int64(len(content)) {
+		t.Fatalf("unexpected content %q (size %d): %v", data, size, err)
+	}
+
+	if err := s.Move(ctx, "foo/bar.txt", "moved/bar.txt"); err != nil {
+		t.Fatalf("Move failed: %v", err)
+	}
+	if s.Exists(ctx, s.JoinStoragePath("foo/bar.txt")) || !s.Exists(ctx, s.JoinStoragePath("moved/bar.txt")) {
+		t.Fatalf("file should be moved")
+	}
+	if err := s.Delete(ctx, "moved/bar.txt"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if s.Exists(ctx, s.JoinStoragePath("moved/bar.txt")) {
+		t.Fatalf("file should be deleted")
+	}
+}
+
+func TestSFTPAuth(t *testing.T) {
+	ts := newTestServer(t)
+	ctx := newTestContext(t)
+
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(ts.userKey, "", []byte("pass"))
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("failed to write private key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *storconfig.SFTPStorageConfig)
+		wantErr error
+	}{
+		{
+			name: "private key and authorized key host key",
+			modify: func(cfg *storconfig.SFTPStorageConfig) {
+				cfg.Password = ""
+				cfg.PrivateKeyPath = keyPath
+				cfg.PrivateKeyPassphrase = "pass"
+				cfg.HostKey = string(ssh.MarshalAuthorizedKey(ts.hostKey))
+			},
+		},
+		{
+			name: "known hosts host key",
+			modify: func(cfg *storconfig.SFTPStorageConfig) {
+				cfg.HostKey = "127.0.0.1 " + string(ssh.MarshalAuthorizedKey(ts.hostKey))
+			},
+		},
+		{
+			name: "insecure ignore host key",
+			modify: func(cfg *storconfig.SFTPStorageConfig) {
+				cfg.HostKey = ""
+				cfg.InsecureIgnoreHostKey = true
+			},
+		},
+		{
+			name: "host key mismatch",
+			modify: func(cfg *storconfig.SFTPStorageConfig) {
+				cfg.HostKey = "SHA256:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+			},
+			wantErr: storsftp.ErrHostKeyMismatch,
+		},
+		{
+			name: "wrong password",
+			modify: func(cfg *storconfig.SFTPStorageConfig) {
+				cfg.Password = "wrong"
+			},
+			wantErr: storsftp.ErrFailedToConnect,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ts.config()
+			tt.modify(cfg)
+			err := (&storsftp.SFTP{}).Init(ctx, cfg)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Init failed: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Init error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -9,10 +9,14 @@ import (
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
 	"github.com/kiss2u/SaveAny-Bot/storage/alist"
+	"github.com/kiss2u/SaveAny-Bot/storage/encrypt"
+	"github.com/kiss2u/SaveAny-Bot/storage/ftp"
 	"github.com/kiss2u/SaveAny-Bot/storage/local"
 	"github.com/kiss2u/SaveAny-Bot/storage/minio"
+	"github.com/kiss2u/SaveAny-Bot/storage/mirror"
 	"github.com/kiss2u/SaveAny-Bot/storage/rclone"
 	"github.com/kiss2u/SaveAny-Bot/storage/s3"
+	"github.com/kiss2u/SaveAny-Bot/storage/sftp"
 	"github.com/kiss2u/SaveAny-Bot/storage/telegram"
 	"github.com/kiss2u/SaveAny-Bot/storage/webdav"
 )
@@ -43,7 +47,35 @@ type StorageReadable interface {
 	OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error)
 }
 
-var Storages = make(map[string]Storage)
+// StorageDeletable 表示支持删除文件的存储
+type StorageDeletable interface {
+	Storage
+	Delete(ctx context.Context, filePath string) error
+}
+
+// StorageMovable 表示支持在存储内移动或重命名文件的存储, 目标目录不存在时会被创建
+type StorageMovable interface {
+	Storage
+	Move(ctx context.Context, srcPath, dstPath string) error
+}
+
+// StorageDirMaker 表示支持创建目录的存储, 父目录不存在时会被一并创建
+type StorageDirMaker interface {
+	Storage
+	MkDir(ctx context.Context, dirPath string) error
+}
+
+// StorageLinkable 表示支持在存储内为已保存的文件创建链接的存储, 目标目录不存在时会被创建
+type StorageLinkable interface {
+	Storage
+	Link(ctx context.Context, srcPath, dstPath string) error
+}
+
+// StorageStatable 表示支持查询容量的存储, 不知道的容量为 storagetypes.StatUnknown
+type StorageStatable interface {
+	Storage
+	Stat(ctx context.Context) (storagetypes.StorageStat, error)
+}
 
 type StorageConstructor func() Storage
 
@@ -55,6 +87,23 @@ var storageConstructors = map[storenum.StorageType]StorageConstructor{
 	storenum.S3:       func() Storage { return new(s3.S3) },
 	storenum.Telegram: func() Storage { return new(telegram.Telegram) },
 	storenum.Rclone:   func() Storage { return new(rclone.Rclone) },
+	storenum.Sftp:     func() Storage { return new(sftp.SFTP) },
+	storenum.Ftp:      func() Storage { return new(ftp.FTP) },
+}
+
+// the encrypt and mirror storages get the storages they wrap with GetStorageByName, which uses storageConstructors,
+// so they are added here to avoid an initialization cycle
+func init() {
+	storageConstructors[storenum.Encrypt] = func() Storage {
+		return encrypt.New(func(ctx context.Context, name string) (encrypt.Inner, error) {
+			return GetStorageByName(ctx, name)
+		})
+	}
+	storageConstructors[storenum.Mirror] = func() Storage {
+		return mirror.New(func(ctx context.Context, name string) (mirror.Member, error) {
+			return GetStorageByName(ctx, name)
+		})
+	}
 }
 
 // NewStorage creates a new storage instance based on the provided config and initializes it
@@ -71,3 +120,16 @@ func NewStorage(ctx context.Context, cfg storcfg.StorageConfig) (Storage, error)
 
 	return storage, nil
 }
+
+// storagePathJoiner is implemented by the storages whose Exists expects the path joined with their base path
+type storagePathJoiner interface {
+	JoinStoragePath(p string) string
+}
+
+// FileExists reports whether the file saved to storagePath, the same path given to Save, exists in the storage.
+func FileExists(ctx context.Context, stor Storage, storagePath string) bool {
+	if j, ok := stor.(storagePathJoiner); ok {
+		storagePath = j.JoinStoragePath(storagePath)
+	}
+	return stor.Exists(ctx, storagePath)
+}
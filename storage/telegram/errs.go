@@ -0,0 +1,9 @@
+package telegram
+
+import "errors"
+
+var (
+	ErrFileNotFound      = errors.New("telegram: file not found in the index")
+	ErrNoTelegramContext = errors.New("telegram: failed to get telegram context")
+	ErrInvalidZip        = errors.New("telegram: invalid split zip")
+)
@@ -0,0 +1,284 @@
+package telegram
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/charmbracelet/log"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/common/tdler"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+)
+
+type noIndexKey struct{}
+
+// WithoutIndex returns a ctx whose saves are only sent to the chat, they are not added to the index of the storage.
+// It is used for the files sent to the users on request, which are not part of the archive.
+func WithoutIndex(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noIndexKey{}, true)
+}
+
+// addToIndex records the messages of the saved file, the save succeeds even if it fails
+func (t *Telegram) addToIndex(ctx context.Context, indexPath string, chatID int64, msgIDs []int, size int64, split bool) {
+	if noIndex, _ := ctx.Value(noIndexKey{}).(bool); noIndex {
+		return
+	}
+	logger := log.FromContext(ctx)
+	if len(msgIDs) == 0 {
+		logger.Warnf("No message found for the saved file %s, it is not added to the index", indexPath)
+		return
+	}
+	replaced, err := database.SaveTelegramFile(ctx, &database.TelegramFile{
+		StorageName: t.Name(),
+		Path:        indexPath,
+		ChatID:      chatID,
+		MessageIDs:  msgIDs,
+		Size:        size,
+		Split:       split,
+	})
+	if err != nil {
+		logger.Errorf("Failed to add %s to the index: %v", indexPath, err)
+		return
+	}
+	// the file overwrote the ones saved to the same path, their messages are deleted as Delete does
+	for _, old := range replaced {
+		if err := t.deleteMessages(ctx, old.ChatID, old.MessageIDs); err != nil {
+			logger.Warnf("Failed to delete the messages of the overwritten file %s: %v", indexPath, err)
+		}
+	}
+}
+
+// sentMessageIDs returns the ids of the new messages in the updates, in the order they are sent
+func sentMessageIDs(updates tg.UpdatesClass) []int {
+	var upds []tg.UpdateClass
+	switch u := updates.(type) {
+	case *tg.UpdateShortSentMessage:
+		return []int{u.ID}
+	case *tg.Updates:
+		upds = u.Updates
+	case *tg.UpdatesCombined:
+		upds = u.Updates
+	}
+	var ids []int
+	for _, upd := range upds {
+		switch upd := upd.(type) {
+		case *tg.UpdateNewMessage:
+			ids = append(ids, upd.Message.GetID())
+		case *tg.UpdateNewChannelMessage:
+			ids = append(ids, upd.Message.GetID())
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// extContext returns the telegram context carried by ctx
+func extContext(ctx context.Context) (*ext.Context, error) {
+	if tctx := tgutil.ExtFromContext(ctx); tctx != nil {
+		return tctx, nil
+	}
+	if tctx, ok := ctx.(*ext.Context); ok {
+		return tctx, nil
+	}
+	return nil, ErrNoTelegramContext
+}
+
+// Exists implements storage.Storage, only the files in the index are known
+func (t *Telegram) Exists(ctx context.Context, storagePath string) bool {
+	_, err := database.GetTelegramFile(ctx, t.Name(), path.Join("/", storagePath))
+	return err == nil
+}
+
+// ListFiles implements storage.StorageListable over the paths of the files in the index
+func (t *Telegram) ListFiles(ctx context.Context, dirPath string) ([]storagetypes.FileInfo, error) {
+	dir := path.Join("/", dirPath)
+	entries, err := database.GetTelegramFilesUnder(ctx, t.Name(), dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	files := make([]storagetypes.FileInfo, 0, len(entries))
+	dirs := make(map[string]bool)
+	for _, entry := range entries {
+		name, _, inSubDir := strings.Cut(strings.TrimPrefix(entry.Path, prefix), "/")
+		if inSubDir {
+			if !dirs[name] {
+				dirs[name] = true
+				files = append(files, storagetypes.FileInfo{
+					Name:  name,
+					Path:  path.Join(dirPath, name),
+					IsDir: true,
+				})
+			}
+			continue
+		}
+		files = append(files, storagetypes.FileInfo{
+			Name:    name,
+			Path:    path.Join(dirPath, name),
+			Size:    entry.Size,
+			ModTime: entry.CreatedAt,
+		})
+	}
+	return files, nil
+}
+
+// OpenFile implements storage.StorageReadable, the file is downloaded from its messages.
+// The split zip parts are joined and unpacked as they are read.
+func (t *Telegram) OpenFile(ctx context.Context, filePath string) (io.ReadCloser, int64, error) {
+	entry, err := database.GetTelegramFile(ctx, t.Name(), path.Join("/", filePath))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+	}
+	tctx, err := extContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	files := make([]tfile.TGFile, 0, len(entry.MessageIDs))
+	for _, id := range entry.MessageIDs {
+		msg, err := tgutil.GetMessageByID(tctx, entry.ChatID, id)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get message %d of %s: %w", id, filePath, err)
+		}
+		file, err := tfile.FromMedia(msg.Media, tctx.Raw)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get file of message %d: %w", id, err)
+		}
+		files = append(files, file)
+	}
+
+	size := entry.Size
+	if !entry.Split && files[0].Size() > 0 {
+		// the images sent as photos are converted by telegram
+		size = files[0].Size()
+	}
+	dlCtx, cancel := context.WithCancel(ctx)
+	pr, pw := io.Pipe()
+	go func() {
+		for _, file := range files {
+			if _, err := tdler.NewDownloader(file).Stream(dlCtx, pw); err != nil {
+				pw.CloseWithError(fmt.Errorf("failed to download %s: %w", file.Name(), err))
+				return
+			}
+		}
+		pw.Close()
+	}()
+	var r io.Reader = pr
+	if entry.Split {
+		r = &storedZipReader{r: pr, size: entry.Size}
+	}
+	return &downloadReader{Reader: r, pipe: pr, cancel: cancel}, size, nil
+}
+
+// Delete implements storage.StorageDeletable, the messages of the file are deleted too
+func (t *Telegram) Delete(ctx context.Context, filePath string) error {
+	entry, err := database.GetTelegramFile(ctx, t.Name(), path.Join("/", filePath))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+	}
+	if err := t.deleteMessages(ctx, entry.ChatID, entry.MessageIDs); err != nil {
+		return fmt.Errorf("failed to delete messages of %s: %w", filePath, err)
+	}
+	return database.DeleteTelegramFile(ctx, entry.ID)
+}
+
+// deleteMessages deletes the messages of a saved file from the chat
+func (t *Telegram) deleteMessages(ctx context.Context, chatID int64, msgIDs []int) error {
+	tctx, err := extContext(ctx)
+	if err != nil {
+		return err
+	}
+	peer := tryGetInputPeer(tctx, chatID)
+	if peer == nil || peer.Zero() {
+		return fmt.Errorf("failed to get input peer for chat ID %d", chatID)
+	}
+	if channel, ok := peer.(*tg.InputPeerChannel); ok {
+		_, err = tctx.Raw.ChannelsDeleteMessages(ctx, &tg.ChannelsDeleteMessagesRequest{
+			Channel: &tg.InputChannel{ChannelID: channel.ChannelID, AccessHash: channel.AccessHash},
+			ID:      msgIDs,
+		})
+	} else {
+		_, err = tctx.Raw.MessagesDeleteMessages(ctx, &tg.MessagesDeleteMessagesRequest{
+			Revoke: true,
+			ID:     msgIDs,
+		})
+	}
+	return err
+}
+
+// downloadReader stops the download when it is closed
+type downloadReader struct {
+	io.Reader
+	pipe   *io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *downloadReader) Close() error {
+	r.cancel()
+	return r.pipe.Close()
+}
+
+// the size of the fixed part of a zip local file header
+const zipLocalHeaderLen = 30
+
+// storedZipReader reads the content of the only entry of a zip stream, which is stored without compression,
+// like the zips created by CreateSplitZip. The size of the entry is not in the header as the zip is written as a stream.
+type storedZipReader struct {
+	r         io.Reader
+	size      int64
+	remaining int64
+	started   bool
+}
+
+func (z *storedZipReader) readHeader() error {
+	header := make([]byte, zipLocalHeaderLen)
+	if _, err := io.ReadFull(z.r, header); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidZip, err)
+	}
+	if binary.LittleEndian.Uint32(header[0:4]) != 0x04034b50 {
+		return fmt.Errorf("%w: bad signature", ErrInvalidZip)
+	}
+	if method := binary.LittleEndian.Uint16(header[8:10]); method != 0 {
+		return fmt.Errorf("%w: unsupported compression method %d", ErrInvalidZip, method)
+	}
+	skip := int64(binary.LittleEndian.Uint16(header[26:28])) + int64(binary.LittleEndian.Uint16(header[28:30]))
+	if _, err := io.CopyN(io.Discard, z.r, skip); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidZip, err)
+	}
+	return nil
+}
+
+func (z *storedZipReader) Read(p []byte) (int, error) {
+	if !z.started {
+		z.started = true
+		z.remaining = z.size
+		if err := z.readHeader(); err != nil {
+			z.remaining = 0
+			return 0, err
+		}
+	}
+	if z.remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > z.remaining {
+		p = p[:z.remaining]
+	}
+	n, err := z.r.Read(p)
+	z.remaining -= int64(n)
+	if errors.Is(err, io.EOF) {
+		if z.remaining > 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		err = nil
+	}
+	return n, err
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/gotd/td/tg"
+)
+
+func TestStoredZipReader(t *testing.T) {
+	data := make([]byte, 10*1024+123)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	output := filepath.Join(t.TempDir(), "file")
+	if err := CreateSplitZip(t.Context(), bytes.NewReader(data), int64(len(data)), "file.dat", output, 4096); err != nil {
+		t.Fatalf("CreateSplitZip failed: %v", err)
+	}
+	parts, err := filepath.Glob(output + ".z*")
+	if err != nil {
+		t.Fatalf("failed to glob split files: %v", err)
+	}
+	if len(parts) < 2 {
+		t.Fatalf("the file should be split, got %v", parts)
+	}
+	var joined bytes.Buffer
+	for _, part := range parts {
+		b, err := os.ReadFile(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		joined.Write(b)
+	}
+
+	got, err := io.ReadAll(&storedZipReader{r: bytes.NewReader(joined.Bytes()), size: int64(len(data))})
+	if err != nil {
+		t.Fatalf("failed to read the zip: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("the content differs, got %d bytes, want %d", len(got), len(data))
+	}
+
+	_, err = io.ReadAll(&storedZipReader{r: bytes.NewReader(joined.Bytes()[:4096]), size: int64(len(data))})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("a missing part should fail with io.ErrUnexpectedEOF, got %v", err)
+	}
+	_, err = io.ReadAll(&storedZipReader{r: bytes.NewReader(data), size: int64(len(data))})
+	if !errors.Is(err, ErrInvalidZip) {
+		t.Fatalf("expected ErrInvalidZip, got %v", err)
+	}
+}
+
+func TestSentMessageIDs(t *testing.T) {
+	if got := sentMessageIDs(&tg.UpdateShortSentMessage{ID: 5}); !slices.Equal(got, []int{5}) {
+		t.Fatalf("unexpected ids: %v", got)
+	}
+	updates := &tg.Updates{Updates: []tg.UpdateClass{
+		&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 12}},
+		&tg.UpdateMessageID{ID: 12},
+		&tg.UpdateNewChannelMessage{Message: &tg.Message{ID: 11}},
+	}}
+	if got := sentMessageIDs(updates); !slices.Equal(got, []int{11, 12}) {
+		t.Fatalf("unexpected ids: %v", got)
+	}
+}
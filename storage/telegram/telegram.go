@@ -19,6 +19,7 @@ import (
 	"github.com/gotd/td/telegram/message/styling"
 	"github.com/gotd/td/telegram/uploader"
 	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/dlutil"
 	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
 	"github.com/kiss2u/SaveAny-Bot/config"
@@ -66,11 +67,8 @@ func (t *Telegram) Name() string {
 	return t.config.Name
 }
 
-func (t *Telegram) Exists(ctx context.Context, storagePath string) bool {
-	return false
-}
-
 func (t *Telegram) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, t.Name(), r)
 	storagePath = path.Clean(storagePath)
 	tctx := tgutil.ExtFromContext(ctx)
 	if tctx == nil {
@@ -135,13 +133,28 @@ func (t *Telegram) Save(ctx context.Context, r io.Reader, storagePath string) er
 			filename = xid.New().String() + mtype.Extension()
 		}
 
+		if size < 0 {
+			if size, err = rs.Seek(0, io.SeekEnd); err != nil {
+				return fmt.Errorf("failed to seek reader: %w", err)
+			}
+		}
 		if _, err := rs.Seek(0, io.SeekStart); err != nil {
 			return fmt.Errorf("failed to seek reader: %w", err)
 		}
 	}
+	indexPath := path.Join("/", storagePath)
+	if len(parts) == 0 {
+		// the path has no file name, the random one is used
+		indexPath = "/" + filename
+	}
 	if size > splitSize {
 		// large file, use split uploader
-		return t.splitUpload(tctx, r, filename, upler, peer, size, splitSize)
+		msgIDs, err := t.splitUpload(tctx, r, filename, upler, peer, size, splitSize)
+		if err != nil {
+			return err
+		}
+		t.addToIndex(ctx, indexPath, chatID, msgIDs, size, true)
+		return nil
 	}
 
 	var file tg.InputFileClass
@@ -209,15 +222,20 @@ func (t *Telegram) Save(ctx context.Context, r io.Reader, storagePath string) er
 		}
 	}
 	sender := tctx.Sender
-	_, err = sender.WithUploader(upler).To(peer).Media(ctx, media)
-	return err
+	updates, err := sender.WithUploader(upler).To(peer).Media(ctx, media)
+	if err != nil {
+		return err
+	}
+	t.addToIndex(ctx, indexPath, chatID, sentMessageIDs(updates), size, false)
+	return nil
 }
 
 func (t *Telegram) CannotStream() string {
 	return "Telegram storage must use a ReaderSeeker"
 }
 
-func (t *Telegram) splitUpload(ctx *ext.Context, r io.Reader, filename string, upler *uploader.Uploader, peer tg.InputPeerClass, fileSize, splitSize int64) error {
+// splitUpload uploads the file as split zip parts and returns the ids of the messages of the parts in order
+func (t *Telegram) splitUpload(ctx *ext.Context, r io.Reader, filename string, upler *uploader.Uploader, peer tg.InputPeerClass, fileSize, splitSize int64) ([]int, error) {
 	tempId := xid.New().String()
 	outputBase := filepath.Join(config.C().Temp.BasePath, tempId, strings.Split(filename, ".")[0])
 	defer func() {
@@ -227,11 +245,11 @@ func (t *Telegram) splitUpload(ctx *ext.Context, r io.Reader, filename string, u
 		}
 	}()
 	if err := CreateSplitZip(ctx, r, fileSize, filename, outputBase, splitSize); err != nil {
-		return fmt.Errorf("failed to create split zip: %w", err)
+		return nil, fmt.Errorf("failed to create split zip: %w", err)
 	}
 	matched, err := filepath.Glob(outputBase + ".z*")
 	if err != nil {
-		return fmt.Errorf("failed to glob split files: %w", err)
+		return nil, fmt.Errorf("failed to glob split files: %w", err)
 	}
 	inputFiles := make([]tg.InputFileClass, 0, len(matched))
 	for _, partPath := range matched {
@@ -256,7 +274,7 @@ func (t *Telegram) splitUpload(ctx *ext.Context, r io.Reader, filename string, u
 			return nil
 		}()
 		if err != nil {
-			return fmt.Errorf("failed to upload split part %s: %w", partPath, err)
+			return nil, fmt.Errorf("failed to upload split part %s: %w", partPath, err)
 		}
 	}
 	if len(inputFiles) == 1 {
@@ -266,11 +284,14 @@ func (t *Telegram) splitUpload(ctx *ext.Context, r io.Reader, filename string, u
 			Filename(filepath.Base(matched[0])).
 			ForceFile(true).
 			MIME("application/zip")
-		_, err = ctx.Sender.
+		updates, err := ctx.Sender.
 			WithUploader(upler).
 			To(peer).
 			Media(ctx, doc)
-		return err
+		if err != nil {
+			return nil, err
+		}
+		return sentMessageIDs(updates), nil
 	}
 
 	multiMedia := make([]message.MultiMediaOption, 0, len(inputFiles))
@@ -283,24 +304,19 @@ func (t *Telegram) splitUpload(ctx *ext.Context, r io.Reader, filename string, u
 
 	sender := ctx.Sender
 
-	if len(multiMedia) <= 10 {
-		_, err = sender.WithUploader(upler).
-			To(peer).
-			Album(ctx, multiMedia[0], multiMedia[1:]...)
-		return err
-	}
-
-	// more than 10 parts, send in batches, each batch up to 10 parts
+	// send in batches, each batch up to 10 parts
+	msgIDs := make([]int, 0, len(multiMedia))
 	for i := 0; i < len(multiMedia); i += 10 {
 		end := min(i+10, len(multiMedia))
 		batch := multiMedia[i:end]
-		_, err = sender.WithUploader(upler).
+		updates, err := sender.WithUploader(upler).
 			To(peer).
 			Album(ctx, batch[0], batch[1:]...)
 		if err != nil {
-			return fmt.Errorf("failed to send album batch: %w", err)
+			return nil, fmt.Errorf("failed to send album batch: %w", err)
 		}
+		msgIDs = append(msgIDs, sentMessageIDs(updates)...)
 	}
-	return nil
+	return msgIDs, nil
 
 }
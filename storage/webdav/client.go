@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/kiss2u/SaveAny-Bot/pkg/enums/ctxkey"
@@ -27,6 +28,8 @@ const (
 	WebdavMethodPropfind WebdavMethod = "PROPFIND"
 	WebdavMethodPut      WebdavMethod = "PUT"
 	WebdavMethodGet      WebdavMethod = "GET"
+	WebdavMethodDelete   WebdavMethod = "DELETE"
+	WebdavMethodMove     WebdavMethod = "MOVE"
 )
 
 // WebDAV XML structures for PROPFIND response
@@ -166,6 +169,66 @@ func (c *Client) WriteFile(ctx context.Context, remotePath string, content io.Re
 	return fmt.Errorf("PUT: %s", resp.Status)
 }
 
+// fileURL returns the url of the remote path
+func (c *Client) fileURL(remotePath string) (string, error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, strings.Trim(remotePath, "/"))
+	return u.String(), nil
+}
+
+// Delete removes the file at the remote path
+func (c *Client) Delete(ctx context.Context, remotePath string) error {
+	u, err := c.fileURL(remotePath)
+	if err != nil {
+		return err
+	}
+	resp, err := c.doRequest(ctx, WebdavMethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("DELETE: %s", resp.Status)
+}
+
+// Move moves the file at srcPath to dstPath, overwriting the existing file at dstPath
+func (c *Client) Move(ctx context.Context, srcPath, dstPath string) error {
+	src, err := c.fileURL(srcPath)
+	if err != nil {
+		return err
+	}
+	dst, err := c.fileURL(dstPath)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, string(WebdavMethodMove), src, nil)
+	if err != nil {
+		return err
+	}
+	if c.Username != "" && c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	req.Header.Set("Destination", dst)
+	req.Header.Set("Overwrite", "T")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return fmt.Errorf("MOVE: %s", resp.Status)
+}
+
 // ListDir lists files and directories in the given path
 func (c *Client) ListDir(ctx context.Context, dirPath string) ([]Response, error) {
 	dirPath = strings.Trim(dirPath, "/")
@@ -240,3 +303,67 @@ func (c *Client) ReadFile(ctx context.Context, filePath string) (io.ReadCloser,
 
 	return resp.Body, resp.ContentLength, nil
 }
+
+// quotaMultistatus is the PROPFIND response of the RFC 4331 quota properties,
+// the properties the server does not have are in another propstat with a 404 status
+type quotaMultistatus struct {
+	Responses []struct {
+		Propstats []struct {
+			Prop struct {
+				QuotaAvailableBytes string `xml:"quota-available-bytes"`
+				QuotaUsedBytes      string `xml:"quota-used-bytes"`
+			} `xml:"prop"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+const quotaPropfind = `<?xml version="1.0" encoding="utf-8"?>
+<D:propfind xmlns:D="DAV:"><D:prop><D:quota-available-bytes/><D:quota-used-bytes/></D:prop></D:propfind>`
+
+// Quota returns the available and used bytes of the directory, -1 for the ones the server does not report
+func (c *Client) Quota(ctx context.Context, dirPath string) (available, used int64, err error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return 0, 0, err
+	}
+	u.Path = path.Join(u.Path, strings.Trim(dirPath, "/")) + "/"
+
+	req, err := http.NewRequestWithContext(ctx, string(WebdavMethodPropfind), u.String(), strings.NewReader(quotaPropfind))
+	if err != nil {
+		return 0, 0, err
+	}
+	if c.Username != "" && c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	req.Header.Set("Depth", "0")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return 0, 0, fmt.Errorf("PROPFIND: %s", resp.Status)
+	}
+
+	var multistatus quotaMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&multistatus); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode PROPFIND response: %w", err)
+	}
+	available, used = -1, -1
+	for _, r := range multistatus.Responses {
+		for _, ps := range r.Propstats {
+			if !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			if n, err := strconv.ParseInt(strings.TrimSpace(ps.Prop.QuotaAvailableBytes), 10, 64); err == nil && n >= 0 {
+				available = n
+			}
+			if n, err := strconv.ParseInt(strings.TrimSpace(ps.Prop.QuotaUsedBytes), 10, 64); err == nil && n >= 0 {
+				used = n
+			}
+		}
+	}
+	return available, used, nil
+}
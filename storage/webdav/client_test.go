@@ -2,6 +2,8 @@ package webdav
 
 import (
 	"context"
+	"io"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
@@ -132,3 +134,77 @@ func TestWriteFile(t *testing.T) {
 		})
 	}
 }
+
+func TestMoveAndDelete(t *testing.T) {
+	server, tempDir := setupWebDAVServer(t)
+	defer os.RemoveAll(tempDir)
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "", nil)
+	ctx := context.Background()
+
+	if err := client.WriteFile(ctx, "src.txt", strings.NewReader("move me")); err != nil {
+		t.Fatalf("Call WriteFile Err: %v", err)
+	}
+	if err := client.MkDir(ctx, "moved/子目录"); err != nil {
+		t.Fatalf("Call MkDir Err: %v", err)
+	}
+	if err := client.Move(ctx, "src.txt", "moved/子目录/dst.txt"); err != nil {
+		t.Fatalf("Call Move Err: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "src.txt")); !os.IsNotExist(err) {
+		t.Fatalf("Source file should not exist after move")
+	}
+	data, err := os.ReadFile(filepath.Join(tempDir, "moved", "子目录", "dst.txt"))
+	if err != nil || string(data) != "move me" {
+		t.Fatalf("Moved file has unexpected content: %q, %v", data, err)
+	}
+
+	if err := client.Delete(ctx, "moved/子目录/dst.txt"); err != nil {
+		t.Fatalf("Call Delete Err: %v", err)
+	}
+	exists, err := client.Exists(ctx, "moved/子目录/dst.txt")
+	if err != nil {
+		t.Fatalf("Call Exists Err: %v", err)
+	}
+	if exists {
+		t.Fatalf("File should not exist after delete")
+	}
+}
+
+func TestQuota(t *testing.T) {
+	// the x/net/webdav server does not support the quota properties
+	server, tempDir := setupWebDAVServer(t)
+	defer os.RemoveAll(tempDir)
+	defer server.Close()
+
+	ctx := context.Background()
+	available, used, err := NewClient(server.URL, "", "", nil).Quota(ctx, "/")
+	if err != nil {
+		t.Fatalf("Call Quota Err: %v", err)
+	}
+	if available != -1 || used != -1 {
+		t.Fatalf("Quota should be unknown, got %d and %d", available, used)
+	}
+
+	quotaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PROPFIND" || r.Header.Get("Depth") != "0" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+		io.WriteString(w, `<?xml version="1.0" encoding="utf-8"?>
+<d:multistatus xmlns:d="DAV:"><d:response><d:href>/</d:href>
+<d:propstat><d:prop><d:quota-available-bytes>1000</d:quota-available-bytes></d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat>
+<d:propstat><d:prop><d:quota-used-bytes/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status></d:propstat>
+</d:response></d:multistatus>`)
+	}))
+	defer quotaServer.Close()
+	available, used, err = NewClient(quotaServer.URL, "", "", nil).Quota(ctx, "/data")
+	if err != nil {
+		t.Fatalf("Call Quota Err: %v", err)
+	}
+	if available != 1000 || used != -1 {
+		t.Fatalf("Unexpected quota: %d and %d", available, used)
+	}
+}
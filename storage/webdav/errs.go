@@ -6,4 +6,6 @@ var (
 	ErrFailedToCreateDirectory = errors.New("webdav: failed to create directory")
 	ErrFailedToWriteFile       = errors.New("webdav: failed to write file")
 	ErrFailedToCheckFileExists = errors.New("webdav: failed to check if file exists")
+	ErrFailedToDeleteFile      = errors.New("webdav: failed to delete file")
+	ErrFailedToMoveFile        = errors.New("webdav: failed to move file")
 )
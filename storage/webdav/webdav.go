@@ -11,10 +11,10 @@ import (
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
 	config "github.com/kiss2u/SaveAny-Bot/config/storage"
 	storenum "github.com/kiss2u/SaveAny-Bot/pkg/enums/storage"
 	"github.com/kiss2u/SaveAny-Bot/pkg/storagetypes"
-	"github.com/rs/xid"
 )
 
 type Webdav struct {
@@ -52,26 +52,16 @@ func (w *Webdav) JoinStoragePath(p string) string {
 }
 
 func (w *Webdav) Save(ctx context.Context, r io.Reader, storagePath string) error {
+	r = bandwidth.UploadReader(ctx, w.Name(), r)
 	w.logger.Infof("Saving file to %s", storagePath)
 	storagePath = w.JoinStoragePath(storagePath)
-	ext := path.Ext(storagePath)
-	base := strings.TrimSuffix(storagePath, ext)
-	candidate := storagePath
-	for i := 1; w.Exists(ctx, candidate); i++ {
-		candidate = fmt.Sprintf("%s_%d%s", base, i, ext)
-		if i > 1000 {
-			w.logger.Errorf("Too many attempts to find a unique filename for %s", storagePath)
-			candidate = fmt.Sprintf("%s_%s%s", base, xid.New().String(), ext)
-			break
-		}
-	}
 
-	if err := w.client.MkDir(ctx, path.Dir(candidate)); err != nil {
-		w.logger.Errorf("Failed to create directory %s: %v", path.Dir(candidate), err)
+	if err := w.client.MkDir(ctx, path.Dir(storagePath)); err != nil {
+		w.logger.Errorf("Failed to create directory %s: %v", path.Dir(storagePath), err)
 		return ErrFailedToCreateDirectory
 	}
-	if err := w.client.WriteFile(ctx, candidate, r); err != nil {
-		w.logger.Errorf("Failed to write file %s: %v", candidate, err)
+	if err := w.client.WriteFile(ctx, storagePath, r); err != nil {
+		w.logger.Errorf("Failed to write file %s: %v", storagePath, err)
 		return ErrFailedToWriteFile
 	}
 	return nil
@@ -163,3 +153,55 @@ func (w *Webdav) OpenFile(ctx context.Context, filePath string) (io.ReadCloser,
 	w.logger.Debugf("Opened file %s (size: %d bytes)", filePath, size)
 	return reader, size, nil
 }
+
+// Delete implements storage.StorageDeletable
+func (w *Webdav) Delete(ctx context.Context, filePath string) error {
+	fullPath := w.JoinStoragePath(filePath)
+	w.logger.Infof("Deleting file %s", fullPath)
+	if err := w.client.Delete(ctx, fullPath); err != nil {
+		w.logger.Errorf("Failed to delete file %s: %v", fullPath, err)
+		return ErrFailedToDeleteFile
+	}
+	return nil
+}
+
+// Move implements storage.StorageMovable
+func (w *Webdav) Move(ctx context.Context, srcPath, dstPath string) error {
+	src := w.JoinStoragePath(srcPath)
+	dst := w.JoinStoragePath(dstPath)
+	w.logger.Infof("Moving file %s to %s", src, dst)
+	if err := w.client.MkDir(ctx, path.Dir(dst)); err != nil {
+		w.logger.Errorf("Failed to create directory %s: %v", path.Dir(dst), err)
+		return ErrFailedToCreateDirectory
+	}
+	if err := w.client.Move(ctx, src, dst); err != nil {
+		w.logger.Errorf("Failed to move file %s to %s: %v", src, dst, err)
+		return ErrFailedToMoveFile
+	}
+	return nil
+}
+
+// MkDir implements storage.StorageDirMaker
+func (w *Webdav) MkDir(ctx context.Context, dirPath string) error {
+	fullPath := w.JoinStoragePath(dirPath)
+	if err := w.client.MkDir(ctx, fullPath); err != nil {
+		w.logger.Errorf("Failed to create directory %s: %v", fullPath, err)
+		return ErrFailedToCreateDirectory
+	}
+	return nil
+}
+
+// Stat implements storage.StorageStatable with the quota of the base path, the server may not report it
+func (w *Webdav) Stat(ctx context.Context) (storagetypes.StorageStat, error) {
+	available, used, err := w.client.Quota(ctx, w.config.BasePath)
+	if err != nil {
+		w.logger.Errorf("Failed to get quota: %v", err)
+		return storagetypes.StorageStat{}, fmt.Errorf("failed to get quota: %w", err)
+	}
+	stat := storagetypes.NewStorageStat()
+	stat.Free, stat.Used = available, used
+	if available >= 0 && used >= 0 {
+		stat.Total = available + used
+	}
+	return stat, nil
+}
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/kiss2u/SaveAny-Bot/common/bandwidth"
+	"github.com/kiss2u/SaveAny-Bot/config"
+)
+
+// handleGetBandwidth returns the current bandwidth limits in bytes per second, 0 means unlimited
+func (s *Server) handleGetBandwidth(c *fiber.Ctx) error {
+	return c.JSON(bandwidth.GetStatus())
+}
+
+// SetBandwidthRequest changes the limits given, the rates are like "10MB", "0" means unlimited
+type SetBandwidthRequest struct {
+	Download *string           `json:"download"`
+	Upload   *string           `json:"upload"`
+	Storages map[string]string `json:"storages"`
+}
+
+func (s *Server) handleSetBandwidth(c *fiber.Ctx) error {
+	var req SetBandwidthRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	// validate everything before changing anything
+	var download, upload int64
+	var err error
+	if req.Download != nil {
+		if download, err = config.ParseBandwidth(*req.Download); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	if req.Upload != nil {
+		if upload, err = config.ParseBandwidth(*req.Upload); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	storages := make(map[string]int64, len(req.Storages))
+	for name, v := range req.Storages {
+		if config.C().GetStorageByName(name) == nil {
+			return c.Status(404).JSON(fiber.Map{"error": "storage " + name + " not found"})
+		}
+		if storages[name], err = config.ParseBandwidth(v); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	if req.Download != nil {
+		bandwidth.SetDownload(download)
+	}
+	if req.Upload != nil {
+		bandwidth.SetUpload(upload)
+	}
+	for name, limit := range storages {
+		if err := bandwidth.SetStorage(name, limit); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+	return c.JSON(bandwidth.GetStatus())
+}
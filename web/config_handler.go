@@ -5,6 +5,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
 )
 
 type ConfigResponse struct {
@@ -38,13 +39,38 @@ func (s *Server) handleGetConfig(c *fiber.Ctx) error {
 	})
 }
 
+// SaveConfigRequest writes the settings to the config file and reloads it, the omitted settings are not changed
 type SaveConfigRequest struct {
-	Lang     string `json:"lang"`
-	Workers  int    `json:"workers"`
-	Retry    int    `json:"retry"`
-	Threads  int    `json:"threads"`
-	Stream   bool   `json:"stream"`
-	Proxy    string `json:"proxy"`
+	Lang    *string `json:"lang"`
+	Workers *int    `json:"workers"`
+	Retry   *int    `json:"retry"`
+	Threads *int    `json:"threads"`
+	Stream  *bool   `json:"stream"`
+	Proxy   *string `json:"proxy"`
+}
+
+// settings returns the settings in the request keyed like in the config file
+func (r SaveConfigRequest) settings() map[string]any {
+	settings := make(map[string]any)
+	if r.Lang != nil {
+		settings["lang"] = *r.Lang
+	}
+	if r.Workers != nil {
+		settings["workers"] = *r.Workers
+	}
+	if r.Retry != nil {
+		settings["retry"] = *r.Retry
+	}
+	if r.Threads != nil {
+		settings["threads"] = *r.Threads
+	}
+	if r.Stream != nil {
+		settings["stream"] = *r.Stream
+	}
+	if r.Proxy != nil {
+		settings["proxy"] = *r.Proxy
+	}
+	return settings
 }
 
 func (s *Server) handleSaveConfig(c *fiber.Ctx) error {
@@ -52,11 +78,30 @@ func (s *Server) handleSaveConfig(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
 	}
+	if (req.Workers != nil && *req.Workers < 1) || (req.Retry != nil && *req.Retry < 1) || (req.Threads != nil && *req.Threads < 1) {
+		return c.Status(400).JSON(fiber.Map{"error": "workers, retry and threads must be at least 1"})
+	}
 
-	// TODO: Validate and save to config file
-	// This requires modifying config package to support hot-reload
+	if err := config.SaveSettings(req.settings()); err != nil {
+		return c.Status(storageErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return s.reloadConfig(c)
+}
 
-	return c.JSON(fiber.Map{"status": "ok", "message": "config saved"})
+func (s *Server) handleReloadConfig(c *fiber.Ctx) error {
+	return s.reloadConfig(c)
+}
+
+// reloadConfig reloads the config file and responds with the changes, see core.ReloadReport
+func (s *Server) reloadConfig(c *fiber.Ctx) error {
+	report, err := core.ReloadConfig(s.ctx)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(report.Storages.Added)+len(report.Storages.Changed)+len(report.Storages.Removed) > 0 {
+		invalidateStorageCache()
+	}
+	return c.JSON(report)
 }
 
 func (s *Server) handleValidateConfig(c *fiber.Ctx) error {
@@ -0,0 +1,148 @@
+package web
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/celestix/gotgproto/ext"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gotd/td/tg"
+	"github.com/kiss2u/SaveAny-Bot/client/bot"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/mediautil"
+	"github.com/kiss2u/SaveAny-Bot/client/bot/handlers/utils/ruleutil"
+	uc "github.com/kiss2u/SaveAny-Bot/client/user"
+	"github.com/kiss2u/SaveAny-Bot/common/utils/tgutil"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/tfile"
+	"gorm.io/gorm"
+)
+
+// TestRulesRequest gives the message to test the rules of the user on, by its link or by the chat and message IDs.
+// All the files of an album are tested unless the link ends with ?single.
+type TestRulesRequest struct {
+	UserID    int64  `json:"user_id"`
+	Link      string `json:"link"`
+	ChatID    int64  `json:"chat_id"`
+	MessageID int    `json:"message_id"`
+}
+
+// RuleTestResult is what saving a file would do, see ruleutil.DryRun
+type RuleTestResult struct {
+	FileName    string        `json:"file_name"`
+	Applied     bool          `json:"applied"` // whether the user applies the rules
+	Matches     []MatchedRule `json:"matches"` // in the order they are evaluated
+	Skip        bool          `json:"skip"`
+	Storage     string        `json:"storage,omitempty"` // empty if no storage is available
+	Path        string        `json:"path"`
+	Also        []string      `json:"also,omitempty"`
+	Conflict    string        `json:"conflict,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	NotifyChats []int64       `json:"notify_chats,omitempty"`
+	Error       string        `json:"error,omitempty"`
+}
+
+type MatchedRule struct {
+	ID   uint   `json:"id"`
+	Rule string `json:"rule"` // like it is added with /rule add
+}
+
+// handleTestRules evaluates the rules of the user on the files of a message without saving them
+func (s *Server) handleTestRules(c *fiber.Ctx) error {
+	var req TestRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	if req.UserID == 0 || (req.Link == "" && (req.ChatID == 0 || req.MessageID == 0)) {
+		return c.Status(400).JSON(fiber.Map{"error": "user_id and either link or chat_id and message_id are required"})
+	}
+	user, err := database.GetUserByChatID(s.ctx, req.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "user not found"})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	tctx := telegramContext()
+	if tctx == nil {
+		return c.Status(503).JSON(fiber.Map{"error": "no telegram client available"})
+	}
+	messages, err := getTestMessages(tctx, req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	items := make([]RuleTestResult, 0, len(messages))
+	for _, msg := range messages {
+		media, ok := msg.GetMedia()
+		if !ok || !mediautil.IsSupported(media) {
+			continue
+		}
+		file, err := tfile.FromMediaMessage(media, tctx.Raw, msg, mediautil.TfileOptions(s.ctx, user, msg)...)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		items = append(items, newRuleTestResult(ruleutil.TestRules(s.ctx, user, file)))
+	}
+	if len(items) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "the message has no file"})
+	}
+	return c.JSON(fiber.Map{"items": items})
+}
+
+// telegramContext returns the client to get the messages with, the userbot can read the chats the bot is not in
+func telegramContext() *ext.Context {
+	if config.C().Telegram.Userbot.Enable && uc.GetCtx() != nil {
+		return uc.GetCtx()
+	}
+	return bot.ExtContext()
+}
+
+func getTestMessages(tctx *ext.Context, req TestRulesRequest) ([]*tg.Message, error) {
+	chatID, msgID := req.ChatID, req.MessageID
+	single := false
+	if req.Link != "" {
+		linkURL, err := url.Parse(req.Link)
+		if err != nil {
+			return nil, err
+		}
+		single = linkURL.Query().Has("single")
+		if chatID, msgID, err = tgutil.ParseMessageLink(tctx, req.Link); err != nil {
+			return nil, err
+		}
+	}
+	msg, err := tgutil.GetMessageByID(tctx, chatID, msgID)
+	if err != nil {
+		return nil, err
+	}
+	if groupID, isGroup := msg.GetGroupedID(); isGroup && groupID != 0 && !single {
+		return tgutil.GetGroupedMessages(tctx, chatID, msg)
+	}
+	return []*tg.Message{msg}, nil
+}
+
+func newRuleTestResult(run ruleutil.DryRun) RuleTestResult {
+	result := RuleTestResult{
+		FileName:    run.FileName,
+		Applied:     run.Applied,
+		Matches:     make([]MatchedRule, 0, len(run.Result.Matches)),
+		Skip:        run.Target.Skip,
+		Path:        run.Path,
+		Conflict:    run.Target.Conflict.String(),
+		Tags:        run.Target.Extras.Tags,
+		NotifyChats: run.Target.Extras.NotifyChats,
+	}
+	for _, r := range run.Result.Matches {
+		result.Matches = append(result.Matches, MatchedRule{ID: r.ID, Rule: ruleutil.FormatRule(r)})
+	}
+	if run.Target.Storage != nil {
+		result.Storage = run.Target.Storage.Name()
+	}
+	for _, stor := range run.Target.Also {
+		result.Also = append(result.Also, stor.Name())
+	}
+	if run.Err != nil {
+		result.Error = run.Err.Error()
+	}
+	return result
+}
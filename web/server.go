@@ -66,15 +66,28 @@ func (s *Server) setupRoutes() {
 	api.Get("/config", s.handleGetConfig)
 	api.Post("/config", s.handleSaveConfig)
 	api.Post("/config/validate", s.handleValidateConfig)
+	api.Post("/config/reload", s.handleReloadConfig)
 
 	// Storage
 	api.Get("/storages", s.handleGetStorages)
 	api.Post("/storages", s.handleAddStorage)
+	api.Put("/storages/:name", s.handleUpdateStorage)
 	api.Delete("/storages/:name", s.handleDeleteStorage)
 
 	// Tasks
 	api.Get("/tasks", s.handleGetTasks)
+	api.Get("/tasks/history", s.handleGetTaskHistory)
 	api.Delete("/tasks/:id", s.handleCancelTask)
+	api.Post("/tasks/:id/pause", s.handlePauseTask)
+	api.Post("/tasks/:id/resume", s.handleResumeTask)
+	api.Post("/tasks/:id/move", s.handleMoveTask)
+
+	// Rules
+	api.Post("/rules/test", s.handleTestRules)
+
+	// Bandwidth
+	api.Get("/bandwidth", s.handleGetBandwidth)
+	api.Post("/bandwidth", s.handleSetBandwidth)
 
 	// Debug - Message logs
 	api.Get("/debug/messages", s.handleGetMessageLogs)
@@ -1,10 +1,13 @@
 package web
 
 import (
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/kiss2u/SaveAny-Bot/config"
+	"github.com/kiss2u/SaveAny-Bot/core"
 	"github.com/kiss2u/SaveAny-Bot/storage"
 )
 
@@ -51,11 +54,16 @@ func (s *Server) handleGetStorages(c *fiber.Ctx) error {
 
 	// Build storage list
 	storages := make([]map[string]interface{}, 0)
-	for name, st := range storage.Storages {
-		storages = append(storages, map[string]interface{}{
+	for name, st := range storage.GetAllStorages() {
+		entry := map[string]interface{}{
 			"name": name,
 			"type": st.Type().String(),
-		})
+		}
+		// only the storages which can report their capacity are probed
+		if h, ok := storage.GetHealth(name); ok {
+			entry["health"] = h
+		}
+		storages = append(storages, entry)
 	}
 
 	// Cache the result
@@ -64,11 +72,48 @@ func (s *Server) handleGetStorages(c *fiber.Ctx) error {
 	return c.JSON(storages)
 }
 
+// AddStorageRequest adds a storage, Config has the other keys of the storage in the config file, like bandwidth_limit
 type AddStorageRequest struct {
-	Name     string                 `json:"name"`
-	Type     string                 `json:"type"`
-	Enable   bool                   `json:"enable"`
-	Config   map[string]interface{} `json:"config"`
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+	Enable *bool                  `json:"enable"` // defaults to true
+	Config map[string]interface{} `json:"config"`
+	Users  []int64                `json:"users"` // the users who can use the storage, if omitted the users are not changed, so only the ones in blacklist mode can
+}
+
+// UpdateStorageRequest replaces the config of the storage with the name in the path, the users are not changed
+type UpdateStorageRequest struct {
+	Type   string                 `json:"type"`
+	Enable *bool                  `json:"enable"` // defaults to true
+	Config map[string]interface{} `json:"config"`
+}
+
+// rawStorageConfig returns the storage entry like in the config file
+func rawStorageConfig(name, typ string, enable *bool, cfg map[string]interface{}) map[string]interface{} {
+	raw := make(map[string]interface{}, len(cfg)+3)
+	for key, value := range cfg {
+		raw[key] = value
+	}
+	raw["name"] = name
+	raw["type"] = typ
+	raw["enable"] = enable == nil || *enable
+	return raw
+}
+
+// storageErrorStatus returns the status code for the error of changing the storages
+func storageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, config.ErrStorageNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, config.ErrStorageExists), errors.Is(err, config.ErrStorageReferenced):
+		return fiber.StatusConflict
+	case errors.Is(err, config.ErrConfigNotWritable):
+		return fiber.StatusForbidden
+	case errors.Is(err, config.ErrFailedToWrite):
+		return fiber.StatusInternalServerError
+	default:
+		return fiber.StatusBadRequest
+	}
 }
 
 // invalidateStorageCache clears the storage cache
@@ -83,26 +128,57 @@ func (s *Server) handleAddStorage(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
 	}
+	if req.Name == "" || req.Type == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "name and type required"})
+	}
+
+	_, err := core.AddStorage(s.ctx, rawStorageConfig(req.Name, req.Type, req.Enable, req.Config), req.Users)
+	if err != nil {
+		return c.Status(storageErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
 
 	// Invalidate cache when storage is added
 	invalidateStorageCache()
 
-	// TODO: Implement storage addition
-	// This requires changes to config and storage packages
-
 	return c.JSON(fiber.Map{"status": "ok", "message": "storage added"})
 }
 
+func (s *Server) handleUpdateStorage(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if name == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "name required"})
+	}
+	var req UpdateStorageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	if req.Type == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "type required"})
+	}
+
+	// the storage is created again with the new config, the tasks using the old one are not interrupted
+	_, err := core.UpdateStorage(s.ctx, rawStorageConfig(name, req.Type, req.Enable, req.Config))
+	if err != nil {
+		return c.Status(storageErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	invalidateStorageCache()
+
+	return c.JSON(fiber.Map{"status": "ok", "message": "storage updated"})
+}
+
 func (s *Server) handleDeleteStorage(c *fiber.Ctx) error {
 	name := c.Params("name")
 	if name == "" {
 		return c.Status(400).JSON(fiber.Map{"error": "name required"})
 	}
 
+	if err := core.RemoveStorage(s.ctx, name); err != nil {
+		return c.Status(storageErrorStatus(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	// Invalidate cache when storage is deleted
 	invalidateStorageCache()
 
-	// TODO: Implement storage deletion
-
 	return c.JSON(fiber.Map{"status": "ok", "message": "storage deleted"})
 }
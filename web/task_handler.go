@@ -3,6 +3,8 @@ package web
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/kiss2u/SaveAny-Bot/core"
+	"github.com/kiss2u/SaveAny-Bot/database"
+	"github.com/kiss2u/SaveAny-Bot/pkg/queue"
 )
 
 func (s *Server) handleGetTasks(c *fiber.Ctx) error {
@@ -15,6 +17,9 @@ func (s *Server) handleGetTasks(c *fiber.Ctx) error {
 		Status    string `json:"status"`
 		Created   int64  `json:"created"` // Unix timestamp
 		Cancelled bool   `json:"cancelled"`
+		Paused    bool   `json:"paused"`
+		Priority  int    `json:"priority"`
+		UserID    int64  `json:"user_id"`
 	}
 
 	runningTasks := make([]TaskInfo, 0, len(running))
@@ -25,6 +30,9 @@ func (s *Server) handleGetTasks(c *fiber.Ctx) error {
 			Status:    "running",
 			Created:   t.Created.Unix(),
 			Cancelled: t.Cancelled,
+			Paused:    t.Paused,
+			Priority:  int(t.Priority),
+			UserID:    t.Owner,
 		})
 	}
 
@@ -36,6 +44,9 @@ func (s *Server) handleGetTasks(c *fiber.Ctx) error {
 			Status:    "queued",
 			Created:   t.Created.Unix(),
 			Cancelled: t.Cancelled,
+			Paused:    t.Paused,
+			Priority:  int(t.Priority),
+			UserID:    t.Owner,
 		})
 	}
 
@@ -62,3 +73,114 @@ func (s *Server) handleCancelTask(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{"status": "ok"})
 }
+
+func (s *Server) handlePauseTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "id required"})
+	}
+	if err := core.PauseTask(s.ctx, id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+func (s *Server) handleResumeTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "id required"})
+	}
+	if err := core.ResumeTask(s.ctx, id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+type MoveTaskRequest struct {
+	To string `json:"to"` // top, up or down
+}
+
+func (s *Server) handleMoveTask(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "id required"})
+	}
+	var req MoveTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	to := queue.MovePosition(req.To)
+	switch to {
+	case queue.MoveTop, queue.MoveUp, queue.MoveDown:
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "to must be one of top, up, down"})
+	}
+	if err := core.MoveTask(s.ctx, id, to); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// handleGetTaskHistory returns the finished tasks, newest first.
+// Query: page, page_size, user_id, status, type, storage, tag
+func (s *Server) handleGetTaskHistory(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := c.QueryInt("page_size", 20)
+	if pageSize < 1 || pageSize > 100 {
+		return c.Status(400).JSON(fiber.Map{"error": "page_size must be between 1 and 100"})
+	}
+	filter := database.TaskHistoryFilter{
+		UserID:      int64(c.QueryInt("user_id")),
+		Status:      c.Query("status"),
+		Type:        c.Query("type"),
+		StorageName: c.Query("storage"),
+		Tag:         c.Query("tag"),
+	}
+	histories, total, err := database.GetTaskHistories(s.ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	type HistoryInfo struct {
+		ID          string   `json:"id"`
+		Type        string   `json:"type"`
+		Title       string   `json:"title"`
+		UserID      int64    `json:"user_id"`
+		StorageName string   `json:"storage_name"`
+		StoragePath string   `json:"storage_path"`
+		Bytes       int64    `json:"bytes"`
+		Duration    int64    `json:"duration"` // milliseconds
+		Status      string   `json:"status"`
+		Error       string   `json:"error,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+		Finished    int64    `json:"finished"` // Unix timestamp
+	}
+
+	items := make([]HistoryInfo, 0, len(histories))
+	for _, h := range histories {
+		items = append(items, HistoryInfo{
+			ID:          h.TaskID,
+			Type:        h.Type,
+			Title:       h.Title,
+			UserID:      h.UserID,
+			StorageName: h.StorageName,
+			StoragePath: h.StoragePath,
+			Bytes:       h.Bytes,
+			Duration:    h.Duration.Milliseconds(),
+			Status:      h.Status,
+			Error:       h.Error,
+			Tags:        h.Tags,
+			Finished:    h.CreatedAt.Unix(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"items":     items,
+		"total":     total,
+		"page":      page,
+		"page_size": pageSize,
+	})
+}